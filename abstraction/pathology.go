@@ -0,0 +1,252 @@
+// Package abstraction implements diagnostics for strategies trained in an
+// abstracted game, in which several InfoSets of the real game ("ground"
+// InfoSets) share a single abstract InfoSet and are therefore forced to
+// play the same strategy.
+package abstraction
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// GroundKey returns the key of the un-abstracted InfoSet of the acting
+// player at a player node. The abstract InfoSet is the one returned by
+// node.InfoSet(node.Player()), which is used to look up the node's policy.
+type GroundKey func(node cfr.GameTreeNode) string
+
+// InfoSetReport summarizes the pathology indicators of a single abstract InfoSet.
+//
+// Counterfactual values and regrets are computed with both players following
+// the average strategy of the profile.
+type InfoSetReport struct {
+	Key    string
+	Player int
+	// The number of distinct ground InfoSets that share this abstract InfoSet.
+	NumGroundInfoSets int
+	// The probability of reaching this InfoSet.
+	RealizationWeight float64
+	// The fraction of RealizationWeight in ground InfoSets for which the
+	// best action of the abstract InfoSet is not a best action.
+	ConflictWeight float64
+	// The immediate counterfactual regret of the abstract InfoSet.
+	// This can be reduced by further training.
+	TrainingRegret float64
+	// The sum of the immediate counterfactual regrets of the ground InfoSets,
+	// less TrainingRegret. This cannot be reduced without refining the abstraction.
+	AbstractionRegret float64
+}
+
+// Report summarizes the pathology indicators of all abstract InfoSets.
+//
+// Since the amount a player could gain by deviating from their strategy in
+// the real game is bounded by the sum of the immediate counterfactual regrets
+// of their ground InfoSets, TrainingRegret[p] + AbstractionRegret[p] is an upper
+// bound on the exploitability of player p's strategy. Comparing the two terms
+// indicates whether poor play stems from insufficient training or from the abstraction.
+type Report struct {
+	// Abstract InfoSets, in decreasing order of AbstractionRegret.
+	InfoSets []InfoSetReport
+	// The total TrainingRegret and AbstractionRegret of each player's InfoSets.
+	TrainingRegret    [2]float64
+	AbstractionRegret [2]float64
+	// The fraction of the total realization weight of all InfoSets
+	// that is in conflicting ground InfoSets.
+	ConflictWeight float64
+}
+
+// Analyze computes abstraction pathology indicators for the average strategy
+// of the given profile by enumerating the game tree rooted at root.
+//
+// This is only feasible for games small enough to be walked exhaustively,
+// such as a reduced version of the game of interest with the same abstraction.
+func Analyze(root cfr.GameTreeNode, profile cfr.StrategyProfile, groundKey GroundKey) Report {
+	a := &analyzer{
+		profile:   profile,
+		groundKey: groundKey,
+		infoSets:  make(map[string]*infoSetStats),
+	}
+
+	a.walk(root, [2]float64{1.0, 1.0}, 1.0)
+	return a.report()
+}
+
+type infoSetStats struct {
+	player            int
+	realizationWeight float64
+	strategy          []float32
+	ground            map[string]*groundStats
+}
+
+type groundStats struct {
+	realizationWeight float64
+	actionValues      []float64 // Counterfactual value of each action.
+}
+
+type analyzer struct {
+	profile   cfr.StrategyProfile
+	groundKey GroundKey
+	infoSets  map[string]*infoSetStats
+}
+
+// walk returns the expected utility of node for each player,
+// and accumulates counterfactual values at each player node.
+func (a *analyzer) walk(node cfr.GameTreeNode, reach [2]float64, chanceReach float64) [2]float64 {
+	var ev [2]float64
+	switch node.Type() {
+	case cfr.TerminalNodeType:
+		ev[0] = node.Utility(0)
+		ev[1] = node.Utility(1)
+	case cfr.ChanceNodeType:
+		for i := 0; i < node.NumChildren(); i++ {
+			p := node.GetChildProbability(i)
+			childEV := a.walk(node.GetChild(i), reach, chanceReach*p)
+			ev[0] += p * childEV[0]
+			ev[1] += p * childEV[1]
+		}
+	default:
+		ev = a.handlePlayerNode(node, reach, chanceReach)
+	}
+
+	node.Close()
+	return ev
+}
+
+func (a *analyzer) handlePlayerNode(node cfr.GameTreeNode, reach [2]float64, chanceReach float64) [2]float64 {
+	nChildren := node.NumChildren()
+	if nChildren == 1 { // Fast path for trivial nodes with no real choice.
+		return a.walk(node.GetChild(0), reach, chanceReach)
+	}
+
+	player := node.Player()
+	strategy := a.profile.GetPolicy(node).GetAverageStrategy()
+	g := a.getGroundStats(node, strategy)
+	g.realizationWeight += chanceReach * reach[0] * reach[1]
+	cfReach := chanceReach * reach[1-player]
+
+	var ev [2]float64
+	for i := 0; i < nChildren; i++ {
+		p := float64(strategy[i])
+		childReach := reach
+		childReach[player] *= p
+		childEV := a.walk(node.GetChild(i), childReach, chanceReach)
+		g.actionValues[i] += cfReach * childEV[player]
+		ev[0] += p * childEV[0]
+		ev[1] += p * childEV[1]
+	}
+
+	return ev
+}
+
+func (a *analyzer) getGroundStats(node cfr.GameTreeNode, strategy []float32) *groundStats {
+	player := node.Player()
+	key := node.InfoSet(player).Key()
+	is, ok := a.infoSets[key]
+	if !ok {
+		is = &infoSetStats{
+			player:   player,
+			strategy: append([]float32(nil), strategy...),
+			ground:   make(map[string]*groundStats),
+		}
+
+		a.infoSets[key] = is
+	} else if len(is.strategy) != node.NumChildren() {
+		panic(fmt.Errorf("infoset has n_actions=%v but node has n_children=%v: %v",
+			len(is.strategy), node.NumChildren(), node))
+	}
+
+	groundKey := a.groundKey(node)
+	g, ok := is.ground[groundKey]
+	if !ok {
+		g = &groundStats{actionValues: make([]float64, len(strategy))}
+		is.ground[groundKey] = g
+	}
+
+	return g
+}
+
+func (a *analyzer) report() Report {
+	var result Report
+	var totalWeight, conflictWeight float64
+	for key, is := range a.infoSets {
+		r := InfoSetReport{
+			Key:               key,
+			Player:            is.player,
+			NumGroundInfoSets: len(is.ground),
+		}
+
+		actionValues := make([]float64, len(is.strategy))
+		var groundRegret float64
+		for _, g := range is.ground {
+			r.RealizationWeight += g.realizationWeight
+			groundRegret += immediateRegret(is.strategy, g.actionValues)
+			for i, v := range g.actionValues {
+				actionValues[i] += v
+			}
+		}
+
+		r.TrainingRegret = immediateRegret(is.strategy, actionValues)
+		r.AbstractionRegret = groundRegret - r.TrainingRegret
+		if r.AbstractionRegret < 0 { // Floating point error.
+			r.AbstractionRegret = 0
+		}
+
+		best := argmax(actionValues)
+		var conflicting float64
+		for _, g := range is.ground {
+			if g.actionValues[best] < g.actionValues[argmax(g.actionValues)]-tol {
+				conflicting += g.realizationWeight
+			}
+		}
+
+		if r.RealizationWeight > 0 {
+			r.ConflictWeight = conflicting / r.RealizationWeight
+		}
+
+		totalWeight += r.RealizationWeight
+		conflictWeight += conflicting
+		result.TrainingRegret[r.Player] += r.TrainingRegret
+		result.AbstractionRegret[r.Player] += r.AbstractionRegret
+		result.InfoSets = append(result.InfoSets, r)
+	}
+
+	if totalWeight > 0 {
+		result.ConflictWeight = conflictWeight / totalWeight
+	}
+
+	sort.Slice(result.InfoSets, func(i, j int) bool {
+		if result.InfoSets[i].AbstractionRegret != result.InfoSets[j].AbstractionRegret {
+			return result.InfoSets[i].AbstractionRegret > result.InfoSets[j].AbstractionRegret
+		}
+
+		return result.InfoSets[i].Key < result.InfoSets[j].Key
+	})
+
+	return result
+}
+
+// Tolerance used to decide whether two action values are tied.
+const tol = 1e-9
+
+// immediateRegret returns the gain from switching to the best action
+// over playing the given strategy.
+func immediateRegret(strategy []float32, actionValues []float64) float64 {
+	var ev float64
+	for i, p := range strategy {
+		ev += float64(p) * actionValues[i]
+	}
+
+	return actionValues[argmax(actionValues)] - ev
+}
+
+func argmax(v []float64) int {
+	best := 0
+	for i, x := range v {
+		if x > v[best] {
+			best = i
+		}
+	}
+
+	return best
+}
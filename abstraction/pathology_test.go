@@ -0,0 +1,103 @@
+package abstraction
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+// mergedNode abstracts Kuhn poker by merging the Jack and Queen.
+type mergedNode struct {
+	cfr.GameTreeNode
+}
+
+func (n mergedNode) GetChild(i int) cfr.GameTreeNode {
+	return mergedNode{n.GameTreeNode.GetChild(i)}
+}
+
+func (n mergedNode) InfoSet(player int) cfr.InfoSet {
+	return mergedInfoSet{n.GameTreeNode.InfoSet(player)}
+}
+
+type mergedInfoSet struct {
+	cfr.InfoSet
+}
+
+func (is mergedInfoSet) Key() string {
+	key := is.InfoSet.Key()
+	if strings.HasSuffix(key, "-J") || strings.HasSuffix(key, "-Q") {
+		return key[:len(key)-1] + "JQ"
+	}
+
+	return key
+}
+
+func groundKey(node cfr.GameTreeNode) string {
+	if n, ok := node.(mergedNode); ok {
+		node = n.GameTreeNode
+	}
+
+	return node.InfoSet(node.Player()).Key()
+}
+
+func train(root cfr.GameTreeNode, nIter int) *cfr.PolicyTable {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	for i := 0; i < nIter; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+
+	return policy
+}
+
+func TestAnalyze_NoAbstraction(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := train(root, 1000)
+	report := Analyze(root, policy, groundKey)
+	if len(report.InfoSets) != 12 {
+		t.Errorf("expected 12 infosets, got %d", len(report.InfoSets))
+	}
+
+	for _, is := range report.InfoSets {
+		if is.NumGroundInfoSets != 1 || is.AbstractionRegret != 0 || is.ConflictWeight != 0 {
+			t.Errorf("expected no abstraction pathology, got %+v", is)
+		}
+	}
+
+	for player, r := range report.TrainingRegret {
+		if r > 0.01 {
+			t.Errorf("expected small training regret for player %d, got %v", player, r)
+		}
+	}
+}
+
+func TestAnalyze_MergedCards(t *testing.T) {
+	root := mergedNode{kuhn.NewGame()}
+	policy := train(root, 1000)
+	report := Analyze(root, policy, groundKey)
+	if len(report.InfoSets) != 8 {
+		t.Errorf("expected 8 infosets, got %d", len(report.InfoSets))
+	}
+
+	for _, is := range report.InfoSets {
+		t.Logf("%+v", is)
+	}
+
+	for player, r := range report.TrainingRegret {
+		if r > 0.05 {
+			t.Errorf("expected small training regret for player %d, got %v", player, r)
+		}
+
+		if report.AbstractionRegret[player] <= r {
+			t.Errorf("expected abstraction regret to dominate for player %d, got %v <= %v",
+				player, report.AbstractionRegret[player], r)
+		}
+	}
+
+	if worst := report.InfoSets[0]; worst.NumGroundInfoSets != 2 || worst.ConflictWeight == 0 {
+		t.Errorf("expected merged infoset with conflicts, got %+v", worst)
+	}
+}
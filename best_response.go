@@ -0,0 +1,205 @@
+package cfr
+
+// BestResponse is a pure strategy for one player that maximizes their
+// expected utility against a fixed strategy of the other player.
+type BestResponse struct {
+	player int
+	// Map of InfoSet Key -> best action.
+	actions map[string]int
+	value   float64
+}
+
+// ComputeBestResponse computes an exact best response for player to the strategy
+// of the other player, which is given by strategy at each of their nodes.
+// It requires a single full traversal of the game tree rooted at root, and memory
+// proportional to the number of InfoSets of player.
+//
+// With perfect recall, the counterfactual value of each of player's InfoSets depends
+// only on the best responses at the InfoSets that follow it. The traversal accumulates
+// the value of terminal nodes reached from each InfoSet-action pair before player acts
+// again, along with the InfoSets that follow it, and the best response is then resolved
+// bottom-up over the tree of InfoSets.
+func ComputeBestResponse(root GameTreeNode, player int, strategy func(node GameTreeNode) []float32) *BestResponse {
+	b := &brBuilder{
+		player:   player,
+		strategy: strategy,
+		infoSets: make(map[string]*brInfoSet),
+		root:     newBRInfoSet(1),
+	}
+
+	b.walk(root, b.root, 0, 1.0)
+	br := &BestResponse{
+		player:  player,
+		actions: make(map[string]int, len(b.infoSets)),
+	}
+
+	br.value = b.resolve(b.root, br.actions)
+	return br
+}
+
+// Exploitability returns the average over both players of the value of a
+// best response to the other player's average strategy in profile. In a
+// two-player zero-sum game this is zero for a Nash equilibrium.
+func Exploitability(root GameTreeNode, profile StrategyProfile) float64 {
+	return StrategyExploitability(root, func(node GameTreeNode) []float32 {
+		return profile.GetPolicy(node).GetAverageStrategy()
+	})
+}
+
+// StrategyExploitability is as Exploitability, for the strategy of both
+// players given by strategy at each of their nodes.
+func StrategyExploitability(root GameTreeNode, strategy func(node GameTreeNode) []float32) float64 {
+	br0 := ComputeBestResponse(root, 0, strategy)
+	br1 := ComputeBestResponse(root, 1, strategy)
+	return (br0.Value() + br1.Value()) / 2
+}
+
+// Player returns the player for whom this is a best response.
+func (br *BestResponse) Player() int {
+	return br.player
+}
+
+// Value returns the expected utility of the best response.
+func (br *BestResponse) Value() float64 {
+	return br.value
+}
+
+// Action returns the best action at a node of the best responding player.
+// InfoSets that cannot be reached given the other player's strategy may
+// play any action, and 0 is returned.
+func (br *BestResponse) Action(node GameTreeNode) int {
+	return br.actions[nodeKey(node)]
+}
+
+// brInfoSet accumulates the counterfactual value of each action at an InfoSet
+// of the best responding player, excluding the value of the InfoSets that follow.
+type brInfoSet struct {
+	key       string
+	immediate []float64
+	// The InfoSets of the best responding player that follow each action,
+	// with the number of histories through which each one is reached.
+	children []map[*brInfoSet]int
+}
+
+func newBRInfoSet(nActions int) *brInfoSet {
+	is := &brInfoSet{
+		immediate: make([]float64, nActions),
+		children:  make([]map[*brInfoSet]int, nActions),
+	}
+
+	for i := range is.children {
+		is.children[i] = make(map[*brInfoSet]int)
+	}
+
+	return is
+}
+
+type brBuilder struct {
+	player   int
+	strategy func(node GameTreeNode) []float32
+	infoSets map[string]*brInfoSet
+	// A pseudo-InfoSet with a single action that precedes all others.
+	root *brInfoSet
+
+	// If non-nil, the state required by IncrementalBestResponse is recorded:
+	// the histories of each InfoSet of the other player, and the strategy
+	// that was used for it. See brHistory.
+	histories  map[string]map[string]*brHistory
+	strategies map[string][]float32
+	// The child indices leading from the root to the current node.
+	path []int
+	// Whether the current walk is removing (rather than adding) values.
+	removing bool
+}
+
+// walk accumulates the values of terminal nodes beneath node into the InfoSet-action
+// pair of the last decision of the best responding player, weighted by reach,
+// the probability of reaching node due to chance and the other player.
+func (b *brBuilder) walk(node GameTreeNode, parent *brInfoSet, action int, reach float64) {
+	switch node.Type() {
+	case TerminalNodeType:
+		parent.immediate[action] += reach * node.Utility(b.player)
+	case ChanceNodeType:
+		for i := 0; i < node.NumChildren(); i++ {
+			p := node.GetChildProbability(i)
+			b.walkChild(node, i, parent, action, reach*p)
+		}
+	default:
+		b.handlePlayerNode(node, parent, action, reach)
+	}
+
+	node.Close()
+}
+
+func (b *brBuilder) handlePlayerNode(node GameTreeNode, parent *brInfoSet, action int, reach float64) {
+	nChildren := node.NumChildren()
+	if nChildren == 1 {
+		// Optimization to skip trivial nodes with no real choice.
+		b.walkChild(node, 0, parent, action, reach)
+		return
+	}
+
+	if node.Player() != b.player {
+		strategy := b.opponentStrategy(node, parent, action, reach)
+		b.walkOpponent(node, strategy, parent, action, reach)
+		return
+	}
+
+	key := nodeKey(node)
+	is, ok := b.infoSets[key]
+	if !ok {
+		is = newBRInfoSet(nChildren)
+		is.key = key
+		b.infoSets[key] = is
+	}
+
+	if b.removing {
+		parent.children[action][is]--
+		if parent.children[action][is] == 0 {
+			delete(parent.children[action], is)
+		}
+	} else {
+		parent.children[action][is]++
+	}
+
+	for i := 0; i < nChildren; i++ {
+		b.walkChild(node, i, is, i, reach)
+	}
+}
+
+// walkOpponent walks the children of a node of the other player that are
+// played with positive probability in strategy.
+func (b *brBuilder) walkOpponent(node GameTreeNode, strategy []float32, parent *brInfoSet, action int, reach float64) {
+	for i, p := range strategy {
+		if p > 0 {
+			b.walkChild(node, i, parent, action, reach*float64(p))
+		}
+	}
+}
+
+func (b *brBuilder) walkChild(node GameTreeNode, i int, parent *brInfoSet, action int, reach float64) {
+	b.path = append(b.path, i)
+	b.walk(node.GetChild(i), parent, action, reach)
+	b.path = b.path[:len(b.path)-1]
+}
+
+// resolve returns the value of the best response at the given InfoSet
+// and records the best action of it and all following InfoSets.
+func (b *brBuilder) resolve(is *brInfoSet, actions map[string]int) float64 {
+	best, bestValue := 0, 0.0
+	for i, v := range is.immediate {
+		for child := range is.children[i] {
+			v += b.resolve(child, actions)
+		}
+
+		if i == 0 || v > bestValue {
+			best, bestValue = i, v
+		}
+	}
+
+	if is != b.root {
+		actions[is.key] = best
+	}
+
+	return bestValue
+}
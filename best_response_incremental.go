@@ -0,0 +1,200 @@
+package cfr
+
+import (
+	"encoding/binary"
+)
+
+// IncrementalBestResponse is a BestResponse that can be updated after the
+// strategies of a small number of the other player's InfoSets have changed,
+// without recomputing it from scratch.
+//
+// In addition to the state of ComputeBestResponse, it records the path from the
+// root to every reachable history of the other player, along with the strategy
+// used at each of their InfoSets. Update then walks only the subtrees beneath the
+// histories of the changed InfoSets, subtracting their values under the old
+// strategy and adding them under the new one, before resolving the best response
+// over the tree of InfoSets of player (which does not traverse the game tree).
+// Its memory use is therefore proportional to the number of histories of the
+// other player, times their depth.
+type IncrementalBestResponse struct {
+	BestResponse
+	root GameTreeNode
+	b    *brBuilder
+}
+
+// brHistory is a single history (node) of an InfoSet of the other player,
+// recorded so that the subtree beneath it may be walked again.
+type brHistory struct {
+	// The child indices leading from the root to the node.
+	path []int
+	// The InfoSet-action pair of the last decision of the best responding
+	// player before the node, and the reach of the node, as passed to walk.
+	parent *brInfoSet
+	action int
+	reach  float64
+}
+
+// NewIncrementalBestResponse computes an exact best response for player to the
+// strategy of the other player, as ComputeBestResponse, that may later be updated.
+func NewIncrementalBestResponse(root GameTreeNode, player int, strategy func(node GameTreeNode) []float32) *IncrementalBestResponse {
+	b := &brBuilder{
+		player:     player,
+		strategy:   strategy,
+		infoSets:   make(map[string]*brInfoSet),
+		root:       newBRInfoSet(1),
+		histories:  make(map[string]map[string]*brHistory),
+		strategies: make(map[string][]float32),
+	}
+
+	b.walk(root, b.root, 0, 1.0)
+	ibr := &IncrementalBestResponse{
+		BestResponse: BestResponse{
+			player:  player,
+			actions: make(map[string]int, len(b.infoSets)),
+		},
+		root: root,
+		b:    b,
+	}
+
+	ibr.value = b.resolve(b.root, ibr.actions)
+	return ibr
+}
+
+// Update updates the best response after the strategies of the given InfoSets
+// of the other player have changed, and returns its new value. The new strategies
+// are obtained from the strategy function passed to NewIncrementalBestResponse.
+// Keys of InfoSets that do not belong to the other player are ignored, so the
+// same keys may be passed to the best responses of both players.
+func (ibr *IncrementalBestResponse) Update(changed []string) float64 {
+	b := ibr.b
+	for _, key := range changed {
+		histories := b.histories[key]
+		if len(histories) == 0 {
+			// The InfoSet is not currently reachable, and its strategy
+			// will be fetched again if it becomes so.
+			delete(b.strategies, key)
+			continue
+		}
+
+		oldStrategy := b.strategies[key]
+		var newStrategy []float32
+		for _, h := range histories {
+			nodes := navigate(ibr.root, h.path)
+			node := nodes[len(nodes)-1]
+			if newStrategy == nil {
+				newStrategy = append([]float32(nil), b.strategy(node)...)
+			}
+
+			b.path = append(b.path[:0], h.path...)
+			b.removing = true
+			b.walkOpponent(node, oldStrategy, h.parent, h.action, -h.reach)
+			b.removing = false
+			b.walkOpponent(node, newStrategy, h.parent, h.action, h.reach)
+
+			for i := len(nodes) - 1; i >= 0; i-- {
+				nodes[i].Close()
+			}
+		}
+
+		b.strategies[key] = newStrategy
+	}
+
+	ibr.value = b.resolve(b.root, ibr.actions)
+	return ibr.value
+}
+
+// opponentStrategy returns the strategy of the other player at node. If the state
+// of IncrementalBestResponse is being recorded, it returns the strategy recorded
+// for the node's InfoSet, and records (or removes) the node as one of its histories.
+func (b *brBuilder) opponentStrategy(node GameTreeNode, parent *brInfoSet, action int, reach float64) []float32 {
+	if b.histories == nil {
+		return b.strategy(node)
+	}
+
+	key := nodeKey(node)
+	strategy, ok := b.strategies[key]
+	if !ok {
+		strategy = append([]float32(nil), b.strategy(node)...)
+		b.strategies[key] = strategy
+	}
+
+	histories := b.histories[key]
+	pathKey := encodePath(b.path)
+	if b.removing {
+		delete(histories, pathKey)
+	} else {
+		if histories == nil {
+			histories = make(map[string]*brHistory)
+			b.histories[key] = histories
+		}
+
+		histories[pathKey] = &brHistory{
+			path:   append([]int(nil), b.path...),
+			parent: parent,
+			action: action,
+			reach:  reach,
+		}
+	}
+
+	return strategy
+}
+
+// encodePath returns a compact string representation of path, for use as a map key.
+func encodePath(path []int) string {
+	buf := make([]byte, 0, len(path))
+	for _, i := range path {
+		buf = binary.AppendUvarint(buf, uint64(i))
+	}
+
+	return string(buf)
+}
+
+// navigate returns the nodes along path from root, ending with the node
+// that it leads to. They must be closed by the caller.
+func navigate(root GameTreeNode, path []int) []GameTreeNode {
+	nodes := make([]GameTreeNode, 0, len(path)+1)
+	nodes = append(nodes, root)
+	for _, i := range path {
+		nodes = append(nodes, nodes[len(nodes)-1].GetChild(i))
+	}
+
+	return nodes
+}
+
+// ExploitabilityTracker maintains the exploitability of the average strategy
+// of a StrategyProfile with an IncrementalBestResponse for each player, so that
+// it may be checked frequently during training when only a few InfoSets
+// have changed since the last check.
+type ExploitabilityTracker struct {
+	brs [2]*IncrementalBestResponse
+}
+
+// NewExploitabilityTracker returns a new ExploitabilityTracker for the
+// average strategy of profile in the game rooted at root.
+func NewExploitabilityTracker(root GameTreeNode, profile StrategyProfile) *ExploitabilityTracker {
+	averageStrategy := func(node GameTreeNode) []float32 {
+		return profile.GetPolicy(node).GetAverageStrategy()
+	}
+
+	return &ExploitabilityTracker{
+		brs: [2]*IncrementalBestResponse{
+			NewIncrementalBestResponse(root, 0, averageStrategy),
+			NewIncrementalBestResponse(root, 1, averageStrategy),
+		},
+	}
+}
+
+// Update updates the exploitability after the average strategies of the
+// InfoSets with the given keys have changed, and returns the new value.
+func (t *ExploitabilityTracker) Update(changed []string) float64 {
+	for _, br := range t.brs {
+		br.Update(changed)
+	}
+
+	return t.Exploitability()
+}
+
+// Exploitability returns the current exploitability, as defined by Exploitability.
+func (t *ExploitabilityTracker) Exploitability() float64 {
+	return (t.brs[0].Value() + t.brs[1].Value()) / 2
+}
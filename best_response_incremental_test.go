@@ -0,0 +1,102 @@
+package cfr_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestIncrementalBestResponse(t *testing.T) {
+	root := kuhn.NewGame()
+	rng := rand.New(rand.NewSource(123))
+	strategies := make(map[string][]float32)
+	var keys []string
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		if _, ok := strategies[key]; !ok {
+			strategies[key] = randomStrategy(rng, node.NumChildren())
+			keys = append(keys, key)
+		}
+	})
+
+	strategy := func(node cfr.GameTreeNode) []float32 {
+		return strategies[node.InfoSet(node.Player()).Key()]
+	}
+
+	brs := []*cfr.IncrementalBestResponse{
+		cfr.NewIncrementalBestResponse(root, 0, strategy),
+		cfr.NewIncrementalBestResponse(root, 1, strategy),
+	}
+
+	for i := 0; i < 20; i++ {
+		changed := []string{keys[rng.Intn(len(keys))], keys[rng.Intn(len(keys))]}
+		for _, key := range changed {
+			// Include actions that are never played, which are not walked.
+			strategy := make([]float32, len(strategies[key]))
+			strategy[rng.Intn(len(strategy))] = 1
+			if rng.Intn(2) == 0 {
+				strategy = randomStrategy(rng, len(strategy))
+			}
+
+			strategies[key] = strategy
+		}
+
+		for player, br := range brs {
+			got := br.Update(changed)
+			expected := cfr.ComputeBestResponse(root, player, strategy).Value()
+			if math.Abs(got-expected) > 1e-6 {
+				t.Errorf("update %d: expected best response value %v for player %d, got %v",
+					i, expected, player, got)
+			}
+		}
+	}
+}
+
+func TestExploitabilityTracker(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	opt.Run(root)
+	policy.Update()
+
+	tracker := cfr.NewExploitabilityTracker(root, policy)
+	var keys []string
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() == cfr.PlayerNodeType {
+			keys = append(keys, node.InfoSet(node.Player()).Key())
+		}
+	})
+
+	for i := 0; i < 10; i++ {
+		opt.Run(root)
+		policy.Update()
+		got := tracker.Update(keys)
+		expected := cfr.Exploitability(root, policy)
+		if math.Abs(got-expected) > 1e-6 {
+			t.Errorf("iteration %d: expected exploitability %v, got %v", i, expected, got)
+		}
+	}
+}
+
+func randomStrategy(rng *rand.Rand, n int) []float32 {
+	strategy := make([]float32, n)
+	var total float32
+	for i := range strategy {
+		strategy[i] = rng.Float32()
+		total += strategy[i]
+	}
+
+	for i := range strategy {
+		strategy[i] /= total
+	}
+
+	return strategy
+}
@@ -0,0 +1,24 @@
+package cfr_test
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestExploitability(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	uniform := cfr.Exploitability(kuhn.NewGame(), policy)
+	opt := cfr.New(policy)
+	runCFR(t, opt, policy, 10000)
+	exploitability := cfr.Exploitability(kuhn.NewGame(), policy)
+	t.Logf("Exploitability: uniform = %.4f, after CFR = %.4f", uniform, exploitability)
+	if exploitability < 0 || exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+
+	if uniform < 0.1 {
+		t.Errorf("expected uniform strategy to be exploitable, got %v", uniform)
+	}
+}
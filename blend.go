@@ -0,0 +1,132 @@
+package cfr
+
+import (
+	"fmt"
+
+	"github.com/timpalpant/go-cfr/internal/f32"
+	"github.com/timpalpant/go-cfr/internal/policy"
+)
+
+// BlendMode selects how the policies of several PolicyTables are combined.
+type BlendMode int
+
+const (
+	// BlendAverageStrategies takes the weighted average of the (normalized)
+	// average strategy of each table at each InfoSet, so that every table
+	// contributes in proportion to its weight regardless of how long it was trained.
+	// This is appropriate for ensembling runs trained with different seeds.
+	BlendAverageStrategies BlendMode = iota
+	// BlendSums adds the weighted regret and strategy sums of each table,
+	// as though they had been accumulated in a single run. Tables that were
+	// trained for longer contribute more. This is appropriate for merging
+	// updates to a blueprint trained on different parts of the game.
+	BlendSums
+)
+
+// BlendPolicyTables combines the given PolicyTables into a new PolicyTable
+// with the given params. Each table is weighted by the corresponding weight,
+// and InfoSets are only blended over the tables that contain them.
+//
+// In both modes, regret sums are combined with the given weights (normalized
+// to sum to 1 in BlendAverageStrategies mode). With BlendAverageStrategies the
+// blended average strategy is rescaled to the weighted average magnitude of the
+// tables' strategy sums, so that training may be resumed from the result.
+// The iteration of the result is that of the most trained table.
+func BlendPolicyTables(params DiscountParams, mode BlendMode, tables []*PolicyTable, weights []float32) (*PolicyTable, error) {
+	if len(tables) != len(weights) {
+		return nil, fmt.Errorf("got %d weights for %d tables", len(weights), len(tables))
+	}
+
+	pt := NewPolicyTable(params)
+	for _, table := range tables {
+		if table.iter > pt.iter {
+			pt.iter = table.iter
+			pt.strategyWeight = table.strategyWeight
+		}
+	}
+
+	if mode == BlendSums {
+		return pt, blendSums(pt, tables, weights)
+	}
+
+	return pt, blendAverageStrategies(pt, tables, weights)
+}
+
+func blendSums(pt *PolicyTable, tables []*PolicyTable, weights []float32) error {
+	for i, table := range tables {
+		w := weights[i]
+		var err error
+		table.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+			var np *policy.Policy
+			np, err = pt.getOrCreatePolicy(key, p.NumActions())
+			if err != nil {
+				return false
+			}
+
+			np.AddWeighted(w, p)
+			return true
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	numInfosets.Set(int64(pt.policiesByKey.Len()))
+	return nil
+}
+
+func blendAverageStrategies(pt *PolicyTable, tables []*PolicyTable, weights []float32) error {
+	// Total weight of the tables containing each InfoSet,
+	// and the weighted sum of their total strategy sums.
+	totalWeight := make(map[string]float32)
+	totalStrategySum := make(map[string]float32)
+	for i, table := range tables {
+		w := weights[i]
+		var err error
+		table.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+			var np *policy.Policy
+			np, err = pt.getOrCreatePolicy(key, p.NumActions())
+			if err != nil {
+				return false
+			}
+
+			np.AddWeightedSums(w, p.GetRegretSum(), w, p.GetAverageStrategy())
+			totalWeight[key] += w
+			totalStrategySum[key] += w * f32.Sum(p.GetStrategySum())
+			return true
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// Normalize by the total weight of each InfoSet and rescale the average strategy.
+	pt.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+		w := totalWeight[key]
+		if w == 0 {
+			return true
+		}
+
+		// The blended average strategy sums to w.
+		p.ScaleSums(1.0/w, totalStrategySum[key]/(w*w))
+		return true
+	})
+
+	numInfosets.Set(int64(pt.policiesByKey.Len()))
+	return nil
+}
+
+func (pt *PolicyTable) getOrCreatePolicy(key string, nActions int) (*policy.Policy, error) {
+	np, ok := pt.policiesByKey.Get(key)
+	if !ok {
+		np = pt.newPolicy(nActions)
+		pt.policiesByKey.Put(key, np)
+	} else if np.NumActions() != nActions {
+		return nil, fmt.Errorf("cannot blend infoset %q with n_actions=%v into n_actions=%v",
+			key, nActions, np.NumActions())
+	}
+
+	return np, nil
+}
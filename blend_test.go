@@ -0,0 +1,58 @@
+package cfr_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/sampling"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestBlendPolicyTables(t *testing.T) {
+	root := kuhn.NewGame()
+	var tables []*cfr.PolicyTable
+	for _, seed := range []int64{1, 2} {
+		rand.Seed(seed)
+		policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+		opt := cfr.NewGeneralizedSampling(policy, sampling.NewOutcomeSampler(0.6))
+		runCFR(t, opt, policy, 1000)
+		tables = append(tables, policy)
+	}
+
+	weights := []float32{0.25, 0.75}
+	averaged, err := cfr.BlendPolicyTables(cfr.DiscountParams{}, cfr.BlendAverageStrategies, tables, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summed, err := cfr.BlendPolicyTables(cfr.DiscountParams{}, cfr.BlendSums, tables, weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		s0 := tables[0].GetPolicy(node).GetAverageStrategy()
+		s1 := tables[1].GetPolicy(node).GetAverageStrategy()
+		avg := averaged.GetPolicy(node).GetAverageStrategy()
+		for i := range avg {
+			if expected := 0.25*s0[i] + 0.75*s1[i]; math.Abs(float64(avg[i]-expected)) > 1e-5 {
+				t.Errorf("expected blended average strategy %v, got %v", expected, avg[i])
+			}
+		}
+
+		if summed.GetPolicy(node).IsEmpty() {
+			t.Errorf("expected summed policy for node: %v", node)
+		}
+	})
+
+	if _, err := cfr.BlendPolicyTables(cfr.DiscountParams{}, cfr.BlendSums, tables, weights[:1]); err == nil {
+		t.Error("expected error with mismatched weights")
+	}
+}
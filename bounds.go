@@ -0,0 +1,48 @@
+package cfr
+
+import (
+	"fmt"
+)
+
+// Tolerance for values that exceed the declared utility bounds due to
+// float32 rounding, as a fraction of the range of utilities.
+const boundsTolerance = 1e-4
+
+// CheckUtilityBounds returns an error if value is outside the utility bounds
+// declared by node (with a small tolerance for rounding error), or nil if it is
+// within them or node does not implement UtilityBoundedNode.
+func CheckUtilityBounds(node GameTreeNode, value float64) error {
+	bounded, ok := node.(UtilityBoundedNode)
+	if !ok {
+		return nil
+	}
+
+	lo, hi := bounded.UtilityBounds()
+	eps := boundsTolerance * (hi - lo)
+	if !(value >= lo-eps && value <= hi+eps) {
+		return fmt.Errorf("value %v is outside of utility bounds [%v, %v] at %v",
+			value, lo, hi, node)
+	}
+
+	return nil
+}
+
+// terminalUtility returns the utility of the terminal node for the given player,
+// asserting that it is within the declared utility bounds in debug builds.
+func terminalUtility(node GameTreeNode, player int) float64 {
+	u := node.Utility(player)
+	if Debug {
+		assertUtilityBounds(node, u)
+	}
+
+	return u
+}
+
+// assertUtilityBounds panics if value is outside the utility bounds declared by node.
+// It is used to check the counterfactual values of runners that do not importance
+// weight them, and which therefore must be expected utilities.
+func assertUtilityBounds(node GameTreeNode, value float64) {
+	if err := CheckUtilityBounds(node, value); err != nil {
+		panic(err)
+	}
+}
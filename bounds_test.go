@@ -0,0 +1,64 @@
+package cfr_test
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+// buggyUtilityNode doubles the utilities of the game,
+// so that they exceed its declared bounds.
+type buggyUtilityNode struct {
+	*kuhn.PokerNode
+}
+
+func (n buggyUtilityNode) GetChild(i int) cfr.GameTreeNode {
+	return buggyUtilityNode{n.PokerNode.GetChild(i).(*kuhn.PokerNode)}
+}
+
+func (n buggyUtilityNode) Utility(player int) float64 {
+	return 2 * n.PokerNode.Utility(player)
+}
+
+func (n buggyUtilityNode) TerminalChildUtility(i, player int) (float64, bool) {
+	u, ok := n.PokerNode.TerminalChildUtility(i, player)
+	return 2 * u, ok
+}
+
+func TestUtilityBounds(t *testing.T) {
+	nOutOfBounds := 0
+	tree.Visit(kuhn.NewGame(), func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.TerminalNodeType {
+			return
+		}
+
+		if err := cfr.CheckUtilityBounds(node, node.Utility(0)); err != nil {
+			t.Error(err)
+		}
+
+		buggy := buggyUtilityNode{node.(*kuhn.PokerNode)}
+		if cfr.CheckUtilityBounds(buggy, buggy.Utility(0)) != nil {
+			nOutOfBounds++
+		}
+	})
+
+	// Showdowns after a bet and call.
+	if nOutOfBounds != 12 {
+		t.Errorf("expected %d out of bounds utilities, got %d", 12, nOutOfBounds)
+	}
+
+	if !cfr.Debug {
+		t.Skip("assertions are only enabled with the cfrdebug build tag")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected out of bounds utility to panic")
+		}
+	}()
+
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	cfr.New(policy).Run(buggyUtilityNode{kuhn.NewGame()})
+}
@@ -0,0 +1,89 @@
+package cfr
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+)
+
+// Breakpoint triggers a callback when traversal reaches a matching node.
+type Breakpoint struct {
+	// Match returns true if the breakpoint should trigger at the given node.
+	Match func(node GameTreeNode) bool
+	// Callback is called with the matching node and its policy.
+	// If nil, the node and its current and average strategies are logged.
+	Callback func(node GameTreeNode, policy NodePolicy)
+}
+
+// MatchKeyPrefix returns a Breakpoint.Match function that matches nodes
+// whose InfoSet key for the acting player begins with prefix.
+func MatchKeyPrefix(prefix string) func(node GameTreeNode) bool {
+	return func(node GameTreeNode) bool {
+		key := node.InfoSet(node.Player()).Key()
+		return strings.HasPrefix(key, prefix)
+	}
+}
+
+// BreakpointProfile wraps a StrategyProfile to trigger breakpoints when
+// the policy for a matching node is requested during traversal. It may be used
+// to debug specific lines of play in games too large to inspect exhaustively.
+//
+// Since runners only request policies at player nodes with more than one
+// action, breakpoints do not trigger at other nodes.
+type BreakpointProfile struct {
+	StrategyProfile
+
+	mx          sync.RWMutex
+	breakpoints []Breakpoint
+}
+
+// NewBreakpointProfile returns a new BreakpointProfile wrapping the given profile.
+func NewBreakpointProfile(profile StrategyProfile) *BreakpointProfile {
+	return &BreakpointProfile{StrategyProfile: profile}
+}
+
+// AddBreakpoint registers a new breakpoint. It is safe to call during traversal.
+func (p *BreakpointProfile) AddBreakpoint(bp Breakpoint) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.breakpoints = append(p.breakpoints, bp)
+}
+
+// ClearBreakpoints removes all registered breakpoints.
+func (p *BreakpointProfile) ClearBreakpoints() {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.breakpoints = nil
+}
+
+// GetPolicy implements StrategyProfile.
+func (p *BreakpointProfile) GetPolicy(node GameTreeNode) NodePolicy {
+	policy := p.StrategyProfile.GetPolicy(node)
+
+	p.mx.RLock()
+	defer p.mx.RUnlock()
+	for _, bp := range p.breakpoints {
+		if !bp.Match(node) {
+			continue
+		}
+
+		if bp.Callback != nil {
+			bp.Callback(node, policy)
+		} else {
+			glog.Infof("Breakpoint at iter %d: %v: strategy=%v, average=%v",
+				p.Iter(), node, policy.GetStrategy(), policy.GetAverageStrategy())
+		}
+	}
+
+	return policy
+}
+
+// Prefetch implements Prefetcher, if the underlying profile does.
+func (p *BreakpointProfile) Prefetch(children []GameTreeNode) (release func()) {
+	if pf, ok := p.StrategyProfile.(Prefetcher); ok {
+		return pf.Prefetch(children)
+	}
+
+	return func() {}
+}
@@ -0,0 +1,35 @@
+package cfr_test
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+func TestBreakpointProfile(t *testing.T) {
+	policy := cfr.NewBreakpointProfile(cfr.NewPolicyTable(cfr.DiscountParams{}))
+	hits := 0
+	policy.AddBreakpoint(cfr.Breakpoint{
+		Match: cfr.MatchKeyPrefix("rrcb-"),
+		Callback: func(node cfr.GameTreeNode, p cfr.NodePolicy) {
+			if node.Player() != 0 {
+				t.Errorf("unexpected node at breakpoint: %v", node)
+			}
+
+			hits++
+		},
+	})
+
+	opt := cfr.New(policy)
+	runCFR(t, opt, policy, 10)
+	// Each iteration of vanilla CFR visits the check-bet node once for each deal.
+	if hits != 60 {
+		t.Errorf("expected %d breakpoint hits, got %d", 60, hits)
+	}
+
+	policy.ClearBreakpoints()
+	runCFR(t, opt, policy, 10)
+	if hits != 60 {
+		t.Errorf("expected no more breakpoint hits after clearing, got %d", hits-60)
+	}
+}
@@ -0,0 +1,168 @@
+package cfr
+
+// ActionCanonicalizer maps the InfoSet of the acting player at a node to a
+// canonical InfoSet, for games in which distinct InfoSets are equivalent up to
+// a permutation of their actions (for example, by the symmetry of suits in poker).
+//
+// It returns the key of the canonical InfoSet, and perm such that action i of the
+// node is action perm[i] of the canonical InfoSet. A nil perm is the identity.
+// Every InfoSet with the same canonical key must have the same number of actions.
+type ActionCanonicalizer func(node GameTreeNode) (key string, perm []int)
+
+// CanonicalProfile wraps a StrategyProfile so that all InfoSets which are equivalent
+// up to a permutation of their actions share a single policy, reducing the size of
+// the profile by up to the number of symmetries of the game.
+//
+// Policies are looked up in the wrapped profile by the canonical key, and the
+// permutation is applied to all strategies, regrets and baselines, so that
+// runners and callers only ever see actions in the order of the node.
+// Note that the node passed to the wrapped profile is otherwise unchanged, so
+// anything else derived from it is in the node's order. A PolicyTable maps the
+// strategy of its Heuristic to the canonical order before seeding the policy.
+type CanonicalProfile struct {
+	StrategyProfile
+	canonicalize ActionCanonicalizer
+}
+
+// NewCanonicalProfile returns a new CanonicalProfile wrapping the given profile.
+func NewCanonicalProfile(profile StrategyProfile, canonicalize ActionCanonicalizer) *CanonicalProfile {
+	return &CanonicalProfile{
+		StrategyProfile: profile,
+		canonicalize:    canonicalize,
+	}
+}
+
+// GetPolicy implements StrategyProfile.
+func (p *CanonicalProfile) GetPolicy(node GameTreeNode) NodePolicy {
+	key, perm := p.canonicalize(node)
+	policy := p.StrategyProfile.GetPolicy(canonicalNode{node, key, perm})
+	if perm == nil {
+		return policy
+	}
+
+	return &permutedPolicy{NodePolicy: policy, perm: perm}
+}
+
+// canonicalNode replaces the key of the acting player's InfoSet with its canonical key.
+type canonicalNode struct {
+	GameTreeNode
+	key  string
+	perm []int
+}
+
+// toCanonical returns x, indexed by action of the node, in the canonical action order.
+func (n canonicalNode) toCanonical(x []float32) []float32 {
+	return permuteToCanonical(n.perm, x)
+}
+
+func (n canonicalNode) InfoSet(player int) InfoSet {
+	is := n.GameTreeNode.InfoSet(player)
+	if player != n.Player() {
+		return is
+	}
+
+	return canonicalInfoSet{is, n.key}
+}
+
+type canonicalInfoSet struct {
+	InfoSet
+	key string
+}
+
+func (is canonicalInfoSet) Key() string {
+	return is.key
+}
+
+// permutedPolicy presents a policy of the canonical InfoSet in the action order of a node.
+type permutedPolicy struct {
+	NodePolicy
+	perm []int
+}
+
+// toNode returns x, indexed by canonical action, in the action order of the node.
+func (p *permutedPolicy) toNode(x []float32) []float32 {
+	result := make([]float32, len(p.perm))
+	for i, j := range p.perm {
+		result[i] = x[j]
+	}
+
+	return result
+}
+
+// toCanonical returns x, indexed by action of the node, in the canonical action order.
+func (p *permutedPolicy) toCanonical(x []float32) []float32 {
+	return permuteToCanonical(p.perm, x)
+}
+
+// permuteToCanonical returns x, indexed by action of a node, in the canonical
+// action order given by perm. A nil perm is the identity.
+func permuteToCanonical(perm []int, x []float32) []float32 {
+	if x == nil || perm == nil {
+		return x
+	}
+
+	result := make([]float32, len(perm))
+	for i, j := range perm {
+		result[j] = x[i]
+	}
+
+	return result
+}
+
+func (p *permutedPolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {
+	p.NodePolicy.AddRegret(w, p.toCanonical(samplingQ), p.toCanonical(instantaneousRegrets))
+}
+
+func (p *permutedPolicy) GetStrategy() []float32 {
+	return p.toNode(p.NodePolicy.GetStrategy())
+}
+
+func (p *permutedPolicy) CopyStrategy(dst []float32) {
+	strategy := p.NodePolicy.GetStrategy()
+	for i, j := range p.perm {
+		dst[i] = strategy[j]
+	}
+}
+
+func (p *permutedPolicy) GetBaseline() []float32 {
+	return p.toNode(p.NodePolicy.GetBaseline())
+}
+
+func (p *permutedPolicy) UpdateBaseline(w float32, action int, value float32) {
+	p.NodePolicy.UpdateBaseline(w, p.perm[action], value)
+}
+
+// AddActionCount implements ActionCounter, falling back to
+// AddStrategyWeight if the wrapped policy does not.
+func (p *permutedPolicy) AddActionCount(action int) {
+	if counter, ok := p.NodePolicy.(ActionCounter); ok {
+		counter.AddActionCount(p.perm[action])
+	} else {
+		p.NodePolicy.AddStrategyWeight(1.0)
+	}
+}
+
+func (p *permutedPolicy) GetAverageStrategy() []float32 {
+	return p.toNode(p.NodePolicy.GetAverageStrategy())
+}
+
+func (p *permutedPolicy) CopyAverageStrategy(dst []float32) {
+	copy(dst, p.GetAverageStrategy())
+}
+
+// PlayerSymmetry returns an ActionCanonicalizer for games that are identical for both
+// players up to relabeling, so that a single set of policies is trained for both.
+// InfoSets of player 0 are canonical, and mirror returns the key of the equivalent
+// InfoSet of player 0 (and the permutation of actions) for each InfoSet of player 1.
+//
+// Each shared policy then accumulates the samples of both players, and the
+// profile requires half of the memory it otherwise would.
+func PlayerSymmetry(mirror ActionCanonicalizer) ActionCanonicalizer {
+	return func(node GameTreeNode) (string, []int) {
+		if node.Player() == 0 {
+			return nodeKey(node), nil
+		}
+
+		return mirror(node)
+	}
+}
@@ -0,0 +1,196 @@
+package cfr_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/simulator"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+// rpsSim is Rock-Paper-Scissors in which Scissors beating Paper pays 2.
+// Player 1 acts without observing the action of player 0, so the game is
+// symmetric, and the equilibrium strategy of both players is (1/2, 1/4, 1/4).
+type rpsSim struct {
+	actions []int
+}
+
+var rpsPayoffs = [3][3]float64{
+	{0, -1, 1},
+	{1, 0, -2},
+	{-1, 2, 0},
+}
+
+func (s *rpsSim) Type() cfr.NodeType {
+	if len(s.actions) == 2 {
+		return cfr.TerminalNodeType
+	}
+
+	return cfr.PlayerNodeType
+}
+
+func (s *rpsSim) Player() int {
+	return len(s.actions) % 2
+}
+
+type rpsInfoSet string
+
+func (is rpsInfoSet) Key() string                     { return string(is) }
+func (is rpsInfoSet) MarshalBinary() ([]byte, error)  { return []byte(is), nil }
+func (is *rpsInfoSet) UnmarshalBinary(b []byte) error { *is = rpsInfoSet(b); return nil }
+
+func (s *rpsSim) InfoSet(player int) cfr.InfoSet {
+	is := rpsInfoSet("p0")
+	if player == 1 {
+		is = "p1"
+	}
+
+	return &is
+}
+
+func (s *rpsSim) Utility(player int) float64 {
+	return rpsPayoffs[s.actions[player]][s.actions[1-player]]
+}
+
+func (s *rpsSim) NumActions() int {
+	if s.Type() == cfr.TerminalNodeType {
+		return 0
+	}
+
+	return 3
+}
+
+func (s *rpsSim) ActionProbability(i int) float64 {
+	panic("no chance nodes")
+}
+
+func (s *rpsSim) Apply(i int) {
+	s.actions = append(s.actions, i)
+}
+
+func (s *rpsSim) Clone() simulator.Simulator {
+	return &rpsSim{actions: append([]int(nil), s.actions...)}
+}
+
+func TestPlayerSymmetry(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	profile := cfr.NewCanonicalProfile(policy, cfr.PlayerSymmetry(
+		func(node cfr.GameTreeNode) (string, []int) {
+			return "p0", nil
+		}))
+
+	opt := cfr.New(profile)
+	root := simulator.NewGame(&rpsSim{})
+	for i := 0; i < 10000; i++ {
+		opt.Run(root)
+		profile.Update()
+	}
+
+	// Both players share the policy of player 0, and player 1's is never created.
+	p1Node := root.GetChild(0)
+	if !policy.GetPolicy(p1Node).IsEmpty() {
+		t.Error("expected player 1 to share the policy of player 0")
+	}
+
+	expected := []float64{0.5, 0.25, 0.25}
+	for _, node := range []cfr.GameTreeNode{root, p1Node} {
+		strategy := profile.GetPolicy(node).GetAverageStrategy()
+		for i, p := range strategy {
+			if math.Abs(float64(p)-expected[i]) > 0.01 {
+				t.Errorf("player %d: expected average strategy %v, got %v",
+					node.Player(), expected, strategy)
+				break
+			}
+		}
+	}
+
+	if exploitability := cfr.Exploitability(root, profile); exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
+
+func TestCanonicalProfile_Heuristic(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	heuristic := []float32{0.6, 0.3, 0.1}
+	policy.SetHeuristic(func(node cfr.GameTreeNode) []float32 {
+		return heuristic
+	}, 1)
+
+	profile := cfr.NewCanonicalProfile(policy, func(node cfr.GameTreeNode) (string, []int) {
+		return "c", []int{1, 2, 0}
+	})
+
+	// The heuristic is in the action order of the node, as is the strategy.
+	root := simulator.NewGame(&rpsSim{})
+	strategy := profile.GetPolicy(root).GetStrategy()
+	for i, p := range strategy {
+		if math.Abs(float64(p-heuristic[i])) > 1e-6 {
+			t.Errorf("expected strategy %v, got %v", heuristic, strategy)
+			break
+		}
+	}
+}
+
+// mirroredNode is Kuhn poker with the order of each player's actions reversed,
+// and distinct InfoSet keys.
+type mirroredNode struct {
+	cfr.GameTreeNode
+}
+
+func (n mirroredNode) GetChild(i int) cfr.GameTreeNode {
+	if n.Type() == cfr.PlayerNodeType {
+		i = n.NumChildren() - 1 - i
+	}
+
+	return mirroredNode{n.GameTreeNode.GetChild(i)}
+}
+
+func (n mirroredNode) InfoSet(player int) cfr.InfoSet {
+	return mirroredInfoSet{n.GameTreeNode.InfoSet(player)}
+}
+
+type mirroredInfoSet struct {
+	cfr.InfoSet
+}
+
+func (is mirroredInfoSet) Key() string {
+	return "m" + is.InfoSet.Key()
+}
+
+func TestCanonicalProfile(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	profile := cfr.NewCanonicalProfile(policy, func(node cfr.GameTreeNode) (string, []int) {
+		key := node.InfoSet(node.Player()).Key()
+		if strings.HasPrefix(key, "m") {
+			return key[1:], []int{1, 0}
+		}
+
+		return key, nil
+	})
+
+	// Policies are shared, so training on the original game also solves the mirrored game.
+	opt := cfr.New(profile)
+	runCFR(t, opt, profile, 10000)
+	root, mirrored := kuhn.NewGame(), mirroredNode{kuhn.NewGame()}
+
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		strategy := profile.GetPolicy(node).GetAverageStrategy()
+		mirroredStrategy := profile.GetPolicy(mirroredNode{node}).GetAverageStrategy()
+		if strategy[0] != mirroredStrategy[1] || strategy[1] != mirroredStrategy[0] {
+			t.Errorf("%v: expected mirrored strategy of %v, got %v", node, strategy, mirroredStrategy)
+		}
+	})
+
+	for _, game := range []cfr.GameTreeNode{root, mirrored} {
+		if exploitability := cfr.Exploitability(game, profile); exploitability > 0.01 {
+			t.Errorf("expected exploitability near 0, got %v", exploitability)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package cfr
+
+import (
+	"github.com/timpalpant/go-cfr/internal/f32"
+)
+
+// CFRBR implements CFR-BR (Johanson et al., 2012):
+// http://poker.cs.ualberta.ca/publications/AAAI12-cfrbr.pdf
+//
+// Only one player runs CFR. On each iteration the other player plays an
+// exact best response to the current strategy of the CFR player, computed
+// with a full traversal of the game tree. The CFR player's average strategy
+// converges to the least exploitable strategy that can be represented in its
+// abstraction, whereas with CFR in an abstract game the exploitability of the
+// average strategy in the real game need not decrease.
+//
+// Since the best response is a pure strategy, each iteration only traverses
+// the subtree in which the best responding player follows its chosen actions.
+// Policies are only requested (and updated) for the CFR player's nodes.
+type CFRBR struct {
+	strategyProfile StrategyProfile
+	slicePool       SlicePool
+	player          int
+
+	br *BestResponse
+}
+
+// NewCFRBR returns a new CFR-BR runner in which player runs CFR against
+// a best response.
+func NewCFRBR(strategyProfile StrategyProfile, player int) *CFRBR {
+	return &CFRBR{
+		strategyProfile: strategyProfile,
+		slicePool:       NewFloatSlicePool(SlicePoolParams{}),
+		player:          player,
+	}
+}
+
+// SetSlicePool sets the pool used to allocate temporary slices during
+// traversal. A single pool may be shared by multiple runners.
+func (c *CFRBR) SetSlicePool(pool SlicePool) {
+	c.slicePool = pool
+}
+
+// BestResponse returns the best response computed on the last iteration.
+// Its value is the exploitability of the CFR player's current strategy
+// on that iteration.
+func (c *CFRBR) BestResponse() *BestResponse {
+	return c.br
+}
+
+func (c *CFRBR) Run(node GameTreeNode) float32 {
+	c.br = ComputeBestResponse(node, 1-c.player, func(node GameTreeNode) []float32 {
+		return c.strategyProfile.GetPolicy(node).GetStrategy()
+	})
+
+	return c.runHelper(node, node.Player(), 1.0, 1.0)
+}
+
+// runHelper is as for vanilla CFR, except that reach is only the reach
+// probability of the CFR player: the best responding player reaches
+// every node that is traversed with probability 1.
+func (c *CFRBR) runHelper(node GameTreeNode, lastPlayer int, reach, reachChance float32) float32 {
+	var ev float32
+	switch node.Type() {
+	case TerminalNodeType:
+		ev = float32(terminalUtility(node, lastPlayer))
+	case ChanceNodeType:
+		ev = c.handleChanceNode(node, lastPlayer, reach, reachChance)
+	default:
+		sgn := getSign(lastPlayer, node.Player())
+		ev = sgn * c.handlePlayerNode(node, reach, reachChance)
+	}
+
+	if Debug {
+		assertUtilityBounds(node, float64(ev))
+	}
+
+	node.Close()
+	return ev
+}
+
+func (c *CFRBR) handleChanceNode(node GameTreeNode, lastPlayer int, reach, reachChance float32) float32 {
+	var expectedValue float32
+	for i := 0; i < node.NumChildren(); i++ {
+		p := float32(node.GetChildProbability(i))
+		child := node.GetChild(i)
+		expectedValue += p * c.runHelper(child, lastPlayer, reach, reachChance*p)
+	}
+
+	return expectedValue
+}
+
+func (c *CFRBR) handlePlayerNode(node GameTreeNode, reach, reachChance float32) float32 {
+	player := node.Player()
+	nChildren := node.NumChildren()
+	if nChildren == 1 {
+		// Optimization to skip trivial nodes with no real choice.
+		child := node.GetChild(0)
+		return c.runHelper(child, player, reach, reachChance)
+	}
+
+	if player != c.player {
+		child := node.GetChild(c.br.Action(node))
+		return c.runHelper(child, player, reach, reachChance)
+	}
+
+	policy := c.strategyProfile.GetPolicy(node)
+	strategy := policy.GetStrategy()
+	regrets := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(regrets)
+	var cfValue float32
+	for i := 0; i < nChildren; i++ {
+		child := node.GetChild(i)
+		p := strategy[i]
+		util := c.runHelper(child, player, p*reach, reachChance)
+		regrets[i] = util
+		cfValue += p * util
+	}
+
+	// Transform action utilities into instantaneous regrets by
+	// subtracting out the expected utility over all possible actions.
+	f32.AddConst(-cfValue, regrets)
+	ones := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(ones)
+	for i := range ones {
+		ones[i] = 1.0
+	}
+	policy.AddRegret(reachChance, ones, regrets)
+	policy.AddStrategyWeight(reach * reachChance)
+	return cfValue
+}
@@ -0,0 +1,340 @@
+package cfr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// SubgameBoundary partitions a public tree into a trunk and a set of subgames.
+// It returns a key that uniquely identifies the public state of node and true
+// if node is the root of a subgame, or false if node is within the trunk or a
+// subgame. It must return false for the root of the game.
+type SubgameBoundary func(node PublicTreeNode) (key string, ok bool)
+
+// CFRDParams are the parameters of the subgame solves performed by CFRD.
+type CFRDParams struct {
+	// Number of iterations of CFR used to solve each subgame.
+	SubgameIterations int
+	// DiscountParams of the PolicyTables used to solve subgames.
+	SubgameParams DiscountParams
+	// Number of sampled traversals of the solved subgame that are averaged to
+	// compute its counterfactual values. It only needs to be greater than one
+	// if the subgame has public chance events. Zero is treated as one.
+	EvaluationSamples int
+}
+
+// CFRD implements CFR-D (Burch, Johanson & Bowling, 2014), which decomposes a game
+// into a trunk and a set of subgames at a boundary of public states, so that only
+// the strategy of the trunk needs to be stored.
+//
+// Each iteration traverses the trunk of the public tree as PublicChanceSamplingCFR.
+// When it reaches the root of a subgame, the subgame is solved from scratch with
+// the reach probabilities of both players' private states at its root (their ranges),
+// and the counterfactual values of the traversing player under the solution are
+// returned to the trunk in place of traversing it. The solution is then discarded,
+// and only the average counterfactual values of each player at the root of the
+// subgame are kept. The average strategy of the trunk converges to that of an
+// equilibrium, and during play each subgame is re-solved as it is reached
+// (see ResolveSubgame).
+type CFRD struct {
+	trunk    *PublicChanceSamplingCFR
+	boundary SubgameBoundary
+	params   CFRDParams
+
+	// Sum of the counterfactual values of each player at the root of each
+	// subgame, and the number of traversals summed, by subgame key.
+	valueSums map[string]*[2]boundaryValues
+}
+
+type boundaryValues struct {
+	sum []float32
+	n   int
+}
+
+// NewCFRD returns a new CFRD runner that accumulates the strategy of the trunk
+// into the given profile.
+func NewCFRD(trunkProfile StrategyProfile, boundary SubgameBoundary, params CFRDParams) *CFRD {
+	c := &CFRD{
+		trunk:     NewPublicChanceSampling(trunkProfile),
+		boundary:  boundary,
+		params:    params,
+		valueSums: make(map[string]*[2]boundaryValues),
+	}
+
+	c.trunk.leafValues = c.subgameValues
+	return c
+}
+
+// SetSlicePool sets the pool used to allocate temporary slices during
+// traversal. A single pool may be shared by multiple runners.
+func (c *CFRD) SetSlicePool(pool SlicePool) {
+	c.trunk.SetSlicePool(pool)
+}
+
+// SetPlayerSchedule sets the PlayerSchedule of the runner.
+func (c *CFRD) SetPlayerSchedule(schedule PlayerSchedule) {
+	c.trunk.SetPlayerSchedule(schedule)
+}
+
+// Run performs one iteration of CFR-D on the public tree rooted at node, and
+// returns the mean of the sampled expected values of the game for the traversing players.
+func (c *CFRD) Run(node PublicTreeNode) float32 {
+	return c.trunk.Run(node)
+}
+
+// BoundaryValues returns the average counterfactual value of each private state
+// of the given player at the root of the subgame with the given key, over all
+// iterations in which the player traversed it. It returns nil if the subgame
+// has not been traversed by the player.
+func (c *CFRD) BoundaryValues(key string, player int) []float32 {
+	values, ok := c.valueSums[key]
+	if !ok || values[player].n == 0 {
+		return nil
+	}
+
+	result := make([]float32, len(values[player].sum))
+	for i, v := range values[player].sum {
+		result[i] = v / float32(values[player].n)
+	}
+
+	return result
+}
+
+// SolveSubgame solves the subgame rooted at the given public node with the given
+// ranges: the reach probability of each private state of each player at the node
+// (including their prior probabilities). It returns a new PolicyTable containing
+// the strategy of the subgame.
+//
+// The solution is an equilibrium of the subgame for the given ranges, but when
+// combined with the strategy of the trunk it may be highly exploitable, since
+// players that are indifferent between actions within the subgame may choose
+// them in any proportion. Use ResolveSubgame to re-solve subgames during play.
+func (c *CFRD) SolveSubgame(node PublicTreeNode, ranges [2][]float32) *PolicyTable {
+	return c.solve(node, ranges)
+}
+
+// ResolveSubgame re-solves the subgame rooted at the given public node for player,
+// given their range determined by the average strategy of the trunk: the reach
+// probability of each of their private states at the node (including their prior
+// probabilities). It returns a new PolicyTable in which the strategy of player
+// (but not of their opponent) may be used to play the subgame.
+//
+// The subgame is solved with the re-solving gadget of CFR-D, in which the opponent
+// may choose, for each of their private states, to receive their counterfactual value
+// from training (see BoundaryValues) rather than entering the subgame, so that the
+// re-solved strategy is not more exploitable than the strategy used in training.
+// The range of the opponent is therefore not needed.
+func (c *CFRD) ResolveSubgame(node PublicTreeNode, player int, reach []float32) (*PolicyTable, error) {
+	key, ok := c.boundary(node)
+	if !ok {
+		return nil, fmt.Errorf("node is not the root of a subgame: %v", node)
+	}
+
+	opponent := 1 - player
+	opponentValues := c.BoundaryValues(key, opponent)
+	if opponentValues == nil {
+		return nil, fmt.Errorf("no counterfactual values for player %d in subgame %q", opponent, key)
+	}
+
+	return resolveSubgame(node, player, reach, opponentValues,
+		c.params.SubgameIterations, c.params.SubgameParams, c.trunk.slicePool), nil
+}
+
+func (c *CFRD) solve(node PublicTreeNode, ranges [2][]float32) *PolicyTable {
+	return solveSubgame(node, ranges, c.params.SubgameIterations, c.params.SubgameParams, c.trunk.slicePool)
+}
+
+// subgameValues implements PublicChanceSamplingCFR.leafValues for the trunk.
+func (c *CFRD) subgameValues(node PublicTreeNode, reach, opponentReach, values []float32) bool {
+	key, ok := c.boundary(node)
+	if !ok {
+		return false
+	}
+
+	traverser := c.trunk.traversingPlayer
+	var ranges [2][]float32
+	ranges[traverser] = reach
+	ranges[1-traverser] = opponentReach
+	pt := c.solve(node, ranges)
+	evaluateSubgame(pt, node, traverser, reach, opponentReach, values,
+		c.params.EvaluationSamples, c.trunk.slicePool)
+	c.addBoundaryValues(key, traverser, values)
+	return true
+}
+
+func (c *CFRD) addBoundaryValues(key string, player int, values []float32) {
+	sums, ok := c.valueSums[key]
+	if !ok {
+		sums = &[2]boundaryValues{}
+		c.valueSums[key] = sums
+	}
+
+	if sums[player].sum == nil {
+		sums[player].sum = make([]float32, len(values))
+	}
+
+	for i, v := range values {
+		sums[player].sum[i] += v
+	}
+
+	sums[player].n++
+}
+
+// solveSubgame solves the public subgame rooted at node for the given ranges of
+// each player with the given number of iterations of PCS-CFR, and returns the solution.
+func solveSubgame(node PublicTreeNode, ranges [2][]float32, nIter int, params DiscountParams, pool SlicePool) *PolicyTable {
+	pt := NewPolicyTable(params)
+	solver := NewPublicChanceSampling(pt)
+	solver.SetSlicePool(pool)
+	for i := 0; i < nIter; i++ {
+		player := pt.Iter() % 2
+		solver.traversingPlayer = player
+		values := pool.Alloc(len(ranges[player]))
+		solver.runHelper(node, ranges[player], ranges[1-player], values)
+		pool.Free(values)
+		pt.Update()
+	}
+
+	return pt
+}
+
+// resolveSubgame solves the public subgame rooted at node for player with the
+// re-solving gadget, given their range and the counterfactual values of the opponent.
+func resolveSubgame(node PublicTreeNode, player int, reach, opponentValues []float32, nIter int, params DiscountParams, pool SlicePool) *PolicyTable {
+	opponent := 1 - player
+	gadget := &resolveGadget{
+		subgame:        node,
+		opponent:       opponent,
+		opponentValues: opponentValues,
+	}
+
+	// The opponent must reach the gadget in every private state for their
+	// counterfactual values to constrain the solution, with any probabilities.
+	var ranges [2][]float32
+	ranges[player] = reach
+	ranges[opponent] = node.PrivateStateProbabilities(opponent)
+	return solveSubgame(gadget, ranges, nIter, params, pool)
+}
+
+// evaluateSubgame sets values to the counterfactual values of traverser at node when
+// both players play the average strategies of profile, averaged over nSamples
+// traversals (at least one). The profile is not modified.
+func evaluateSubgame(profile StrategyProfile, node PublicTreeNode, traverser int, reach, opponentReach, values []float32, nSamples int, pool SlicePool) {
+	evaluator := NewPublicChanceSampling(averageStrategyProfile{profile})
+	evaluator.SetSlicePool(pool)
+	evaluator.traversingPlayer = traverser
+	nSamples = max(nSamples, 1)
+	sampleValues := pool.Alloc(len(values))
+	defer pool.Free(sampleValues)
+	for i := range values {
+		values[i] = 0
+	}
+
+	for i := 0; i < nSamples; i++ {
+		evaluator.runHelper(node, reach, opponentReach, sampleValues)
+		for j, v := range sampleValues {
+			values[j] += v / float32(nSamples)
+		}
+	}
+}
+
+// averageStrategyProfile is a read-only view of a StrategyProfile
+// in which every policy plays its average strategy.
+type averageStrategyProfile struct {
+	StrategyProfile
+}
+
+func (p averageStrategyProfile) GetPolicy(node GameTreeNode) NodePolicy {
+	np := p.StrategyProfile.GetPolicy(node)
+	return &lockedPolicy{NodePolicy: np, strategy: np.GetAverageStrategy()}
+}
+
+// resolveGadget is the root of the re-solving gadget of CFR-D: the opponent
+// chooses either to terminate (child 0), receiving opponentValues, or to
+// enter the subgame (child 1).
+type resolveGadget struct {
+	subgame        PublicTreeNode
+	opponent       int
+	opponentValues []float32
+}
+
+func (g *resolveGadget) Type() NodeType { return PlayerNodeType }
+func (g *resolveGadget) Close()         {}
+func (g *resolveGadget) NumChildren() int {
+	return 2
+}
+
+func (g *resolveGadget) GetChild(i int) PublicTreeNode {
+	if i == 0 {
+		return &gadgetTerminal{g}
+	}
+
+	return g.subgame
+}
+
+func (g *resolveGadget) SampleChild() (PublicTreeNode, float64) {
+	panic("cfr: SampleChild is not supported by the re-solving gadget")
+}
+
+func (g *resolveGadget) Player() int { return g.opponent }
+
+func (g *resolveGadget) NumPrivateStates(player int) int {
+	return g.subgame.NumPrivateStates(player)
+}
+
+func (g *resolveGadget) PrivateStateProbabilities(player int) []float32 {
+	return g.subgame.PrivateStateProbabilities(player)
+}
+
+func (g *resolveGadget) InfoSet(privateState int) InfoSet {
+	is := gadgetInfoSet("cfrd-gadget:" + strconv.Itoa(privateState))
+	return &is
+}
+
+func (g *resolveGadget) TerminalUtilities(player int, opponentReach, utilities []float32) {
+	panic("cfr: TerminalUtilities called on non-terminal node")
+}
+
+func (g *resolveGadget) String() string {
+	return fmt.Sprintf("re-solving gadget for %v", g.subgame)
+}
+
+// gadgetTerminal is the terminal node of the re-solving gadget.
+type gadgetTerminal struct {
+	*resolveGadget
+}
+
+func (t *gadgetTerminal) Type() NodeType   { return TerminalNodeType }
+func (t *gadgetTerminal) NumChildren() int { return 0 }
+
+func (t *gadgetTerminal) GetChild(i int) PublicTreeNode {
+	panic("cfr: GetChild called on terminal node")
+}
+
+// TerminalUtilities implements PublicTreeNode. The utility of the player
+// re-solving the subgame is zero, since it does not affect their strategy.
+func (t *gadgetTerminal) TerminalUtilities(player int, opponentReach, utilities []float32) {
+	if player == t.opponent {
+		copy(utilities, t.opponentValues)
+		return
+	}
+
+	for i := range utilities {
+		utilities[i] = 0
+	}
+}
+
+type gadgetInfoSet string
+
+func (is gadgetInfoSet) Key() string {
+	return string(is)
+}
+
+func (is gadgetInfoSet) MarshalBinary() ([]byte, error) {
+	return []byte(is), nil
+}
+
+func (is *gadgetInfoSet) UnmarshalBinary(buf []byte) error {
+	*is = gadgetInfoSet(buf)
+	return nil
+}
@@ -0,0 +1,95 @@
+package cfr_test
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestCFRD(t *testing.T) {
+	// The trunk is the first action of player 0, and each of their actions
+	// is the root of a subgame.
+	boundary := func(node cfr.PublicTreeNode) (string, bool) {
+		history := kuhnHistory(node.InfoSet(0))[2:]
+		return history, len(history) == 1
+	}
+
+	publicRoot := kuhn.NewPublicGame()
+	trunk := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewCFRD(trunk, boundary, cfr.CFRDParams{SubgameIterations: 400})
+	nIter := 2000
+	var ev float32
+	for i := 0; i < nIter; i++ {
+		value := opt.Run(publicRoot)
+		if trunk.Iter()%2 == 1 {
+			value = -value // Value for player 0.
+		}
+
+		ev += value
+		trunk.Update()
+	}
+
+	if ev /= float32(nIter); ev > -0.045 || ev < -0.065 {
+		t.Errorf("expected game value near -1/18, got %v", ev)
+	}
+
+	// Re-solve each subgame for each player with the ranges of the trunk's
+	// average strategy, and check that the combined strategy is near an equilibrium.
+	rootStrategies := make(map[kuhn.Card][]float32)
+	tree.Visit(kuhn.NewGame(), func(node cfr.GameTreeNode) {
+		if node.Type() == cfr.PlayerNodeType && node.Parent().Type() == cfr.ChanceNodeType {
+			card := kuhnCard(node.InfoSet(0))
+			rootStrategies[card] = trunk.GetPolicy(node).GetAverageStrategy()
+		}
+	})
+
+	profile := &cfrdProfile{PolicyTable: trunk, subgames: make(map[byte]*[2]*cfr.PolicyTable)}
+	for a := 0; a < publicRoot.NumChildren(); a++ {
+		var ranges [2][]float32
+		ranges[0] = make([]float32, len(rootStrategies))
+		for card, strategy := range rootStrategies {
+			ranges[0][card] = strategy[a] / 3
+		}
+		ranges[1] = publicRoot.PrivateStateProbabilities(1)
+
+		subgame := publicRoot.GetChild(a)
+		var resolved [2]*cfr.PolicyTable
+		for player := range resolved {
+			var err error
+			resolved[player], err = opt.ResolveSubgame(subgame, player, ranges[player])
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		profile.subgames[kuhnHistory(subgame.InfoSet(0))[2]] = &resolved
+	}
+
+	exploitability := cfr.Exploitability(kuhn.NewGame(), profile)
+	t.Logf("Exploitability of re-solved strategy: %v", exploitability)
+	if exploitability > 0.02 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+
+	if _, err := opt.ResolveSubgame(publicRoot, 0, nil); err == nil {
+		t.Error("expected error re-solving the trunk")
+	}
+}
+
+// cfrdProfile combines the strategy of the trunk with that of each player
+// in re-solved subgames.
+type cfrdProfile struct {
+	*cfr.PolicyTable
+	subgames map[byte]*[2]*cfr.PolicyTable
+}
+
+func (p *cfrdProfile) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
+	history := kuhnHistory(node.InfoSet(node.Player()))
+	if len(history) == 2 {
+		return p.PolicyTable.GetPolicy(node)
+	}
+
+	return p.subgames[history[2]][node.Player()].GetPolicy(node)
+}
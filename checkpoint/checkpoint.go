@@ -0,0 +1,210 @@
+// Package checkpoint saves and loads StrategyProfiles together with metadata
+// describing how they were produced, so that long-running experiments
+// can be audited and resumed correctly.
+package checkpoint
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// Metadata records the provenance of a checkpoint.
+type Metadata struct {
+	// The iteration of the strategy profile when the checkpoint was saved.
+	Iter int
+	// The time at which the checkpoint was saved.
+	Created time.Time
+	// Arbitrary key-value description of the experiment configuration.
+	Config map[string]string
+	// The revision of the code that produced the checkpoint.
+	GitRevision string
+	// RNG seeds used during training.
+	Seeds []int64
+	// The hash (as returned by Save) of the checkpoint this one was resumed from,
+	// or the empty string if training started from scratch.
+	ParentHash string
+	// Total training time over the entire lineage of this checkpoint,
+	// including all of its ancestors.
+	CumulativeCompute time.Duration
+}
+
+// BuildRevision returns the VCS revision that the running binary was built from,
+// or the empty string if it is not available.
+func BuildRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+
+	return ""
+}
+
+// Resume returns the metadata for a new checkpoint continuing training
+// from the checkpoint with the given metadata and hash.
+// The config, revision, seeds and cumulative compute are carried over,
+// and should be updated by the caller as training proceeds.
+func (m Metadata) Resume(hash string) Metadata {
+	config := make(map[string]string, len(m.Config))
+	for k, v := range m.Config {
+		config[k] = v
+	}
+
+	return Metadata{
+		Iter:              m.Iter,
+		Config:            config,
+		GitRevision:       m.GitRevision,
+		Seeds:             append([]int64(nil), m.Seeds...),
+		ParentHash:        hash,
+		CumulativeCompute: m.CumulativeCompute,
+	}
+}
+
+// Save writes the given strategy profile and metadata to a new checkpoint
+// file at path. The iteration and creation time in meta are set from the profile
+// and the current time. It returns the hash identifying the checkpoint,
+// which may be used as the ParentHash of checkpoints resumed from it.
+func Save(path string, profile cfr.StrategyProfile, meta Metadata) (string, error) {
+	meta.Iter = profile.Iter()
+	meta.Created = time.Now()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(f, h))
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(meta); err != nil {
+		return "", err
+	}
+
+	if err := enc.Encode(profile); err != nil {
+		return "", err
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Load reads the checkpoint file at path into the given strategy profile,
+// which must be of the same concrete type as the profile that was saved
+// (e.g. a new(cfr.PolicyTable)), and returns its metadata.
+func Load(path string, profile cfr.StrategyProfile) (Metadata, error) {
+	var meta Metadata
+	f, err := os.Open(path)
+	if err != nil {
+		return meta, err
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(bufio.NewReader(f))
+	if err := dec.Decode(&meta); err != nil {
+		return meta, err
+	}
+
+	err = dec.Decode(profile)
+	return meta, err
+}
+
+// ReadMetadata reads only the metadata of the checkpoint file at path,
+// without loading the strategy profile.
+func ReadMetadata(path string) (Metadata, error) {
+	var meta Metadata
+	f, err := os.Open(path)
+	if err != nil {
+		return meta, err
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(bufio.NewReader(f))
+	err = dec.Decode(&meta)
+	return meta, err
+}
+
+// Hash returns the hash of the checkpoint file at path.
+func Hash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Entry is a checkpoint in a lineage.
+type Entry struct {
+	Path string
+	Hash string
+	Metadata
+}
+
+// Lineage returns the chain of checkpoints leading to the one at path,
+// starting with path itself and ending with the checkpoint trained from scratch.
+// Ancestors are looked up by hash among the given candidate paths.
+// It is an error if an ancestor cannot be found.
+func Lineage(path string, candidates []string) ([]Entry, error) {
+	byHash := make(map[string]string, len(candidates))
+	for _, candidate := range candidates {
+		hash, err := Hash(candidate)
+		if err != nil {
+			return nil, err
+		}
+
+		byHash[hash] = candidate
+	}
+
+	var result []Entry
+	for {
+		hash, err := Hash(path)
+		if err != nil {
+			return nil, err
+		}
+
+		meta, err := ReadMetadata(path)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, Entry{Path: path, Hash: hash, Metadata: meta})
+		if meta.ParentHash == "" {
+			return result, nil
+		}
+
+		parent, ok := byHash[meta.ParentHash]
+		if !ok {
+			return result, fmt.Errorf("parent checkpoint %s of %s not found", meta.ParentHash, path)
+		}
+
+		path = parent
+	}
+}
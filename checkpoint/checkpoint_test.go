@@ -0,0 +1,83 @@
+package checkpoint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestLineage(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cfr-checkpoint-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	opt.Run(root)
+	policy.Update()
+
+	parentPath := filepath.Join(tmpDir, "parent")
+	parentMeta := Metadata{
+		Config:            map[string]string{"algorithm": "vanilla"},
+		Seeds:             []int64{123},
+		CumulativeCompute: time.Minute,
+	}
+	parentHash, err := Save(parentPath, policy, parentMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opt.Run(root)
+	policy.Update()
+
+	childPath := filepath.Join(tmpDir, "child")
+	childMeta := parentMeta.Resume(parentHash)
+	childMeta.CumulativeCompute += time.Minute
+	childHash, err := Save(childPath, policy, childMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lineage, err := Lineage(childPath, []string{childPath, parentPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lineage) != 2 {
+		t.Fatalf("expected lineage of 2 checkpoints, got %d", len(lineage))
+	}
+
+	if lineage[0].Hash != childHash || lineage[1].Hash != parentHash {
+		t.Errorf("unexpected lineage: %v", lineage)
+	}
+
+	if lineage[0].Iter != 3 || lineage[1].Iter != 2 {
+		t.Errorf("expected iterations 3 and 2, got %d and %d", lineage[0].Iter, lineage[1].Iter)
+	}
+
+	if lineage[0].CumulativeCompute != 2*time.Minute {
+		t.Errorf("expected cumulative compute %v, got %v", 2*time.Minute, lineage[0].CumulativeCompute)
+	}
+
+	reloaded := &cfr.PolicyTable{}
+	meta, err := Load(childPath, reloaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded.Iter() != policy.Iter() {
+		t.Errorf("expected reloaded iter %d, got %d", policy.Iter(), reloaded.Iter())
+	}
+
+	if meta.Config["algorithm"] != "vanilla" || meta.ParentHash != parentHash {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+}
@@ -0,0 +1,184 @@
+package checkpoint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// Ensemble is a read-only cfr.StrategyProfile that averages the current
+// strategies of several snapshots of a profile taken during training. When only
+// periodic snapshots were kept (e.g. from a runner that does not accumulate
+// average strategies), this approximates the CFR average strategy.
+//
+// At each InfoSet, snapshots are only averaged over those in which the InfoSet's
+// policy is not empty, so that snapshots taken before an InfoSet was first
+// visited do not pull its strategy toward uniform.
+//
+// Both GetStrategy and GetAverageStrategy return the ensemble average.
+// Regret and strategy updates are ignored.
+type Ensemble struct {
+	profiles []cfr.StrategyProfile
+	weights  []float32
+}
+
+// NewEnsemble returns an Ensemble of the given profiles, each weighted
+// by the corresponding weight.
+func NewEnsemble(profiles []cfr.StrategyProfile, weights []float32) *Ensemble {
+	if len(profiles) != len(weights) {
+		panic(fmt.Errorf("got %d weights for %d profiles", len(weights), len(profiles)))
+	}
+
+	return &Ensemble{profiles: profiles, weights: weights}
+}
+
+// LoadEnsemble loads the k checkpoints with the highest iteration among the
+// given paths into profiles created with newProfile (e.g. new(cfr.PolicyTable)),
+// and returns their Ensemble.
+//
+// Each snapshot is weighted uniformly over the iterations it represents: the number
+// of iterations since the previous checkpoint among paths (or since the start of
+// training, for the first).
+func LoadEnsemble(paths []string, k int, newProfile func() cfr.StrategyProfile) (*Ensemble, error) {
+	entries := make([]Entry, len(paths))
+	for i, path := range paths {
+		meta, err := ReadMetadata(path)
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i] = Entry{Path: path, Metadata: meta}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Iter < entries[j].Iter })
+	start := 0
+	if k < len(entries) {
+		start = len(entries) - k
+	}
+
+	var profiles []cfr.StrategyProfile
+	var weights []float32
+	for i := start; i < len(entries); i++ {
+		prevIter := 0
+		if i > 0 {
+			prevIter = entries[i-1].Iter
+		}
+
+		profile := newProfile()
+		if _, err := Load(entries[i].Path, profile); err != nil {
+			return nil, err
+		}
+
+		profiles = append(profiles, profile)
+		weights = append(weights, float32(entries[i].Iter-prevIter))
+	}
+
+	return NewEnsemble(profiles, weights), nil
+}
+
+// GetPolicy implements cfr.StrategyProfile.
+func (e *Ensemble) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
+	strategy := make([]float32, node.NumChildren())
+	var total float32
+	for i, profile := range e.profiles {
+		p := profile.GetPolicy(node)
+		if p.IsEmpty() {
+			continue
+		}
+
+		w := e.weights[i]
+		for j, x := range p.GetStrategy() {
+			strategy[j] += w * x
+		}
+
+		total += w
+	}
+
+	if total > 0 {
+		for j := range strategy {
+			strategy[j] /= total
+		}
+	} else {
+		for j := range strategy {
+			strategy[j] = 1.0 / float32(len(strategy))
+		}
+	}
+
+	return &ensemblePolicy{strategy: strategy, isEmpty: total == 0}
+}
+
+// Update implements cfr.StrategyProfile. It is a no-op.
+func (e *Ensemble) Update() {}
+
+// Iter implements cfr.StrategyProfile. It returns the highest
+// iteration of any snapshot in the ensemble.
+func (e *Ensemble) Iter() int {
+	iter := 0
+	for _, profile := range e.profiles {
+		if profile.Iter() > iter {
+			iter = profile.Iter()
+		}
+	}
+
+	return iter
+}
+
+// Close implements io.Closer by closing all snapshots in the ensemble.
+func (e *Ensemble) Close() error {
+	var err error
+	for _, profile := range e.profiles {
+		if closeErr := profile.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Ensembles are
+// assembled from checkpoints and cannot be saved themselves.
+func (e *Ensemble) MarshalBinary() ([]byte, error) {
+	return nil, fmt.Errorf("checkpoint: cannot marshal an Ensemble")
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (e *Ensemble) UnmarshalBinary(buf []byte) error {
+	return fmt.Errorf("checkpoint: cannot unmarshal an Ensemble")
+}
+
+// ensemblePolicy implements cfr.NodePolicy for the averaged strategy of an Ensemble.
+type ensemblePolicy struct {
+	strategy []float32
+	isEmpty  bool
+}
+
+func (p *ensemblePolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {}
+
+func (p *ensemblePolicy) GetStrategy() []float32 {
+	return p.strategy
+}
+
+func (p *ensemblePolicy) CopyStrategy(dst []float32) {
+	copy(dst, p.strategy)
+}
+
+func (p *ensemblePolicy) GetBaseline() []float32 {
+	return make([]float32, len(p.strategy))
+}
+
+func (p *ensemblePolicy) UpdateBaseline(w float32, action int, value float32) {}
+
+func (p *ensemblePolicy) AddStrategyWeight(w float32) {}
+
+func (p *ensemblePolicy) GetAverageStrategy() []float32 {
+	return p.strategy
+}
+
+func (p *ensemblePolicy) CopyAverageStrategy(dst []float32) {
+	copy(dst, p.strategy)
+}
+
+func (p *ensemblePolicy) IsEmpty() bool {
+	return p.isEmpty
+}
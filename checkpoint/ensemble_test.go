@@ -0,0 +1,110 @@
+package checkpoint
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestLoadEnsemble(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cfr-checkpoint-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	var paths []string
+	for i := 1; i <= 10; i++ {
+		for j := 0; j < 10*i; j++ {
+			opt.Run(root)
+			policy.Update()
+		}
+
+		path := filepath.Join(tmpDir, fmt.Sprintf("snapshot-%d", i))
+		if _, err := Save(path, policy, Metadata{}); err != nil {
+			t.Fatal(err)
+		}
+
+		paths = append(paths, path)
+	}
+
+	newProfile := func() cfr.StrategyProfile { return new(cfr.PolicyTable) }
+	ensemble, err := LoadEnsemble(paths, 3, newProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ensemble.Iter() != policy.Iter() {
+		t.Errorf("expected iter %d, got %d", policy.Iter(), ensemble.Iter())
+	}
+
+	// The last 3 snapshots represent 80, 90 and 100 iterations.
+	var snapshots []cfr.StrategyProfile
+	for _, path := range paths[7:] {
+		snapshot := newProfile()
+		if _, err := Load(path, snapshot); err != nil {
+			t.Fatal(err)
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	weights := []float32{80, 90, 100}
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		expected := make([]float32, node.NumChildren())
+		for i, snapshot := range snapshots {
+			for j, p := range snapshot.GetPolicy(node).GetStrategy() {
+				expected[j] += weights[i] * p / 270
+			}
+		}
+
+		actual := ensemble.GetPolicy(node).GetAverageStrategy()
+		for j := range expected {
+			if math.Abs(float64(actual[j]-expected[j])) > 1e-5 {
+				t.Errorf("expected ensemble strategy %v, got %v", expected, actual)
+				break
+			}
+		}
+	})
+}
+
+func TestEnsemble_SkipsEmptyPolicies(t *testing.T) {
+	root := kuhn.NewGame()
+	empty := cfr.NewPolicyTable(cfr.DiscountParams{})
+	trained := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(trained)
+	for i := 0; i < 100; i++ {
+		opt.Run(root)
+		trained.Update()
+	}
+
+	ensemble := NewEnsemble([]cfr.StrategyProfile{empty, trained}, []float32{1, 1})
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		expected := trained.GetPolicy(node).GetStrategy()
+		actual := ensemble.GetPolicy(node).GetStrategy()
+		for j := range expected {
+			if actual[j] != expected[j] {
+				t.Errorf("expected ensemble strategy %v, got %v", expected, actual)
+				break
+			}
+		}
+	})
+}
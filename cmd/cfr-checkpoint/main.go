@@ -0,0 +1,85 @@
+// Command cfr-checkpoint prints the metadata and lineage of CFR checkpoints.
+//
+// Usage:
+//
+//	cfr-checkpoint [-dir DIR] CHECKPOINT...
+//
+// For each checkpoint, its metadata is printed followed by that of each of its
+// ancestors. Ancestors are searched for among the files in -dir, which defaults
+// to the directory containing the checkpoint.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/timpalpant/go-cfr/checkpoint"
+)
+
+func main() {
+	dir := flag.String("dir", "", "Directory to search for ancestor checkpoints")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: cfr-checkpoint [-dir DIR] CHECKPOINT...")
+		os.Exit(2)
+	}
+
+	for _, path := range flag.Args() {
+		searchDir := *dir
+		if searchDir == "" {
+			searchDir = filepath.Dir(path)
+		}
+
+		if err := printLineage(path, searchDir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+func printLineage(path, dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var candidates []string
+	for _, file := range files {
+		if file.Mode().IsRegular() {
+			candidates = append(candidates, filepath.Join(dir, file.Name()))
+		}
+	}
+
+	lineage, err := checkpoint.Lineage(path, candidates)
+	for _, entry := range lineage {
+		printEntry(entry)
+	}
+
+	return err
+}
+
+func printEntry(entry checkpoint.Entry) {
+	fmt.Printf("%s:\n", entry.Path)
+	fmt.Printf("  hash:               %s\n", entry.Hash)
+	fmt.Printf("  parent:             %s\n", entry.ParentHash)
+	fmt.Printf("  iter:               %d\n", entry.Iter)
+	fmt.Printf("  created:            %s\n", entry.Created)
+	fmt.Printf("  git revision:       %s\n", entry.GitRevision)
+	fmt.Printf("  seeds:              %v\n", entry.Seeds)
+	fmt.Printf("  cumulative compute: %s\n", entry.CumulativeCompute)
+
+	keys := make([]string, 0, len(entry.Config))
+	for key := range entry.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Printf("  config %s = %s\n", key, entry.Config[key])
+	}
+}
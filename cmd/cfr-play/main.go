@@ -0,0 +1,93 @@
+// Command cfr-play plays a game interactively against a strategy
+// loaded from a checkpoint.
+//
+// Usage:
+//
+//	cfr-play [-game kuhn] [-player 0] [-show_strategy] [-seed N] CHECKPOINT
+//
+// The checkpoint must contain a cfr.PolicyTable trained on the given game.
+// Games are played repeatedly until the input ends, after which the human's
+// average utility is printed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/checkpoint"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/play"
+)
+
+// game describes how to construct a game and name its actions.
+type game struct {
+	newGame     func() cfr.GameTreeNode
+	actionNames func(node cfr.GameTreeNode) []string
+}
+
+// games are the games that may be played, by name.
+var games = map[string]game{
+	"kuhn": {
+		newGame: func() cfr.GameTreeNode { return kuhn.NewGame() },
+		actionNames: func(node cfr.GameTreeNode) []string {
+			// InfoSet keys are of the form "rrcb-K".
+			key := node.InfoSet(node.Player()).Key()
+			if history := strings.SplitN(key, "-", 2)[0]; strings.HasSuffix(history, "b") {
+				return []string{"fold", "call"}
+			}
+
+			return []string{"check", "bet"}
+		},
+	},
+}
+
+func main() {
+	gameName := flag.String("game", "kuhn", "Game to play")
+	player := flag.Int("player", 0, "Player to act as (0 or 1)")
+	showStrategy := flag.Bool("show_strategy", false, "Show the bot's strategy at each decision")
+	seed := flag.Int64("seed", 0, "Random seed (0 for a random seed)")
+	flag.Parse()
+
+	g, ok := games[*gameName]
+	if flag.NArg() != 1 || !ok || (*player != 0 && *player != 1) {
+		fmt.Fprintln(os.Stderr, "usage: cfr-play [-game kuhn] [-player 0] [-show_strategy] [-seed N] CHECKPOINT")
+		os.Exit(2)
+	}
+
+	policy := new(cfr.PolicyTable)
+	if _, err := checkpoint.Load(flag.Arg(0), policy); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	console := play.NewConsole(policy, *player, os.Stdin, os.Stdout)
+	console.ActionNames = g.actionNames
+	console.ShowStrategy = *showStrategy
+	if *seed != 0 {
+		console.Seed(*seed)
+	}
+
+	var total float64
+	var n int
+	for {
+		u, err := console.Play(g.newGame())
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		total += u
+		n++
+		fmt.Println()
+	}
+
+	if n > 0 {
+		fmt.Printf("\nAverage utility over %d games: %.4f\n", n, total/float64(n))
+	}
+}
@@ -0,0 +1,212 @@
+package cfr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// CompressedStrategy is a FrozenStrategy in which InfoSets with nearly identical
+// strategies share the probabilities of a single representative, and each InfoSet
+// only stores the index of its cluster. Many InfoSets of a converged strategy play
+// (nearly) pure or identical strategies, so it is typically much smaller than the
+// FrozenStrategy it is compressed from. It is safe for concurrent use.
+type CompressedStrategy struct {
+	// Map of InfoSet Key -> index of its cluster in clusters.
+	index map[string]uint32
+	// Location of the probabilities of the representative of each cluster in probs.
+	clusters []frozenEntry
+	probs    []float32
+}
+
+// Compress clusters the InfoSets of the strategy whose probabilities are within
+// epsilon of each other, and returns a CompressedStrategy in which each InfoSet
+// plays the mean strategy of its cluster. With epsilon = 0, only InfoSets with
+// identical strategies are merged, and the strategy is unchanged.
+//
+// InfoSets are clustered by dividing the probability simplex of each number of
+// actions into a grid of cells of width epsilon, so the probability of each action
+// in the representative strategy differs from that of each InfoSet in its cluster
+// by less than epsilon. The cost of compression can be measured by comparing the
+// StrategyExploitability of the two strategies.
+func (s *FrozenStrategy) Compress(epsilon float32) *CompressedStrategy {
+	keys := make([]string, 0, len(s.index))
+	for key := range s.index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	c := &CompressedStrategy{
+		index: make(map[string]uint32, len(keys)),
+	}
+
+	// Sum of the strategies of the members of each cluster, and their number.
+	var sums [][]float32
+	var counts []int
+	cells := make(map[string]uint32)
+	for _, key := range keys {
+		p, _ := s.Probabilities(key)
+		cell := gridCell(p, epsilon)
+		cluster, ok := cells[cell]
+		if !ok {
+			cluster = uint32(len(sums))
+			cells[cell] = cluster
+			sums = append(sums, make([]float32, len(p)))
+			counts = append(counts, 0)
+		}
+
+		for i, x := range p {
+			sums[cluster][i] += x
+		}
+
+		counts[cluster]++
+		c.index[key] = cluster
+	}
+
+	c.clusters = make([]frozenEntry, len(sums))
+	for cluster, sum := range sums {
+		c.clusters[cluster] = frozenEntry{offset: uint32(len(c.probs)), n: uint32(len(sum))}
+		for _, x := range sum {
+			c.probs = append(c.probs, x/float32(counts[cluster]))
+		}
+	}
+
+	return c
+}
+
+// gridCell returns the key of the grid cell of width epsilon containing p,
+// or of p itself if epsilon is zero. Strategies with different numbers of
+// actions are in different cells.
+func gridCell(p []float32, epsilon float32) string {
+	buf := make([]byte, 0, (len(p)+1)*binary.MaxVarintLen32)
+	buf = binary.AppendUvarint(buf, uint64(len(p)))
+	for _, x := range p {
+		var v uint64
+		if epsilon > 0 {
+			v = uint64(math.Floor(float64(x / epsilon)))
+		} else {
+			v = uint64(math.Float32bits(x))
+		}
+
+		buf = binary.AppendUvarint(buf, v)
+	}
+
+	return string(buf)
+}
+
+// Len returns the number of InfoSets in the strategy.
+func (c *CompressedStrategy) Len() int {
+	return len(c.index)
+}
+
+// NumClusters returns the number of distinct strategies that are stored.
+func (c *CompressedStrategy) NumClusters() int {
+	return len(c.clusters)
+}
+
+// Probabilities returns the probability of each action at the InfoSet with
+// the given key, and false if it is not in the strategy. The returned slice
+// must not be modified.
+func (c *CompressedStrategy) Probabilities(key string) ([]float32, bool) {
+	cluster, ok := c.index[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := c.clusters[cluster]
+	return c.probs[e.offset : e.offset+e.n], true
+}
+
+// ProbabilitiesInto is as FrozenStrategy.ProbabilitiesInto. It does not allocate.
+func (c *CompressedStrategy) ProbabilitiesInto(key []byte, dst []float32) (n int, ok bool) {
+	cluster, ok := c.index[string(key)]
+	if !ok {
+		return 0, false
+	}
+
+	e := c.clusters[cluster]
+	copy(dst, c.probs[e.offset:e.offset+e.n])
+	return int(e.n), true
+}
+
+// Strategy is as FrozenStrategy.Strategy.
+func (c *CompressedStrategy) Strategy(node GameTreeNode) []float32 {
+	if p, ok := c.Probabilities(nodeKey(node)); ok {
+		return p
+	}
+
+	return uniformStrategy(node.NumChildren())
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (c *CompressedStrategy) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(c.index); err != nil {
+		return nil, err
+	}
+
+	offsets := make([]uint32, len(c.clusters))
+	for i, e := range c.clusters {
+		offsets[i] = e.offset
+	}
+
+	if err := enc.Encode(offsets); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(c.probs); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (c *CompressedStrategy) UnmarshalBinary(buf []byte) error {
+	dec := gob.NewDecoder(bytes.NewReader(buf))
+	var index map[string]uint32
+	if err := dec.Decode(&index); err != nil {
+		return err
+	}
+
+	var offsets []uint32
+	if err := dec.Decode(&offsets); err != nil {
+		return err
+	}
+
+	var probs []float32
+	if err := dec.Decode(&probs); err != nil {
+		return err
+	}
+
+	// The representatives are stored contiguously, so the number of
+	// probabilities of each one is the distance to the next.
+	clusters := make([]frozenEntry, len(offsets))
+	for i, offset := range offsets {
+		end := uint32(len(probs))
+		if i+1 < len(offsets) {
+			end = offsets[i+1]
+		}
+
+		if offset > end {
+			return fmt.Errorf("invalid compressed strategy: cluster %d has offset %d > %d", i, offset, end)
+		}
+
+		clusters[i] = frozenEntry{offset: offset, n: end - offset}
+	}
+
+	for key, cluster := range index {
+		if int(cluster) >= len(clusters) {
+			return fmt.Errorf("invalid compressed strategy: %q has cluster %d of %d", key, cluster, len(clusters))
+		}
+	}
+
+	c.index = index
+	c.clusters = clusters
+	c.probs = probs
+	return nil
+}
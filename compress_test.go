@@ -0,0 +1,97 @@
+package cfr_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestCompressedStrategy(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	for i := 0; i < 1000; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+
+	frozen := policy.Freeze()
+	// Only InfoSets with identical (e.g. pure) strategies are merged losslessly.
+	lossless := frozen.Compress(0)
+	if lossless.Len() != frozen.Len() || lossless.NumClusters() > frozen.Len() {
+		t.Errorf("expected %d InfoSets in at most as many clusters, got %d in %d clusters",
+			frozen.Len(), lossless.Len(), lossless.NumClusters())
+	}
+
+	const epsilon = 0.1
+	compressed := frozen.Compress(epsilon)
+	t.Logf("Compressed %d InfoSets into %d clusters", compressed.Len(), compressed.NumClusters())
+	if compressed.Len() != frozen.Len() || compressed.NumClusters() >= frozen.Len() {
+		t.Errorf("expected %d InfoSets in fewer clusters, got %d in %d clusters",
+			frozen.Len(), compressed.Len(), compressed.NumClusters())
+	}
+
+	dst := make([]float32, 2)
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		expected, _ := frozen.Probabilities(key)
+		if p := lossless.Strategy(node); !reflect.DeepEqual(p, expected) {
+			t.Errorf("%s: expected lossless compression to be unchanged (%v), got %v", key, expected, p)
+		}
+
+		p, ok := compressed.Probabilities(key)
+		if !ok {
+			t.Errorf("%s: expected InfoSet to be in compressed strategy", key)
+			return
+		}
+
+		for i := range p {
+			if math.Abs(float64(p[i]-expected[i])) >= epsilon {
+				t.Errorf("%s: expected probabilities within %v of %v, got %v", key, epsilon, expected, p)
+				break
+			}
+		}
+
+		if n, ok := compressed.ProbabilitiesInto([]byte(key), dst); !ok || n != 2 || !reflect.DeepEqual(dst, p) {
+			t.Errorf("%s: expected %v, got %v (n=%d)", key, p, dst, n)
+		}
+	})
+
+	before := cfr.StrategyExploitability(root, frozen.Strategy)
+	after := cfr.StrategyExploitability(root, compressed.Strategy)
+	t.Logf("Exploitability before compression: %.4f, after: %.4f", before, after)
+	if after-before > 0.05 {
+		t.Errorf("expected compression to cost little exploitability, got %v -> %v", before, after)
+	}
+
+	buf, err := compressed.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reloaded cfr.CompressedStrategy
+	if err := reloaded.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(&reloaded, compressed) {
+		t.Error("expected compressed strategy to survive a binary round trip")
+	}
+
+	key := []byte("rr-K")
+	allocs := testing.AllocsPerRun(100, func() {
+		compressed.ProbabilitiesInto(key, dst)
+	})
+
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
@@ -0,0 +1,600 @@
+// Package config defines a typed schema for describing a CFR experiment
+// in a single JSON file: the game, algorithm, discounting, sampling,
+// strategy store and evaluation settings.
+//
+// Since JSON is a subset of YAML, configs may also be written in YAML
+// that restricts itself to JSON (flow) syntax.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/checkpoint"
+	"github.com/timpalpant/go-cfr/profiling"
+	"github.com/timpalpant/go-cfr/sampling"
+)
+
+// Config fully describes a CFR experiment.
+type Config struct {
+	Game       GameConfig       `json:"game"`
+	Algorithm  AlgorithmConfig  `json:"algorithm"`
+	Discount   DiscountConfig   `json:"discount"`
+	Sampling   SamplingConfig   `json:"sampling"`
+	Store      StoreConfig      `json:"store"`
+	Evaluation EvaluationConfig `json:"evaluation"`
+	Budget     BudgetConfig     `json:"budget"`
+	Profiling  *ProfilingConfig `json:"profiling,omitempty"`
+}
+
+// GameConfig identifies the game to be solved. The interpretation of
+// Name and Params is left to the program constructing the game tree.
+type GameConfig struct {
+	Name   string            `json:"name"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Supported values of AlgorithmConfig.Name.
+const (
+	Vanilla               = "vanilla"
+	ChanceSampling        = "chance_sampling"
+	ExternalSampling      = "external_sampling"
+	OutcomeSampling       = "outcome_sampling"
+	MCCFR                 = "mccfr"
+	GeneralizedSampling   = "generalized_sampling"
+	OnlineOutcomeSampling = "online_outcome_sampling"
+	VRMCCFR               = "vrmccfr"
+	Lazy                  = "lazy"
+	Pure                  = "pure"
+	CFRBR                 = "cfr_br"
+	FictitiousPlay        = "xfp"
+)
+
+// Supported values of AlgorithmConfig.Schedule.
+const (
+	AlternatingSchedule  = "alternating"
+	SimultaneousSchedule = "simultaneous"
+)
+
+// AlgorithmConfig selects the CFR variant and how long to run it.
+type AlgorithmConfig struct {
+	Name       string `json:"name"`
+	Iterations int    `json:"iterations"`
+	// Seeds for the random number generators. If empty, training is
+	// not reproducible.
+	Seeds []int64 `json:"seeds,omitempty"`
+	// Reach probability below which Lazy-CFR defers traversal of a subtree.
+	LazyThreshold float32 `json:"lazy_threshold,omitempty"`
+	// The player who runs CFR in CFR-BR. The other plays a best response.
+	CFRPlayer int `json:"cfr_player,omitempty"`
+	// Number of traversals per iteration of the Monte Carlo algorithms
+	// (see cfr.Minibatch). Zero or one performs a single traversal.
+	MinibatchSize int `json:"minibatch_size,omitempty"`
+	// Which players are updated on each iteration by the algorithms that
+	// traverse for one player at a time (see cfr.PlayerSchedule).
+	// The default is alternating.
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// Default value of DiscountConfig.HedgeLearningRate.
+const defaultHedgeLearningRate = 1.0
+
+// DiscountConfig corresponds to cfr.DiscountParams.
+type DiscountConfig struct {
+	RegretMatchingPlus       bool    `json:"regret_matching_plus,omitempty"`
+	PredictiveRegretMatching bool    `json:"predictive_regret_matching,omitempty"`
+	LinearWeighting          bool    `json:"linear_weighting,omitempty"`
+	Alpha                    float32 `json:"alpha,omitempty"`
+	Beta                     float32 `json:"beta,omitempty"`
+	Gamma                    float32 `json:"gamma,omitempty"`
+	CompensatedSummation     bool    `json:"compensated_summation,omitempty"`
+	// Use Discounted CFR with cfr.DefaultDCFRSchedule,
+	// instead of LinearWeighting and Alpha/Beta/Gamma.
+	DCFR bool `json:"dcfr,omitempty"`
+	// Weight of new samples in the moving average of VR-MCCFR baselines.
+	BaselineDecay float32 `json:"baseline_decay,omitempty"`
+	// Minimum number of samples before dominated actions are eliminated.
+	// Zero disables elimination.
+	DominanceMinSamples int `json:"dominance_min_samples,omitempty"`
+	// Dynamic thresholding constant. Zero disables thresholding.
+	DynamicThreshold float32 `json:"dynamic_threshold,omitempty"`
+	// Use Hedge instead of regret matching, with a learning rate of
+	// HedgeLearningRate / sqrt(t) on iteration t. Zero uses the default of 1.
+	Hedge             bool    `json:"hedge,omitempty"`
+	HedgeLearningRate float32 `json:"hedge_learning_rate,omitempty"`
+	// Use optimistic FTRL instead of regret matching, with the given constant
+	// step size. Zero disables it.
+	OptimisticFTRLStepSize float32 `json:"optimistic_ftrl_step_size,omitempty"`
+}
+
+// Params returns the cfr.DiscountParams described by the config.
+func (c DiscountConfig) Params() cfr.DiscountParams {
+	params := cfr.DiscountParams{
+		UseRegretMatchingPlus:       c.RegretMatchingPlus,
+		UsePredictiveRegretMatching: c.PredictiveRegretMatching,
+		LinearWeighting:             c.LinearWeighting,
+		DiscountAlpha:               c.Alpha,
+		DiscountBeta:                c.Beta,
+		DiscountGamma:               c.Gamma,
+		CompensatedSummation:        c.CompensatedSummation,
+		BaselineDecay:               c.BaselineDecay,
+		DominanceMinSamples:         c.DominanceMinSamples,
+		DynamicThreshold:            c.DynamicThreshold,
+	}
+
+	if c.Hedge {
+		eta := c.HedgeLearningRate
+		if eta == 0 {
+			eta = defaultHedgeLearningRate
+		}
+
+		params.Minimizer = cfr.HedgeMinimizer{StepSize: eta, StepDecay: 0.5}
+	}
+
+	if c.OptimisticFTRLStepSize != 0 {
+		params.Minimizer = cfr.FTRLMinimizer{
+			StepSize:      c.OptimisticFTRLStepSize,
+			UsePrediction: true,
+		}
+	}
+
+	if c.DCFR {
+		params.Schedule = cfr.DefaultDCFRSchedule
+	}
+
+	return params
+}
+
+// Supported values of SamplingConfig.Sampler.
+const (
+	ExternalSampler        = "external"
+	OutcomeSampler         = "outcome"
+	RobustSampler          = "robust"
+	WeightedRobustSampler  = "weighted_robust"
+	MultiOutcomeSampler    = "multi_outcome"
+	AverageStrategySampler = "average_strategy"
+	ChanceOnlySampler      = "chance_only"
+)
+
+// SamplingConfig selects the sampler used by the Monte Carlo algorithms.
+// It is ignored by vanilla, chance sampling and external sampling CFR,
+// and only ExplorationEps is used by outcome sampling CFR.
+type SamplingConfig struct {
+	Sampler string `json:"sampler"`
+	// Exploration for outcome, weighted robust and multi-outcome sampling.
+	// It must be positive for weighted robust sampling.
+	ExplorationEps float32 `json:"exploration_eps,omitempty"`
+	// Number of actions sampled by (weighted) robust and multi-outcome sampling.
+	K int `json:"k,omitempty"`
+	// Parameters of average strategy sampling.
+	AverageStrategy sampling.AverageStrategyParams `json:"average_strategy"`
+	// Chance nodes with at most this many outcomes are enumerated rather than
+	// sampled by generalized sampling. Zero samples all chance nodes.
+	ChanceEnumerationThreshold int `json:"chance_enumeration_threshold,omitempty"`
+	// If true, generalized sampling corrects the sampled values of actions
+	// with learned per-action baselines. See discount.baseline_decay.
+	BaselineCorrection bool `json:"baseline_correction,omitempty"`
+	// If set, the sampler is wrapped with regret-based pruning.
+	RegretPruning *RegretPruningConfig `json:"regret_pruning,omitempty"`
+}
+
+// RegretPruningConfig corresponds to the arguments of sampling.NewRegretPruningSampler.
+// Actions are only pruned in policies that track the samples of their regrets,
+// so it requires discount.dominance_min_samples.
+type RegretPruningConfig struct {
+	Threshold float32 `json:"threshold"`
+	Explore   float32 `json:"explore"`
+}
+
+// Supported values of StoreConfig.Type.
+const (
+	MemoryStore           = "memory"
+	CompactStore          = "compact"
+	PrefixCompressedStore = "prefix_compressed"
+	RocksDBStore          = "rocksdb"
+)
+
+// StoreConfig describes where the strategy profile is kept.
+type StoreConfig struct {
+	Type string `json:"type"`
+	// Path to the database, for on-disk stores.
+	Path string `json:"path,omitempty"`
+	// Prefetch child policies in the background (see cfr.PrefetchingProfile).
+	// Only supported by on-disk stores.
+	Prefetch bool `json:"prefetch,omitempty"`
+}
+
+// EvaluationConfig controls how often progress is evaluated and saved.
+type EvaluationConfig struct {
+	// Number of iterations between evaluations. Zero disables evaluation.
+	Interval int `json:"interval,omitempty"`
+	// Number of iterations between checkpoints. Zero disables checkpointing.
+	CheckpointInterval int `json:"checkpoint_interval,omitempty"`
+	// Directory in which checkpoints are written.
+	CheckpointDir string `json:"checkpoint_dir,omitempty"`
+	// Maximum fraction of training time spent writing checkpoints, for the
+	// adaptive checkpointing of a trainer (see Config.NewTrainer).
+	// Zero disables adaptive checkpointing.
+	CheckpointOverhead float64 `json:"checkpoint_overhead,omitempty"`
+}
+
+// ProfilingConfig enables capturing runtime profiles for a window of
+// iterations during training (see profiling.Profiler).
+type ProfilingConfig struct {
+	StartIter int `json:"start_iter"`
+	EndIter   int `json:"end_iter"`
+	// Directory in which profiles are written.
+	// Defaults to evaluation.checkpoint_dir.
+	Dir   string `json:"dir,omitempty"`
+	CPU   bool   `json:"cpu,omitempty"`
+	Heap  bool   `json:"heap,omitempty"`
+	Trace bool   `json:"trace,omitempty"`
+}
+
+// NewProfiler returns a new profiling.Profiler as described by the config.
+func (c ProfilingConfig) NewProfiler() *profiling.Profiler {
+	return profiling.New(profiling.Params{
+		StartIter: c.StartIter,
+		EndIter:   c.EndIter,
+		Dir:       c.Dir,
+		CPU:       c.CPU,
+		Heap:      c.Heap,
+		Trace:     c.Trace,
+	})
+}
+
+// BudgetConfig limits the resources an experiment is expected to use.
+// It is checked against the estimates of Config.Plan. Zero means unlimited.
+type BudgetConfig struct {
+	MaxMemoryBytes int64 `json:"max_memory_bytes,omitempty"`
+	MaxDiskBytes   int64 `json:"max_disk_bytes,omitempty"`
+	// Max total wall-clock time for all iterations, in seconds.
+	MaxWallTimeSeconds float64 `json:"max_wall_time_seconds,omitempty"`
+}
+
+// Load reads, defaults and validates the config in the file at path.
+func Load(path string) (*Config, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return c, nil
+}
+
+// Parse reads, defaults and validates a JSON config from r.
+// Unknown fields are an error, so that typos are not silently ignored.
+func Parse(r io.Reader) (*Config, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var c Config
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+
+	c.SetDefaults()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// SetDefaults fills in default values for any unset fields.
+func (c *Config) SetDefaults() {
+	if c.Algorithm.Name == "" {
+		c.Algorithm.Name = Vanilla
+	}
+
+	if c.Store.Type == "" {
+		c.Store.Type = MemoryStore
+	}
+
+	if c.usesSampler() && c.Sampling.Sampler == "" {
+		c.Sampling.Sampler = ExternalSampler
+	}
+
+	if c.Sampling.K == 0 {
+		switch c.Sampling.Sampler {
+		case RobustSampler, WeightedRobustSampler, MultiOutcomeSampler:
+			c.Sampling.K = 1
+		}
+	}
+
+	if c.Profiling != nil && c.Profiling.Dir == "" {
+		c.Profiling.Dir = c.Evaluation.CheckpointDir
+	}
+}
+
+// Validate returns an error if the config is incomplete or inconsistent.
+func (c *Config) Validate() error {
+	if c.Game.Name == "" {
+		return fmt.Errorf("game.name is required")
+	}
+
+	switch c.Algorithm.Name {
+	case Vanilla, ChanceSampling, ExternalSampling, OutcomeSampling, MCCFR, GeneralizedSampling, OnlineOutcomeSampling, VRMCCFR, Lazy, Pure, CFRBR, FictitiousPlay:
+	default:
+		return fmt.Errorf("unknown algorithm.name: %q", c.Algorithm.Name)
+	}
+
+	if c.Algorithm.Iterations < 0 {
+		return fmt.Errorf("algorithm.iterations must be non-negative, got %d", c.Algorithm.Iterations)
+	}
+
+	if c.Algorithm.LazyThreshold < 0 {
+		return fmt.Errorf("algorithm.lazy_threshold must be non-negative, got %v", c.Algorithm.LazyThreshold)
+	}
+
+	if c.Algorithm.CFRPlayer != 0 && c.Algorithm.CFRPlayer != 1 {
+		return fmt.Errorf("algorithm.cfr_player must be 0 or 1, got %d", c.Algorithm.CFRPlayer)
+	}
+
+	switch c.Algorithm.Schedule {
+	case "", AlternatingSchedule, SimultaneousSchedule:
+	default:
+		return fmt.Errorf("unknown algorithm.schedule: %q", c.Algorithm.Schedule)
+	}
+
+	if c.Algorithm.MinibatchSize < 0 {
+		return fmt.Errorf("algorithm.minibatch_size must be non-negative, got %d", c.Algorithm.MinibatchSize)
+	}
+
+	if c.Discount.BaselineDecay < 0 || c.Discount.BaselineDecay > 1 {
+		return fmt.Errorf("discount.baseline_decay must be in [0, 1], got %v", c.Discount.BaselineDecay)
+	}
+
+	if c.Discount.DominanceMinSamples < 0 {
+		return fmt.Errorf("discount.dominance_min_samples must be >= 0, got %d", c.Discount.DominanceMinSamples)
+	}
+
+	if c.Discount.DynamicThreshold < 0 {
+		return fmt.Errorf("discount.dynamic_threshold must be >= 0, got %v", c.Discount.DynamicThreshold)
+	}
+
+	if c.Discount.HedgeLearningRate < 0 {
+		return fmt.Errorf("discount.hedge_learning_rate must be >= 0, got %v", c.Discount.HedgeLearningRate)
+	}
+
+	if c.Discount.OptimisticFTRLStepSize < 0 {
+		return fmt.Errorf("discount.optimistic_ftrl_step_size must be >= 0, got %v", c.Discount.OptimisticFTRLStepSize)
+	}
+
+	if c.Discount.Hedge && c.Discount.OptimisticFTRLStepSize != 0 {
+		return fmt.Errorf("discount.hedge and discount.optimistic_ftrl_step_size are mutually exclusive")
+	}
+
+	if c.usesSampler() {
+		if err := c.Sampling.validate(); err != nil {
+			return err
+		}
+
+		if c.Sampling.RegretPruning != nil && c.Discount.DominanceMinSamples == 0 {
+			return fmt.Errorf("sampling.regret_pruning requires discount.dominance_min_samples")
+		}
+	} else if c.Algorithm.Name == OutcomeSampling {
+		if err := c.Sampling.validateExploration(); err != nil {
+			return err
+		}
+	}
+
+	switch c.Store.Type {
+	case MemoryStore, CompactStore, PrefixCompressedStore:
+		if c.Store.Prefetch {
+			return fmt.Errorf("store.prefetch is not supported for store.type %q", c.Store.Type)
+		}
+	case RocksDBStore:
+		if c.Store.Path == "" {
+			return fmt.Errorf("store.path is required for store.type %q", c.Store.Type)
+		}
+	default:
+		return fmt.Errorf("unknown store.type: %q", c.Store.Type)
+	}
+
+	if c.Evaluation.Interval < 0 || c.Evaluation.CheckpointInterval < 0 {
+		return fmt.Errorf("evaluation intervals must be non-negative")
+	}
+
+	if c.Evaluation.CheckpointOverhead < 0 {
+		return fmt.Errorf("evaluation.checkpoint_overhead must be >= 0, got %v", c.Evaluation.CheckpointOverhead)
+	}
+
+	checkpointing := c.Evaluation.CheckpointInterval > 0 || c.Evaluation.CheckpointOverhead > 0
+	if checkpointing && c.Evaluation.CheckpointDir == "" {
+		return fmt.Errorf("evaluation.checkpoint_dir is required when checkpointing")
+	}
+
+	if c.Budget.MaxMemoryBytes < 0 || c.Budget.MaxDiskBytes < 0 || c.Budget.MaxWallTimeSeconds < 0 {
+		return fmt.Errorf("budget limits must be non-negative")
+	}
+
+	if c.Profiling != nil {
+		if c.Profiling.StartIter < 0 || c.Profiling.EndIter <= c.Profiling.StartIter {
+			return fmt.Errorf("profiling window [%d, %d) is empty",
+				c.Profiling.StartIter, c.Profiling.EndIter)
+		}
+
+		if c.Profiling.Dir == "" {
+			return fmt.Errorf("profiling.dir is required when evaluation.checkpoint_dir is not set")
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) usesSampler() bool {
+	switch c.Algorithm.Name {
+	case MCCFR, GeneralizedSampling, OnlineOutcomeSampling, VRMCCFR:
+		return true
+	}
+
+	return false
+}
+
+func (c SamplingConfig) validate() error {
+	switch c.Sampler {
+	case ExternalSampler, OutcomeSampler, AverageStrategySampler, ChanceOnlySampler:
+	case RobustSampler, WeightedRobustSampler, MultiOutcomeSampler:
+		if c.K < 1 {
+			return fmt.Errorf("sampling.k must be positive, got %d", c.K)
+		}
+
+		// Without exploration, actions the current strategy never plays are
+		// never sampled, and their regrets are biased.
+		if c.Sampler == WeightedRobustSampler && c.ExplorationEps <= 0 {
+			return fmt.Errorf("sampling.exploration_eps must be positive for sampler %q, got %v",
+				c.Sampler, c.ExplorationEps)
+		}
+	default:
+		return fmt.Errorf("unknown sampling.sampler: %q", c.Sampler)
+	}
+
+	if err := c.validateExploration(); err != nil {
+		return err
+	}
+
+	if c.ChanceEnumerationThreshold < 0 {
+		return fmt.Errorf("sampling.chance_enumeration_threshold must be non-negative, got %d",
+			c.ChanceEnumerationThreshold)
+	}
+
+	if c.RegretPruning != nil && (c.RegretPruning.Explore <= 0 || c.RegretPruning.Explore > 1) {
+		return fmt.Errorf("sampling.regret_pruning.explore must be in (0, 1], got %v",
+			c.RegretPruning.Explore)
+	}
+
+	return nil
+}
+
+func (c SamplingConfig) validateExploration() error {
+	if c.ExplorationEps < 0 || c.ExplorationEps > 1 {
+		return fmt.Errorf("sampling.exploration_eps must be in [0, 1], got %v", c.ExplorationEps)
+	}
+
+	return nil
+}
+
+// NewSampler returns a new cfr.Sampler as described by the config.
+func (c SamplingConfig) NewSampler() cfr.Sampler {
+	var s cfr.Sampler
+	switch c.Sampler {
+	case OutcomeSampler:
+		s = sampling.NewOutcomeSampler(c.ExplorationEps)
+	case RobustSampler:
+		s = sampling.NewRobustSampler(c.K)
+	case WeightedRobustSampler:
+		s = sampling.NewWeightedRobustSampler(c.K, c.ExplorationEps)
+	case MultiOutcomeSampler:
+		s = sampling.NewMultiOutcomeSampler(c.K, c.ExplorationEps)
+	case AverageStrategySampler:
+		s = sampling.NewAverageStrategySampler(c.AverageStrategy)
+	case ChanceOnlySampler:
+		s = sampling.NewChanceOnlySampler()
+	default:
+		s = sampling.NewExternalSampler()
+	}
+
+	if c.RegretPruning != nil {
+		s = sampling.NewRegretPruningSampler(s, c.RegretPruning.Threshold, c.RegretPruning.Explore)
+	}
+
+	return s
+}
+
+// Runner is the interface implemented by all CFR algorithms.
+type Runner = cfr.Runner
+
+// NewStrategyProfile returns a new in-memory strategy profile as described by
+// the config. On-disk stores must be constructed by the caller, to avoid
+// depending on their storage backends here.
+func (c *Config) NewStrategyProfile() (cfr.StrategyProfile, error) {
+	switch c.Store.Type {
+	case MemoryStore:
+		return cfr.NewPolicyTable(c.Discount.Params()), nil
+	case CompactStore:
+		return cfr.NewCompactPolicyTable(c.Discount.Params()), nil
+	case PrefixCompressedStore:
+		return cfr.NewPrefixCompressedPolicyTable(c.Discount.Params()), nil
+	}
+
+	return nil, fmt.Errorf("store.type %q must be constructed by the caller", c.Store.Type)
+}
+
+// NewRunner returns the CFR algorithm described by the config,
+// accumulating into the given strategy profile.
+func (c *Config) NewRunner(profile cfr.StrategyProfile) Runner {
+	runner := c.newRunner(profile)
+	if c.Algorithm.Schedule == SimultaneousSchedule {
+		if r, ok := runner.(interface{ SetPlayerSchedule(cfr.PlayerSchedule) }); ok {
+			r.SetPlayerSchedule(cfr.SimultaneousUpdates)
+		}
+	}
+
+	if c.Algorithm.MinibatchSize > 1 {
+		return cfr.NewMinibatch(c.Algorithm.MinibatchSize, runner)
+	}
+
+	return runner
+}
+
+// NewTrainer returns a cfr.Trainer that trains the given strategy profile on the
+// game tree rooted at root with the CFR algorithm described by the config. If
+// evaluation.checkpoint_overhead is set, it checkpoints adaptively (see
+// cfr.Trainer.SetCheckpointer) to a file in evaluation.checkpoint_dir named by
+// the iteration of the profile.
+func (c *Config) NewTrainer(root cfr.GameTreeNode, profile cfr.StrategyProfile) *cfr.Trainer {
+	trainer := cfr.NewTrainer(root, profile, c.NewRunner(profile))
+	if c.Evaluation.CheckpointOverhead > 0 {
+		dir := c.Evaluation.CheckpointDir
+		meta := checkpoint.Metadata{GitRevision: checkpoint.BuildRevision()}
+		trainer.SetCheckpointer(func(profile cfr.StrategyProfile) error {
+			path := filepath.Join(dir, fmt.Sprintf("checkpoint-%d", profile.Iter()))
+			_, err := checkpoint.Save(path, profile, meta)
+			return err
+		}, c.Evaluation.CheckpointOverhead)
+	}
+
+	return trainer
+}
+
+func (c *Config) newRunner(profile cfr.StrategyProfile) Runner {
+	switch c.Algorithm.Name {
+	case ChanceSampling:
+		return cfr.NewChanceSampling(profile)
+	case ExternalSampling:
+		return cfr.NewExternalSampling(profile)
+	case OutcomeSampling:
+		return cfr.NewOutcomeSampling(profile, c.Sampling.ExplorationEps)
+	case MCCFR:
+		return cfr.NewMCCFR(profile, c.Sampling.NewSampler())
+	case GeneralizedSampling:
+		gs := cfr.NewGeneralizedSampling(profile, c.Sampling.NewSampler())
+		gs.SetChanceEnumerationThreshold(c.Sampling.ChanceEnumerationThreshold)
+		gs.SetBaselineCorrection(c.Sampling.BaselineCorrection)
+		return gs
+	case OnlineOutcomeSampling:
+		return cfr.NewOnlineOutcomeSamplingCFR(profile, c.Sampling.NewSampler())
+	case VRMCCFR:
+		return cfr.NewVRMCCFR(profile, c.Sampling.NewSampler(), c.Sampling.NewSampler())
+	case Lazy:
+		return cfr.NewLazy(profile, c.Algorithm.LazyThreshold)
+	case Pure:
+		return cfr.NewPure(profile)
+	case CFRBR:
+		return cfr.NewCFRBR(profile, c.Algorithm.CFRPlayer)
+	case FictitiousPlay:
+		return cfr.NewFictitiousPlay(profile)
+	default:
+		return cfr.New(profile)
+	}
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/checkpoint"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestParse(t *testing.T) {
+	c, err := Parse(strings.NewReader(`{
+		"game": {"name": "kuhn"},
+		"algorithm": {"name": "mccfr", "iterations": 100, "minibatch_size": 4},
+		"discount": {"linear_weighting": true},
+		"sampling": {"sampler": "robust"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Sampling.K != 1 {
+		t.Errorf("expected default sampling.k = 1, got %d", c.Sampling.K)
+	}
+
+	if c.Store.Type != MemoryStore {
+		t.Errorf("expected default store.type %q, got %q", MemoryStore, c.Store.Type)
+	}
+
+	if !c.Discount.Params().LinearWeighting {
+		t.Error("expected linear weighting to be enabled")
+	}
+
+	profile, err := c.NewStrategyProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opt := c.NewRunner(profile)
+	if _, ok := opt.(*cfr.Minibatch); !ok {
+		t.Errorf("expected minibatch runner, got %T", opt)
+	}
+
+	root := kuhn.NewGame()
+	for i := 0; i < c.Algorithm.Iterations; i++ {
+		opt.Run(root)
+		profile.Update()
+	}
+}
+
+func TestNewTrainer_Checkpoints(t *testing.T) {
+	c, err := Parse(strings.NewReader(`{
+		"game": {"name": "kuhn"},
+		"algorithm": {"name": "vanilla", "iterations": 100}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Evaluation.CheckpointOverhead = 1.0
+	if err := c.Validate(); err == nil {
+		t.Error("expected error for adaptive checkpointing without checkpoint_dir")
+	}
+
+	c.Evaluation.CheckpointDir = t.TempDir()
+	profile, err := c.NewStrategyProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trainer := c.NewTrainer(kuhn.NewGame(), profile)
+	if err := trainer.Train(c.Algorithm.Iterations); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(c.Evaluation.CheckpointDir, "checkpoint-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) == 0 {
+		t.Fatal("expected checkpoints to be written")
+	}
+
+	for _, path := range paths {
+		var restored cfr.PolicyTable
+		meta, err := checkpoint.Load(path, &restored)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if filepath.Base(path) != "checkpoint-"+strconv.Itoa(meta.Iter) {
+			t.Errorf("expected checkpoint of iteration %d to be named by it, got %v", meta.Iter, path)
+		}
+	}
+}
+
+func TestParse_DCFR(t *testing.T) {
+	c, err := Parse(strings.NewReader(`{
+		"game": {"name": "kuhn"},
+		"algorithm": {"name": "vanilla", "iterations": 10, "schedule": "simultaneous"},
+		"discount": {"dcfr": true}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := c.Discount.Params().Schedule; s != cfr.DefaultDCFRSchedule {
+		t.Errorf("expected default DCFR schedule, got %v", s)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, tc := range []string{
+		`{"algorithm": {"name": "vanilla"}}`,
+		`{"game": {"name": "kuhn"}, "algorithm": {"name": "foo"}}`,
+		`{"game": {"name": "kuhn"}, "algorthm": {"name": "vanilla"}}`,
+		`{"game": {"name": "kuhn"}, "store": {"type": "rocksdb"}}`,
+		`{"game": {"name": "kuhn"}, "algorithm": {"name": "mccfr"}, "sampling": {"sampler": "outcome", "exploration_eps": 2}}`,
+		`{"game": {"name": "kuhn"}, "algorithm": {"name": "mccfr", "minibatch_size": -1}}`,
+		`{"game": {"name": "kuhn"}, "algorithm": {"name": "generalized_sampling"}, "sampling": {"sampler": "robust", "chance_enumeration_threshold": -1}}`,
+		`{"game": {"name": "kuhn"}, "algorithm": {"name": "mccfr", "schedule": "sequential"}}`,
+		`{"game": {"name": "kuhn"}, "algorithm": {"name": "mccfr"}, "sampling": {"sampler": "weighted_robust"}}`,
+		`{"game": {"name": "kuhn"}, "algorithm": {"name": "mccfr"}, "sampling": {"regret_pruning": {"threshold": -10, "explore": 0.05}}}`,
+	} {
+		if _, err := Parse(strings.NewReader(tc)); err == nil {
+			t.Errorf("expected error parsing config: %s", tc)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	c, err := Parse(strings.NewReader(`{
+		"game": {"name": "kuhn"},
+		"algorithm": {"name": "vanilla", "iterations": 1000},
+		"evaluation": {"checkpoint_interval": 100, "checkpoint_dir": "/tmp"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := kuhn.NewGame()
+	stats := tree.GetStats(root)
+	plan, err := c.Plan(root, stats, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.PolicyTableMemory <= 0 || plan.StoreDisk != 0 {
+		t.Errorf("unexpected store estimates: %+v", plan)
+	}
+
+	if expected := 10 * stats.PolicyTableEncodedSize(c.Discount.Params()); plan.CheckpointDisk != expected {
+		t.Errorf("expected checkpoint disk %d, got %d", expected, plan.CheckpointDisk)
+	}
+
+	if plan.IterationTime <= 0 {
+		t.Errorf("expected positive iteration time, got %v", plan.IterationTime)
+	}
+
+	c.Budget.MaxMemoryBytes = plan.PolicyTableMemory - 1
+	if _, err := c.Plan(root, stats, 0); err == nil {
+		t.Error("expected error when memory budget is exceeded")
+	}
+
+	c.Budget = BudgetConfig{MaxWallTimeSeconds: 1e-9}
+	if _, err := c.Plan(root, stats, 10); err == nil {
+		t.Error("expected error when wall time budget is exceeded")
+	}
+}
+
+func TestParse_Profiling(t *testing.T) {
+	c, err := Parse(strings.NewReader(`{
+		"game": {"name": "kuhn"},
+		"evaluation": {"checkpoint_interval": 100, "checkpoint_dir": "/tmp/ckpt"},
+		"profiling": {"start_iter": 1000, "end_iter": 1100, "cpu": true}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Profiling.Dir != "/tmp/ckpt" {
+		t.Errorf("expected profiling.dir to default to checkpoint dir, got %q", c.Profiling.Dir)
+	}
+
+	_, err = Parse(strings.NewReader(`{
+		"game": {"name": "kuhn"},
+		"profiling": {"start_iter": 1000, "end_iter": 1000, "dir": "/tmp"}
+	}`))
+	if err == nil {
+		t.Error("expected error for empty profiling window")
+	}
+}
+
+func TestTune(t *testing.T) {
+	c, err := Parse(strings.NewReader(`{
+		"game": {"name": "kuhn"},
+		"algorithm": {"name": "vanilla", "iterations": 1000}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	space := SearchSpace{
+		Discount: []DiscountConfig{
+			{},
+			{RegretMatchingPlus: true, LinearWeighting: true},
+			{DCFR: true},
+			{Hedge: true, HedgeLearningRate: 0.01},
+		},
+	}
+
+	root := kuhn.NewGame()
+	best, results, err := c.Tune(root, space, TuneParams{
+		NumCandidates: 8,
+		MinIterations: 50,
+		Seed:          42,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 8 {
+		t.Fatalf("expected results for %d candidates, got %d", 8, len(results))
+	}
+
+	if results[0].Config != best || results[0].Iterations != 400 {
+		t.Errorf("expected best candidate to be trained for %d iterations, got %+v", 400, results[0])
+	}
+
+	// A very low Hedge learning rate barely moves from the uniform strategy.
+	if best.Discount.Hedge {
+		t.Errorf("expected slow candidate to be eliminated, got %+v", best.Discount)
+	}
+
+	if best.Algorithm.Iterations != c.Algorithm.Iterations {
+		t.Errorf("expected tuned config to keep the number of iterations, got %d",
+			best.Algorithm.Iterations)
+	}
+
+	for _, r := range results {
+		t.Logf("%+v: %d iterations, score %v", r.Config.Discount, r.Iterations, r.Score)
+	}
+}
+
+func TestTune_Sampling(t *testing.T) {
+	c, err := Parse(strings.NewReader(`{
+		"game": {"name": "kuhn"},
+		"algorithm": {"name": "mccfr", "iterations": 1000},
+		"sampling": {"sampler": "multi_outcome"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	space := SearchSpace{
+		ExplorationEps: []float32{0.1, 0.6},
+		K:              []int{1, 2},
+	}
+
+	_, results, err := c.Tune(kuhn.NewGame(), space, TuneParams{
+		NumCandidates: 3,
+		MinIterations: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range results {
+		if r.Config.Sampling.K < 1 || r.Config.Sampling.ExplorationEps == 0 {
+			t.Errorf("expected sampling params to be drawn from the search space, got %+v",
+				r.Config.Sampling)
+		}
+	}
+
+	space.K = []int{0}
+	if _, _, err := c.Tune(kuhn.NewGame(), space, TuneParams{NumCandidates: 1, MinIterations: 1}); err == nil {
+		t.Error("expected error for invalid candidate")
+	}
+}
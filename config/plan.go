@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// Plan is an estimate of the resources needed to run an experiment.
+type Plan struct {
+	// Memory used by the strategy profile. Zero for on-disk stores.
+	PolicyTableMemory int64
+	// Disk used by the strategy profile. Zero for in-memory stores.
+	StoreDisk int64
+	// Disk used by all checkpoints written during training.
+	CheckpointDisk int64
+	// Mean wall-clock time per iteration, measured by a calibration run.
+	// Zero if no calibration run was performed.
+	IterationTime time.Duration
+}
+
+// TotalDisk returns the total disk used by the store and checkpoints.
+func (p Plan) TotalDisk() int64 {
+	return p.StoreDisk + p.CheckpointDisk
+}
+
+// TotalTime returns the estimated wall-clock time to run the given
+// number of iterations.
+func (p Plan) TotalTime(iterations int) time.Duration {
+	return time.Duration(iterations) * p.IterationTime
+}
+
+// Plan estimates the resources needed to run the experiment on a game tree
+// with the given statistics (see tree.GetStats), and checks them against the
+// configured budget.
+//
+// If calibrationIters > 0, the iteration time is estimated by running the
+// configured algorithm for that many iterations on the tree rooted at root.
+// Calibration always uses an in-memory store, so it will underestimate the
+// iteration time of on-disk stores. Memory and disk budgets are checked
+// before calibrating, so that an infeasible plan fails fast.
+func (c *Config) Plan(root cfr.GameTreeNode, stats cfr.TreeStats, calibrationIters int) (Plan, error) {
+	params := c.Discount.Params()
+	var plan Plan
+	switch c.Store.Type {
+	case RocksDBStore:
+		plan.StoreDisk = stats.PolicyTableEncodedSize(params)
+	default:
+		// The memory of prefix-compressed keys is overestimated.
+		compact := c.Store.Type == CompactStore || c.Store.Type == PrefixCompressedStore
+		plan.PolicyTableMemory = stats.PolicyTableMemory(params, compact)
+	}
+
+	if n := c.Evaluation.CheckpointInterval; n > 0 {
+		numCheckpoints := int64(c.Algorithm.Iterations / n)
+		plan.CheckpointDisk = numCheckpoints * stats.PolicyTableEncodedSize(params)
+	}
+
+	if err := c.checkSpace(plan); err != nil {
+		return plan, err
+	}
+
+	if calibrationIters > 0 {
+		plan.IterationTime = c.calibrate(root, calibrationIters)
+		if err := c.checkTime(plan); err != nil {
+			return plan, err
+		}
+	}
+
+	return plan, nil
+}
+
+func (c *Config) checkSpace(plan Plan) error {
+	if max := c.Budget.MaxMemoryBytes; max > 0 && plan.PolicyTableMemory > max {
+		return fmt.Errorf("estimated memory %d bytes exceeds budget.max_memory_bytes %d",
+			plan.PolicyTableMemory, max)
+	}
+
+	if max := c.Budget.MaxDiskBytes; max > 0 && plan.TotalDisk() > max {
+		return fmt.Errorf("estimated disk %d bytes exceeds budget.max_disk_bytes %d",
+			plan.TotalDisk(), max)
+	}
+
+	return nil
+}
+
+func (c *Config) checkTime(plan Plan) error {
+	maxSeconds := c.Budget.MaxWallTimeSeconds
+	if maxSeconds <= 0 {
+		return nil
+	}
+
+	total := plan.TotalTime(c.Algorithm.Iterations)
+	if total.Seconds() > maxSeconds {
+		return fmt.Errorf("estimated wall time %v exceeds budget.max_wall_time_seconds %v",
+			total, maxSeconds)
+	}
+
+	return nil
+}
+
+// calibrate returns the mean time per iteration of running
+// the configured algorithm for n iterations.
+func (c *Config) calibrate(root cfr.GameTreeNode, n int) time.Duration {
+	profile, err := c.NewStrategyProfile()
+	if err != nil {
+		profile = cfr.NewPolicyTable(c.Discount.Params())
+	}
+
+	opt := c.NewRunner(profile)
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		opt.Run(root)
+		profile.Update()
+	}
+
+	return time.Since(start) / time.Duration(n)
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/golang/glog"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// SearchSpace is the space of hyperparameters searched by Tune. Each candidate
+// is a copy of the base config with one value chosen from each non-empty list.
+type SearchSpace struct {
+	Discount       []DiscountConfig
+	ExplorationEps []float32
+	K              []int
+}
+
+// TuneParams control the search performed by Tune.
+type TuneParams struct {
+	// Number of candidate configs sampled at random from the search space.
+	NumCandidates int
+	// Number of iterations for which every candidate is trained before the
+	// first round of elimination. Each round doubles the number of iterations
+	// of the remaining candidates, and eliminates the worse half of them.
+	MinIterations int
+	// Seed for the random number generator used to sample candidates.
+	Seed int64
+	// Score returns the score of a trained profile, with lower scores being
+	// better. If nil, cfr.Exploitability is used. For games too large to
+	// compute exact exploitability, it should be replaced by an estimate
+	// (e.g. of a best response computed on sampled histories).
+	Score func(root cfr.GameTreeNode, profile cfr.StrategyProfile) float64
+}
+
+// TuneResult is the final score of a single candidate config.
+type TuneResult struct {
+	Config *Config
+	// The number of iterations for which the candidate was trained
+	// before being eliminated (or before the search ended).
+	Iterations int
+	Score      float64
+}
+
+// candidate is a config under evaluation, along with its training state.
+type candidate struct {
+	config  *Config
+	profile cfr.StrategyProfile
+	runner  Runner
+	result  TuneResult
+}
+
+// Tune searches for the discount and sampling parameters of the config that
+// converge fastest on the game rooted at root, using successive halving:
+// candidate configs are sampled at random from the search space and trained for
+// a short run, then the better half are trained for twice as long, and so on,
+// until a single candidate remains. Candidates are trained with in-memory stores,
+// regardless of the configured store.
+//
+// It returns the best config, and the results of all candidates sorted from
+// best to worst.
+func (c *Config) Tune(root cfr.GameTreeNode, space SearchSpace, params TuneParams) (*Config, []TuneResult, error) {
+	if params.NumCandidates < 1 || params.MinIterations < 1 {
+		return nil, nil, fmt.Errorf("tuning requires at least 1 candidate and iteration")
+	}
+
+	score := params.Score
+	if score == nil {
+		score = func(root cfr.GameTreeNode, profile cfr.StrategyProfile) float64 {
+			return cfr.Exploitability(root, profile)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(params.Seed))
+	candidates := make([]*candidate, params.NumCandidates)
+	for i := range candidates {
+		config := space.sample(c, rng)
+		if err := config.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("candidate %d: %v", i, err)
+		}
+
+		profile := cfr.NewPolicyTable(config.Discount.Params())
+		candidates[i] = &candidate{
+			config:  config,
+			profile: profile,
+			runner:  config.NewRunner(profile),
+			result:  TuneResult{Config: config},
+		}
+	}
+
+	// Candidates eliminated in later rounds rank above those eliminated earlier.
+	var results []TuneResult
+	for nIter := params.MinIterations; ; nIter *= 2 {
+		for _, cand := range candidates {
+			cand.train(root, nIter)
+			cand.result.Score = score(root, cand.profile)
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].result.Score < candidates[j].result.Score
+		})
+
+		glog.Infof("Tuning: best of %d candidates after %d iterations scored %v",
+			len(candidates), nIter, candidates[0].result.Score)
+		keep := (len(candidates) + 1) / 2
+		if len(candidates) == 1 {
+			keep = 0
+		}
+
+		var eliminated []TuneResult
+		for _, cand := range candidates[keep:] {
+			eliminated = append(eliminated, cand.result)
+		}
+
+		results = append(eliminated, results...)
+		if keep == 0 {
+			return candidates[0].config, results, nil
+		}
+
+		candidates = candidates[:keep]
+	}
+}
+
+// train continues training the candidate until it has run nIter iterations.
+func (cand *candidate) train(root cfr.GameTreeNode, nIter int) {
+	for ; cand.result.Iterations < nIter; cand.result.Iterations++ {
+		cand.runner.Run(root)
+		cand.profile.Update()
+	}
+}
+
+// sample returns a copy of base with parameters chosen at random from the space.
+func (s SearchSpace) sample(base *Config, rng *rand.Rand) *Config {
+	config := *base
+	if len(s.Discount) > 0 {
+		config.Discount = s.Discount[rng.Intn(len(s.Discount))]
+	}
+
+	if len(s.ExplorationEps) > 0 {
+		config.Sampling.ExplorationEps = s.ExplorationEps[rng.Intn(len(s.ExplorationEps))]
+	}
+
+	if len(s.K) > 0 {
+		config.Sampling.K = s.K[rng.Intn(len(s.K))]
+	}
+
+	return &config
+}
@@ -0,0 +1,140 @@
+package cfr
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// ContinualResolvingParams are the parameters of a ContinualResolver.
+type ContinualResolvingParams struct {
+	// Number of iterations of CFR used to re-solve the subgame at each decision.
+	Iterations int
+	// DiscountParams of the PolicyTables used to re-solve subgames.
+	DiscountParams DiscountParams
+	// Number of sampled traversals that are averaged to compute the counterfactual
+	// values of the opponent after each action. It only needs to be greater than
+	// one if the game has public chance events. Zero is treated as one.
+	EvaluationSamples int
+}
+
+// ContinualResolver plays one player of a two-player game with safe continual
+// re-solving (Moravčík et al., 2017), as in DeepStack. Rather than looking up a
+// precomputed strategy, it re-solves the subgame rooted at the current public
+// state at each of its decisions, with the re-solving gadget of CFR-D (see
+// CFRD.ResolveSubgame), and plays according to the solution.
+//
+// Between decisions it tracks only its own range (the probability of reaching
+// the current public state in each of its private states) and the counterfactual
+// values of the opponent, which are updated from each solution. The strategy it
+// plays is therefore no more exploitable than that of the blueprint used to
+// compute the opponent's counterfactual values at the root, up to the error of
+// each re-solve.
+//
+// Each re-solve traverses the entire subgame, to its terminal nodes, so it is only
+// practical for games whose subgames are small enough to be solved in real time.
+type ContinualResolver struct {
+	player    int
+	params    ContinualResolvingParams
+	slicePool SlicePool
+	rng       *rand.Rand
+
+	reach          []float32
+	opponentValues []float32
+	// The solution of the most recent re-solve, from which opponent
+	// counterfactual values are computed until the next one.
+	solution *PolicyTable
+}
+
+// NewContinualResolver returns a new ContinualResolver for the given player,
+// beginning at a public state at which the player has the given range and the
+// opponent has the given counterfactual values. At the root of a game, reach is
+// the prior probability of each private state, and opponentValues may be computed
+// from a trained blueprint with CounterfactualValues.
+func NewContinualResolver(player int, reach, opponentValues []float32, params ContinualResolvingParams) *ContinualResolver {
+	return &ContinualResolver{
+		player:         player,
+		params:         params,
+		slicePool:      NewFloatSlicePool(SlicePoolParams{}),
+		rng:            rand.New(rand.NewSource(rand.Int63())),
+		reach:          append([]float32(nil), reach...),
+		opponentValues: append([]float32(nil), opponentValues...),
+	}
+}
+
+// Seed sets the seed of the random number generator used to sample actions.
+func (r *ContinualResolver) Seed(seed int64) {
+	r.rng.Seed(seed)
+}
+
+// Range returns the probability of reaching the current public state
+// in each of the player's private states.
+func (r *ContinualResolver) Range() []float32 {
+	return r.reach
+}
+
+// OpponentValues returns the counterfactual value of each of the opponent's
+// private states at the current public state.
+func (r *ContinualResolver) OpponentValues() []float32 {
+	return r.opponentValues
+}
+
+// Act re-solves the subgame rooted at the given public node, at which the player
+// is to act, and returns the action sampled from the solution for the player's
+// actual private state.
+func (r *ContinualResolver) Act(node PublicTreeNode, privateState int) int {
+	if node.Type() != PlayerNodeType || node.Player() != r.player {
+		panic(fmt.Errorf("cfr: continual re-solving for player %d called at node: %v", r.player, node))
+	}
+
+	r.resolve(node)
+	strategies := make([][]float32, len(r.reach))
+	for s := range strategies {
+		strategies[s] = r.solution.GetPolicy(&privateStateNode{node, s}).GetAverageStrategy()
+	}
+
+	action := sampleOne(strategies[privateState], r.rng.Float32())
+	for s, strategy := range strategies {
+		r.reach[s] *= strategy[action]
+	}
+
+	r.updateOpponentValues(node.GetChild(action))
+	return action
+}
+
+// Observe updates the state of the resolver after an action of the opponent,
+// or a public chance outcome, at the given public node.
+func (r *ContinualResolver) Observe(node PublicTreeNode, action int) {
+	if r.solution == nil {
+		// The opponent acted before the player's first decision.
+		r.resolve(node)
+	}
+
+	r.updateOpponentValues(node.GetChild(action))
+}
+
+func (r *ContinualResolver) resolve(node PublicTreeNode) {
+	r.solution = resolveSubgame(node, r.player, r.reach, r.opponentValues,
+		r.params.Iterations, r.params.DiscountParams, r.slicePool)
+}
+
+// updateOpponentValues sets the counterfactual values of the opponent
+// to their values at the given child in the most recent solution.
+func (r *ContinualResolver) updateOpponentValues(child PublicTreeNode) {
+	opponent := 1 - r.player
+	// The counterfactual values of the opponent do not depend on their own reach.
+	opponentReach := child.PrivateStateProbabilities(opponent)
+	evaluateSubgame(r.solution, child, opponent, opponentReach, r.reach, r.opponentValues,
+		r.params.EvaluationSamples, r.slicePool)
+}
+
+// CounterfactualValues returns the counterfactual value of each private state of
+// player at the given public node, when both players play the average strategies
+// of profile and the opponent reaches the node with the given probabilities.
+// The profile is not modified.
+func CounterfactualValues(profile StrategyProfile, node PublicTreeNode, player int, opponentReach []float32, nSamples int) []float32 {
+	pool := NewFloatSlicePool(SlicePoolParams{})
+	values := make([]float32, node.NumPrivateStates(player))
+	evaluateSubgame(profile, node, player, node.PrivateStateProbabilities(player),
+		opponentReach, values, nSamples, pool)
+	return values
+}
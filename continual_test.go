@@ -0,0 +1,79 @@
+package cfr_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/sampling"
+)
+
+func TestContinualResolving(t *testing.T) {
+	publicRoot := kuhn.NewPublicGame()
+	blueprint := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewPublicChanceSampling(blueprint)
+	for i := 0; i < 10000; i++ {
+		opt.Run(publicRoot)
+		blueprint.Update()
+	}
+
+	params := cfr.ContinualResolvingParams{Iterations: 200}
+	rng := rand.New(rand.NewSource(123))
+	for player := 0; player < 2; player++ {
+		priors := publicRoot.PrivateStateProbabilities(player)
+		opponentValues := cfr.CounterfactualValues(blueprint, publicRoot, 1-player, priors, 1)
+		nGames := 2000
+		var total float64
+		for i := 0; i < nGames; i++ {
+			resolver := cfr.NewContinualResolver(player, priors, opponentValues, params)
+			resolver.Seed(int64(i))
+			total += playContinualResolving(t, resolver, player, blueprint, rng)
+		}
+
+		// The value of Kuhn poker for the first player is -1/18.
+		expected := -1.0 / 18
+		if player == 1 {
+			expected = -expected
+		}
+
+		mean := total / float64(nGames)
+		t.Logf("Mean value of continual re-solving as player %d: %.4f", player, mean)
+		if mean < expected-0.1 {
+			t.Errorf("expected value near %v for player %d, got %v", expected, player, mean)
+		}
+	}
+}
+
+// playContinualResolving plays a game of Kuhn poker with a random deal in which
+// the resolver plays against the average strategy of blueprint, and returns the
+// utility of the resolver.
+func playContinualResolving(t *testing.T, resolver *cfr.ContinualResolver, player int, blueprint cfr.StrategyProfile, rng *rand.Rand) float64 {
+	deal := kuhn.NewGame()
+	node := deal.GetChild(rng.Intn(deal.NumChildren()))
+	for node.Type() == cfr.ChanceNodeType {
+		node = node.GetChild(rng.Intn(node.NumChildren()))
+	}
+
+	card := kuhnCard(node.InfoSet(player))
+	var publicNode cfr.PublicTreeNode = kuhn.NewPublicGame()
+	for node.Type() != cfr.TerminalNodeType {
+		var action int
+		if node.Player() == player {
+			action = resolver.Act(publicNode, int(card))
+		} else {
+			strategy := blueprint.GetPolicy(node).GetAverageStrategy()
+			action = sampling.SampleOne(strategy, rng.Float32())
+			resolver.Observe(publicNode, action)
+		}
+
+		if reach := resolver.Range(); reach[card] <= 0 {
+			t.Fatalf("expected positive reach of actual private state, got %v", reach)
+		}
+
+		node = node.GetChild(action)
+		publicNode = publicNode.GetChild(action)
+	}
+
+	return node.Utility(player)
+}
@@ -0,0 +1,109 @@
+package cfr
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"github.com/timpalpant/go-cfr/internal/policy"
+)
+
+// CurriculumStage is a single game in a curriculum of progressively larger
+// parameterizations of a game (e.g. with more cards, or deeper stacks).
+type CurriculumStage struct {
+	// Root is the root of the game tree of this stage.
+	Root GameTreeNode
+	// Iterations is the number of iterations to train this stage.
+	Iterations int
+	// Translate maps the InfoSet keys of the previous stage to the keys of this
+	// stage that they correspond to. It is not used for the first stage.
+	Translate KeyTranslation
+	// WarmStartIterations is the number of iterations of this stage that the
+	// policies transferred from the previous stages count as: their accumulated
+	// regrets and strategy sums are scaled as though they had been trained for
+	// this many iterations, rather than for all iterations of the previous stages.
+	// Since the strategies of a smaller game are only an approximation, a small
+	// number lets training correct them quickly. Zero keeps their full weight.
+	WarmStartIterations int
+}
+
+// TrainCurriculum trains a PolicyTable with the given params on each stage in turn,
+// warm starting each stage from the policies of the previous one with
+// TransferPolicyTable, and returns the PolicyTable of the final stage.
+// Since the smaller games are much cheaper to solve and their strategies are
+// often close to those of the larger ones, this may converge faster than
+// solving the final stage from scratch.
+//
+// newRunner is called to create the runner used to train each stage.
+func TrainCurriculum(stages []CurriculumStage, params DiscountParams,
+	newRunner func(StrategyProfile) Runner) (*PolicyTable, error) {
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("curriculum has no stages")
+	}
+
+	var pt *PolicyTable
+	for i, stage := range stages {
+		if i == 0 {
+			pt = NewPolicyTable(params)
+		} else if stage.Translate == nil {
+			return nil, fmt.Errorf("curriculum stage %d has no key translation", i)
+		} else {
+			var err error
+			pt, err = TransferPolicyTable(pt, params, stage.Translate)
+			if err != nil {
+				return nil, fmt.Errorf("curriculum stage %d: %v", i, err)
+			}
+
+			if stage.WarmStartIterations > 0 {
+				warmStart(pt, params, stage.WarmStartIterations)
+			}
+		}
+
+		glog.Infof("Training curriculum stage %d for %d iterations", i, stage.Iterations)
+		runner := newRunner(pt)
+		for t := 0; t < stage.Iterations; t++ {
+			runner.Run(stage.Root)
+			pt.Update()
+		}
+	}
+
+	return pt, nil
+}
+
+// warmStart rescales the accumulated regrets and strategy sums of pt, and resets
+// its iteration and strategy weight, as though it had been trained with params
+// for nIter iterations rather than for all of the iterations it has completed.
+func warmStart(pt *PolicyTable, params DiscountParams, nIter int) {
+	completed := pt.iter - 1
+	if completed <= 0 {
+		return
+	}
+
+	// Strategy sums are accumulated in units of the strategy weight, which
+	// grows with each iteration under discounting (see PolicyTable.Update).
+	oldTotal, _ := cumulativeStrategyWeight(params, completed)
+	newTotal, newWeight := cumulativeStrategyWeight(params, nIter)
+	wRegret := float32(nIter) / float32(completed)
+	wStrategy := float32(newTotal / oldTotal)
+	pt.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+		p.ScaleSums(wRegret, wStrategy)
+		return true
+	})
+
+	pt.iter = nIter + 1
+	pt.strategyWeight = newWeight
+}
+
+// cumulativeStrategyWeight returns the total weight of the strategies accumulated
+// by a PolicyTable with params over its first nIter iterations, and the weight
+// of the last of them.
+func cumulativeStrategyWeight(params DiscountParams, nIter int) (total, last float64) {
+	last = 1.0
+	for t := 1; t <= nIter; t++ {
+		_, _, discountSum := params.GetDiscountFactors(t)
+		last /= float64(discountSum)
+		total += last
+	}
+
+	return total, last
+}
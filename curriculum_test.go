@@ -0,0 +1,181 @@
+package cfr_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/simulator"
+)
+
+// nCardKuhnSim is Kuhn poker played with a deck of nCards cards.
+type nCardKuhnSim struct {
+	nCards  int
+	cards   []int
+	history string
+}
+
+func (k *nCardKuhnSim) Type() cfr.NodeType {
+	switch {
+	case len(k.cards) < 2:
+		return cfr.ChanceNodeType
+	case k.isTerminal():
+		return cfr.TerminalNodeType
+	}
+
+	return cfr.PlayerNodeType
+}
+
+func (k *nCardKuhnSim) isTerminal() bool {
+	switch k.history {
+	case "cc", "cbc", "cbb", "bc", "bb":
+		return true
+	}
+
+	return false
+}
+
+func (k *nCardKuhnSim) Player() int {
+	if len(k.cards) < 2 {
+		return -1
+	}
+
+	return len(k.history) % 2
+}
+
+type kuhnInfoSet string
+
+func (is kuhnInfoSet) Key() string                     { return string(is) }
+func (is kuhnInfoSet) MarshalBinary() ([]byte, error)  { return []byte(is), nil }
+func (is *kuhnInfoSet) UnmarshalBinary(b []byte) error { *is = kuhnInfoSet(b); return nil }
+
+func (k *nCardKuhnSim) InfoSet(player int) cfr.InfoSet {
+	is := kuhnInfoSet(fmt.Sprintf("%d-%s", k.cards[player], k.history))
+	return &is
+}
+
+func (k *nCardKuhnSim) Utility(player int) float64 {
+	winner := 0
+	if k.cards[1] > k.cards[0] {
+		winner = 1
+	}
+
+	var stake float64
+	switch k.history {
+	case "cbc", "bc":
+		// Last player folded, so the player to act wins the ante.
+		winner = len(k.history) % 2
+		stake = 1
+	case "cc":
+		stake = 1
+	default:
+		stake = 2
+	}
+
+	if player == winner {
+		return stake
+	}
+
+	return -stake
+}
+
+func (k *nCardKuhnSim) NumActions() int {
+	switch {
+	case len(k.cards) < 2:
+		return k.nCards - len(k.cards)
+	case k.isTerminal():
+		return 0
+	}
+
+	return 2
+}
+
+func (k *nCardKuhnSim) ActionProbability(i int) float64 {
+	return 1.0 / float64(k.NumActions())
+}
+
+func (k *nCardKuhnSim) Apply(i int) {
+	if len(k.cards) < 2 {
+		if len(k.cards) == 1 && i >= k.cards[0] {
+			i++ // Skip the card dealt to player 0.
+		}
+
+		k.cards = append(k.cards, i)
+		return
+	}
+
+	k.history += string("cb"[i])
+}
+
+func (k *nCardKuhnSim) Clone() simulator.Simulator {
+	return &nCardKuhnSim{
+		nCards:  k.nCards,
+		cards:   append([]int(nil), k.cards...),
+		history: k.history,
+	}
+}
+
+// splitCards translates the InfoSets of n-card Kuhn poker to those of
+// 2n-card Kuhn poker, in which each card is split into two adjacent cards.
+func splitCards(oldKey string) []string {
+	parts := strings.SplitN(oldKey, "-", 2)
+	card, err := strconv.Atoi(parts[0])
+	if err != nil {
+		panic(err)
+	}
+
+	return []string{
+		fmt.Sprintf("%d-%s", 2*card, parts[1]),
+		fmt.Sprintf("%d-%s", 2*card+1, parts[1]),
+	}
+}
+
+func newVanilla(profile cfr.StrategyProfile) cfr.Runner {
+	return cfr.New(profile)
+}
+
+func TestTrainCurriculum(t *testing.T) {
+	root := simulator.NewGame(&nCardKuhnSim{nCards: 12})
+	stages := []cfr.CurriculumStage{
+		{Root: simulator.NewGame(&nCardKuhnSim{nCards: 3}), Iterations: 1000},
+		{Root: simulator.NewGame(&nCardKuhnSim{nCards: 6}), Iterations: 100,
+			Translate: splitCards, WarmStartIterations: 10},
+		{Root: root, Iterations: 300, Translate: splitCards, WarmStartIterations: 5},
+	}
+
+	policy, err := cfr.TrainCurriculum(stages, cfr.DiscountParams{}, newVanilla)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The final stage continues from its warm start iterations.
+	final := stages[len(stages)-1]
+	if iter := policy.Iter(); iter != final.WarmStartIterations+final.Iterations+1 {
+		t.Errorf("expected iteration %d, got %d", final.WarmStartIterations+final.Iterations+1, iter)
+	}
+
+	scratch, err := cfr.TrainCurriculum(stages[2:], cfr.DiscountParams{}, newVanilla)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exploitability := cfr.Exploitability(root, policy)
+	scratchExploitability := cfr.Exploitability(root, scratch)
+	if exploitability > scratchExploitability {
+		t.Errorf("expected curriculum to converge faster than training from scratch, got %v (scratch: %v)",
+			exploitability, scratchExploitability)
+	}
+}
+
+func TestTrainCurriculum_MissingTranslation(t *testing.T) {
+	stages := []cfr.CurriculumStage{
+		{Root: simulator.NewGame(&nCardKuhnSim{nCards: 3}), Iterations: 1},
+		{Root: simulator.NewGame(&nCardKuhnSim{nCards: 6}), Iterations: 1},
+	}
+
+	if _, err := cfr.TrainCurriculum(stages, cfr.DiscountParams{}, newVanilla); err == nil {
+		t.Error("expected error for stage without key translation")
+	}
+}
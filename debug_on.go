@@ -0,0 +1,9 @@
+//go:build cfrdebug
+
+package cfr
+
+// Debug is true if the package was built with the cfrdebug build tag, which
+// enables assertions that are too expensive to check during normal training,
+// such as that all utilities and counterfactual values are within the bounds
+// declared by UtilityBoundedNode.
+const Debug = true
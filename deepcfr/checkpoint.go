@@ -0,0 +1,237 @@
+package deepcfr
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/rand"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// MemoryModelRegistry implements ModelRegistry by keeping all models in memory.
+type MemoryModelRegistry struct {
+	models []TrainedModel
+}
+
+// NewMemoryModelRegistry returns a new MemoryModelRegistry containing the given models.
+func NewMemoryModelRegistry(models ...TrainedModel) *MemoryModelRegistry {
+	return &MemoryModelRegistry{
+		models: append([]TrainedModel(nil), models...),
+	}
+}
+
+func (r *MemoryModelRegistry) Add(model TrainedModel) {
+	r.models = append(r.models, model)
+}
+
+func (r *MemoryModelRegistry) Get(t int) TrainedModel {
+	return r.models[t]
+}
+
+func (r *MemoryModelRegistry) Len() int {
+	return len(r.models)
+}
+
+func (r *MemoryModelRegistry) Close() error {
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// Note that the concrete types implementing the TrainedModels
+// must be registered with gob.
+func (r *MemoryModelRegistry) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(r.models); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (r *MemoryModelRegistry) UnmarshalBinary(buf []byte) error {
+	dec := gob.NewDecoder(bytes.NewReader(buf))
+	return dec.Decode(&r.models)
+}
+
+// CheckpointProfile implements cfr.StrategyProfile for playing the average strategy
+// of SD-CFR without training an average strategy network or evaluating every
+// checkpoint at each decision (as the average strategy of SingleDeepCFR does).
+//
+// At the start of each game, SampleCheckpoints selects a single advantage model
+// checkpoint for each player, with probability proportional to the iteration on
+// which it was trained (as in Linear CFR), and the player follows the strategy of
+// that checkpoint until the end of the game. This plays the SD-CFR average
+// strategy exactly in distribution over whole games.
+//
+// It is intended for play only: the policies it returns may not be updated,
+// and Update panics.
+type CheckpointProfile struct {
+	registries []ModelRegistry
+	rng        *rand.Rand
+	// The checkpoint followed by each player in the current game.
+	selected []TrainedModel
+}
+
+// NewCheckpointProfile returns a new CheckpointProfile that samples among the
+// checkpoints of each player in the given registries.
+func NewCheckpointProfile(registries []ModelRegistry) *CheckpointProfile {
+	p := &CheckpointProfile{
+		registries: registries,
+		rng:        rand.New(rand.NewSource(rand.Int63())),
+	}
+
+	p.SampleCheckpoints()
+	return p
+}
+
+// Seed sets the seed of the random number generator used to sample checkpoints.
+func (p *CheckpointProfile) Seed(seed int64) {
+	p.rng.Seed(seed)
+}
+
+// SampleCheckpoints selects the checkpoint that each player follows until it is
+// next called. It should be called at the start of every game.
+func (p *CheckpointProfile) SampleCheckpoints() {
+	p.selected = make([]TrainedModel, len(p.registries))
+	for player, registry := range p.registries {
+		p.selected[player] = sampleCheckpoint(registry, p.rng.Float64())
+	}
+}
+
+// sampleCheckpoint selects the t'th model of the registry with probability
+// proportional to t+1, or returns nil if it is empty.
+func sampleCheckpoint(registry ModelRegistry, x float64) TrainedModel {
+	n := registry.Len()
+	if n == 0 {
+		return nil
+	}
+
+	target := x * float64(n*(n+1)/2)
+	var cumulative float64
+	for t := 0; t < n-1; t++ {
+		cumulative += float64(t + 1)
+		if target < cumulative {
+			return registry.Get(t)
+		}
+	}
+
+	return registry.Get(n - 1)
+}
+
+// GetPolicy implements cfr.StrategyProfile. Both the current and average
+// strategy of the returned policy are those of the selected checkpoint.
+func (p *CheckpointProfile) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
+	return &checkpointPolicy{
+		node:  node,
+		model: p.selected[node.Player()],
+	}
+}
+
+// Update implements cfr.StrategyProfile.
+func (p *CheckpointProfile) Update() {
+	panic("deepcfr: CheckpointProfile may not be updated")
+}
+
+// Iter implements cfr.StrategyProfile. It is the iteration of SD-CFR
+// after which the checkpoints were taken.
+func (p *CheckpointProfile) Iter() int {
+	iter := 1
+	for _, registry := range p.registries {
+		iter += registry.Len()
+	}
+
+	return iter
+}
+
+func (p *CheckpointProfile) Close() error {
+	for _, registry := range p.registries {
+		if err := registry.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// Note that the concrete types implementing the ModelRegistries
+// must be registered with gob.
+func (p *CheckpointProfile) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(p.registries); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *CheckpointProfile) UnmarshalBinary(buf []byte) error {
+	dec := gob.NewDecoder(bytes.NewReader(buf))
+	if err := dec.Decode(&p.registries); err != nil {
+		return err
+	}
+
+	p.rng = rand.New(rand.NewSource(rand.Int63()))
+	p.SampleCheckpoints()
+	return nil
+}
+
+type checkpointPolicy struct {
+	node     cfr.GameTreeNode
+	model    TrainedModel
+	strategy []float32
+}
+
+func (c *checkpointPolicy) GetStrategy() []float32 {
+	if c.strategy == nil {
+		if c.model == nil {
+			c.strategy = uniformDist(c.node.NumChildren())
+		} else {
+			infoSet := c.node.InfoSet(c.node.Player())
+			c.strategy = c.model.Predict(infoSet, c.node.NumChildren())
+		}
+	}
+
+	return c.strategy
+}
+
+func (c *checkpointPolicy) CopyStrategy(dst []float32) {
+	copy(dst, c.GetStrategy())
+}
+
+func (c *checkpointPolicy) GetAverageStrategy() []float32 {
+	return c.GetStrategy()
+}
+
+func (c *checkpointPolicy) CopyAverageStrategy(dst []float32) {
+	copy(dst, c.GetStrategy())
+}
+
+func (c *checkpointPolicy) IsEmpty() bool {
+	return c.model == nil
+}
+
+func (c *checkpointPolicy) GetBaseline() []float32 {
+	return make([]float32, c.node.NumChildren())
+}
+
+func (c *checkpointPolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {
+	panic("deepcfr: CheckpointProfile policies may not be updated")
+}
+
+func (c *checkpointPolicy) UpdateBaseline(w float32, action int, value float32) {
+	panic("deepcfr: CheckpointProfile policies may not be updated")
+}
+
+func (c *checkpointPolicy) AddStrategyWeight(w float32) {
+	panic("deepcfr: CheckpointProfile policies may not be updated")
+}
+
+func init() {
+	gob.Register(&MemoryModelRegistry{})
+	gob.Register(&CheckpointProfile{})
+}
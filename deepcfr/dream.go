@@ -0,0 +1,99 @@
+package deepcfr
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/sampling"
+)
+
+// DREAM implements cfr.StrategyProfile for DREAM (Steinberger et al., 2020):
+// https://arxiv.org/abs/2006.10410
+//
+// Like VRSingleDeepCFR, advantages are estimated with function approximation and the
+// average strategy is computed from all past advantage models, as in SD-CFR. However,
+// traversals sample a single outcome (see NewDREAMRunner) rather than all actions of
+// the traversing player, and the high variance of outcome sampling is reduced with a
+// learned baseline of the value of each action, which is used as a control variate.
+// Since only a single trajectory is sampled, the game never needs to be reset to an
+// earlier state, which is much cheaper per iteration than Deep CFR in very large games.
+//
+// The baseline is fit by a separate Model to the (baseline-corrected) sampled action
+// values observed at the nodes of both players. Since any function of the history is
+// an unbiased control variate, it is conditioned on the acting player's InfoSet.
+// Because the value of an action changes with the strategies, baseline buffers should
+// favor recent samples (e.g. a CircularBuffer), and both players' baseline models are
+// retrained on every Update.
+type DREAM struct {
+	*VRSingleDeepCFR
+	baselineModel Model
+}
+
+// NewDREAM returns a new DREAM policy that fits advantages with model to the
+// samples in buffers, and baselines with baselineModel to the samples in
+// baselineBuffers. There must be one buffer of each kind per player.
+func NewDREAM(model, baselineModel Model, buffers, baselineBuffers []Buffer) *DREAM {
+	return &DREAM{
+		VRSingleDeepCFR: NewVRSingleDeepCFR(model, buffers, baselineBuffers),
+		baselineModel:   baselineModel,
+	}
+}
+
+// NewDREAMRunner returns a runner that performs DREAM traversals for the given
+// profile: VR-MCCFR with outcome sampling, in which the traversing player explores
+// uniformly at random with probability explorationEps and the other player samples
+// from their current strategy.
+func NewDREAMRunner(profile *DREAM, explorationEps float32) *cfr.VRMCCFR {
+	return cfr.NewVRMCCFR(profile,
+		sampling.NewOutcomeSampler(explorationEps),
+		sampling.NewOutcomeSampler(0))
+}
+
+// Update implements cfr.StrategyProfile.
+func (d *DREAM) Update() {
+	d.trainAdvantageModel()
+	for p, baselineBuf := range d.baselineBuffers {
+		d.baselineModels[p] = d.baselineModel.Train(baselineBuf)
+	}
+
+	d.iter++
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// Note that to be able to use this method, the concrete types
+// implementing the Models, TrainedModels, and Buffers must be registered
+// with gob.
+func (d *DREAM) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+
+	// Need to pass pointer to interface so that Gob sees the interface rather
+	// than the concrete type. See the example in encoding/gob.
+	if err := enc.Encode(&d.baselineModel); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(d.VRSingleDeepCFR); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (d *DREAM) UnmarshalBinary(buf []byte) error {
+	r := bytes.NewReader(buf)
+	dec := gob.NewDecoder(r)
+
+	if err := dec.Decode(&d.baselineModel); err != nil {
+		return err
+	}
+
+	d.VRSingleDeepCFR = &VRSingleDeepCFR{}
+	return dec.Decode(d.VRSingleDeepCFR)
+}
+
+func init() {
+	gob.Register(&DREAM{})
+}
@@ -29,3 +29,15 @@ type Model interface {
 type TrainedModel interface {
 	Predict(infoSet cfr.InfoSet, nActions int) (advantages []float32)
 }
+
+// ModelRegistry stores the sequence of advantage models trained for a single
+// player in SD-CFR, one per iteration in which they were the traversing player.
+// Implementations may keep the models in memory or load them from storage on demand.
+type ModelRegistry interface {
+	// Add appends the model trained on the next iteration.
+	Add(model TrainedModel)
+	// Get returns the t'th model, in the order they were added.
+	Get(t int) TrainedModel
+	Len() int
+	io.Closer
+}
@@ -43,6 +43,12 @@ func (d *SingleDeepCFR) GetBuffer(player int) Buffer {
 	return d.buffers[player]
 }
 
+// Checkpoints returns a registry of the advantage models trained so far for player,
+// for example to play with a CheckpointProfile.
+func (d *SingleDeepCFR) Checkpoints(player int) ModelRegistry {
+	return NewMemoryModelRegistry(d.trainedModels[player]...)
+}
+
 func (d *SingleDeepCFR) currentPlayer() int {
 	return d.iter % 2
 }
@@ -197,6 +203,14 @@ func (d *dcfrPolicy) GetAverageStrategy() []float32 {
 	return getAverageStrategy(d.node, d.models)
 }
 
+func (d *dcfrPolicy) CopyStrategy(dst []float32) {
+	copy(dst, d.GetStrategy())
+}
+
+func (d *dcfrPolicy) CopyAverageStrategy(dst []float32) {
+	copy(dst, d.GetAverageStrategy())
+}
+
 func getAverageStrategy(node cfr.GameTreeNode, models []TrainedModel) []float32 {
 	nChildren := node.NumChildren()
 	if nChildren == 1 {
@@ -45,6 +45,16 @@ func (d *VRSingleDeepCFR) GetBuffer(player int) Buffer {
 	return d.buffers[player]
 }
 
+func (d *VRSingleDeepCFR) GetBaselineBuffer(player int) Buffer {
+	return d.baselineBuffers[player]
+}
+
+// Checkpoints returns a registry of the advantage models trained so far for player,
+// for example to play with a CheckpointProfile.
+func (d *VRSingleDeepCFR) Checkpoints(player int) ModelRegistry {
+	return NewMemoryModelRegistry(d.trainedModels[player]...)
+}
+
 func (d *VRSingleDeepCFR) currentPlayer() int {
 	return d.iter % 2
 }
@@ -62,16 +72,22 @@ func (d *VRSingleDeepCFR) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
 
 // Update implements cfr.StrategyProfile.
 func (d *VRSingleDeepCFR) Update() {
+	player := d.trainAdvantageModel()
+	baselineBuf := d.baselineBuffers[player]
+	d.baselineModels[player] = d.model.Train(baselineBuf)
+
+	d.iter++
+}
+
+// trainAdvantageModel trains a new advantage model for the player
+// traversing on the current iteration, and returns the player.
+func (d *VRSingleDeepCFR) trainAdvantageModel() int {
 	player := d.currentPlayer()
 	buf := d.buffers[player]
 	trained := d.model.Train(buf)
 	model := &AdvantageModel{trained}
 	d.trainedModels[player] = append(d.trainedModels[player], model)
-
-	baselineBuf := d.baselineBuffers[player]
-	d.baselineModels[player] = d.model.Train(baselineBuf)
-
-	d.iter++
+	return player
 }
 
 // Iter implements cfr.StrategyProfile.
@@ -247,6 +263,14 @@ func (d *vrdcfrPolicy) GetAverageStrategy() []float32 {
 	return getAverageStrategy(d.node, d.models)
 }
 
+func (d *vrdcfrPolicy) CopyStrategy(dst []float32) {
+	copy(dst, d.GetStrategy())
+}
+
+func (d *vrdcfrPolicy) CopyAverageStrategy(dst []float32) {
+	copy(dst, d.GetAverageStrategy())
+}
+
 func init() {
 	gob.Register(&VRSingleDeepCFR{})
 }
@@ -0,0 +1,127 @@
+package cfr
+
+import (
+	"github.com/timpalpant/go-cfr/internal/f32"
+)
+
+// LeafEvaluator estimates the value of non-terminal nodes at which traversal
+// is cut off by a depth limit (see CFR.SetDepthLimit). Implementations may use
+// a heuristic, simulated rollouts (see rollout.NewLeafEvaluator), or a learned
+// value function.
+type LeafEvaluator interface {
+	// LeafValue returns the estimated expected utility of the given player
+	// from node. The node must not be closed by the evaluator.
+	LeafValue(node GameTreeNode, player int) float32
+}
+
+// LeafEvaluatorFunc adapts an ordinary function to a LeafEvaluator.
+type LeafEvaluatorFunc func(node GameTreeNode, player int) float32
+
+// LeafValue implements LeafEvaluator.
+func (f LeafEvaluatorFunc) LeafValue(node GameTreeNode, player int) float32 {
+	return f(node, player)
+}
+
+// MultiValuedLeafEvaluator estimates the value of a leaf node under each of
+// several continuation strategies of one of the players (the chooser), as in
+// depth-limited solving with multi-valued states (Brown, Sandholm & Amos, 2018).
+// Rather than assuming a single continuation beyond the depth limit, the chooser
+// selects among their continuation strategies at each leaf, so that the strategy
+// found above the depth limit is robust to however they play below it.
+type MultiValuedLeafEvaluator interface {
+	// Chooser returns the player who chooses among continuation strategies.
+	Chooser() int
+	// LeafValues returns the estimated expected utility of the given player from
+	// node when the chooser plays each of their continuation strategies. The
+	// number of values must be the same for all nodes with the same InfoSet of
+	// the chooser. The node must not be closed by the evaluator.
+	LeafValues(node GameTreeNode, player int) []float32
+}
+
+// SetDepthLimit limits traversal to the given number of player actions below the
+// root. Non-terminal nodes at the depth limit are not expanded, and their values are
+// instead estimated by evaluator. Policies are therefore only requested (and updated)
+// for nodes above the depth limit. Actions at nodes with a single child are not
+// counted, since they are skipped during traversal.
+//
+// A nil evaluator disables the depth limit.
+func (c *CFR) SetDepthLimit(maxDepth int, evaluator LeafEvaluator) {
+	c.maxDepth = maxDepth
+	c.leafEvaluator = evaluator
+	c.multiLeafEvaluator = nil
+}
+
+// SetMultiValuedDepthLimit is as SetDepthLimit, except that the value of each leaf
+// is given by the continuation strategy chosen by the chooser of evaluator. The
+// choice at each leaf is a decision of the chooser, made at their InfoSet at the
+// leaf, whose regrets and average strategy are updated like those of any other.
+//
+// A nil evaluator disables the depth limit.
+func (c *CFR) SetMultiValuedDepthLimit(maxDepth int, evaluator MultiValuedLeafEvaluator) {
+	c.maxDepth = maxDepth
+	c.leafEvaluator = nil
+	c.multiLeafEvaluator = evaluator
+}
+
+// atDepthLimit returns true if the value of node should be estimated by the
+// leaf evaluator rather than by expanding it.
+func (c *CFR) atDepthLimit(node GameTreeNode) bool {
+	return (c.leafEvaluator != nil || c.multiLeafEvaluator != nil) &&
+		c.depth >= c.maxDepth && node.Type() != TerminalNodeType
+}
+
+// evaluateLeaf returns the estimated value of a node at the depth limit
+// for lastPlayer.
+func (c *CFR) evaluateLeaf(node GameTreeNode, lastPlayer int, reachP0, reachP1, reachChance float32) float32 {
+	if c.leafEvaluator != nil {
+		return c.leafEvaluator.LeafValue(node, lastPlayer)
+	}
+
+	chooser := c.multiLeafEvaluator.Chooser()
+	values := c.multiLeafEvaluator.LeafValues(node, chooser)
+	choice := &leafChoiceNode{GameTreeNode: node, chooser: chooser, nContinuations: len(values)}
+	policy := c.strategyProfile.GetPolicy(choice)
+	strategy := policy.GetStrategy()
+
+	regrets := c.slicePool.Alloc(len(values))
+	defer c.slicePool.Free(regrets)
+	copy(regrets, values)
+	cfValue := f32.DotUnitary(strategy, regrets)
+	f32.AddConst(-cfValue, regrets)
+	ones := c.slicePool.Alloc(len(values))
+	defer c.slicePool.Free(ones)
+	for i := range ones {
+		ones[i] = 1.0
+	}
+
+	counterFactualP := counterFactualProb(chooser, reachP0, reachP1, reachChance)
+	policy.AddRegret(counterFactualP, ones, regrets)
+	policy.AddStrategyWeight(reachProb(chooser, reachP0, reachP1, reachChance))
+	return getSign(lastPlayer, chooser) * cfValue
+}
+
+// leafChoiceNode is the decision of the chooser among continuation strategies
+// at a leaf node. It only implements the methods needed to look up its policy
+// in a StrategyProfile.
+type leafChoiceNode struct {
+	GameTreeNode
+	chooser        int
+	nContinuations int
+}
+
+func (n *leafChoiceNode) Type() NodeType {
+	return PlayerNodeType
+}
+
+func (n *leafChoiceNode) Player() int {
+	return n.chooser
+}
+
+func (n *leafChoiceNode) NumChildren() int {
+	return n.nContinuations
+}
+
+func (n *leafChoiceNode) InfoSet(player int) InfoSet {
+	is := gadgetInfoSet("depth-limit:" + n.GameTreeNode.InfoSet(player).Key())
+	return &is
+}
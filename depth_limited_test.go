@@ -0,0 +1,142 @@
+package cfr_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestDepthLimitedCFR(t *testing.T) {
+	blueprint := cfr.NewPolicyTable(cfr.DiscountParams{})
+	runCFR(t, cfr.New(blueprint), blueprint, 10000)
+
+	// Only player 0's first action is solved, with the values of
+	// player 1's decisions given by the blueprint.
+	var nLeaves int
+	evaluator := cfr.LeafEvaluatorFunc(func(node cfr.GameTreeNode, player int) float32 {
+		nLeaves++
+		if node.Type() != cfr.PlayerNodeType || node.Player() != 1 {
+			t.Errorf("expected leaves at player 1's first decision, got %v", node)
+		}
+
+		return float32(expectedUtility(averageStrategy(blueprint), node, player))
+	})
+
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	opt.SetDepthLimit(1, evaluator)
+	root := kuhn.NewGame()
+	var expectedValue float32
+	nIter := 1000
+	for i := 0; i < nIter; i++ {
+		expectedValue += opt.Run(root)
+		policy.Update()
+	}
+
+	expectedValue /= float32(nIter)
+	// The value at the chance root is negated relative to player 0, who acts first.
+	t.Logf("Expected game value: %.4f", expectedValue)
+	if math.Abs(float64(expectedValue)-1.0/18) > 0.01 {
+		t.Errorf("expected game value near 1/18, got %v", expectedValue)
+	}
+
+	if nLeaves == 0 {
+		t.Error("expected leaf evaluator to be used")
+	}
+}
+
+func expectedUtility(strategy func(node cfr.GameTreeNode) []float32, node cfr.GameTreeNode, player int) float64 {
+	switch node.Type() {
+	case cfr.TerminalNodeType:
+		return node.Utility(player)
+	case cfr.ChanceNodeType:
+		var ev float64
+		for i := 0; i < node.NumChildren(); i++ {
+			ev += node.GetChildProbability(i) * expectedUtility(strategy, node.GetChild(i), player)
+		}
+
+		return ev
+	default:
+		var ev float64
+		for i, p := range strategy(node) {
+			ev += float64(p) * expectedUtility(strategy, node.GetChild(i), player)
+		}
+
+		return ev
+	}
+}
+
+func averageStrategy(profile cfr.StrategyProfile) func(node cfr.GameTreeNode) []float32 {
+	return func(node cfr.GameTreeNode) []float32 {
+		return profile.GetPolicy(node).GetAverageStrategy()
+	}
+}
+
+func TestMultiValuedDepthLimitedCFR(t *testing.T) {
+	blueprint := cfr.NewPolicyTable(cfr.DiscountParams{})
+	runCFR(t, cfr.New(blueprint), blueprint, 10000)
+
+	// In the passive continuation, player 1 always checks or folds,
+	// which player 0 exploits by always betting if it is the only one.
+	passive := func(node cfr.GameTreeNode) []float32 {
+		if node.Player() == 1 {
+			return []float32{1, 0}
+		}
+
+		return blueprint.GetPolicy(node).GetAverageStrategy()
+	}
+
+	evaluator := &continuationEvaluator{
+		continuations: []func(node cfr.GameTreeNode) []float32{passive},
+	}
+
+	single := runDepthLimited(evaluator, 1000)
+	t.Logf("Expected game value with passive continuation: %.4f", single)
+	if single > -0.5 {
+		t.Errorf("expected passive continuation to be exploited, got value %v", single)
+	}
+
+	evaluator.continuations = append(evaluator.continuations, averageStrategy(blueprint))
+	multi := runDepthLimited(evaluator, 1000)
+	t.Logf("Expected game value with multi-valued leaves: %.4f", multi)
+	if math.Abs(float64(multi)-1.0/18) > 0.01 {
+		t.Errorf("expected game value near 1/18, got %v", multi)
+	}
+}
+
+// continuationEvaluator is a cfr.MultiValuedLeafEvaluator in which player 1
+// chooses among the given continuation strategies.
+type continuationEvaluator struct {
+	continuations []func(node cfr.GameTreeNode) []float32
+}
+
+func (e *continuationEvaluator) Chooser() int {
+	return 1
+}
+
+func (e *continuationEvaluator) LeafValues(node cfr.GameTreeNode, player int) []float32 {
+	values := make([]float32, len(e.continuations))
+	for i, strategy := range e.continuations {
+		values[i] = float32(expectedUtility(strategy, node, player))
+	}
+
+	return values
+}
+
+// runDepthLimited solves player 0's first action in Kuhn poker with
+// multi-valued leaves, and returns the mean value of the root.
+func runDepthLimited(evaluator cfr.MultiValuedLeafEvaluator, nIter int) float32 {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	opt.SetMultiValuedDepthLimit(1, evaluator)
+	root := kuhn.NewGame()
+	var expectedValue float32
+	for i := 0; i < nIter; i++ {
+		expectedValue += opt.Run(root)
+		policy.Update()
+	}
+
+	return expectedValue / float32(nIter)
+}
@@ -0,0 +1,265 @@
+package cfr
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/timpalpant/go-cfr/internal/policy"
+)
+
+// NewDoubleBufferedPolicyTable creates a new PolicyTable with the given DiscountParams
+// in which regrets and strategy weights are accumulated into a shadow buffer with
+// atomic operations, and only folded into the accumulated sums by Update.
+//
+// GetPolicy and the accumulating methods of the returned policies (AddRegret,
+// AddStrategyWeight and UpdateBaseline) are safe to call concurrently from multiple
+// goroutines, including while Update is in progress. Update atomically swaps the
+// shadow buffer, so that writes which begin after it has been called are accumulated
+// into the next iteration, then waits for writes to the previous buffer to complete
+// before folding it. Current strategies are replaced atomically at the end of Update,
+// so the strategy returned by GetStrategy is immutable for the duration of an iteration.
+//
+// Other methods of the returned policies, which read the accumulated sums, reflect
+// the last call to Update and must not be called concurrently with it. Accumulations
+// since the last call to Update are not included in MarshalBinary.
+func NewDoubleBufferedPolicyTable(params DiscountParams) *PolicyTable {
+	pt := NewPolicyTable(params)
+	pt.buffers = newDoubleBuffer()
+	return pt
+}
+
+// doubleBuffer is the state of a double-buffered PolicyTable.
+type doubleBuffer struct {
+	// Guards the PolicyTable's policiesByKey and wrappers.
+	mx       sync.RWMutex
+	wrappers map[*policy.Policy]*bufferedPolicy
+
+	// Writes accumulate into shadow buffer epoch%2 of each policy.
+	epoch atomic.Uint32
+	// The number of writes in progress to each shadow buffer.
+	writers [2]atomic.Int64
+
+	touchedMx sync.Mutex
+	// The policies with accumulations in each shadow buffer.
+	touched [2][]*bufferedPolicy
+}
+
+func newDoubleBuffer() *doubleBuffer {
+	return &doubleBuffer{
+		wrappers: make(map[*policy.Policy]*bufferedPolicy),
+	}
+}
+
+// beginWrite returns the index of the shadow buffer that a write should accumulate
+// into, and which will not be folded until the write is ended with endWrite.
+func (b *doubleBuffer) beginWrite() int {
+	for {
+		epoch := b.epoch.Load()
+		i := int(epoch % 2)
+		b.writers[i].Add(1)
+		if b.epoch.Load() == epoch {
+			return i
+		}
+
+		// Update swapped buffers concurrently, and may already be folding this one.
+		b.writers[i].Add(-1)
+	}
+}
+
+func (b *doubleBuffer) endWrite(i int) {
+	b.writers[i].Add(-1)
+}
+
+// swap directs subsequent writes to the other shadow buffer. Once all writes to
+// the previous buffer are complete, it returns its index and touched policies.
+func (b *doubleBuffer) swap() (int, []*bufferedPolicy) {
+	i := int((b.epoch.Add(1) - 1) % 2)
+	for b.writers[i].Load() > 0 {
+		runtime.Gosched()
+	}
+
+	b.touchedMx.Lock()
+	defer b.touchedMx.Unlock()
+	touched := b.touched[i]
+	b.touched[i] = nil
+	return i, touched
+}
+
+func (pt *PolicyTable) getBufferedPolicy(node GameTreeNode) NodePolicy {
+	b := pt.buffers
+	b.mx.RLock()
+	np, ok := pt.policiesByKey.Get(nodeKey(node))
+	bp := b.wrappers[np]
+	b.mx.RUnlock()
+	if ok && bp != nil && np.NumActions() == node.NumChildren() {
+		return bp
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	np = pt.lookupPolicy(node)
+	if bp = b.wrappers[np]; bp == nil {
+		bp = newBufferedPolicy(b, np)
+		b.wrappers[np] = bp
+	}
+
+	return bp
+}
+
+// updateBuffered implements Update for a double-buffered PolicyTable.
+// It must not be called concurrently with itself.
+func (pt *PolicyTable) updateBuffered() {
+	b := pt.buffers
+	i, touched := b.swap()
+	discountPos, discountNeg, discountSum := pt.params.GetDiscountFactors(pt.iter)
+	threshold := pt.params.GetThreshold(pt.iter + 1)
+	strategyWeight := float32(pt.strategyWeight / float64(discountSum))
+	for _, bp := range touched {
+		bp.fold(i)
+		bp.policy.ScaleStrategyWeight(strategyWeight)
+		bp.mx.Lock()
+		bp.policy.NextStrategy(discountPos, discountNeg, 1.0, pt.params.Minimizer, pt.iter+1)
+		bp.mx.Unlock()
+		if threshold > 0 {
+			bp.policy.ApplyThreshold(threshold)
+		}
+
+		bp.publishStrategy()
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	pt.strategyWeight /= float64(discountSum)
+	pt.iter++
+}
+
+// bufferedPolicy implements NodePolicy for a double-buffered PolicyTable.
+type bufferedPolicy struct {
+	buffers *doubleBuffer
+	policy  *policy.Policy
+
+	// The current strategy, which is replaced by Update.
+	strategy atomic.Pointer[[]float32]
+
+	// Shadow buffers of accumulated weighted regrets and strategy weight,
+	// stored as float32 bits for atomic addition.
+	regrets        [2][]uint32
+	strategyWeight [2]uint32
+	// Whether the policy is in the list of touched policies of each buffer.
+	touched [2]atomic.Bool
+
+	// Guards the baseline and the bounds on regrets used for dominance
+	// checking, which are updated in place by each write.
+	mx sync.Mutex
+}
+
+func newBufferedPolicy(buffers *doubleBuffer, p *policy.Policy) *bufferedPolicy {
+	nActions := p.NumActions()
+	bp := &bufferedPolicy{
+		buffers: buffers,
+		policy:  p,
+		regrets: [2][]uint32{make([]uint32, nActions), make([]uint32, nActions)},
+	}
+
+	bp.publishStrategy()
+	return bp
+}
+
+func (bp *bufferedPolicy) publishStrategy() {
+	strategy := make([]float32, bp.policy.NumActions())
+	bp.policy.CopyStrategy(strategy)
+	bp.strategy.Store(&strategy)
+}
+
+// markTouched adds the policy to the list of touched policies of buffer i.
+func (bp *bufferedPolicy) markTouched(i int) {
+	if bp.touched[i].CompareAndSwap(false, true) {
+		b := bp.buffers
+		b.touchedMx.Lock()
+		b.touched[i] = append(b.touched[i], bp)
+		b.touchedMx.Unlock()
+	}
+}
+
+// fold adds the accumulations of shadow buffer i to the policy, and resets it.
+// The folded regrets are not exact samples, so they do not affect the bounds
+// used for dominance checking, which are updated by each write instead.
+func (bp *bufferedPolicy) fold(i int) {
+	regrets := make([]float32, len(bp.regrets[i]))
+	for j := range regrets {
+		regrets[j] = math.Float32frombits(atomic.SwapUint32(&bp.regrets[i][j], 0))
+	}
+
+	bp.policy.AddRegret(1.0, nil, regrets)
+	bp.policy.AddStrategyWeight(math.Float32frombits(atomic.SwapUint32(&bp.strategyWeight[i], 0)))
+	bp.touched[i].Store(false)
+}
+
+func (bp *bufferedPolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {
+	i := bp.buffers.beginWrite()
+	defer bp.buffers.endWrite(i)
+	for j, r := range instantaneousRegrets {
+		atomicAddFloat32(&bp.regrets[i][j], w*r)
+	}
+
+	bp.mx.Lock()
+	bp.policy.UpdateRegretBounds(samplingQ, instantaneousRegrets)
+	bp.mx.Unlock()
+	bp.markTouched(i)
+}
+
+func (bp *bufferedPolicy) GetStrategy() []float32 {
+	return *bp.strategy.Load()
+}
+
+func (bp *bufferedPolicy) CopyStrategy(dst []float32) {
+	copy(dst, *bp.strategy.Load())
+}
+
+func (bp *bufferedPolicy) GetBaseline() []float32 {
+	bp.mx.Lock()
+	defer bp.mx.Unlock()
+	return append([]float32(nil), bp.policy.GetBaseline()...)
+}
+
+func (bp *bufferedPolicy) UpdateBaseline(w float32, action int, value float32) {
+	bp.mx.Lock()
+	defer bp.mx.Unlock()
+	bp.policy.UpdateBaseline(w, action, value)
+}
+
+func (bp *bufferedPolicy) AddStrategyWeight(w float32) {
+	i := bp.buffers.beginWrite()
+	defer bp.buffers.endWrite(i)
+	atomicAddFloat32(&bp.strategyWeight[i], w)
+	bp.markTouched(i)
+}
+
+func (bp *bufferedPolicy) GetAverageStrategy() []float32 {
+	return bp.policy.GetAverageStrategy()
+}
+
+func (bp *bufferedPolicy) CopyAverageStrategy(dst []float32) {
+	bp.policy.CopyAverageStrategy(dst)
+}
+
+func (bp *bufferedPolicy) IsEmpty() bool {
+	return bp.policy.IsEmpty()
+}
+
+// atomicAddFloat32 atomically adds delta to the float32 stored as bits in addr.
+func atomicAddFloat32(addr *uint32, delta float32) {
+	if delta == 0 {
+		return
+	}
+
+	for {
+		old := atomic.LoadUint32(addr)
+		sum := math.Float32frombits(old) + delta
+		if atomic.CompareAndSwapUint32(addr, old, math.Float32bits(sum)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package cfr_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestDoubleBufferedCFR(t *testing.T) {
+	params := cfr.DiscountParams{LinearWeighting: true}
+	policy := cfr.NewPolicyTable(params)
+	runCFR(t, cfr.New(policy), policy, 1000)
+	buffered := cfr.NewDoubleBufferedPolicyTable(params)
+	runCFR(t, cfr.New(buffered), buffered, 1000)
+
+	tree.Visit(kuhn.NewGame(), func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		expected := policy.GetPolicy(node).GetAverageStrategy()
+		actual := buffered.GetPolicy(node).GetAverageStrategy()
+		for i := range expected {
+			if math.Abs(float64(expected[i]-actual[i])) > 1e-3 {
+				t.Errorf("%v: expected %v, got %v", node, expected, actual)
+				break
+			}
+		}
+	})
+
+	testMarshalRoundTrip(t, buffered)
+}
+
+func TestDoubleBufferedConcurrentUpdate(t *testing.T) {
+	policy := cfr.NewDoubleBufferedPolicyTable(cfr.DiscountParams{})
+	const nWorkers = 4
+	const nIter = 50000
+
+	ran := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < nWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			root := kuhn.NewGame()
+			opt := cfr.NewChanceSampling(policy)
+			for j := 0; j < nIter/nWorkers; j++ {
+				opt.Run(root)
+				ran <- struct{}{}
+			}
+		}()
+	}
+
+	// Update once per nWorkers traversals, without waiting for traversals in flight.
+	// Workers block until their traversal is counted, so that iterations stay balanced.
+	for i := 1; i <= nIter; i++ {
+		<-ran
+		if i%nWorkers == 0 {
+			policy.Update()
+		}
+	}
+
+	wg.Wait()
+	exploitability := cfr.Exploitability(kuhn.NewGame(), policy)
+	t.Logf("Exploitability after %d iterations: %.4f", policy.Iter(), exploitability)
+	if exploitability > 0.02 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
@@ -0,0 +1,70 @@
+package cfr
+
+// EntrySampler is a distribution over game states from which training may start
+// instead of the root of the game, for example to focus training on late-game
+// situations. A state may be any node of the game tree.
+type EntrySampler interface {
+	// SampleEntry returns a state sampled from the distribution, and the weight
+	// of its traversal. Each state should be weighted by the probability of
+	// reaching it divided by the probability with which it was sampled, so that
+	// states sampled in proportion to their reach probability have weight 1.
+	//
+	// The returned node is closed at the end of its traversal, and must
+	// support being traversed again if it is returned again.
+	SampleEntry() (node GameTreeNode, weight float64)
+}
+
+// EntryProfile wraps a StrategyProfile for training from states sampled by
+// an EntrySampler. The regrets and strategy weights added by each traversal are
+// scaled by the weight of its entry state, so any runner constructed with the
+// EntryProfile may be used with Traverse.
+//
+// Traversals begin with reach probabilities of 1 at the entry state, so its
+// weight accounts for the reach of all players and chance. Only InfoSets at or
+// below the entry states are trained; policies elsewhere are left unchanged.
+type EntryProfile struct {
+	StrategyProfile
+	weight float32
+}
+
+// NewEntryProfile returns a new EntryProfile wrapping the given profile.
+func NewEntryProfile(profile StrategyProfile) *EntryProfile {
+	return &EntryProfile{
+		StrategyProfile: profile,
+		weight:          1.0,
+	}
+}
+
+// Traverse runs a single iteration of the given runner from an entry state
+// sampled from entries. It must not be called concurrently.
+func (p *EntryProfile) Traverse(runner Runner, entries EntrySampler) float32 {
+	node, w := entries.SampleEntry()
+	p.weight = float32(w)
+	defer func() { p.weight = 1.0 }()
+	return runner.Run(node)
+}
+
+// GetPolicy implements StrategyProfile.
+func (p *EntryProfile) GetPolicy(node GameTreeNode) NodePolicy {
+	policy := p.StrategyProfile.GetPolicy(node)
+	if p.weight == 1.0 {
+		return policy
+	}
+
+	return &weightedPolicy{NodePolicy: policy, weight: p.weight}
+}
+
+// weightedPolicy scales all regrets and strategy weights added to a policy.
+// It does not implement ActionCounter, so that action counts are also weighted.
+type weightedPolicy struct {
+	NodePolicy
+	weight float32
+}
+
+func (p *weightedPolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {
+	p.NodePolicy.AddRegret(p.weight*w, samplingQ, instantaneousRegrets)
+}
+
+func (p *weightedPolicy) AddStrategyWeight(w float32) {
+	p.NodePolicy.AddStrategyWeight(p.weight * w)
+}
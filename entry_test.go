@@ -0,0 +1,62 @@
+package cfr_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+// dealSampler samples the states after the deal of both cards, with
+// probability proportional to 1, 2, ..., 6 rather than uniformly.
+type dealSampler struct {
+	rng   *rand.Rand
+	deals []cfr.GameTreeNode
+}
+
+func newDealSampler() *dealSampler {
+	root := kuhn.NewGame()
+	var deals []cfr.GameTreeNode
+	for i := 0; i < root.NumChildren(); i++ {
+		p0Deal := root.GetChild(i)
+		for j := 0; j < p0Deal.NumChildren(); j++ {
+			deals = append(deals, p0Deal.GetChild(j))
+		}
+	}
+
+	return &dealSampler{rng: rand.New(rand.NewSource(123)), deals: deals}
+}
+
+func (s *dealSampler) SampleEntry() (cfr.GameTreeNode, float64) {
+	n := len(s.deals)
+	total := n * (n + 1) / 2
+	x := s.rng.Intn(total)
+	for i := range s.deals {
+		if x < i+1 {
+			q := float64(i+1) / float64(total)
+			return s.deals[i], (1.0 / float64(n)) / q
+		}
+
+		x -= i + 1
+	}
+
+	panic("unreachable")
+}
+
+func TestEntryProfile(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	profile := cfr.NewEntryProfile(policy)
+	opt := cfr.New(profile)
+	entries := newDealSampler()
+	for i := 0; i < 100000; i++ {
+		profile.Traverse(opt, entries)
+		profile.Update()
+	}
+
+	// Without the entry weights, the average strategy would be biased
+	// towards the deals that are sampled more often.
+	if exploitability := cfr.Exploitability(kuhn.NewGame(), policy); exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
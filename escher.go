@@ -0,0 +1,276 @@
+package cfr
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/timpalpant/go-cfr/internal/f32"
+)
+
+// HistoryValueFunction estimates the expected utility of a player from a node
+// (history) of the game when both players follow their current strategies.
+// It is used by ESCHER in place of importance-weighted sampled values.
+type HistoryValueFunction interface {
+	// HistoryValue returns the estimated expected utility of player from node.
+	// It is never called with terminal nodes. The node must not be closed.
+	HistoryValue(node GameTreeNode, player int) float32
+}
+
+// HistoryValueLearner is an optional interface that may be implemented by a
+// HistoryValueFunction that learns from the values observed during traversal.
+type HistoryValueLearner interface {
+	// ObserveHistoryValue is called with the value of player estimated from
+	// the sampled trajectory through each non-terminal node that is traversed.
+	ObserveHistoryValue(node GameTreeNode, player int, value float32)
+}
+
+// ESCHER implements ESCHER (McAleer et al., 2022): MCCFR without importance
+// sampling. As in outcome sampling, a single trajectory is sampled on each
+// traversal, but the actions of the traversing player are sampled from a fixed
+// distribution given by the Sampler, and chance and opponent actions according to
+// their probabilities. At each node of the traversing player, the regret of every
+// action is estimated as the difference between the value of its child and the
+// value of the node given by a HistoryValueFunction, with no importance weights.
+//
+// The regrets of each InfoSet are therefore weighted by the probability that the
+// fixed sampling distribution reaches it, which is constant over iterations and so
+// does not change the average strategy to which CFR converges, while avoiding the
+// variance of dividing by the sampling probabilities. For the same reason, the
+// Sampler should not depend on the current strategy; for example, a RobustSampler
+// with k = 1 samples one action uniformly at random.
+//
+// All children of the traversing player's nodes are constructed to evaluate the
+// value function, so games that implement SharedStateNode are not supported.
+type ESCHER struct {
+	strategyProfile StrategyProfile
+	sampler         Sampler
+	values          HistoryValueFunction
+
+	arena *arena
+	rng   *rand.Rand
+
+	schedule         PlayerSchedule
+	traversingPlayer int
+}
+
+// NewESCHER returns a new ESCHER runner that samples the actions of the
+// traversing player with sampler, and estimates regrets with values.
+func NewESCHER(strategyProfile StrategyProfile, sampler Sampler, values HistoryValueFunction) *ESCHER {
+	return &ESCHER{
+		strategyProfile: strategyProfile,
+		sampler:         sampler,
+		values:          values,
+		arena:           &arena{},
+		rng:             rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Seed sets the seed of the random number generator used to sample
+// the actions of the other players.
+func (c *ESCHER) Seed(seed int64) {
+	c.rng.Seed(seed)
+}
+
+// SetPlayerSchedule sets the PlayerSchedule of the runner.
+func (c *ESCHER) SetPlayerSchedule(schedule PlayerSchedule) {
+	c.schedule = schedule
+}
+
+// Run performs a traversal from node for each player of the current
+// iteration's schedule, and returns the mean of their values.
+func (c *ESCHER) Run(node GameTreeNode) float32 {
+	return runScheduled(c.schedule, c.strategyProfile.Iter(), func(player int) float32 {
+		c.traversingPlayer = player
+		return getSign(node.Player(), player) * c.run(node)
+	})
+}
+
+func (c *ESCHER) run(node GameTreeNode) float32 {
+	defer c.arena.reset()
+	return c.runHelper(node)
+}
+
+// runHelper returns the value of the traversing player from node,
+// estimated from the sampled trajectory.
+func (c *ESCHER) runHelper(node GameTreeNode) float32 {
+	var ev float32
+	switch node.Type() {
+	case TerminalNodeType:
+		ev = float32(node.Utility(c.traversingPlayer))
+		node.Close()
+		return ev
+	case ChanceNodeType:
+		child, _ := node.SampleChild()
+		ev = c.runHelper(child)
+	default:
+		if node.Player() == c.traversingPlayer {
+			ev = c.handleTraversingPlayerNode(node)
+		} else {
+			ev = c.handleSampledPlayerNode(node)
+		}
+	}
+
+	if learner, ok := c.values.(HistoryValueLearner); ok {
+		learner.ObserveHistoryValue(node, c.traversingPlayer, ev)
+	}
+
+	node.Close()
+	return ev
+}
+
+func (c *ESCHER) handleTraversingPlayerNode(node GameTreeNode) float32 {
+	nChildren := node.NumChildren()
+	if nChildren == 1 {
+		// Optimization to skip trivial nodes with no real choice.
+		return c.runHelper(node.GetChild(0))
+	}
+
+	policy := c.strategyProfile.GetPolicy(node)
+	strategy := policy.GetStrategy()
+	qs := c.arena.alloc(nChildren)
+	copy(qs, c.sampler.Sample(node, policy))
+	values := c.arena.alloc(nChildren)
+	for i := range values {
+		values[i] = c.historyValue(node.GetChild(i))
+	}
+
+	regrets := c.arena.alloc(nChildren)
+	copy(regrets, values)
+	f32.AddConst(-f32.DotUnitary(strategy, values), regrets)
+	policy.AddRegret(1.0, qs, regrets)
+
+	// The values of sampled actions are replaced by those of their trajectories.
+	for i, q := range qs {
+		if q > 0 {
+			values[i] = c.runHelper(node.GetChild(i))
+		}
+	}
+
+	ev := f32.DotUnitary(strategy, values)
+	c.arena.free(regrets)
+	c.arena.free(values)
+	c.arena.free(qs)
+	return ev
+}
+
+// historyValue returns the value of the traversing player from node.
+func (c *ESCHER) historyValue(node GameTreeNode) float32 {
+	if node.Type() == TerminalNodeType {
+		return float32(node.Utility(c.traversingPlayer))
+	}
+
+	return c.values.HistoryValue(node, c.traversingPlayer)
+}
+
+func (c *ESCHER) handleSampledPlayerNode(node GameTreeNode) float32 {
+	policy := c.strategyProfile.GetPolicy(node)
+	// The opponent's InfoSets are reached with the probability of their strategy,
+	// times a factor of the traversing player's fixed sampling distribution that
+	// is the same on every iteration, so their average strategy is unweighted.
+	policy.AddStrategyWeight(1.0)
+	selected := sampleOne(policy.GetStrategy(), c.rng.Float32())
+	return c.runHelper(node.GetChild(selected))
+}
+
+// exactHistoryValues implements HistoryValueFunction by enumerating
+// the subtree of each node.
+type exactHistoryValues struct {
+	strategyProfile StrategyProfile
+}
+
+// NewExactHistoryValues returns a HistoryValueFunction that computes the exact
+// value of each node under the current strategies of strategyProfile, by
+// enumerating its subtree. It is only practical for small games, but is useful
+// as an oracle to check the convergence of ESCHER independently of the error of
+// a learned value function.
+func NewExactHistoryValues(strategyProfile StrategyProfile) HistoryValueFunction {
+	return exactHistoryValues{strategyProfile}
+}
+
+// HistoryValue implements HistoryValueFunction.
+func (e exactHistoryValues) HistoryValue(node GameTreeNode, player int) float32 {
+	var ev float32
+	switch node.Type() {
+	case TerminalNodeType:
+		return float32(node.Utility(player))
+	case ChanceNodeType:
+		for i := 0; i < node.NumChildren(); i++ {
+			p := float32(node.GetChildProbability(i))
+			ev += p * e.childValue(node.GetChild(i), player)
+		}
+	default:
+		strategy := e.strategyProfile.GetPolicy(node).GetStrategy()
+		for i, p := range strategy {
+			if p > 0 {
+				ev += p * e.childValue(node.GetChild(i), player)
+			}
+		}
+	}
+
+	return ev
+}
+
+func (e exactHistoryValues) childValue(child GameTreeNode, player int) float32 {
+	ev := e.HistoryValue(child, player)
+	child.Close()
+	return ev
+}
+
+// TabularHistoryValues implements HistoryValueFunction and HistoryValueLearner
+// with a table of the exponential moving average of the values observed at each
+// history. Histories that have not been observed have value zero. It is safe
+// for concurrent use.
+type TabularHistoryValues struct {
+	key          func(node GameTreeNode) string
+	learningRate float32
+
+	mx     sync.Mutex
+	values map[string][2]float32
+}
+
+// NewTabularHistoryValues returns a new TabularHistoryValues in which each new
+// observation has the given weight in the moving average of its history. Histories
+// are identified by key, or if it is nil, by the keys of the InfoSets of both
+// players, which identify the history in games whose chance events are all
+// observed by at least one player.
+func NewTabularHistoryValues(key func(node GameTreeNode) string, learningRate float32) *TabularHistoryValues {
+	if key == nil {
+		key = historyKey
+	}
+
+	return &TabularHistoryValues{
+		key:          key,
+		learningRate: learningRate,
+		values:       make(map[string][2]float32),
+	}
+}
+
+// historyKey joins the InfoSet keys of both players at node.
+func historyKey(node GameTreeNode) string {
+	return node.InfoSet(0).Key() + "|" + node.InfoSet(1).Key()
+}
+
+// HistoryValue implements HistoryValueFunction.
+func (t *TabularHistoryValues) HistoryValue(node GameTreeNode, player int) float32 {
+	key := t.key(node)
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	return t.values[key][player]
+}
+
+// ObserveHistoryValue implements HistoryValueLearner.
+func (t *TabularHistoryValues) ObserveHistoryValue(node GameTreeNode, player int, value float32) {
+	key := t.key(node)
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	v := t.values[key]
+	v[player] += t.learningRate * (value - v[player])
+	t.values[key] = v
+}
+
+// Len returns the number of histories whose values have been observed.
+func (t *TabularHistoryValues) Len() int {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	return len(t.values)
+}
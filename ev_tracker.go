@@ -0,0 +1,137 @@
+package cfr
+
+import (
+	"expvar"
+	"math"
+	"sync"
+)
+
+// EVStats are running statistics of the root expected values
+// returned by each iteration of a Runner.
+type EVStats struct {
+	// Number of iterations observed.
+	Iterations int64
+	// The value of the most recent iteration.
+	Last float64
+	// Mean and (sample) variance of the values of all iterations.
+	Mean     float64
+	Variance float64
+	// Mean and variance of the values of the most recent iterations,
+	// if a window was set. See NewEVTracker.
+	WindowMean     float64
+	WindowVariance float64
+}
+
+// StdErr returns the standard error of the mean of all iterations.
+func (s EVStats) StdErr() float64 {
+	if s.Iterations == 0 {
+		return 0
+	}
+
+	return math.Sqrt(s.Variance / float64(s.Iterations))
+}
+
+// EVTracker wraps a Runner to record the root expected value returned by each
+// iteration, and maintains the running mean and variance of the values. For
+// Monte Carlo runners the variance of the values is an estimate of the sampling
+// variance of each traversal, which may be used to decide when to sample more
+// actions or increase the minibatch size (see Minibatch).
+//
+// The values of runners that alternate the traversing player are those of
+// different players on successive iterations, and so the variance includes
+// the difference between their values.
+type EVTracker struct {
+	runner Runner
+
+	mx    sync.Mutex
+	stats EVStats
+	// Sum of squared differences from the mean (Welford's algorithm).
+	m2 float64
+	// Ring buffer of the values of the most recent iterations.
+	window []float64
+	next   int
+	filled bool
+}
+
+// NewEVTracker returns a new EVTracker that wraps runner. If window is positive,
+// the statistics of the most recent window iterations are also maintained.
+func NewEVTracker(runner Runner, window int) *EVTracker {
+	return &EVTracker{
+		runner: runner,
+		window: make([]float64, max(window, 0)),
+	}
+}
+
+// Run implements Runner.
+func (t *EVTracker) Run(node GameTreeNode) float32 {
+	ev := t.runner.Run(node)
+	t.observe(float64(ev))
+	return ev
+}
+
+func (t *EVTracker) observe(x float64) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	t.stats.Iterations++
+	t.stats.Last = x
+	delta := x - t.stats.Mean
+	t.stats.Mean += delta / float64(t.stats.Iterations)
+	t.m2 += delta * (x - t.stats.Mean)
+	if t.stats.Iterations > 1 {
+		t.stats.Variance = t.m2 / float64(t.stats.Iterations-1)
+	}
+
+	if len(t.window) > 0 {
+		t.window[t.next] = x
+		t.next = (t.next + 1) % len(t.window)
+		t.filled = t.filled || t.next == 0
+		t.stats.WindowMean, t.stats.WindowVariance = meanVariance(t.windowValues())
+	}
+}
+
+func (t *EVTracker) windowValues() []float64 {
+	if t.filled {
+		return t.window
+	}
+
+	return t.window[:t.next]
+}
+
+// Stats returns the current statistics.
+func (t *EVTracker) Stats() EVStats {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	return t.stats
+}
+
+// Publish exports the current statistics as an expvar with the given name,
+// alongside the other metrics of this package. Like expvar.Publish, it panics
+// if the name is already in use.
+func (t *EVTracker) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return t.Stats()
+	}))
+}
+
+// meanVariance returns the mean and sample variance of x.
+func meanVariance(x []float64) (mean, variance float64) {
+	if len(x) == 0 {
+		return 0, 0
+	}
+
+	for _, xi := range x {
+		mean += xi
+	}
+	mean /= float64(len(x))
+
+	if len(x) == 1 {
+		return mean, 0
+	}
+
+	for _, xi := range x {
+		variance += (xi - mean) * (xi - mean)
+	}
+
+	return mean, variance / float64(len(x)-1)
+}
@@ -0,0 +1,47 @@
+package cfr_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/sampling"
+)
+
+func TestEVTracker(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	tracker := cfr.NewEVTracker(cfr.New(policy), 100)
+	runCFR(t, tracker, policy, 1000)
+	stats := tracker.Stats()
+	t.Logf("Vanilla CFR: %+v", stats)
+	if stats.Iterations != 1000 {
+		t.Errorf("expected 1000 iterations, got %d", stats.Iterations)
+	}
+
+	// The values of the current strategies oscillate around the value of the game.
+	if math.Abs(stats.Mean-1.0/18) > 0.01 {
+		t.Errorf("expected mean EV near 1/18, got %v", stats.Mean)
+	}
+
+	if stats.WindowVariance <= 0 || stats.WindowVariance > stats.Variance {
+		t.Errorf("expected recent variance (%v) to be in (0, %v]", stats.WindowVariance, stats.Variance)
+	}
+
+	// The variance of the sampled values of outcome sampling
+	// is much greater than that of external sampling.
+	sampled := func(sampler cfr.Sampler) cfr.EVStats {
+		policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+		tracker := cfr.NewEVTracker(cfr.NewMCCFR(policy, sampler), 0)
+		runCFR(t, tracker, policy, 10000)
+		return tracker.Stats()
+	}
+
+	external := sampled(sampling.NewExternalSampler())
+	outcome := sampled(sampling.NewOutcomeSampler(0.3))
+	t.Logf("External sampling: %+v", external)
+	t.Logf("Outcome sampling: %+v", outcome)
+	if external.Variance <= 0 || outcome.Variance <= external.Variance {
+		t.Errorf("expected 0 < external variance (%v) < outcome variance (%v)",
+			external.Variance, outcome.Variance)
+	}
+}
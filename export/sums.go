@@ -0,0 +1,223 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+// sumsMagic identifies the binary format written by WriteSumsBinary.
+const sumsMagic = "CFRSUMS1"
+
+// maxSumsKeyLen is the longest InfoSet key accepted by ReadSumsBinary,
+// so that a corrupt length does not cause a huge allocation.
+const maxSumsKeyLen = 1 << 20
+
+// InfoSetSums are the raw cumulative sums of a single InfoSet, as accumulated
+// during training (with any discounting applied), rather than the normalized
+// average strategy. They are intended for research analyses, such as studies
+// of the distribution of regrets.
+type InfoSetSums struct {
+	Key    string
+	Player int
+	// Cumulative regret of each action.
+	RegretSum []float32
+	// Cumulative (unnormalized) weight of each action in the average strategy.
+	// Rather than discounting earlier iterations, tables scale the weight of each
+	// iteration by their strategy weight (the inverse of the cumulative discount),
+	// so these are the discounted sums multiplied by a factor common to all
+	// InfoSets of the table. Normalized, they are the average strategy.
+	StrategySum []float32
+}
+
+// sumsRanger is implemented by strategy profiles that can enumerate the cumulative
+// sums of their InfoSets without modifying them, such as cfr.PolicyTable.
+type sumsRanger interface {
+	RangeSums(f func(key string, regretSum, strategySum []float32) bool)
+}
+
+// Sums returns the cumulative sums of each InfoSet of the game tree rooted at root
+// in the given profile, in the order in which they are first encountered in the
+// tree. InfoSets without a policy in the profile have zero sums, and nodes with a
+// single child are omitted. The profile is not modified. It returns an error if
+// the profile does not expose its sums.
+func Sums(root cfr.GameTreeNode, profile cfr.StrategyProfile) ([]InfoSetSums, error) {
+	ranger, ok := profile.(sumsRanger)
+	if !ok {
+		return nil, fmt.Errorf("profile of type %T does not expose its cumulative sums", profile)
+	}
+
+	var result []InfoSetSums
+	index := make(map[string]int)
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType || node.NumChildren() <= 1 {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		if _, ok := index[key]; ok {
+			return
+		}
+
+		index[key] = len(result)
+		result = append(result, InfoSetSums{
+			Key:         key,
+			Player:      node.Player(),
+			RegretSum:   make([]float32, node.NumChildren()),
+			StrategySum: make([]float32, node.NumChildren()),
+		})
+	})
+
+	ranger.RangeSums(func(key string, regretSum, strategySum []float32) bool {
+		if i, ok := index[key]; ok {
+			copy(result[i].RegretSum, regretSum)
+			copy(result[i].StrategySum, strategySum)
+		}
+
+		return true
+	})
+
+	return result, nil
+}
+
+// WriteSumsCSV writes the given sums as CSV, with one record per action of each
+// InfoSet. The header is:
+//
+//	infoset,player,action,regret_sum,strategy_sum
+//
+// where action is the child index. Sums are written with full float32 precision.
+func WriteSumsCSV(w io.Writer, sums []InfoSetSums) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"infoset", "player", "action", "regret_sum", "strategy_sum"}); err != nil {
+		return err
+	}
+
+	for _, s := range sums {
+		for i := range s.RegretSum {
+			record := []string{
+				s.Key,
+				strconv.Itoa(s.Player),
+				strconv.Itoa(i),
+				strconv.FormatFloat(float64(s.RegretSum[i]), 'g', -1, 32),
+				strconv.FormatFloat(float64(s.StrategySum[i]), 'g', -1, 32),
+			}
+
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSumsBinary writes the given sums in a compact binary format. All integers
+// and floats are little-endian. The file begins with the 8-byte magic "CFRSUMS1"
+// and the number of InfoSets (uint64), followed by each InfoSet as:
+//
+//	key length (uint32), key (bytes),
+//	player (uint8), number of actions n (uint32),
+//	n regret sums (float32), n strategy sums (float32)
+func WriteSumsBinary(w io.Writer, sums []InfoSetSums) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(sumsMagic); err != nil {
+		return err
+	}
+
+	if err := binary.Write(bw, binary.LittleEndian, uint64(len(sums))); err != nil {
+		return err
+	}
+
+	for _, s := range sums {
+		header := []interface{}{uint32(len(s.Key)), []byte(s.Key), uint8(s.Player), uint32(len(s.RegretSum))}
+		for _, x := range header {
+			if err := binary.Write(bw, binary.LittleEndian, x); err != nil {
+				return err
+			}
+		}
+
+		if err := binary.Write(bw, binary.LittleEndian, s.RegretSum); err != nil {
+			return err
+		}
+
+		if err := binary.Write(bw, binary.LittleEndian, s.StrategySum); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ReadSumsBinary reads sums in the format written by WriteSumsBinary.
+func ReadSumsBinary(r io.Reader) ([]InfoSetSums, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(sumsMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	} else if string(magic) != sumsMagic {
+		return nil, fmt.Errorf("invalid magic: %q", magic)
+	}
+
+	var n uint64
+	if err := binary.Read(br, binary.LittleEndian, &n); err != nil {
+		return nil, err
+	}
+
+	var result []InfoSetSums
+	for i := uint64(0); i < n; i++ {
+		var keyLen uint32
+		if err := binary.Read(br, binary.LittleEndian, &keyLen); err != nil {
+			return nil, err
+		}
+
+		if keyLen > maxSumsKeyLen {
+			return nil, fmt.Errorf("invalid key length: %d", keyLen)
+		}
+
+		key := make([]byte, keyLen)
+		if _, err := io.ReadFull(br, key); err != nil {
+			return nil, err
+		}
+
+		var player uint8
+		var nActions uint32
+		if err := binary.Read(br, binary.LittleEndian, &player); err != nil {
+			return nil, err
+		}
+
+		if err := binary.Read(br, binary.LittleEndian, &nActions); err != nil {
+			return nil, err
+		}
+
+		if nActions > math.MaxInt32/8 {
+			return nil, fmt.Errorf("invalid number of actions for %q: %d", key, nActions)
+		}
+
+		s := InfoSetSums{
+			Key:         string(key),
+			Player:      int(player),
+			RegretSum:   make([]float32, nActions),
+			StrategySum: make([]float32, nActions),
+		}
+
+		if err := binary.Read(br, binary.LittleEndian, s.RegretSum); err != nil {
+			return nil, err
+		}
+
+		if err := binary.Read(br, binary.LittleEndian, s.StrategySum); err != nil {
+			return nil, err
+		}
+
+		result = append(result, s)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestSums(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	for i := 0; i < 1000; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+
+	sums, err := Sums(root, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sums) != 12 {
+		t.Fatalf("expected 12 InfoSets, got %d", len(sums))
+	}
+
+	// The normalized strategy sums are the average strategy.
+	for _, s := range sums {
+		total := s.StrategySum[0] + s.StrategySum[1]
+		node := findInfoSet(root, s.Key)
+		expected := policy.GetPolicy(node).GetAverageStrategy()
+		for i, x := range s.StrategySum {
+			if math.Abs(float64(x/total-expected[i])) > 1e-5 {
+				t.Errorf("%s: expected average strategy %v, got strategy sums %v", s.Key, expected, s.StrategySum)
+				break
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSumsCSV(&buf, sums); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1+2*len(sums) {
+		t.Errorf("expected %d CSV lines, got %d", 1+2*len(sums), len(lines))
+	}
+
+	if lines[0] != "infoset,player,action,regret_sum,strategy_sum" {
+		t.Errorf("unexpected CSV header: %q", lines[0])
+	}
+
+	buf.Reset()
+	if err := WriteSumsBinary(&buf, sums); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := ReadSumsBinary(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, sums) {
+		t.Errorf("binary round trip failed: expected %v, got %v", sums, decoded)
+	}
+}
+
+func TestSums_Untrained(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	sums, err := Sums(root, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range sums {
+		if !reflect.DeepEqual(s.RegretSum, []float32{0, 0}) || !reflect.DeepEqual(s.StrategySum, []float32{0, 0}) {
+			t.Errorf("%s: expected zero sums, got %v and %v", s.Key, s.RegretSum, s.StrategySum)
+		}
+	}
+
+	// No policies are created for the InfoSets.
+	policy.RangeSums(func(key string, regretSum, strategySum []float32) bool {
+		t.Errorf("unexpected policy for %s", key)
+		return true
+	})
+}
+
+func TestReadSumsBinary_InvalidKeyLength(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString(sumsMagic)
+	buf.Write([]byte{1, 0, 0, 0, 0, 0, 0, 0}) // One InfoSet.
+	buf.Write([]byte{0xff, 0xff, 0xff, 0xff}) // Key length.
+	if _, err := ReadSumsBinary(&buf); err == nil {
+		t.Error("expected error reading invalid key length")
+	}
+}
+
+func TestReadSumsBinary_InvalidMagic(t *testing.T) {
+	if _, err := ReadSumsBinary(strings.NewReader("NOTSUMS1")); err == nil {
+		t.Error("expected error reading invalid sums")
+	}
+}
+
+func findInfoSet(root cfr.GameTreeNode, key string) cfr.GameTreeNode {
+	if root.Type() == cfr.PlayerNodeType && root.InfoSet(root.Player()).Key() == key {
+		return root
+	}
+
+	for i := 0; i < root.NumChildren(); i++ {
+		if node := findInfoSet(root.GetChild(i), key); node != nil {
+			return node
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+// Package export writes trained strategies in formats intended for human review,
+// such as per-decision-point strategy tables (e.g. preflop charts) that can be
+// opened in a spreadsheet, and the raw cumulative regrets and strategy sums
+// of each InfoSet for external analysis.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+// Labeler assigns a player node to a decision point (for example, "preflop,
+// facing a raise") and to a row of that decision point's table (for example,
+// the hand "AKs"). Nodes for which ok is false are omitted from the export.
+type Labeler func(node cfr.GameTreeNode) (decisionPoint, row string, ok bool)
+
+// Table is the average strategy at a single decision point,
+// with one row per distinct row label.
+type Table struct {
+	DecisionPoint string
+	// The name of each action (column) of the table.
+	Actions []string
+	Rows    []Row
+}
+
+// Row is the average strategy of all InfoSets with the same row label
+// at a decision point.
+type Row struct {
+	Label    string
+	Strategy []float32
+	// The number of distinct InfoSets that were averaged into this row.
+	NumInfoSets int
+}
+
+// Exporter groups the InfoSets of a game by decision point.
+type Exporter struct {
+	Label Labeler
+	// ActionNames returns the names of the actions at a player node.
+	// If nil, actions are named by their child index.
+	ActionNames func(node cfr.GameTreeNode) []string
+}
+
+// Tables enumerates the game tree rooted at root and returns the average
+// strategy of the given profile at each decision point. Each distinct InfoSet
+// is weighted equally within its row. Tables and rows are returned in the
+// order in which they are first encountered in the tree.
+func (e *Exporter) Tables(root cfr.GameTreeNode, profile cfr.StrategyProfile) ([]Table, error) {
+	var tables []*Table
+	tablesByDP := make(map[string]*Table)
+	rowsByDP := make(map[string]map[string]int) // Index of each row in its table.
+	seen := make(map[string]struct{})
+	var err error
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if err != nil || node.Type() != cfr.PlayerNodeType || node.NumChildren() <= 1 {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+
+		dp, label, ok := e.Label(node)
+		if !ok {
+			return
+		}
+
+		table, ok := tablesByDP[dp]
+		if !ok {
+			table = &Table{DecisionPoint: dp, Actions: e.actionNames(node)}
+			tables = append(tables, table)
+			tablesByDP[dp] = table
+			rowsByDP[dp] = make(map[string]int)
+		} else if len(table.Actions) != node.NumChildren() {
+			err = fmt.Errorf("decision point %q has n_actions=%v but node has n_children=%v: %v",
+				dp, len(table.Actions), node.NumChildren(), node)
+			return
+		}
+
+		idx, ok := rowsByDP[dp][label]
+		if !ok {
+			idx = len(table.Rows)
+			table.Rows = append(table.Rows, Row{
+				Label:    label,
+				Strategy: make([]float32, node.NumChildren()),
+			})
+			rowsByDP[dp][label] = idx
+		}
+
+		row := &table.Rows[idx]
+
+		strategy := profile.GetPolicy(node).GetAverageStrategy()
+		for i, p := range strategy {
+			row.Strategy[i] += p
+		}
+		row.NumInfoSets++
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Table, len(tables))
+	for i, table := range tables {
+		for j := range table.Rows {
+			row := &table.Rows[j]
+			for k := range row.Strategy {
+				row.Strategy[k] /= float32(row.NumInfoSets)
+			}
+		}
+
+		result[i] = *table
+	}
+
+	return result, nil
+}
+
+func (e *Exporter) actionNames(node cfr.GameTreeNode) []string {
+	if e.ActionNames != nil {
+		return e.ActionNames(node)
+	}
+
+	names := make([]string, node.NumChildren())
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+	}
+
+	return names
+}
+
+// WriteCSV writes the given tables as CSV, separated by blank lines.
+// The header of each table is the decision point followed by the action names,
+// and each row is its label followed by the probability of each action.
+func WriteCSV(w io.Writer, tables []Table) error {
+	cw := csv.NewWriter(w)
+	for i, table := range tables {
+		if i > 0 {
+			if err := cw.Write(nil); err != nil {
+				return err
+			}
+		}
+
+		header := append([]string{table.DecisionPoint}, table.Actions...)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+
+		for _, row := range table.Rows {
+			record := make([]string, 0, len(row.Strategy)+1)
+			record = append(record, row.Label)
+			for _, p := range row.Strategy {
+				record = append(record, strconv.FormatFloat(float64(p), 'f', 4, 32))
+			}
+
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
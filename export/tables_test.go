@@ -0,0 +1,104 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+// kuhnLabel groups Kuhn poker InfoSets (keys like "rrcb-K")
+// by betting history, with one row per card.
+func kuhnLabel(node cfr.GameTreeNode) (string, string, bool) {
+	key := node.InfoSet(node.Player()).Key()
+	parts := strings.SplitN(key, "-", 2)
+	return parts[0], parts[1], true
+}
+
+func TestTables(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	for i := 0; i < 1000; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+
+	e := &Exporter{
+		Label: kuhnLabel,
+		ActionNames: func(node cfr.GameTreeNode) []string {
+			return []string{"check", "bet"}
+		},
+	}
+
+	tables, err := e.Tables(root, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tables) != 4 {
+		t.Fatalf("expected 4 decision points, got %d: %v", len(tables), tables)
+	}
+
+	for _, table := range tables {
+		if len(table.Rows) != 3 {
+			t.Errorf("expected 3 rows in table %q, got %v", table.DecisionPoint, table.Rows)
+		}
+
+		for _, row := range table.Rows {
+			if row.NumInfoSets != 1 {
+				t.Errorf("expected 1 infoset in row, got %v", row)
+			}
+
+			if sum := row.Strategy[0] + row.Strategy[1]; sum < 0.999 || sum > 1.001 {
+				t.Errorf("expected strategy to sum to 1, got %v", row)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, tables); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log(buf.String())
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 4*4+3 {
+		t.Errorf("expected 19 lines of output, got %d", len(lines))
+	}
+}
+
+func TestTables_MergedRows(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	e := &Exporter{
+		// A single table for each player, with one row per card.
+		Label: func(node cfr.GameTreeNode) (string, string, bool) {
+			_, card, _ := kuhnLabel(node)
+			return fmt.Sprintf("player %d", node.Player()), card, true
+		},
+	}
+
+	tables, err := e.Tables(root, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tables) != 2 {
+		t.Fatalf("expected 2 decision points, got %d: %v", len(tables), tables)
+	}
+
+	for _, table := range tables {
+		if table.Actions[0] != "0" || table.Actions[1] != "1" {
+			t.Errorf("expected default action names, got %v", table.Actions)
+		}
+
+		for _, row := range table.Rows {
+			if row.NumInfoSets != 2 || row.Strategy[0] != 0.5 {
+				t.Errorf("expected 2 uniform infosets in row, got %v", row)
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package cfr
+
+// NewExternalSampling returns a runner for external sampling MC-CFR (Lanctot et
+// al., 2009), in which chance and opponent actions are sampled, while all actions
+// of the traversing player are expanded. It is MCCFR with a Sampler that traverses
+// every action of the traversing player (as with sampling.ExternalSampler).
+func NewExternalSampling(strategyProfile StrategyProfile) *MCCFR {
+	return NewMCCFR(strategyProfile, &allActionsSampler{})
+}
+
+// allActionsSampler implements Sampler by traversing all actions
+// of the traversing player.
+type allActionsSampler struct {
+	p []float32
+}
+
+func (s *allActionsSampler) Sample(node GameTreeNode, policy NodePolicy) []float32 {
+	nChildren := node.NumChildren()
+	for len(s.p) < nChildren {
+		s.p = append(s.p, 1.0)
+	}
+
+	return s.p[:nChildren]
+}
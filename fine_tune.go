@@ -0,0 +1,155 @@
+package cfr
+
+import (
+	"sort"
+
+	"github.com/timpalpant/go-cfr/internal/policy"
+)
+
+// FineTuneParams are the parameters of a FineTuner.
+type FineTuneParams struct {
+	// Number of iterations between checks for converged InfoSets.
+	Window int
+	// InfoSets that were updated during the last window, and whose positive regret
+	// sums changed by at most Threshold per iteration (in any action), are frozen.
+	Threshold float32
+	// If true, compensated summation is enabled for all policies of the table,
+	// which accumulates regrets and strategy sums with roughly the precision of
+	// float64, so that small late updates are not lost to rounding.
+	HighPrecision bool
+}
+
+// FineTuner implements a late-stage fine-tuning mode for a PolicyTable, in which
+// InfoSets that have converged are frozen and training continues only on the
+// rest, concentrating the remaining compute where it still matters.
+//
+// An InfoSet is considered converged when the positive part of its regret sums,
+// which determines its current strategy, has changed by less than the threshold
+// per iteration over the last window. Negative regrets are ignored, since those
+// of dominated actions grow without bound even once the strategy has converged.
+// InfoSets whose regrets did not change at all during the window are not frozen,
+// since they were not visited and so may be far from convergence.
+//
+// Frozen InfoSets are locked (see SetLockedInfoSets), and play their average
+// strategy as of when they were frozen. They are never unfrozen, so Threshold
+// should be small enough that frozen InfoSets do not need further training.
+type FineTuner struct {
+	pt     *PolicyTable
+	params FineTuneParams
+
+	// Regret sums of each InfoSet at the start of the current window.
+	snapshot     map[string][]float32
+	snapshotIter int
+	frozen       []string
+}
+
+// NewFineTuner returns a new FineTuner for pt, which should already have been
+// trained until most of its InfoSets are close to convergence.
+func NewFineTuner(pt *PolicyTable, params FineTuneParams) *FineTuner {
+	if params.HighPrecision && !pt.params.CompensatedSummation {
+		discountParams := pt.params
+		discountParams.CompensatedSummation = true
+		pt.SetDiscountParams(discountParams)
+	}
+
+	f := &FineTuner{pt: pt, params: params}
+	f.takeSnapshot()
+	return f
+}
+
+// Update must be called after each call to PolicyTable.Update. At the end of each
+// window, it freezes the InfoSets that have converged and returns their number.
+// It must not be called during traversal.
+func (f *FineTuner) Update() int {
+	elapsed := f.pt.iter - f.snapshotIter
+	if elapsed < f.params.Window {
+		return 0
+	}
+
+	var converged []string
+	f.pt.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+		if f.pt.isLocked(key) {
+			return true
+		}
+
+		prev, ok := f.snapshot[key]
+		if !ok {
+			return true
+		}
+
+		regretSum := p.GetRegretSum()
+		if isUpdated(prev, regretSum) && maxPositiveChange(prev, regretSum) <= f.params.Threshold*float32(elapsed) {
+			converged = append(converged, key)
+		}
+
+		return true
+	})
+
+	if len(converged) > 0 {
+		sort.Strings(converged)
+		f.frozen = append(f.frozen, converged...)
+		f.pt.SetLockedInfoSets(f.frozen...)
+	}
+
+	f.takeSnapshot()
+	return len(converged)
+}
+
+// NumFrozen returns the number of InfoSets that have been frozen.
+func (f *FineTuner) NumFrozen() int {
+	return len(f.frozen)
+}
+
+// Frozen returns the keys of the InfoSets that have been frozen,
+// in the order in which they were frozen.
+func (f *FineTuner) Frozen() []string {
+	return f.frozen
+}
+
+// takeSnapshot records the regret sums of all InfoSets that are not frozen.
+func (f *FineTuner) takeSnapshot() {
+	f.snapshot = make(map[string][]float32)
+	f.pt.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+		if !f.pt.isLocked(key) {
+			regretSum := p.GetRegretSum()
+			snapshot := make([]float32, len(regretSum))
+			copy(snapshot, regretSum)
+			f.snapshot[key] = snapshot
+		}
+
+		return true
+	})
+
+	f.snapshotIter = f.pt.iter
+}
+
+// isUpdated returns true if the regret sums changed in any action from prev.
+// Regret sums are only discounted on Update if the InfoSet was visited, so
+// they do not change at all during iterations in which it was not.
+func isUpdated(prev, regretSum []float32) bool {
+	for i, r := range regretSum {
+		if r != prev[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maxPositiveChange returns the largest absolute change in any action
+// between the positive parts of the regret sums prev and regretSum.
+func maxPositiveChange(prev, regretSum []float32) float32 {
+	var result float32
+	for i, r := range regretSum {
+		change := max(r, 0) - max(prev[i], 0)
+		if change < 0 {
+			change = -change
+		}
+
+		if change > result {
+			result = change
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,80 @@
+package cfr_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestFineTuner(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	runCFR(t, opt, policy, 1000)
+
+	tuner := cfr.NewFineTuner(policy, cfr.FineTuneParams{
+		Window:        250,
+		Threshold:     0.001,
+		HighPrecision: true,
+	})
+
+	for i := 0; i < 1000; i++ {
+		opt.Run(root)
+		policy.Update()
+		tuner.Update()
+	}
+
+	t.Logf("Froze %d InfoSets: %v", tuner.NumFrozen(), tuner.Frozen())
+	if tuner.NumFrozen() == 0 || tuner.NumFrozen() == 12 {
+		t.Errorf("expected some but not all InfoSets to be frozen, got %d", tuner.NumFrozen())
+	}
+
+	isFrozen := make(map[string]bool)
+	for _, key := range tuner.Frozen() {
+		isFrozen[key] = true
+	}
+
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		if !isFrozen[key] {
+			return
+		}
+
+		if p := policy.GetPolicy(node); !reflect.DeepEqual(p.GetStrategy(), p.GetAverageStrategy()) {
+			t.Errorf("%s: expected frozen policy to play its average strategy", key)
+		}
+	})
+
+	if exploitability := cfr.Exploitability(root, policy); exploitability > 0.01 {
+		t.Errorf("exploitability too high: %.4f", exploitability)
+	}
+}
+
+func TestFineTuner_NotVisited(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	runCFR(t, opt, policy, 1000)
+
+	tuner := cfr.NewFineTuner(policy, cfr.FineTuneParams{
+		Window:    250,
+		Threshold: 0.001,
+	})
+
+	// InfoSets whose regrets do not change because they
+	// are not visited during a window are not frozen.
+	for i := 0; i < 1000; i++ {
+		policy.Update()
+		tuner.Update()
+	}
+
+	if tuner.NumFrozen() != 0 {
+		t.Errorf("expected no InfoSets to be frozen, got %v", tuner.Frozen())
+	}
+}
@@ -0,0 +1,96 @@
+package cfr
+
+import (
+	"github.com/timpalpant/go-cfr/internal/policy"
+)
+
+// FrozenStrategy is an immutable snapshot of the average strategies of a
+// PolicyTable, for embedding in latency-sensitive game servers. All of the
+// probabilities are stored in a single contiguous slice, and lookups do not
+// allocate. It is safe for concurrent use.
+type FrozenStrategy struct {
+	// Map of InfoSet Key -> location of its probabilities in probs.
+	index map[string]frozenEntry
+	probs []float32
+}
+
+type frozenEntry struct {
+	offset uint32
+	n      uint32
+}
+
+// Freeze returns a FrozenStrategy with the current average strategy of each
+// InfoSet in the table. Later updates to the table do not affect it.
+func (pt *PolicyTable) Freeze() *FrozenStrategy {
+	s := &FrozenStrategy{
+		index: make(map[string]frozenEntry, pt.policiesByKey.Len()),
+	}
+
+	pt.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+		n := p.NumActions()
+		offset := len(s.probs)
+		for i := 0; i < n; i++ {
+			s.probs = append(s.probs, 0)
+		}
+
+		p.CopyAverageStrategy(s.probs[offset:])
+		s.index[key] = frozenEntry{offset: uint32(offset), n: uint32(n)}
+		return true
+	})
+
+	return s
+}
+
+// Len returns the number of InfoSets in the strategy.
+func (s *FrozenStrategy) Len() int {
+	return len(s.index)
+}
+
+// Probabilities returns the probability of each action at the InfoSet with
+// the given key, and false if it is not in the strategy. The returned slice
+// must not be modified.
+func (s *FrozenStrategy) Probabilities(key string) ([]float32, bool) {
+	e, ok := s.index[key]
+	if !ok {
+		return nil, false
+	}
+
+	return s.probs[e.offset : e.offset+e.n], true
+}
+
+// Strategy returns the probability of each action at a player node, or the
+// uniform distribution if its InfoSet is not in the strategy, as for a new
+// policy of a PolicyTable. It may be used with ComputeBestResponse and
+// StrategyExploitability.
+func (s *FrozenStrategy) Strategy(node GameTreeNode) []float32 {
+	if p, ok := s.Probabilities(nodeKey(node)); ok {
+		return p
+	}
+
+	return uniformStrategy(node.NumChildren())
+}
+
+// uniformStrategy returns the uniform distribution over n actions.
+func uniformStrategy(n int) []float32 {
+	p := make([]float32, n)
+	for i := range p {
+		p[i] = 1.0 / float32(n)
+	}
+
+	return p
+}
+
+// ProbabilitiesInto copies the probability of each action at the InfoSet with
+// the given key into dst, and returns the number of actions. If dst is shorter
+// than n, only the first len(dst) probabilities are copied. It returns false if
+// the InfoSet is not in the strategy. It does not allocate.
+func (s *FrozenStrategy) ProbabilitiesInto(key []byte, dst []float32) (n int, ok bool) {
+	// The conversion of key is optimized away by the compiler for map lookups.
+	e, ok := s.index[string(key)]
+	if !ok {
+		return 0, false
+	}
+
+	copy(dst, s.probs[e.offset:e.offset+e.n])
+	return int(e.n), true
+}
@@ -0,0 +1,68 @@
+package cfr_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestFrozenStrategy(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	for i := 0; i < 100; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+
+	frozen := policy.Freeze()
+	if frozen.Len() != 12 {
+		t.Errorf("expected 12 InfoSets, got %d", frozen.Len())
+	}
+
+	dst := make([]float32, 2)
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		expected := policy.GetPolicy(node).GetAverageStrategy()
+		if p, ok := frozen.Probabilities(key); !ok || !reflect.DeepEqual(p, expected) {
+			t.Errorf("%s: expected %v, got %v", key, expected, p)
+		}
+
+		n, ok := frozen.ProbabilitiesInto([]byte(key), dst)
+		if !ok || n != 2 || !reflect.DeepEqual(dst, expected) {
+			t.Errorf("%s: expected %v, got %v (n=%d)", key, expected, dst, n)
+		}
+	})
+
+	if _, ok := frozen.ProbabilitiesInto([]byte("missing"), dst); ok {
+		t.Error("expected missing InfoSet not to be found")
+	}
+
+	key := []byte("rr-K")
+	allocs := testing.AllocsPerRun(100, func() {
+		frozen.ProbabilitiesInto(key, dst)
+	})
+
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+
+	// Later updates do not affect the frozen strategy.
+	before, _ := frozen.Probabilities("rr-K")
+	before = append([]float32(nil), before...)
+	for i := 0; i < 100; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+
+	if after, _ := frozen.Probabilities("rr-K"); !reflect.DeepEqual(before, after) {
+		t.Errorf("expected frozen strategy to be unchanged, got %v (before: %v)", after, before)
+	}
+}
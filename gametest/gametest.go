@@ -0,0 +1,319 @@
+// Package gametest implements simulation-based checks of cfr.GameTreeNode
+// implementations, so that a new game can be tested before it is used to train.
+//
+// Check samples random trajectories through the game tree and verifies properties
+// that the runners in go-cfr rely on, but cannot check themselves during training:
+// chance probabilities form distributions, each InfoSet has the same acting player
+// and number of actions wherever it occurs, InfoSets survive a binary round trip,
+// and utilities are finite (and antisymmetric, for games claimed to be zero-sum).
+package gametest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// DefaultSamples is the number of trajectories sampled when Options.Samples is zero.
+const DefaultSamples = 1000
+
+// DefaultTolerance is the tolerance of numerical checks when Options.Tolerance is zero.
+const DefaultTolerance = 1e-6
+
+// maxFailuresPerCheck limits the number of failures recorded for each check,
+// so that a systematic error does not produce a failure for every sample.
+const maxFailuresPerCheck = 10
+
+// Options configures Check.
+type Options struct {
+	// Number of trajectories from the root to a terminal node to sample.
+	Samples int
+	// Seed of the random number generator used to sample trajectories.
+	Seed int64
+	// Absolute tolerance of numerical checks.
+	Tolerance float64
+	// If true, check that the utilities of the two players sum to zero
+	// at every terminal node.
+	ZeroSum bool
+}
+
+// Failure is a single failed check.
+type Failure struct {
+	// The name of the check that failed.
+	Check string
+	// A description of the node at which it failed.
+	Node    string
+	Message string
+}
+
+func (f Failure) String() string {
+	return fmt.Sprintf("%s: %s (at %s)", f.Check, f.Message, f.Node)
+}
+
+// Report is the result of Check.
+type Report struct {
+	// Number of trajectories sampled, and nodes visited along them.
+	Samples      int
+	NodesChecked int
+	// Number of distinct InfoSets encountered.
+	InfoSets int
+	// Failed checks. At most a few failures are recorded for each check.
+	Failures []Failure
+	// Number of failures of each check, including those not recorded.
+	FailureCounts map[string]int
+}
+
+// Passed returns true if no checks failed.
+func (r *Report) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// String returns a human-readable pass/fail report.
+func (r *Report) String() string {
+	var sb strings.Builder
+	status := "PASS"
+	if !r.Passed() {
+		status = "FAIL"
+	}
+
+	fmt.Fprintf(&sb, "%s: checked %d nodes and %d InfoSets in %d sampled trajectories\n",
+		status, r.NodesChecked, r.InfoSets, r.Samples)
+	for _, f := range r.Failures {
+		fmt.Fprintf(&sb, "  %v\n", f)
+	}
+
+	checks := make([]string, 0, len(r.FailureCounts))
+	for check := range r.FailureCounts {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+
+	for _, check := range checks {
+		if n := r.FailureCounts[check]; n > maxFailuresPerCheck {
+			fmt.Fprintf(&sb, "  ... and %d more %s failures\n", n-maxFailuresPerCheck, check)
+		}
+	}
+
+	return sb.String()
+}
+
+// Run checks the game rooted at root with Check, and fails t with
+// the report if any checks fail.
+func Run(t testing.TB, root cfr.GameTreeNode, opts Options) {
+	t.Helper()
+	if report := Check(root, opts); !report.Passed() {
+		t.Error(report)
+	}
+}
+
+// infoSetInfo is what must be consistent across all nodes with the same InfoSet.
+type infoSetInfo struct {
+	player     int
+	numActions int
+	node       string
+}
+
+type checker struct {
+	opts     Options
+	rng      *rand.Rand
+	report   *Report
+	infoSets map[string]infoSetInfo
+}
+
+// Check samples trajectories through the game tree rooted at root and checks
+// the properties of every node along them, returning a report of any failures.
+// At chance nodes, children are sampled according to their probabilities, and at
+// player nodes, actions are sampled uniformly at random.
+//
+// The order in which nodes are opened and closed respects cfr.SharedStateNode.
+func Check(root cfr.GameTreeNode, opts Options) *Report {
+	if opts.Samples == 0 {
+		opts.Samples = DefaultSamples
+	}
+
+	if opts.Tolerance == 0 {
+		opts.Tolerance = DefaultTolerance
+	}
+
+	c := &checker{
+		opts:     opts,
+		rng:      rand.New(rand.NewSource(opts.Seed)),
+		report:   &Report{FailureCounts: make(map[string]int)},
+		infoSets: make(map[string]infoSetInfo),
+	}
+
+	for i := 0; i < opts.Samples; i++ {
+		c.sampleTrajectory(root)
+		c.report.Samples++
+	}
+
+	c.report.InfoSets = len(c.infoSets)
+	return c.report
+}
+
+func (c *checker) fail(check string, node cfr.GameTreeNode, format string, args ...interface{}) {
+	c.report.FailureCounts[check]++
+	if c.report.FailureCounts[check] <= maxFailuresPerCheck {
+		c.report.Failures = append(c.report.Failures, Failure{
+			Check:   check,
+			Node:    fmt.Sprintf("%v", node),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+}
+
+func (c *checker) sampleTrajectory(root cfr.GameTreeNode) {
+	var visited []cfr.GameTreeNode
+	defer func() {
+		// Close deepest nodes first, as required by cfr.SharedStateNode.
+		for i := len(visited) - 1; i >= 0; i-- {
+			visited[i].Close()
+		}
+	}()
+
+	node := root
+	for {
+		c.report.NodesChecked++
+		var next int
+		switch node.Type() {
+		case cfr.TerminalNodeType:
+			c.checkTerminal(node)
+			return
+		case cfr.ChanceNodeType:
+			var ok bool
+			if next, ok = c.checkChance(node); !ok {
+				return
+			}
+		case cfr.PlayerNodeType:
+			var ok bool
+			if next, ok = c.checkPlayer(node); !ok {
+				return
+			}
+		default:
+			c.fail("node-type", node, "unknown node type %v", node.Type())
+			return
+		}
+
+		node = node.GetChild(next)
+		visited = append(visited, node)
+	}
+}
+
+// checkChance checks a chance node, and returns the index of a child sampled
+// according to its probabilities.
+func (c *checker) checkChance(node cfr.GameTreeNode) (int, bool) {
+	n := node.NumChildren()
+	if n <= 0 {
+		c.fail("chance-children", node, "chance node has %d children", n)
+		return 0, false
+	}
+
+	probs := make([]float64, n)
+	var total float64
+	for i := range probs {
+		probs[i] = node.GetChildProbability(i)
+		if math.IsNaN(probs[i]) || probs[i] < 0 || probs[i] > 1+c.opts.Tolerance {
+			c.fail("chance-probability", node, "child %d has probability %v", i, probs[i])
+		}
+
+		total += probs[i]
+	}
+
+	if math.Abs(total-1) > c.opts.Tolerance {
+		c.fail("chance-probability-sum", node, "child probabilities sum to %v", total)
+	}
+
+	child, p := node.SampleChild()
+	if math.IsNaN(p) || p <= 0 || p > 1+c.opts.Tolerance {
+		c.fail("sample-child-probability", node, "SampleChild returned probability %v", p)
+	}
+	child.Close()
+
+	x := c.rng.Float64() * total
+	for i, p := range probs {
+		if x -= p; x < 0 {
+			return i, true
+		}
+	}
+
+	return n - 1, true
+}
+
+// checkPlayer checks a player node and its InfoSet, and returns
+// the index of an action sampled uniformly at random.
+func (c *checker) checkPlayer(node cfr.GameTreeNode) (int, bool) {
+	player := node.Player()
+	if player != 0 && player != 1 {
+		c.fail("player", node, "acting player is %d", player)
+		return 0, false
+	}
+
+	n := node.NumChildren()
+	if n <= 0 {
+		c.fail("player-children", node, "player node has %d children", n)
+		return 0, false
+	}
+
+	is := node.InfoSet(player)
+	key := is.Key()
+	if again := node.InfoSet(player).Key(); again != key {
+		c.fail("infoset-key-stable", node, "InfoSet key changed from %q to %q", key, again)
+	}
+
+	info := infoSetInfo{player: player, numActions: n, node: fmt.Sprintf("%v", node)}
+	if prev, ok := c.infoSets[key]; !ok {
+		c.infoSets[key] = info
+	} else if prev.player != info.player || prev.numActions != info.numActions {
+		c.fail("infoset-consistency", node,
+			"InfoSet %q has player %d and %d actions, but player %d and %d actions at %s",
+			key, info.player, info.numActions, prev.player, prev.numActions, prev.node)
+	}
+
+	c.checkMarshal(node, is)
+	return c.rng.Intn(n), true
+}
+
+// checkMarshal checks that an InfoSet survives a binary round trip with the same key.
+func (c *checker) checkMarshal(node cfr.GameTreeNode, is cfr.InfoSet) {
+	buf, err := is.MarshalBinary()
+	if err != nil {
+		c.fail("infoset-marshal", node, "MarshalBinary failed: %v", err)
+		return
+	}
+
+	t := reflect.TypeOf(is)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	decoded, ok := reflect.New(t).Interface().(cfr.InfoSet)
+	if !ok {
+		// Only value receivers implement InfoSet, so it cannot be unmarshaled into.
+		return
+	}
+
+	if err := decoded.UnmarshalBinary(buf); err != nil {
+		c.fail("infoset-marshal", node, "UnmarshalBinary failed: %v", err)
+	} else if decoded.Key() != is.Key() {
+		c.fail("infoset-marshal", node, "key %q became %q after round trip", is.Key(), decoded.Key())
+	}
+}
+
+func (c *checker) checkTerminal(node cfr.GameTreeNode) {
+	u0, u1 := node.Utility(0), node.Utility(1)
+	for player, u := range []float64{u0, u1} {
+		if math.IsNaN(u) || math.IsInf(u, 0) {
+			c.fail("utility-finite", node, "utility of player %d is %v", player, u)
+		}
+	}
+
+	if c.opts.ZeroSum && math.Abs(u0+u1) > c.opts.Tolerance {
+		c.fail("utility-zero-sum", node, "utilities %v and %v do not sum to zero", u0, u1)
+	}
+}
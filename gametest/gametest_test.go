@@ -0,0 +1,71 @@
+package gametest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestCheck_Kuhn(t *testing.T) {
+	report := Check(kuhn.NewGame(), Options{ZeroSum: true})
+	t.Log(report)
+	if !report.Passed() {
+		t.Fatal("expected Kuhn poker to pass")
+	}
+
+	if report.Samples != DefaultSamples {
+		t.Errorf("expected %d samples, got %d", DefaultSamples, report.Samples)
+	}
+
+	if report.InfoSets != 12 {
+		t.Errorf("expected 12 InfoSets, got %d", report.InfoSets)
+	}
+}
+
+func TestCheck_BrokenGame(t *testing.T) {
+	report := Check(&brokenNode{kuhn.NewGame()}, Options{ZeroSum: true})
+	t.Log(report)
+	if report.Passed() {
+		t.Fatal("expected broken game to fail")
+	}
+
+	for _, check := range []string{"chance-probability-sum", "utility-zero-sum", "infoset-consistency"} {
+		if report.FailureCounts[check] == 0 {
+			t.Errorf("expected %s to fail", check)
+		}
+	}
+
+	if !strings.HasPrefix(report.String(), "FAIL") {
+		t.Errorf("expected report to begin with FAIL, got %q", report)
+	}
+}
+
+// brokenNode wraps a Kuhn poker node, breaking the probabilities of chance nodes,
+// the antisymmetry of utilities, and the InfoSets of both players, which collide.
+type brokenNode struct {
+	cfr.GameTreeNode
+}
+
+func (n *brokenNode) GetChild(i int) cfr.GameTreeNode {
+	return &brokenNode{n.GameTreeNode.GetChild(i)}
+}
+
+func (n *brokenNode) GetChildProbability(i int) float64 {
+	return 2 * n.GameTreeNode.GetChildProbability(i)
+}
+
+func (n *brokenNode) Utility(player int) float64 {
+	return 1.0
+}
+
+func (n *brokenNode) InfoSet(player int) cfr.InfoSet {
+	return &collidingInfoSet{}
+}
+
+type collidingInfoSet struct{}
+
+func (is *collidingInfoSet) Key() string                      { return "collision" }
+func (is *collidingInfoSet) MarshalBinary() ([]byte, error)   { return []byte(is.Key()), nil }
+func (is *collidingInfoSet) UnmarshalBinary(buf []byte) error { return nil }
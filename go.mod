@@ -1,5 +1,7 @@
 module github.com/timpalpant/go-cfr
 
+go 1.21
+
 require (
 	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b
 	github.com/pkg/errors v0.8.1
@@ -0,0 +1,246 @@
+package cfr
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// GuardedProfile wraps a StrategyProfile so that traversals may run concurrently
+// in multiple goroutines, each with its own runner, and Update may be called while
+// traversals are in flight.
+//
+// Traversals are grouped into epochs, one per iteration. Each traversal must be
+// bracketed by BeginTraversal and EndTraversal (or run with Traverse). Update waits
+// for all traversals in flight to end, and traversals that begin while an Update is
+// waiting or in progress are blocked until it is complete. Every traversal therefore
+// reads the current strategies of a single iteration, and all of its regret and
+// strategy updates are accumulated into that iteration.
+//
+// The number of traversals in each iteration is up to the caller, for example one
+// Update after each traversal of each goroutine. It should stay roughly constant,
+// since an iteration with many traversals carries proportionally more weight in
+// the average strategy. Runners that alternate the traversing player by iteration
+// also require that no iterations are skipped without any traversals.
+//
+// The policy of each InfoSet is requested from the wrapped profile once per
+// iteration, and the methods of the returned policy are guarded by a lock of its
+// own, so neither needs to be safe for concurrent use. The same guarded policy is
+// returned for all nodes of the InfoSet until the next Update. Current strategies
+// must only change in Update, as they are returned without copying.
+//
+// Of the optional interfaces that runners and samplers look for on policies, only
+// ActionCounter and the accessors of the regret and strategy sums (GetRegretSum
+// and GetStrategySum) are forwarded to the wrapped policies; any others are hidden.
+type GuardedProfile struct {
+	StrategyProfile
+
+	// Held for reading by each traversal, and for writing by Update.
+	epoch sync.RWMutex
+	iter  atomic.Int64
+
+	// Serializes calls to the wrapped profile's GetPolicy.
+	mx sync.Mutex
+	// Guarded policies of the InfoSets requested during the current iteration, by key.
+	policies *sync.Map
+}
+
+// NewGuardedProfile returns a new GuardedProfile wrapping the given profile.
+func NewGuardedProfile(profile StrategyProfile) *GuardedProfile {
+	p := &GuardedProfile{
+		StrategyProfile: profile,
+		policies:        &sync.Map{},
+	}
+
+	p.iter.Store(int64(profile.Iter()))
+	return p
+}
+
+// BeginTraversal must be called before starting a traversal.
+// It blocks while an Update is waiting or in progress.
+func (p *GuardedProfile) BeginTraversal() {
+	p.epoch.RLock()
+}
+
+// EndTraversal must be called once a traversal started with BeginTraversal is complete.
+func (p *GuardedProfile) EndTraversal() {
+	p.epoch.RUnlock()
+}
+
+// Traverse runs a single iteration of the given runner from root,
+// within BeginTraversal and EndTraversal.
+func (p *GuardedProfile) Traverse(runner Runner, root GameTreeNode) float32 {
+	p.BeginTraversal()
+	defer p.EndTraversal()
+	return runner.Run(root)
+}
+
+// GetPolicy implements StrategyProfile. It may only be called during a traversal.
+func (p *GuardedProfile) GetPolicy(node GameTreeNode) NodePolicy {
+	key := nodeKey(node)
+	if policy, ok := p.policies.Load(key); ok {
+		return policy.(NodePolicy)
+	}
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if policy, ok := p.policies.Load(key); ok {
+		return policy.(NodePolicy)
+	}
+
+	policy := newGuardedPolicy(p.StrategyProfile.GetPolicy(node))
+	p.policies.Store(key, policy)
+	return policy
+}
+
+// Update implements StrategyProfile. It waits for all traversals in flight to end,
+// and must not be called during a traversal by the same goroutine.
+func (p *GuardedProfile) Update() {
+	p.epoch.Lock()
+	defer p.epoch.Unlock()
+	p.StrategyProfile.Update()
+	p.policies = &sync.Map{}
+	p.iter.Store(int64(p.StrategyProfile.Iter()))
+}
+
+// Iter implements StrategyProfile. It is safe to call at any time.
+func (p *GuardedProfile) Iter() int {
+	return int(p.iter.Load())
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. It waits for all
+// traversals in flight to end, as for Update.
+func (p *GuardedProfile) MarshalBinary() ([]byte, error) {
+	p.epoch.Lock()
+	defer p.epoch.Unlock()
+	return p.StrategyProfile.MarshalBinary()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *GuardedProfile) UnmarshalBinary(buf []byte) error {
+	p.epoch.Lock()
+	defer p.epoch.Unlock()
+	if err := p.StrategyProfile.UnmarshalBinary(buf); err != nil {
+		return err
+	}
+
+	p.policies = &sync.Map{}
+	p.iter.Store(int64(p.StrategyProfile.Iter()))
+	return nil
+}
+
+// Close implements io.Closer. It waits for all traversals in flight to end.
+func (p *GuardedProfile) Close() error {
+	p.epoch.Lock()
+	defer p.epoch.Unlock()
+	p.policies = &sync.Map{}
+	return p.StrategyProfile.Close()
+}
+
+// guardedPolicy guards all accesses to a NodePolicy with a lock.
+type guardedPolicy struct {
+	NodePolicy
+	mx sync.Mutex
+}
+
+// summingPolicy is implemented by policies that expose their accumulated sums,
+// such as those of a PolicyTable.
+type summingPolicy interface {
+	GetRegretSum() []float32
+	GetStrategySum() []float32
+}
+
+// guardedSummingPolicy is a guardedPolicy that also forwards the
+// accessors of the accumulated sums of a summingPolicy.
+type guardedSummingPolicy struct {
+	*guardedPolicy
+}
+
+// newGuardedPolicy returns a guarded wrapper of policy.
+func newGuardedPolicy(policy NodePolicy) NodePolicy {
+	gp := &guardedPolicy{NodePolicy: policy}
+	if _, ok := policy.(summingPolicy); ok {
+		return guardedSummingPolicy{gp}
+	}
+
+	return gp
+}
+
+// GetRegretSum returns a copy of the regret sums of the wrapped policy.
+func (p guardedSummingPolicy) GetRegretSum() []float32 {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return append([]float32(nil), p.NodePolicy.(summingPolicy).GetRegretSum()...)
+}
+
+// GetStrategySum returns a copy of the strategy sums of the wrapped policy.
+func (p guardedSummingPolicy) GetStrategySum() []float32 {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return append([]float32(nil), p.NodePolicy.(summingPolicy).GetStrategySum()...)
+}
+
+func (p *guardedPolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.NodePolicy.AddRegret(w, samplingQ, instantaneousRegrets)
+}
+
+func (p *guardedPolicy) GetStrategy() []float32 {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return p.NodePolicy.GetStrategy()
+}
+
+func (p *guardedPolicy) CopyStrategy(dst []float32) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.NodePolicy.CopyStrategy(dst)
+}
+
+func (p *guardedPolicy) GetBaseline() []float32 {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return append([]float32(nil), p.NodePolicy.GetBaseline()...)
+}
+
+func (p *guardedPolicy) UpdateBaseline(w float32, action int, value float32) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.NodePolicy.UpdateBaseline(w, action, value)
+}
+
+func (p *guardedPolicy) AddStrategyWeight(w float32) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.NodePolicy.AddStrategyWeight(w)
+}
+
+// AddActionCount implements ActionCounter, falling back to
+// AddStrategyWeight if the wrapped policy does not.
+func (p *guardedPolicy) AddActionCount(action int) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if counter, ok := p.NodePolicy.(ActionCounter); ok {
+		counter.AddActionCount(action)
+	} else {
+		p.NodePolicy.AddStrategyWeight(1.0)
+	}
+}
+
+func (p *guardedPolicy) GetAverageStrategy() []float32 {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return p.NodePolicy.GetAverageStrategy()
+}
+
+func (p *guardedPolicy) CopyAverageStrategy(dst []float32) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.NodePolicy.CopyAverageStrategy(dst)
+}
+
+func (p *guardedPolicy) IsEmpty() bool {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return p.NodePolicy.IsEmpty()
+}
@@ -0,0 +1,62 @@
+package cfr_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestGuardedProfile_ConcurrentUpdate(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	guarded := cfr.NewGuardedProfile(policy)
+	const nWorkers = 4
+	const nIter = 50000
+
+	var wg sync.WaitGroup
+	for i := 0; i < nWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			root := kuhn.NewGame()
+			// Chance sampling updates both players on every traversal, so it does
+			// not depend on the number of traversals within each iteration.
+			opt := cfr.NewChanceSampling(guarded)
+			for j := 0; j < nIter/nWorkers; j++ {
+				guarded.Traverse(opt, root)
+				// Update while the other workers' traversals are in flight.
+				guarded.Update()
+			}
+		}()
+	}
+
+	wg.Wait()
+	exploitability := cfr.Exploitability(kuhn.NewGame(), guarded)
+	t.Logf("Exploitability after %d iterations: %.4f", guarded.Iter(), exploitability)
+	if exploitability > 0.02 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
+
+func TestGuardedProfile_Policies(t *testing.T) {
+	guarded := cfr.NewGuardedProfile(cfr.NewPolicyTable(cfr.DiscountParams{}))
+	node := kuhn.NewGame().GetChild(0)
+	guarded.BeginTraversal()
+	p := guarded.GetPolicy(node)
+	if guarded.GetPolicy(node) != p {
+		t.Error("expected the same policy for each request within an iteration")
+	}
+
+	if _, ok := p.(interface{ GetRegretSum() []float32 }); !ok {
+		t.Error("expected guarded policy to forward GetRegretSum")
+	}
+
+	guarded.EndTraversal()
+	guarded.Update()
+	guarded.BeginTraversal()
+	defer guarded.EndTraversal()
+	if guarded.GetPolicy(node) == p {
+		t.Error("expected a new policy after Update")
+	}
+}
@@ -76,6 +76,62 @@ type GameTreeNode interface {
 	PlayerNode
 }
 
+// SharedStateNode is an optional interface that may be implemented by a
+// GameTreeNode whose children are produced by applying an action to game state
+// shared with the node (and undoing it when the child is closed), rather than
+// by allocating independent nodes. For games with heavy state, this avoids
+// copying the entire state at every edge of the tree.
+//
+// If SharesState returns true, at most one child of the node may be open at a
+// time: a child returned by GetChild or SampleChild must be closed before
+// another child is requested, and before any other method of the node is called.
+// The runners in this package respect this order, and do not prefetch the
+// children of such nodes.
+type SharedStateNode interface {
+	SharesState() bool
+}
+
+// StaticChanceNode is an optional interface that may be implemented by a chance
+// node whose children and their probabilities are the same on every traversal,
+// for example the deal of private cards at the root of a poker game. Runners that
+// cache chance outcomes (see CFR.SetCacheChanceOutcomes) enumerate the children of
+// each such node once, and reuse them in later traversals instead of the children
+// of the node with the same ChanceKey.
+//
+// Cached children are traversed again after they have been closed, as the root
+// of a game is, so closing a node must not prevent its subtree from being rebuilt.
+// Their Parent is still the chance node of the traversal in which they were
+// cached, so a node should only implement StaticChanceNode if it is the same
+// on every traversal (for example, the root passed to each Run) or if nothing
+// beneath it depends on the identity of its Parent.
+type StaticChanceNode interface {
+	// ChanceKey uniquely identifies the chance node within the game tree.
+	ChanceKey() string
+}
+
+// UtilityBoundedNode is an optional interface that may be implemented by a
+// GameTreeNode to declare the range of utilities in the game. When built with the
+// cfrdebug tag (see Debug), runners assert that terminal utilities and expected
+// values are within these bounds, since values outside of them indicate a bug in
+// the implementation of the game.
+type UtilityBoundedNode interface {
+	// UtilityBounds returns the minimum and maximum utility of any player
+	// at any terminal node of the game.
+	UtilityBounds() (min, max float64)
+}
+
+// TerminalChildNode is an optional interface that may be implemented by a player
+// node whose terminal children have utilities that can be computed without
+// constructing them, such as the children in which a player folds. Vanilla CFR
+// uses it to skip building such children during traversal, which may be a large
+// fraction of all nodes in games with many early endings.
+type TerminalChildNode interface {
+	// TerminalChildUtility returns the utility of player at child i, and true, if
+	// the child is a terminal node whose utility is known. Otherwise it returns
+	// false, and the child is constructed with GetChild as usual.
+	TerminalChildUtility(i, player int) (float64, bool)
+}
+
 // StrategyProfile maintains a collection of regret-matching policies for each
 // player node in the game tree.
 //
@@ -102,6 +158,10 @@ type NodePolicy interface {
 	// GetStrategy gets the current vector of probabilities with which the ith
 	// available action should be played.
 	GetStrategy() []float32
+	// CopyStrategy copies the current strategy into dst, which must have
+	// length equal to the number of available actions. Unlike GetStrategy,
+	// implementations should not allocate.
+	CopyStrategy(dst []float32)
 
 	// GetBaseline gets the current vector of action-dependend baseline values,
 	// used in VR-MCCFR.
@@ -113,6 +173,10 @@ type NodePolicy interface {
 	AddStrategyWeight(w float32)
 	// GetAverageStrategy returns the average strategy over all iterations.
 	GetAverageStrategy() []float32
+	// CopyAverageStrategy copies the average strategy into dst, which must have
+	// length equal to the number of available actions. Unlike GetAverageStrategy,
+	// implementations should not allocate.
+	CopyAverageStrategy(dst []float32)
 
 	// IsEmpty returns true if the NodePolicy is new and has no accumulated regret.
 	IsEmpty() bool
@@ -74,3 +74,18 @@ func Sum(x []float32) float32 {
 	}
 	return sum
 }
+
+// KahanAxpyUnitary is
+//  for i, v := range x {
+//  	y[i] += alpha * v
+//  }
+// using compensated (Kahan) summation, with the running
+// compensation for lost low-order bits of y kept in c.
+func KahanAxpyUnitary(alpha float32, x, y, c []float32) {
+	for i, v := range x {
+		d := alpha*v - c[i]
+		t := y[i] + d
+		c[i] = (t - y[i]) - d
+		y[i] = t
+	}
+}
@@ -0,0 +1,296 @@
+package policy
+
+import (
+	"encoding/binary"
+)
+
+// Map is a mapping from InfoSet key to Policy.
+type Map interface {
+	Get(key string) (*Policy, bool)
+	Put(key string, p *Policy)
+	Len() int
+	// Range calls f for each entry in the Map, in arbitrary order,
+	// until f returns false.
+	Range(f func(key string, p *Policy) bool)
+}
+
+// NewBuiltinMap returns a Map backed by the built-in map type.
+func NewBuiltinMap(capacity int) Map {
+	return builtinMap(make(map[string]*Policy, capacity))
+}
+
+type builtinMap map[string]*Policy
+
+func (m builtinMap) Get(key string) (*Policy, bool) {
+	p, ok := m[key]
+	return p, ok
+}
+
+func (m builtinMap) Put(key string, p *Policy) {
+	m[key] = p
+}
+
+func (m builtinMap) Len() int {
+	return len(m)
+}
+
+func (m builtinMap) Range(f func(key string, p *Policy) bool) {
+	for key, p := range m {
+		if !f(key, p) {
+			return
+		}
+	}
+}
+
+const maxLoadFactor = 0.75
+
+// CompactMap is an open-addressing (linear probing) hash table that stores
+// all key bytes inline in a single append-only buffer. Compared to the
+// built-in map it avoids a separate allocation and string header per key,
+// which dominates memory usage for tables with hundreds of millions of keys.
+type CompactMap struct {
+	slots  []slot
+	keys   []byte
+	values []*Policy
+
+	// If true, keys are prefix-compressed. See NewPrefixCompressedMap.
+	prefixCompressed bool
+	// The last inserted key, the offset of its record, and the length of the
+	// chain of references that must be followed to decode it.
+	lastKey    string
+	lastKeyOff uint64
+	lastDepth  int
+}
+
+const (
+	// Max number of references followed to decode a prefix-compressed key.
+	maxPrefixDepth = 16
+	// Min length of the prefix shared with the previous key for it to be referenced.
+	minSharedPrefix = 4
+)
+
+type slot struct {
+	keyOff uint64
+	keyLen uint32
+	hash   uint32
+	// Index of the value in values, plus one. Zero indicates an empty slot.
+	value uint32
+}
+
+// NewCompactMap returns an empty CompactMap with room for
+// capacity entries before it must be resized.
+func NewCompactMap(capacity int) *CompactMap {
+	nSlots := 8
+	for float64(capacity) > maxLoadFactor*float64(nSlots) {
+		nSlots *= 2
+	}
+
+	return &CompactMap{
+		slots:  make([]slot, nSlots),
+		values: make([]*Policy, 0, capacity),
+	}
+}
+
+// NewPrefixCompressedMap returns an empty CompactMap that also compresses keys
+// by their common prefixes. Each key is stored as a reference to the previously
+// inserted key, the length of the prefix they share, and the remaining suffix.
+// Since InfoSets are typically created in the order in which they are traversed,
+// consecutive keys often share most of their history, and this can greatly
+// reduce the memory used by long keys at the cost of slower lookups.
+func NewPrefixCompressedMap(capacity int) *CompactMap {
+	m := NewCompactMap(capacity)
+	m.prefixCompressed = true
+	return m
+}
+
+func (m *CompactMap) Get(key string) (*Policy, bool) {
+	h := hashKey(key)
+	mask := uint64(len(m.slots) - 1)
+	for i := uint64(h) & mask; ; i = (i + 1) & mask {
+		s := &m.slots[i]
+		if s.value == 0 {
+			return nil, false
+		} else if s.hash == h && m.keyEquals(s, key) {
+			return m.values[s.value-1], true
+		}
+	}
+}
+
+func (m *CompactMap) Put(key string, p *Policy) {
+	h := hashKey(key)
+	mask := uint64(len(m.slots) - 1)
+	for i := uint64(h) & mask; ; i = (i + 1) & mask {
+		s := &m.slots[i]
+		if s.value == 0 {
+			break
+		} else if s.hash == h && m.keyEquals(s, key) {
+			m.values[s.value-1] = p
+			return
+		}
+	}
+
+	if float64(len(m.values)+1) > maxLoadFactor*float64(len(m.slots)) {
+		m.grow()
+	}
+
+	m.insert(slot{
+		hash:   h,
+		keyLen: uint32(len(key)),
+		keyOff: uint64(len(m.keys)),
+		value:  uint32(len(m.values) + 1),
+	})
+
+	if m.prefixCompressed {
+		m.appendCompressedKey(key)
+	} else {
+		m.keys = append(m.keys, key...)
+	}
+
+	m.values = append(m.values, p)
+}
+
+// appendCompressedKey appends the record of a prefix-compressed key: the distance
+// back to the record of the key it references (or zero if none), the length of
+// the prefix it shares with that key, and the remaining suffix.
+func (m *CompactMap) appendCompressedKey(key string) {
+	off := uint64(len(m.keys))
+	shared := commonPrefixLen(key, m.lastKey)
+	if len(m.values) == 0 || shared < minSharedPrefix || m.lastDepth >= maxPrefixDepth {
+		m.keys = binary.AppendUvarint(m.keys, 0)
+		m.keys = binary.AppendUvarint(m.keys, 0)
+		m.keys = append(m.keys, key...)
+		m.lastDepth = 0
+	} else {
+		m.keys = binary.AppendUvarint(m.keys, off-m.lastKeyOff)
+		m.keys = binary.AppendUvarint(m.keys, uint64(shared))
+		m.keys = append(m.keys, key[shared:]...)
+		m.lastDepth++
+	}
+
+	m.lastKey = key
+	m.lastKeyOff = off
+}
+
+// decodeRecord returns the reference distance, shared prefix length and
+// the offset of the suffix of the prefix-compressed key record at off.
+func (m *CompactMap) decodeRecord(off uint64) (dist uint64, prefixLen int, suffixOff uint64) {
+	dist, n1 := binary.Uvarint(m.keys[off:])
+	p, n2 := binary.Uvarint(m.keys[off+uint64(n1):])
+	return dist, int(p), off + uint64(n1+n2)
+}
+
+// hasPrefix returns true if key is a prefix of the
+// prefix-compressed key whose record is at off.
+func (m *CompactMap) hasPrefix(off uint64, key string) bool {
+	for {
+		dist, prefixLen, suffixOff := m.decodeRecord(off)
+		if len(key) > prefixLen {
+			suffix := m.keys[suffixOff : suffixOff+uint64(len(key)-prefixLen)]
+			if string(suffix) != key[prefixLen:] {
+				return false
+			}
+
+			key = key[:prefixLen]
+		}
+
+		if len(key) == 0 {
+			return true
+		}
+
+		off -= dist
+	}
+}
+
+// key returns the key of the given slot.
+func (m *CompactMap) key(s *slot) string {
+	if !m.prefixCompressed {
+		return string(m.keys[s.keyOff : s.keyOff+uint64(s.keyLen)])
+	}
+
+	buf := make([]byte, s.keyLen)
+	n := len(buf)
+	for off := s.keyOff; n > 0; {
+		dist, prefixLen, suffixOff := m.decodeRecord(off)
+		if n > prefixLen {
+			copy(buf[prefixLen:n], m.keys[suffixOff:])
+			n = prefixLen
+		}
+
+		off -= dist
+	}
+
+	return string(buf)
+}
+
+func commonPrefixLen(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	return n
+}
+
+func (m *CompactMap) Len() int {
+	return len(m.values)
+}
+
+func (m *CompactMap) Range(f func(key string, p *Policy) bool) {
+	for _, s := range m.slots {
+		if s.value == 0 {
+			continue
+		}
+
+		if !f(m.key(&s), m.values[s.value-1]) {
+			return
+		}
+	}
+}
+
+func (m *CompactMap) keyEquals(s *slot, key string) bool {
+	if m.prefixCompressed {
+		return int(s.keyLen) == len(key) && m.hasPrefix(s.keyOff, key)
+	}
+
+	return string(m.keys[s.keyOff:s.keyOff+uint64(s.keyLen)]) == key
+}
+
+// insert places s in the first empty slot of its probe sequence.
+func (m *CompactMap) insert(s slot) {
+	mask := uint64(len(m.slots) - 1)
+	for i := uint64(s.hash) & mask; ; i = (i + 1) & mask {
+		if m.slots[i].value == 0 {
+			m.slots[i] = s
+			return
+		}
+	}
+}
+
+func (m *CompactMap) grow() {
+	old := m.slots
+	m.slots = make([]slot, 2*len(old))
+	for _, s := range old {
+		if s.value != 0 {
+			m.insert(s)
+		}
+	}
+}
+
+// hashKey is the 32-bit FNV-1a hash of key, followed by the MurmurHash3
+// finalizer so that the low bits used for probing are well mixed.
+func hashKey(key string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
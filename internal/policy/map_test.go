@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestCompactMap(t *testing.T) {
+	m := NewCompactMap(0)
+	expected := make(map[string]*Policy)
+	for i := 0; i < 10000; i++ {
+		key := strconv.Itoa(i)
+		p := New(2)
+		m.Put(key, p)
+		expected[key] = p
+	}
+
+	m.Put("", New(3))
+	expected[""], _ = m.Get("")
+
+	if m.Len() != len(expected) {
+		t.Errorf("expected %d entries, got %d", len(expected), m.Len())
+	}
+
+	for key, p := range expected {
+		if got, ok := m.Get(key); !ok || got != p {
+			t.Errorf("expected %p for key %q, got %p", p, key, got)
+		}
+	}
+
+	if _, ok := m.Get("missing"); ok {
+		t.Error("expected missing key to not be found")
+	}
+
+	n := 0
+	m.Range(func(key string, p *Policy) bool {
+		if expected[key] != p {
+			t.Errorf("unexpected value for key %q", key)
+		}
+		n++
+		return true
+	})
+
+	if n != len(expected) {
+		t.Errorf("expected to visit %d entries, visited %d", len(expected), n)
+	}
+}
+
+func BenchmarkCompactMapGet(b *testing.B) {
+	m := NewCompactMap(0)
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		m.Put(keys[i], New(2))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkBuiltinMapGet(b *testing.B) {
+	m := NewBuiltinMap(0)
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		m.Put(keys[i], New(2))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(keys[i%len(keys)])
+	}
+}
+
+func TestPrefixCompressedMap(t *testing.T) {
+	m := NewPrefixCompressedMap(0)
+	expected := make(map[string]*Policy)
+	for i := 0; i < 10000; i++ {
+		// Keys that share long prefixes with the previous key, and some that don't.
+		key := "history:" + strconv.Itoa(i/100) + ":" + strconv.Itoa(i)
+		if i%7 == 0 {
+			key = strconv.Itoa(i)
+		}
+
+		p := New(2)
+		m.Put(key, p)
+		expected[key] = p
+	}
+
+	m.Put("", New(3))
+	expected[""], _ = m.Get("")
+
+	for key, p := range expected {
+		if got, ok := m.Get(key); !ok || got != p {
+			t.Errorf("expected %p for key %q, got %p", p, key, got)
+		}
+	}
+
+	for _, key := range []string{"missing", "history:1", "history:1:1000", "history:1:1000:", "history:1:10"} {
+		if _, ok := m.Get(key); ok {
+			t.Errorf("expected missing key %q to not be found", key)
+		}
+	}
+
+	n := 0
+	m.Range(func(key string, p *Policy) bool {
+		if expected[key] != p {
+			t.Errorf("unexpected value for key %q", key)
+		}
+		n++
+		return true
+	})
+
+	if n != len(expected) {
+		t.Errorf("expected to visit %d entries, visited %d", len(expected), n)
+	}
+
+	uncompressed := NewCompactMap(0)
+	for key, p := range expected {
+		uncompressed.Put(key, p)
+	}
+
+	t.Logf("Key bytes: %d compressed, %d uncompressed", len(m.keys), len(uncompressed.keys))
+	if len(m.keys) >= len(uncompressed.keys) {
+		t.Errorf("expected compressed keys to use less memory")
+	}
+}
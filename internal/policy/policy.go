@@ -2,13 +2,34 @@ package policy
 
 import (
 	"encoding/binary"
+	"expvar"
+	"fmt"
 	"math"
 
+	"github.com/golang/glog"
+
 	"github.com/timpalpant/go-cfr/internal/f32"
 )
 
-// TODO: Make configurable for VR-MCCFR.
-const decayAlpha = 0.5
+var (
+	numSaturatedPolicies = expvar.NewInt("num_saturated_policies")
+)
+
+// Default weight of each new sample in the moving average of VR-MCCFR baselines.
+const defaultBaselineDecay = 0.5
+
+// Params are parameters shared by all of the Policies of a table.
+// They are not persisted with each Policy.
+type Params struct {
+	// Weight of each new sample in the moving average of the baseline.
+	// Zero uses defaultBaselineDecay.
+	BaselineDecay float32
+	// Minimum number of exact samples before dominated actions are excluded
+	// from regret matching, if dominance checking is enabled.
+	DominanceMinSamples uint32
+}
+
+var defaultParams Params
 
 // Policy implements cfr.NodePolicy by keeping a table of
 // accumulated regrets and strategies.
@@ -17,9 +38,29 @@ type Policy struct {
 	currentStrategyWeight float32
 
 	baseline []float32
+	// Parameters of the table to which this Policy belongs.
+	// If nil, the zero Params are used.
+	params *Params
 
 	regretSum   []float32
 	strategySum []float32
+
+	// Compensation terms for Kahan summation of regretSum and strategySum.
+	// These are nil until float32 saturation is detected in the accumulators.
+	regretComp   []float32
+	strategyComp []float32
+
+	// Instantaneous regrets accumulated since the last call to NextStrategy,
+	// used as the prediction of the next iteration's regrets in predictive
+	// regret matching. It is nil unless prediction is enabled.
+	prediction []float32
+
+	// Lower and upper bounds on the instantaneous regret of each action less the
+	// mean over all actions (its value less the mean value of all actions), over
+	// all exact samples. They are used to detect dominated actions, and are nil
+	// unless dominance checking is enabled.
+	regretLo, regretHi []float32
+	numExactSamples    uint32
 }
 
 // NewPolicy returns a new Policy for a game node with the given number of actions.
@@ -33,10 +74,29 @@ func New(nActions int) *Policy {
 	}
 }
 
+// SetParams sets the table parameters of this Policy. The Params are
+// shared rather than copied, so they may be changed for all Policies
+// of a table at once.
+func (p *Policy) SetParams(params *Params) {
+	p.params = params
+}
+
+func (p *Policy) getParams() *Params {
+	if p.params == nil {
+		return &defaultParams
+	}
+
+	return p.params
+}
+
 func (p *Policy) GetStrategy() []float32 {
 	return p.currentStrategy
 }
 
+func (p *Policy) CopyStrategy(dst []float32) {
+	copy(dst, p.currentStrategy)
+}
+
 func (p *Policy) IsEmpty() bool {
 	// TODO(palpant): Worth keeping a separate bit?
 	for _, r := range p.regretSum {
@@ -48,17 +108,40 @@ func (p *Policy) IsEmpty() bool {
 	return true
 }
 
-func (p *Policy) NextStrategy(discountPositiveRegret, discountNegativeRegret, discountstrategySum float32) {
+// Minimizer computes the current strategy of a Policy from its accumulated regrets.
+type Minimizer interface {
+	// Strategy replaces the accumulated regrets (plus prediction, if enabled) of
+	// each action in x with the unnormalized weight with which it should be
+	// played on the given iteration.
+	Strategy(iter int, x []float32)
+}
+
+// NextStrategy accumulates the current strategy into the strategy sum, discounts
+// the accumulated regrets, and computes the strategy for iteration iter with the
+// given local regret minimizer, or with regret matching if it is nil.
+func (p *Policy) NextStrategy(discountPositiveRegret, discountNegativeRegret, discountstrategySum float32, minimizer Minimizer, iter int) {
 	if discountstrategySum != 1.0 {
 		f32.ScalUnitary(discountstrategySum, p.strategySum)
+		if p.strategyComp != nil {
+			f32.ScalUnitary(discountstrategySum, p.strategyComp)
+		}
 	}
 
-	f32.AxpyUnitary(p.currentStrategyWeight, p.currentStrategy, p.strategySum)
+	if p.strategyComp != nil {
+		f32.KahanAxpyUnitary(p.currentStrategyWeight, p.currentStrategy, p.strategySum, p.strategyComp)
+	} else if saturatingAxpy(p.currentStrategyWeight, p.currentStrategy, p.strategySum) {
+		glog.Warningf("Strategy sum saturated (%v), switching to compensated summation", p.strategySum)
+		numSaturatedPolicies.Add(1)
+		p.EnableCompensatedSummation()
+	}
 
 	if discountPositiveRegret != 1.0 {
 		for i, x := range p.regretSum {
 			if x > 0 {
 				p.regretSum[i] *= discountPositiveRegret
+				if p.regretComp != nil {
+					p.regretComp[i] *= discountPositiveRegret
+				}
 			}
 		}
 	}
@@ -67,49 +150,288 @@ func (p *Policy) NextStrategy(discountPositiveRegret, discountNegativeRegret, di
 		for i, x := range p.regretSum {
 			if x < 0 {
 				p.regretSum[i] *= discountNegativeRegret
+				if p.regretComp != nil {
+					p.regretComp[i] *= discountNegativeRegret
+				}
 			}
 		}
 	}
 
-	p.regretMatching()
+	if minimizer != nil {
+		p.minimize(minimizer, iter)
+	} else {
+		p.regretMatching()
+	}
+
 	p.currentStrategyWeight = 0.0
+	if p.prediction != nil {
+		for i := range p.prediction {
+			p.prediction[i] = 0
+		}
+	}
 }
 
 func (p *Policy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {
-	f32.AxpyUnitary(w, instantaneousRegrets, p.regretSum)
+	p.UpdateRegretBounds(samplingQ, instantaneousRegrets)
+	if p.prediction != nil {
+		f32.AxpyUnitary(w, instantaneousRegrets, p.prediction)
+	}
+
+	if p.regretComp != nil {
+		f32.KahanAxpyUnitary(w, instantaneousRegrets, p.regretSum, p.regretComp)
+	} else if saturatingAxpy(w, instantaneousRegrets, p.regretSum) {
+		glog.Warningf("Regret sum saturated (%v), switching to compensated summation", p.regretSum)
+		numSaturatedPolicies.Add(1)
+		p.EnableCompensatedSummation()
+	}
+}
+
+// ApplyThreshold sets the probability of actions in the current strategy that is
+// less than threshold to zero, and renormalizes. The most probable action is
+// always kept, so the strategy remains a valid distribution.
+func (p *Policy) ApplyThreshold(threshold float32) {
+	best := 0
+	for i, x := range p.currentStrategy {
+		if x > p.currentStrategy[best] {
+			best = i
+		}
+	}
+
+	for i, x := range p.currentStrategy {
+		if x < threshold && i != best {
+			p.currentStrategy[i] = 0
+		}
+	}
+
+	f32.ScalUnitary(1.0/f32.Sum(p.currentStrategy), p.currentStrategy)
+}
+
+// Initialize sets the current strategy of a new Policy to the given distribution,
+// and its accumulated regrets to regretWeight times the distribution so that
+// regret matching continues to play it until it accumulates contrary regret.
+func (p *Policy) Initialize(strategy []float32, regretWeight float32) {
+	copy(p.currentStrategy, strategy)
+	f32.ScalUnitaryTo(p.regretSum, regretWeight, strategy)
+}
+
+// EnableCompensatedSummation switches this Policy to accumulate regrets and
+// strategy sums with compensated (Kahan) summation. This is otherwise enabled
+// automatically once float32 saturation is detected.
+func (p *Policy) EnableCompensatedSummation() {
+	if p.regretComp == nil {
+		p.regretComp = make([]float32, len(p.regretSum))
+		p.strategyComp = make([]float32, len(p.strategySum))
+	}
+}
+
+// EnablePrediction switches this Policy to predictive regret matching
+// (as in PCFR+), in which the next strategy is computed from the accumulated
+// regrets plus the instantaneous regrets of the last iteration.
+func (p *Policy) EnablePrediction() {
+	if p.prediction == nil {
+		p.prediction = make([]float32, len(p.regretSum))
+	}
+}
+
+// EnableDominanceCheck switches this Policy to track bounds on the value of each
+// action relative to the others, and to exclude an action from regret matching
+// once its upper bound is less than the lower bound of another action after at
+// least Params.DominanceMinSamples samples. The action was then worse than the
+// other in every sample.
+//
+// Only samples in which the values of all actions were computed exactly (every
+// action was sampled with probability 1) contribute to the bounds, since the
+// importance-sampled regrets of Monte Carlo runners do not bound the true values.
+func (p *Policy) EnableDominanceCheck() {
+	if p.regretLo == nil {
+		p.regretLo = make([]float32, len(p.regretSum))
+		p.regretHi = make([]float32, len(p.regretSum))
+	}
+}
+
+// isExact returns true if samplingQ indicates that all actions were sampled.
+// A nil samplingQ is used for aggregated regrets, which are never exact.
+func isExact(samplingQ []float32) bool {
+	if len(samplingQ) == 0 {
+		return false
+	}
+
+	for _, q := range samplingQ {
+		if q != 1.0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// UpdateRegretBounds updates the bounds used for dominance checking with a
+// sample of instantaneous regrets, if it is exact. It is called by AddRegret,
+// and only needs to be called directly when regrets are aggregated before
+// being added, since the aggregate is not exact.
+func (p *Policy) UpdateRegretBounds(samplingQ, instantaneousRegrets []float32) {
+	if p.regretLo == nil || !isExact(samplingQ) {
+		return
+	}
+
+	// Instantaneous regrets are relative to the value of the current strategy,
+	// which changes between samples, but the difference from the mean does not.
+	mean := f32.Sum(instantaneousRegrets) / float32(len(instantaneousRegrets))
+	for i, r := range instantaneousRegrets {
+		r -= mean
+		if p.numExactSamples == 0 || r < p.regretLo[i] {
+			p.regretLo[i] = r
+		}
+
+		if p.numExactSamples == 0 || r > p.regretHi[i] {
+			p.regretHi[i] = r
+		}
+	}
+
+	p.numExactSamples++
+}
+
+// eliminateDominated sets the probability of dominated actions in the current
+// strategy to zero, and returns the number of undominated actions. The current
+// strategy is not renormalized.
+func (p *Policy) eliminateDominated() int {
+	if p.regretLo == nil || p.numExactSamples == 0 || p.numExactSamples < p.getParams().DominanceMinSamples {
+		return len(p.currentStrategy)
+	}
+
+	maxLo := p.regretLo[0]
+	for _, lo := range p.regretLo[1:] {
+		if lo > maxLo {
+			maxLo = lo
+		}
+	}
+
+	n := 0
+	for i, hi := range p.regretHi {
+		if hi < maxLo {
+			p.currentStrategy[i] = 0
+		} else {
+			n++
+		}
+	}
+
+	return n
+}
+
+// saturatingAxpy is f32.AxpyUnitary, but returns true if any nonzero
+// increment did not change its accumulator (the float32 accumulator has
+// saturated), or if any accumulator overflowed.
+func saturatingAxpy(alpha float32, x, y []float32) bool {
+	saturated := false
+	for i, v := range x {
+		dy := alpha * v
+		if dy == 0 {
+			continue
+		}
+
+		sum := y[i] + dy
+		if sum == y[i] || math.IsInf(float64(sum), 0) {
+			saturated = true
+		}
+
+		y[i] = sum
+	}
+
+	return saturated
 }
 
 func (p *Policy) AddStrategyWeight(w float32) {
 	p.currentStrategyWeight += w
 }
 
+// ScaleStrategyWeight multiplies the weight of the current strategy,
+// as accumulated by AddStrategyWeight since the last call to NextStrategy, by w.
+func (p *Policy) ScaleStrategyWeight(w float32) {
+	p.currentStrategyWeight *= w
+}
+
 func (p *Policy) GetAverageStrategy() []float32 {
 	avgStrat := make([]float32, len(p.strategySum))
+	p.CopyAverageStrategy(avgStrat)
+	return avgStrat
+}
 
+func (p *Policy) CopyAverageStrategy(dst []float32) {
 	total := f32.Sum(p.strategySum)
 	if total > 0 {
-		f32.ScalUnitaryTo(avgStrat, 1.0/total, p.strategySum)
+		f32.ScalUnitaryTo(dst, 1.0/total, p.strategySum)
 	} else {
-		for i := range avgStrat {
-			avgStrat[i] = 1.0 / float32(len(avgStrat))
+		for i := range dst {
+			dst[i] = 1.0 / float32(len(dst))
 		}
 	}
+}
 
-	return avgStrat
+// AddWeighted adds w times the accumulated regrets and strategy sums
+// of other into this Policy, and recomputes the current strategy.
+func (p *Policy) AddWeighted(w float32, other *Policy) {
+	p.AddWeightedSums(w, other.regretSum, w, other.strategySum)
+}
+
+// AddWeightedSums adds wRegret times regretSum and wStrategy times strategySum
+// into the accumulated sums of this Policy, and recomputes the current strategy.
+// The compensation terms of compensated summation, if enabled, no longer apply
+// to the new sums and are reset.
+func (p *Policy) AddWeightedSums(wRegret float32, regretSum []float32, wStrategy float32, strategySum []float32) {
+	f32.AxpyUnitary(wRegret, regretSum, p.regretSum)
+	f32.AxpyUnitary(wStrategy, strategySum, p.strategySum)
+	if p.regretComp != nil {
+		for i := range p.regretComp {
+			p.regretComp[i] = 0
+			p.strategyComp[i] = 0
+		}
+	}
+
+	p.regretMatching()
+}
+
+// ScaleSums multiplies the accumulated regrets by wRegret and the accumulated
+// strategy sums by wStrategy, along with their compensation terms if compensated
+// summation is enabled, and recomputes the current strategy.
+func (p *Policy) ScaleSums(wRegret, wStrategy float32) {
+	f32.ScalUnitary(wRegret, p.regretSum)
+	f32.ScalUnitary(wStrategy, p.strategySum)
+	if p.regretComp != nil {
+		f32.ScalUnitary(wRegret, p.regretComp)
+		f32.ScalUnitary(wStrategy, p.strategyComp)
+	}
+
+	p.regretMatching()
 }
 
 func (p *Policy) GetStrategySum() []float32 {
 	return p.strategySum
 }
 
+func (p *Policy) GetRegretSum() []float32 {
+	return p.regretSum
+}
+
+// GetRegretBounds returns the bounds on the instantaneous regret of each action,
+// relative to their mean, and the number of exact samples they were computed
+// from. The number of samples is zero unless dominance checking is enabled.
+func (p *Policy) GetRegretBounds() (lo, hi []float32, numSamples uint32) {
+	return p.regretLo, p.regretHi, p.numExactSamples
+}
+
 func (p *Policy) GetBaseline() []float32 {
 	return p.baseline
 }
 
 func (p *Policy) UpdateBaseline(w float32, action int, value float32) {
+	decay := p.getParams().BaselineDecay
+	if decay == 0 {
+		decay = defaultBaselineDecay
+	}
+
 	v := p.baseline[action] + w*(value-p.baseline[action])
-	p.baseline[action] *= (1 - decayAlpha)
-	p.baseline[action] += decayAlpha * v
+	p.baseline[action] *= (1 - decay)
+	p.baseline[action] += decay * v
 }
 
 func (p *Policy) NumActions() int {
@@ -118,21 +440,85 @@ func (p *Policy) NumActions() int {
 
 func (p *Policy) regretMatching() {
 	copy(p.currentStrategy, p.regretSum)
+	if p.prediction != nil {
+		f32.Add(p.currentStrategy, p.prediction)
+	}
+
 	makePositive(p.currentStrategy)
+	p.normalizeStrategy()
+}
+
+// minimize sets the current strategy to the weights computed by
+// the given local regret minimizer from the accumulated regrets.
+func (p *Policy) minimize(m Minimizer, iter int) {
+	copy(p.currentStrategy, p.regretSum)
+	if p.prediction != nil {
+		f32.Add(p.currentStrategy, p.prediction)
+	}
+
+	m.Strategy(iter, p.currentStrategy)
+	p.normalizeStrategy()
+}
+
+// normalizeStrategy excludes dominated actions from the current strategy
+// and normalizes it, or sets it to uniform over the remaining actions if
+// none of them have positive weight.
+func (p *Policy) normalizeStrategy() {
+	p.eliminateDominated()
 	total := f32.Sum(p.currentStrategy)
 	if total > 0 {
 		f32.ScalUnitary(1.0/total, p.currentStrategy)
 	} else {
 		for i := range p.currentStrategy {
-			p.currentStrategy[i] = 1.0 / float32(len(p.currentStrategy))
+			p.currentStrategy[i] = 1.0
 		}
+
+		n := p.eliminateDominated()
+		f32.ScalUnitary(1.0/float32(n), p.currentStrategy)
 	}
 }
 
+// The legacy binary layout of a Policy is its currentStrategyWeight followed by
+// the currentStrategy, regretSum, strategySum and baseline vectors. Policies with
+// additional state use an extended layout that begins with formatMagic (a NaN
+// currentStrategyWeight, which is never valid in the legacy layout), followed by
+// a bitmask of the optional sections that are present and the number of actions.
+const formatMagic = 0x7fc0cf52
+
+const (
+	// Kahan summation compensation terms: regretComp and strategyComp.
+	sectionCompensation = 1 << iota
+	// Predicted regrets for predictive regret matching: prediction.
+	sectionPrediction
+	// Bounds on instantaneous regrets for dominance checking: regretLo and
+	// regretHi, followed by numExactSamples.
+	sectionDominance
+)
+
 // UnmarshalBinary implements encoding.BinaryUnmarshaler.
 func (p *Policy) UnmarshalBinary(buf []byte) error {
-	nFloats := len(buf) / 4
-	nActions := (nFloats - 1) / 4
+	if len(buf) < 4 {
+		return fmt.Errorf("policy is truncated: %d bytes", len(buf))
+	}
+
+	var sections uint32
+	var nActions int
+	if binary.LittleEndian.Uint32(buf) == formatMagic {
+		if len(buf) < 12 {
+			return fmt.Errorf("policy header is truncated: %d bytes", len(buf))
+		}
+
+		sections = binary.LittleEndian.Uint32(buf[4:])
+		nActions = int(binary.LittleEndian.Uint32(buf[8:]))
+		buf = buf[12:]
+		if expected := encodedLen(sections, nActions); len(buf) < expected {
+			return fmt.Errorf("policy with %d actions is truncated: %d bytes, expected %d",
+				nActions, len(buf), expected)
+		}
+	} else {
+		nFloats := len(buf) / 4
+		nActions = (nFloats - 1) / 4
+	}
 
 	p.currentStrategyWeight = decodeF32(buf[:4])
 	buf = buf[4:]
@@ -147,6 +533,30 @@ func (p *Policy) UnmarshalBinary(buf []byte) error {
 	buf = buf[4*nActions:]
 
 	p.baseline = decodeF32s(buf[:4*nActions])
+	buf = buf[4*nActions:]
+
+	if sections&sectionCompensation != 0 {
+		p.regretComp = decodeF32s(buf[:4*nActions])
+		buf = buf[4*nActions:]
+
+		p.strategyComp = decodeF32s(buf[:4*nActions])
+		buf = buf[4*nActions:]
+	}
+
+	if sections&sectionPrediction != 0 {
+		p.prediction = decodeF32s(buf[:4*nActions])
+		buf = buf[4*nActions:]
+	}
+
+	if sections&sectionDominance != 0 {
+		p.regretLo = decodeF32s(buf[:4*nActions])
+		buf = buf[4*nActions:]
+
+		p.regretHi = decodeF32s(buf[:4*nActions])
+		buf = buf[4*nActions:]
+
+		p.numExactSamples = binary.LittleEndian.Uint32(buf)
+	}
 
 	return nil
 }
@@ -154,11 +564,35 @@ func (p *Policy) UnmarshalBinary(buf []byte) error {
 // MarshalBinary implements encoding.BinaryMarshaler.
 func (p *Policy) MarshalBinary() ([]byte, error) {
 	nActions := len(p.regretSum)
-	nBytes := 4 * (4*nActions + 1)
+	var sections uint32
+	if p.regretComp != nil {
+		sections |= sectionCompensation
+	}
+
+	if p.prediction != nil {
+		sections |= sectionPrediction
+	}
+
+	if p.regretLo != nil {
+		sections |= sectionDominance
+	}
+
+	nBytes := encodedLen(sections, nActions)
+	if sections != 0 {
+		nBytes += 12
+	}
+
 	result := make([]byte, nBytes)
+	buf := result
+	if sections != 0 {
+		binary.LittleEndian.PutUint32(buf, formatMagic)
+		binary.LittleEndian.PutUint32(buf[4:], sections)
+		binary.LittleEndian.PutUint32(buf[8:], uint32(nActions))
+		buf = buf[12:]
+	}
 
-	putF32(result, p.currentStrategyWeight)
-	buf := result[4:]
+	putF32(buf, p.currentStrategyWeight)
+	buf = buf[4:]
 
 	putF32s(buf, p.currentStrategy)
 	buf = buf[4*nActions:]
@@ -170,10 +604,55 @@ func (p *Policy) MarshalBinary() ([]byte, error) {
 	buf = buf[4*nActions:]
 
 	putF32s(buf, p.baseline)
+	buf = buf[4*nActions:]
+
+	if sections&sectionCompensation != 0 {
+		putF32s(buf, p.regretComp)
+		buf = buf[4*nActions:]
+
+		putF32s(buf, p.strategyComp)
+		buf = buf[4*nActions:]
+	}
+
+	if sections&sectionPrediction != 0 {
+		putF32s(buf, p.prediction)
+		buf = buf[4*nActions:]
+	}
+
+	if sections&sectionDominance != 0 {
+		putF32s(buf, p.regretLo)
+		buf = buf[4*nActions:]
+
+		putF32s(buf, p.regretHi)
+		buf = buf[4*nActions:]
+
+		binary.LittleEndian.PutUint32(buf, p.numExactSamples)
+	}
 
 	return result, nil
 }
 
+// encodedLen returns the number of bytes of a Policy with the given sections
+// and number of actions, excluding the header of the extended layout.
+func encodedLen(sections uint32, nActions int) int {
+	nVectors := 4
+	nBytes := 0
+	if sections&sectionCompensation != 0 {
+		nVectors += 2
+	}
+
+	if sections&sectionPrediction != 0 {
+		nVectors++
+	}
+
+	if sections&sectionDominance != 0 {
+		nVectors += 2
+		nBytes += 4 // numExactSamples.
+	}
+
+	return nBytes + 4*(nVectors*nActions+1)
+}
+
 func putF32(buf []byte, x float32) {
 	bits := math.Float32bits(x)
 	binary.LittleEndian.PutUint32(buf, bits)
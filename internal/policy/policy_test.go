@@ -0,0 +1,64 @@
+package policy
+
+import (
+	"testing"
+)
+
+func TestPolicy_UnmarshalTruncated(t *testing.T) {
+	p := New(3)
+	p.EnableCompensatedSummation()
+	p.EnableDominanceCheck()
+	buf, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var q Policy
+	if err := q.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, 3, 8, len(buf) - 1} {
+		var q Policy
+		if err := q.UnmarshalBinary(buf[:n]); err == nil {
+			t.Errorf("expected error unmarshaling %d of %d bytes", n, len(buf))
+		}
+	}
+}
+
+func TestPolicy_RegretBoundsExactOnly(t *testing.T) {
+	p := New(2)
+	p.EnableDominanceCheck()
+	regrets := []float32{1, -1}
+	p.AddRegret(1.0, nil, regrets)
+	p.AddRegret(1.0, []float32{1, 0.5}, regrets)
+	if p.numExactSamples != 0 {
+		t.Errorf("expected no exact samples, got %d", p.numExactSamples)
+	}
+
+	p.AddRegret(1.0, []float32{1, 1}, regrets)
+	if p.numExactSamples != 1 {
+		t.Errorf("expected 1 exact sample, got %d", p.numExactSamples)
+	}
+}
+
+func TestPolicy_ScaleSumsCompensation(t *testing.T) {
+	p := New(2)
+	p.EnableCompensatedSummation()
+	// The increment is lost against the large sum, and kept in the compensation.
+	p.AddRegret(1.0, nil, []float32{1e8, 0})
+	p.AddRegret(1.0, nil, []float32{1, 0})
+	if p.regretComp[0] != -1 {
+		t.Fatalf("expected compensation of -1, got %v", p.regretComp[0])
+	}
+
+	p.ScaleSums(0.5, 1.0)
+	if p.regretComp[0] != -0.5 {
+		t.Errorf("expected compensation to be scaled to -0.5, got %v", p.regretComp[0])
+	}
+
+	p.AddWeightedSums(1.0, []float32{1, 0}, 1.0, []float32{0, 0})
+	if p.regretComp[0] != 0 {
+		t.Errorf("expected compensation to be reset, got %v", p.regretComp[0])
+	}
+}
@@ -71,6 +71,12 @@ func (k *PokerNode) Close() {
 	k.probabilities = nil
 }
 
+// ChanceKey implements cfr.StaticChanceNode. The cards dealt at chance
+// nodes depend only on the cards that have already been dealt.
+func (k *PokerNode) ChanceKey() string {
+	return k.history + "-" + k.p0Card.String()
+}
+
 // NumChildren implements cfr.GameTreeNode.
 func (k *PokerNode) NumChildren() int {
 	if k.children == nil {
@@ -91,6 +97,10 @@ func (k *PokerNode) GetChild(i int) cfr.GameTreeNode {
 
 // Parent implements cfr.GameTreeNode.
 func (k *PokerNode) Parent() cfr.GameTreeNode {
+	if k.parent == nil {
+		return nil
+	}
+
 	return k.parent
 }
 
@@ -166,6 +176,31 @@ func (k *PokerNode) Utility(player int) float64 {
 	return -2.0
 }
 
+// TerminalChildUtility implements cfr.TerminalChildNode.
+func (k *PokerNode) TerminalChildUtility(i, player int) (float64, bool) {
+	if k.player == chance {
+		return 0, false
+	}
+
+	child := PokerNode{
+		player:  1 - k.player,
+		history: k.history + string([]byte{[]byte{Check, Bet}[i]}),
+		p0Card:  k.p0Card,
+		p1Card:  k.p1Card,
+	}
+
+	if !child.IsTerminal() {
+		return 0, false
+	}
+
+	return child.Utility(player), true
+}
+
+// UtilityBounds implements cfr.UtilityBoundedNode.
+func (k *PokerNode) UtilityBounds() (min, max float64) {
+	return -2.0, 2.0
+}
+
 type pokerInfoSet struct {
 	history string
 	card    string
@@ -180,7 +215,7 @@ func (p pokerInfoSet) MarshalBinary() ([]byte, error) {
 }
 
 func (p *pokerInfoSet) UnmarshalBinary(buf []byte) error {
-	parts := strings.SplitN(string(buf), "-", 1)
+	parts := strings.SplitN(string(buf), "-", 2)
 	if len(parts) != 2 {
 		return fmt.Errorf("invalid binary poker info set: %v", parts)
 	}
@@ -3,7 +3,10 @@ package kuhn
 import (
 	"bytes"
 	"encoding/gob"
+	"math"
+	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/timpalpant/go-cfr"
@@ -34,18 +37,171 @@ func TestPoker_InfoSets(t *testing.T) {
 	}
 }
 
+func TestPoker_Stats(t *testing.T) {
+	root := NewGame()
+	stats := tree.GetStats(root)
+	expected := cfr.TreeStats{NumInfoSets: 12, MaxBranching: 2, MaxDepth: 5, KeyBytes: 60}
+	if stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+}
+
 func TestPoker_VanillaCFR(t *testing.T) {
 	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
 	opt := cfr.New(policy)
 	testCFR(t, opt, policy, 10000)
 }
 
+// chanceCountingNode counts the children requested from chance nodes.
+type chanceCountingNode struct {
+	*PokerNode
+	count *int
+}
+
+func (n chanceCountingNode) GetChild(i int) cfr.GameTreeNode {
+	if n.Type() == cfr.ChanceNodeType {
+		*n.count++
+	}
+
+	return chanceCountingNode{n.PokerNode.GetChild(i).(*PokerNode), n.count}
+}
+
+func TestPoker_VanillaCFRChanceCache(t *testing.T) {
+	var uncachedCount, cachedCount int
+	uncached := cfr.NewPolicyTable(cfr.DiscountParams{})
+	cached := cfr.NewPolicyTable(cfr.DiscountParams{})
+	uncachedOpt := cfr.New(uncached)
+	cachedOpt := cfr.New(cached)
+	cachedOpt.SetCacheChanceOutcomes(true)
+	uncachedRoot := chanceCountingNode{NewGame(), &uncachedCount}
+	cachedRoot := chanceCountingNode{NewGame(), &cachedCount}
+	for i := 0; i < 100; i++ {
+		uncachedOpt.Run(uncachedRoot)
+		uncached.Update()
+		cachedOpt.Run(cachedRoot)
+		cached.Update()
+	}
+
+	// 3 deals to player 0, each followed by 2 deals to player 1.
+	if cachedCount != 9 {
+		t.Errorf("expected chance outcomes to be enumerated once, got %d children (uncached: %d)",
+			cachedCount, uncachedCount)
+	}
+
+	tree.Visit(NewGame(), func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		expected := uncached.GetPolicy(node).GetAverageStrategy()
+		actual := cached.GetPolicy(node).GetAverageStrategy()
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%v: expected %v, got %v", node, expected, actual)
+		}
+	})
+}
+
+// visitCountingNode counts the children requested from all nodes. Terminal
+// children are only short-circuited (see cfr.TerminalChildNode) if enabled.
+type visitCountingNode struct {
+	*PokerNode
+	count            *int
+	terminalChildren bool
+}
+
+func (n visitCountingNode) GetChild(i int) cfr.GameTreeNode {
+	*n.count++
+	return visitCountingNode{n.PokerNode.GetChild(i).(*PokerNode), n.count, n.terminalChildren}
+}
+
+func (n visitCountingNode) TerminalChildUtility(i, player int) (float64, bool) {
+	if !n.terminalChildren {
+		return 0, false
+	}
+
+	return n.PokerNode.TerminalChildUtility(i, player)
+}
+
+func TestPoker_VanillaCFRTerminalChildren(t *testing.T) {
+	var fullCount, shortCircuitCount int
+	full := cfr.NewPolicyTable(cfr.DiscountParams{})
+	shortCircuit := cfr.NewPolicyTable(cfr.DiscountParams{})
+	fullOpt := cfr.New(full)
+	shortCircuitOpt := cfr.New(shortCircuit)
+	fullRoot := visitCountingNode{NewGame(), &fullCount, false}
+	shortCircuitRoot := visitCountingNode{NewGame(), &shortCircuitCount, true}
+	for i := 0; i < 100; i++ {
+		fullOpt.Run(fullRoot)
+		full.Update()
+		shortCircuitOpt.Run(shortCircuitRoot)
+		shortCircuit.Update()
+	}
+
+	// Of the 57 children of the nodes in Kuhn poker, 30 are terminal.
+	if fullCount != 100*57 || shortCircuitCount != 100*27 {
+		t.Errorf("expected %d and %d children to be visited, got %d and %d",
+			100*57, 100*27, fullCount, shortCircuitCount)
+	}
+
+	tree.Visit(NewGame(), func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		expected := full.GetPolicy(node).GetAverageStrategy()
+		actual := shortCircuit.GetPolicy(node).GetAverageStrategy()
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%v: expected %v, got %v", node, expected, actual)
+		}
+	})
+}
+
+func TestPoker_VanillaCFRPartialPruning(t *testing.T) {
+	var unprunedCount, prunedCount int
+	unpruned := cfr.NewPolicyTable(cfr.DiscountParams{})
+	pruned := cfr.NewPolicyTable(cfr.DiscountParams{})
+	unprunedOpt := cfr.New(unpruned)
+	prunedOpt := cfr.New(pruned)
+	prunedOpt.SetPartialPruning(true)
+	unprunedRoot := visitCountingNode{NewGame(), &unprunedCount, false}
+	prunedRoot := visitCountingNode{NewGame(), &prunedCount, false}
+	for i := 0; i < 1000; i++ {
+		unprunedOpt.Run(unprunedRoot)
+		unpruned.Update()
+		prunedOpt.Run(prunedRoot)
+		pruned.Update()
+	}
+
+	if prunedCount >= unprunedCount {
+		t.Errorf("expected pruning to visit fewer nodes, got %d (unpruned: %d)",
+			prunedCount, unprunedCount)
+	}
+
+	tree.Visit(NewGame(), func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		expected := unpruned.GetPolicy(node).GetAverageStrategy()
+		actual := pruned.GetPolicy(node).GetAverageStrategy()
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%v: expected %v, got %v", node, expected, actual)
+		}
+	})
+}
+
 func TestPoker_ChanceSamplingCFR(t *testing.T) {
 	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
 	opt := cfr.NewChanceSampling(policy)
 	testCFR(t, opt, policy, 200000)
 }
 
+func TestPoker_ExternalSamplingRunnerCFR(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewExternalSampling(policy)
+	testCFR(t, opt, policy, 200000)
+}
+
 func TestPoker_ExternalSamplingCFR(t *testing.T) {
 	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
 	es := sampling.NewExternalSampler()
@@ -53,6 +209,29 @@ func TestPoker_ExternalSamplingCFR(t *testing.T) {
 	testCFR(t, opt, policy, 200000)
 }
 
+func TestPoker_ChanceOnlySamplerMCCFR(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	cs := sampling.NewChanceOnlySampler()
+	opt := cfr.NewMCCFR(policy, cs)
+	testCFR(t, opt, policy, 200000)
+}
+
+// exhaustiveSampler traverses every node of the game tree.
+type exhaustiveSampler struct {
+	*sampling.ChanceOnlySampler
+}
+
+func (es exhaustiveSampler) SampleChance(node cfr.GameTreeNode) []float32 {
+	return es.Sample(node, nil)
+}
+
+func TestPoker_ExhaustiveSamplerMCCFR(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	es := exhaustiveSampler{sampling.NewChanceOnlySampler()}
+	opt := cfr.NewMCCFR(policy, es)
+	testCFR(t, opt, policy, 10000)
+}
+
 func TestPoker_OutcomeSamplingCFR(t *testing.T) {
 	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
 	os := sampling.NewOutcomeSampler(0.3)
@@ -60,6 +239,12 @@ func TestPoker_OutcomeSamplingCFR(t *testing.T) {
 	testCFR(t, opt, policy, 200000)
 }
 
+func TestPoker_OutcomeSamplingRunnerCFR(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewOutcomeSampling(policy, 0.6)
+	testCFR(t, opt, policy, 1000000)
+}
+
 func TestPoker_OnlineOutcomeSamplingCFR(t *testing.T) {
 	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
 	os := sampling.NewOutcomeSampler(0.3)
@@ -75,6 +260,45 @@ func TestPoker_VRMCCFR(t *testing.T) {
 	testCFR(t, opt, policy, 200000)
 }
 
+func TestPoker_VRMCCFRBaselineDecay(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{BaselineDecay: 0.05})
+	rs1 := sampling.NewRobustSampler(1)
+	rs2 := sampling.NewRobustSampler(1)
+	opt := cfr.NewVRMCCFR(policy, rs1, rs2)
+	testCFR(t, opt, policy, 200000)
+	testMarshalRoundTrip(t, policy)
+}
+
+func TestPoker_ESCHER(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewESCHER(policy, sampling.NewRobustSampler(1), cfr.NewExactHistoryValues(policy))
+	testCFR(t, opt, policy, 100000)
+	if exploitability := cfr.Exploitability(NewGame(), policy); exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
+
+func TestPoker_ESCHERTabularHistoryValues(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	values := cfr.NewTabularHistoryValues(nil, 0.05)
+	// Runners, samplers and chance are seeded so that the test is reproducible.
+	sampler := sampling.NewRobustSampler(1)
+	sampler.Seed(1)
+	opt := cfr.NewESCHER(policy, sampler, values)
+	opt.Seed(2)
+	root := newSeededGame(3)
+	for i := 0; i < 200000; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+
+	exploitability := cfr.Exploitability(NewGame(), policy)
+	t.Logf("Exploitability with %d learned history values: %.4f", values.Len(), exploitability)
+	if exploitability > 0.02 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
+
 func TestPoker_AverageStrategySamplingCFR(t *testing.T) {
 	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
 	params := sampling.AverageStrategyParams{
@@ -94,6 +318,104 @@ func TestPoker_RobustSamplingCFR(t *testing.T) {
 	testCFR(t, opt, policy, 200000)
 }
 
+func TestPoker_RobustSamplingPerPlayerK(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	rs := sampling.NewRobustSampler(1, sampling.WithKFunc(func(player, depth int) int {
+		return 2 - player
+	}))
+
+	opt := cfr.NewGeneralizedSampling(policy, rs)
+	testCFR(t, opt, policy, 200000)
+}
+
+func TestPoker_RobustSamplingChanceEnumeration(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	rs := sampling.NewRobustSampler(1)
+	opt := cfr.NewGeneralizedSampling(policy, rs)
+	// Both deals in Kuhn poker have at most 3 outcomes.
+	opt.SetChanceEnumerationThreshold(3)
+	testCFR(t, opt, policy, 20000)
+	if exploitability := cfr.Exploitability(NewGame(), policy); exploitability > 0.02 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
+
+func TestPoker_BaselineCorrectedRobustSampling(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	rs := sampling.NewRobustSampler(1)
+	opt := cfr.NewGeneralizedSampling(policy, rs)
+	opt.SetBaselineCorrection(true)
+	testCFR(t, opt, policy, 200000)
+	if exploitability := cfr.Exploitability(NewGame(), policy); exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
+
+func TestPoker_AdaptiveKRobustSampling(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	as := sampling.NewAdaptiveKSampler(sampling.NewRobustSampler(1), sampling.AdaptiveKParams{
+		MinK:         1,
+		MaxK:         2,
+		HighVariance: 0.5,
+		Decay:        0.99,
+	})
+
+	opt := cfr.NewGeneralizedSampling(policy, as)
+	testCFR(t, opt, policy, 20000)
+	t.Logf("Final k: %d, variance: %v", as.K(), as.Variance())
+	// The regret estimates in Kuhn poker have a mean square above 0.5
+	// even when both actions are sampled, so k increases to its maximum.
+	if as.K() != 2 {
+		t.Errorf("expected k to increase to 2, got %d", as.K())
+	}
+}
+
+func TestPoker_SimultaneousUpdates(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewExternalSampling(policy)
+	opt.SetPlayerSchedule(cfr.SimultaneousUpdates)
+	testCFR(t, opt, policy, 40000)
+	if exploitability := cfr.Exploitability(NewGame(), policy); exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0 with simultaneous updates, got %v", exploitability)
+	}
+
+	// The runner, sampler and chance are seeded so that the test is reproducible.
+	policy = cfr.NewPolicyTable(cfr.DiscountParams{})
+	sampler := sampling.NewOutcomeSampler(0.6)
+	sampler.Seed(1)
+	mccfr := cfr.NewMCCFR(policy, sampler)
+	mccfr.Seed(2)
+	// Update player 0 twice as often as player 1.
+	mccfr.SetPlayerSchedule(func(iter int) []int {
+		return []int{0, iter % 2}[:1+iter%2]
+	})
+	root := newSeededGame(3)
+	for i := 0; i < 200000; i++ {
+		mccfr.Run(root)
+		policy.Update()
+	}
+
+	if exploitability := cfr.Exploitability(NewGame(), policy); exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0 with custom schedule, got %v", exploitability)
+	}
+}
+
+func TestPoker_EmptySchedule(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewExternalSampling(policy)
+	opt.SetPlayerSchedule(func(iter int) []int { return nil })
+	if value := opt.Run(NewGame()); value != 0 {
+		t.Errorf("expected value 0 with no traversing players, got %v", value)
+	}
+}
+
+func TestPoker_WeightedRobustSamplingCFR(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	rs := sampling.NewWeightedRobustSampler(1, 0.5)
+	opt := cfr.NewGeneralizedSampling(policy, rs)
+	testCFR(t, opt, policy, 200000)
+}
+
 func TestPoker_MultiOutcomeSamplingCFR(t *testing.T) {
 	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
 	mos := sampling.NewMultiOutcomeSampler(1, 0.1)
@@ -101,6 +423,158 @@ func TestPoker_MultiOutcomeSamplingCFR(t *testing.T) {
 	testCFR(t, opt, policy, 200000)
 }
 
+func TestPoker_RegretPruningSamplingCFR(t *testing.T) {
+	// Dominance checking tracks the samples from which the confidence
+	// bound on the regrets is computed.
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{DominanceMinSamples: 1000})
+	es := sampling.NewExternalSampler()
+	rp := sampling.NewRegretPruningSampler(es, -10.0, 0.05)
+	rp.Seed(1)
+	opt := cfr.NewGeneralizedSampling(policy, rp)
+	opt.Seed(2)
+	root := newSeededGame(3)
+	for i := 0; i < 200000; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+
+	t.Logf("Pruned %d actions", rp.NumPruned())
+	if rp.NumPruned() == 0 {
+		t.Error("expected some actions to be pruned")
+	}
+
+	if exploitability := cfr.Exploitability(NewGame(), policy); exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0 with pruning, got %v", exploitability)
+	}
+}
+
+func TestPoker_LazyCFR(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewLazy(policy, 0.2)
+	testCFR(t, opt, policy, 10000)
+	if opt.NumDeferred() == 0 {
+		t.Error("expected some subtrees to be deferred")
+	}
+}
+
+func TestPoker_PureCFR(t *testing.T) {
+	policy := cfr.NewPurePolicyTable()
+	opt := cfr.NewPure(policy)
+	testCFR(t, opt, policy, 200000)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(policy); err != nil {
+		t.Fatal(err)
+	}
+
+	var reloaded cfr.PurePolicyTable
+	if err := gob.NewDecoder(&buf).Decode(&reloaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded.Iter() != policy.Iter() {
+		t.Errorf("expected iter %d, got %d", policy.Iter(), reloaded.Iter())
+	}
+
+	tree.Visit(NewGame(), func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		avgStrat1 := policy.GetPolicy(node).GetAverageStrategy()
+		avgStrat2 := reloaded.GetPolicy(node).GetAverageStrategy()
+		if !reflect.DeepEqual(avgStrat1, avgStrat2) {
+			t.Errorf("expected %v, got %v", avgStrat1, avgStrat2)
+		}
+	})
+}
+
+func TestPoker_PureCFRPolicyTable(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewPure(policy)
+	testCFR(t, opt, policy, 200000)
+}
+
+func TestPoker_CFRBR(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewCFRBR(policy, 0)
+	testCFR(t, opt, policy, 10000)
+
+	// Player 1's best response against the average strategy of player 0
+	// should approach the value of the game for player 1 (1/18).
+	br := cfr.ComputeBestResponse(NewGame(), 1, func(node cfr.GameTreeNode) []float32 {
+		return policy.GetPolicy(node).GetAverageStrategy()
+	})
+
+	t.Logf("Best response value: %.4f (current strategy: %.4f)", br.Value(), opt.BestResponse().Value())
+	if br.Value() > 1.0/18+0.01 {
+		t.Errorf("expected best response value near 1/18, got %v", br.Value())
+	}
+}
+
+func TestPoker_FictitiousPlay(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewFictitiousPlay(policy)
+	root := NewGame()
+	var first, last float32
+	for i := 0; i < 2000; i++ {
+		last = opt.Run(root)
+		if i == 0 {
+			first = last
+		}
+
+		policy.Update()
+	}
+
+	exploitability := cfr.Exploitability(root, policy)
+	t.Logf("Exploitability: initial = %.4f, last = %.4f, final = %.4f", first, last, exploitability)
+	if exploitability > 0.02 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+
+	testMarshalRoundTrip(t, policy)
+}
+
+func TestPoker_WeakenedFictitiousPlay(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewFictitiousPlay(policy)
+	opt.SetStepSize(func(iter int) float32 {
+		return float32(1.0 / math.Pow(float64(iter), 0.7))
+	})
+
+	root := NewGame()
+	for i := 0; i < 2000; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+
+	if exploitability := cfr.Exploitability(root, policy); exploitability > 0.05 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
+
+func TestPoker_Minibatch(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewMinibatch(8, cfr.NewMCCFR(policy, sampling.NewOutcomeSampler(0.3)))
+	testCFR(t, opt, policy, 20000)
+}
+
+func TestPoker_ParallelMinibatch(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	guarded := cfr.NewGuardedProfile(policy)
+	const nWorkers = 4
+	runners := make([]cfr.Runner, nWorkers)
+	roots := make([]cfr.GameTreeNode, nWorkers)
+	for i := range runners {
+		runners[i] = cfr.NewMCCFR(guarded, sampling.NewOutcomeSampler(0.3))
+		roots[i] = NewGame()
+	}
+
+	opt := cfr.NewMinibatch(8, runners...)
+	opt.SetRoots(roots...)
+	testCFR(t, opt, guarded, 20000)
+}
+
 func TestPoker_CFRPlus(t *testing.T) {
 	plus := cfr.DiscountParams{UseRegretMatchingPlus: true}
 	policy := cfr.NewPolicyTable(plus)
@@ -109,6 +583,18 @@ func TestPoker_CFRPlus(t *testing.T) {
 	testCFR(t, opt, policy, 200000)
 }
 
+func TestPoker_PredictiveCFRPlus(t *testing.T) {
+	params := cfr.DiscountParams{
+		UsePredictiveRegretMatching: true,
+		DiscountGamma:               2.0, // Quadratic averaging.
+	}
+
+	policy := cfr.NewPolicyTable(params)
+	opt := cfr.New(policy)
+	testCFR(t, opt, policy, 1000)
+	testMarshalRoundTrip(t, policy)
+}
+
 func TestPoker_LinearCFR(t *testing.T) {
 	linear := cfr.DiscountParams{LinearWeighting: true}
 	policy := cfr.NewPolicyTable(linear)
@@ -132,6 +618,211 @@ func TestPoker_DiscountedCFR(t *testing.T) {
 	testCFR(t, opt, policy, 10000)
 }
 
+func TestPoker_DCFRSchedule(t *testing.T) {
+	params := cfr.DiscountParams{Schedule: cfr.DefaultDCFRSchedule}
+	policy := cfr.NewPolicyTable(params)
+	opt := cfr.New(policy)
+	testCFR(t, opt, policy, 10000)
+	testMarshalRoundTrip(t, policy)
+}
+
+func TestPoker_DCFRScheduleMCCFR(t *testing.T) {
+	params := cfr.DiscountParams{Schedule: cfr.DefaultDCFRSchedule}
+	policy := cfr.NewPolicyTable(params)
+	es := sampling.NewExternalSampler()
+	opt := cfr.NewGeneralizedSampling(policy, es)
+	testCFR(t, opt, policy, 100000)
+}
+
+func TestPoker_LinearWeightingSkippedIterations(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{LinearWeighting: true})
+	node := NewGame().GetChild(0).GetChild(1)
+	// Iteration 1: play uniform and accumulate regret for the first action.
+	p := policy.GetPolicy(node)
+	p.AddStrategyWeight(1.0)
+	p.AddRegret(1.0, nil, []float32{1.0, 0.0})
+	policy.Update()
+	// Iterations 2 and 3: not sampled.
+	policy.Update()
+	policy.Update()
+	// Iteration 4: play the first action.
+	p = policy.GetPolicy(node)
+	p.AddStrategyWeight(1.0)
+	policy.Update()
+
+	// Strategies are weighted by t+1: 2*[0.5, 0.5] + 5*[1, 0].
+	avg := p.GetAverageStrategy()
+	if math.Abs(float64(avg[0])-6.0/7) > 1e-6 || math.Abs(float64(avg[1])-1.0/7) > 1e-6 {
+		t.Errorf("expected average strategy [6/7, 1/7], got %v", avg)
+	}
+}
+
+// targetCheckBet targets the subtree in which player 0 checks and player 1 bets.
+func targetCheckBet(node cfr.GameTreeNode) bool {
+	h := node.(*PokerNode).history
+	return strings.HasPrefix("rrcb", h) || strings.HasPrefix(h, "rrcb")
+}
+
+// seededNode samples chance outcomes with rng rather than the global random
+// number generator, so that traversals of seeded runners are reproducible.
+type seededNode struct {
+	*PokerNode
+	rng *rand.Rand
+}
+
+func newSeededGame(seed int64) seededNode {
+	return seededNode{NewGame(), rand.New(rand.NewSource(seed))}
+}
+
+func (n seededNode) GetChild(i int) cfr.GameTreeNode {
+	return seededNode{n.PokerNode.GetChild(i).(*PokerNode), n.rng}
+}
+
+func (n seededNode) SampleChild() (cfr.GameTreeNode, float64) {
+	i := n.rng.Intn(n.NumChildren())
+	return n.GetChild(i), n.GetChildProbability(i)
+}
+
+// terminalCountingNode counts the terminal utilities evaluated in the
+// subtree in which player 0 checks and player 1 bets.
+type terminalCountingNode struct {
+	seededNode
+	count *int
+}
+
+func (n terminalCountingNode) GetChild(i int) cfr.GameTreeNode {
+	return terminalCountingNode{n.seededNode.GetChild(i).(seededNode), n.count}
+}
+
+func (n terminalCountingNode) SampleChild() (cfr.GameTreeNode, float64) {
+	child, p := n.seededNode.SampleChild()
+	return terminalCountingNode{child.(seededNode), n.count}, p
+}
+
+func (n terminalCountingNode) Utility(player int) float64 {
+	if strings.HasPrefix(n.history, "rrcb") {
+		*n.count++
+	}
+
+	return n.PokerNode.Utility(player)
+}
+
+func TestPoker_TargetedSamplingCFR(t *testing.T) {
+	target := func(node cfr.GameTreeNode) bool {
+		return targetCheckBet(node.(terminalCountingNode).PokerNode)
+	}
+
+	// Runners, samplers and chance are seeded so that the test is reproducible.
+	newRunner := func(policy cfr.StrategyProfile, delta float32) *cfr.MCCFR {
+		sampler := sampling.NewTargetedSampler(target, delta, 0.3)
+		sampler.Seed(1)
+		opt := cfr.NewMCCFR(policy, sampler)
+		opt.Seed(2)
+		return opt
+	}
+
+	// With a fixed strategy, count the iterations that reach the targeted subtree.
+	nIter := 2000
+	var counts [2]int
+	for i, delta := range []float32{0, 0.8} {
+		policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+		opt := newRunner(policy, delta)
+		root := terminalCountingNode{newSeededGame(3), &counts[i]}
+		for j := 0; j < nIter; j++ {
+			opt.Run(root)
+		}
+	}
+
+	if counts[1] < 2*counts[0] {
+		t.Errorf("expected targeting to concentrate samples in the targeted subtree, got %d (untargeted: %d)",
+			counts[1], counts[0])
+	}
+
+	// Importance weighting keeps the average strategy unbiased
+	// over the whole game, not only the targeted subtree.
+	var count int
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := newRunner(policy, 0.8)
+	root := terminalCountingNode{newSeededGame(3), &count}
+	for i := 0; i < 400000; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+
+	exploitability := cfr.Exploitability(NewGame(), policy)
+	t.Logf("Exploitability after %d iterations: %.4f", 400000, exploitability)
+	if exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
+
+func TestPoker_HedgeCFR(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{
+		Minimizer: cfr.HedgeMinimizer{StepSize: 3, StepDecay: 0.5},
+	})
+	opt := cfr.New(policy)
+	testCFR(t, opt, policy, 10000)
+}
+
+func TestPoker_OptimisticFTRL(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{
+		Minimizer: cfr.FTRLMinimizer{StepSize: 1, UsePrediction: true},
+	})
+	opt := cfr.New(policy)
+	testCFR(t, opt, policy, 10000)
+	testMarshalRoundTrip(t, policy)
+}
+
+func TestPoker_DynamicThresholding(t *testing.T) {
+	params := cfr.DiscountParams{DynamicThreshold: 0.5}
+	for _, tc := range []struct {
+		name  string
+		opt   func(cfr.StrategyProfile) cfrImpl
+		nIter int
+	}{
+		{"vanilla", func(p cfr.StrategyProfile) cfrImpl { return cfr.New(p) }, 10000},
+		{"external sampling", func(p cfr.StrategyProfile) cfrImpl { return cfr.NewExternalSampling(p) }, 200000},
+	} {
+		policy := cfr.NewPolicyTable(params)
+		runCFR(t, tc.opt(policy), policy, tc.nIter)
+
+		// Kuhn poker has dominated actions (e.g. folding the King), which
+		// should have been thresholded to exactly zero.
+		nThresholded := 0
+		tree.Visit(NewGame(), func(node cfr.GameTreeNode) {
+			if node.Type() != cfr.PlayerNodeType {
+				return
+			}
+
+			strategy := policy.GetPolicy(node).GetStrategy()
+			for _, p := range strategy {
+				if p > 0 && p < params.GetThreshold(policy.Iter()) {
+					t.Errorf("%s: %v: expected actions below threshold to be removed, got %v",
+						tc.name, node, strategy)
+				} else if p == 0 {
+					nThresholded++
+				}
+			}
+		})
+
+		if nThresholded == 0 {
+			t.Errorf("%s: expected some actions to be thresholded", tc.name)
+		}
+
+		if exploitability := cfr.Exploitability(NewGame(), policy); exploitability > 0.01 {
+			t.Errorf("%s: expected exploitability near 0, got %v", tc.name, exploitability)
+		}
+	}
+}
+
+func TestPoker_CompensatedSummationCFR(t *testing.T) {
+	params := cfr.DiscountParams{CompensatedSummation: true}
+	policy := cfr.NewPolicyTable(params)
+	opt := cfr.New(policy)
+	testCFR(t, opt, policy, 10000)
+	testMarshalRoundTrip(t, policy)
+}
+
 func BenchmarkPoker_VanillaCFR(b *testing.B) {
 	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
 	opt := cfr.New(policy)
@@ -139,6 +830,16 @@ func BenchmarkPoker_VanillaCFR(b *testing.B) {
 	runCFR(b, opt, policy, b.N)
 }
 
+func BenchmarkPoker_PreallocatedVanillaCFR(b *testing.B) {
+	stats := tree.GetStats(NewGame())
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	policy.Preallocate(stats)
+	opt := cfr.New(policy)
+	opt.SetSlicePool(cfr.NewFloatSlicePool(stats.SlicePoolParams()))
+	b.ResetTimer()
+	runCFR(b, opt, policy, b.N)
+}
+
 func BenchmarkPoker_ChanceSamplingCFR(b *testing.B) {
 	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
 	opt := cfr.NewChanceSampling(policy)
@@ -342,13 +1043,145 @@ func TestPoker_VRSingleDeepCFR(t *testing.T) {
 	}
 }
 
+func TestPoker_DREAM(t *testing.T) {
+	model := &randomGuessModel{}
+	gob.Register(model)
+	buf0 := deepcfr.NewReservoirBuffer(10, 1)
+	buf1 := deepcfr.NewReservoirBuffer(10, 1)
+	baselineBuf0 := deepcfr.NewCircularBuffer(10)
+	baselineBuf1 := deepcfr.NewCircularBuffer(10)
+	dream := deepcfr.NewDREAM(model, model,
+		[]deepcfr.Buffer{buf0, buf1}, []deepcfr.Buffer{baselineBuf0, baselineBuf1})
+	root := NewGame()
+	opt := deepcfr.NewDREAMRunner(dream, 0.6)
+	for i := 1; i <= 1000; i++ {
+		opt.Run(root)
+	}
+
+	dream.Update()
+
+	for i := 1; i <= 1000; i++ {
+		opt.Run(root)
+	}
+
+	for player := 0; player < 2; player++ {
+		if dream.GetBuffer(player).Len() == 0 {
+			t.Errorf("no advantage samples for player %d", player)
+		}
+
+		if dream.GetBaselineBuffer(player).Len() == 0 {
+			t.Errorf("no baseline samples for player %d", player)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(dream); err != nil {
+		t.Error(err)
+	}
+
+	dec := gob.NewDecoder(&buf)
+	var reloaded deepcfr.DREAM
+	if err := dec.Decode(&reloaded); err != nil {
+		t.Error(err)
+	}
+
+	if reloaded.Iter() != dream.Iter() {
+		t.Errorf("reloaded iter %d != %d", reloaded.Iter(), dream.Iter())
+	}
+
+	for player := 0; player < 2; player++ {
+		if n := reloaded.GetBaselineBuffer(player).Len(); n != dream.GetBaselineBuffer(player).Len() {
+			t.Errorf("reloaded baseline buffer %d has %d samples", player, n)
+		}
+	}
+}
+
+// pureModel always plays the same action.
+type pureModel struct {
+	Action int
+}
+
+func (m pureModel) Predict(infoSet cfr.InfoSet, nActions int) []float32 {
+	result := make([]float32, nActions)
+	result[m.Action] = 1.0
+	return result
+}
+
+func TestPoker_CheckpointProfile(t *testing.T) {
+	gob.Register(pureModel{})
+	registries := []deepcfr.ModelRegistry{
+		deepcfr.NewMemoryModelRegistry(pureModel{0}, pureModel{1}),
+		deepcfr.NewMemoryModelRegistry(pureModel{0}),
+	}
+
+	profile := deepcfr.NewCheckpointProfile(registries)
+	profile.Seed(123)
+	if profile.Iter() != 4 {
+		t.Errorf("expected iter 4, got %d", profile.Iter())
+	}
+
+	// Checkpoints are weighted by iteration, so the second is followed in 2/3 of games.
+	root := NewGame()
+	node := root.GetChild(0).GetChild(0)
+	nGames, nBet := 30000, 0
+	for i := 0; i < nGames; i++ {
+		profile.SampleCheckpoints()
+		strategy := profile.GetPolicy(node).GetAverageStrategy()
+		if strategy[1] == 1.0 {
+			nBet++
+		}
+	}
+
+	if p := float64(nBet) / float64(nGames); math.Abs(p-2.0/3) > 0.01 {
+		t.Errorf("expected to follow the last checkpoint in 2/3 of games, got %v", p)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(profile); err != nil {
+		t.Fatal(err)
+	}
+
+	var reloaded deepcfr.CheckpointProfile
+	if err := gob.NewDecoder(&buf).Decode(&reloaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded.Iter() != profile.Iter() {
+		t.Errorf("expected iter %d, got %d", profile.Iter(), reloaded.Iter())
+	}
+
+	// Checkpoints of a trained SingleDeepCFR.
+	model := &randomGuessModel{}
+	gob.Register(model)
+	buffers := []deepcfr.Buffer{deepcfr.NewReservoirBuffer(10, 1), deepcfr.NewReservoirBuffer(10, 1)}
+	deepCFR := deepcfr.NewSingleDeepCFR(model, buffers)
+	opt := cfr.NewExternalSampling(deepCFR)
+	runCFR(t, opt, deepCFR, 4)
+	profile = deepcfr.NewCheckpointProfile([]deepcfr.ModelRegistry{
+		deepCFR.Checkpoints(0), deepCFR.Checkpoints(1),
+	})
+
+	if profile.Iter() != deepCFR.Iter() {
+		t.Errorf("expected iter %d, got %d", deepCFR.Iter(), profile.Iter())
+	}
+
+	if strategy := profile.GetPolicy(node).GetStrategy(); len(strategy) != 2 {
+		t.Errorf("unexpected strategy: %v", strategy)
+	}
+}
+
 func TestMarshalStrategy(t *testing.T) {
 	root := NewGame()
 	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
 	opt := cfr.New(policy)
 	opt.Run(root)
 	policy.Update()
+	testMarshalRoundTrip(t, policy)
+}
 
+func testMarshalRoundTrip(t *testing.T, policy *cfr.PolicyTable) {
+	root := NewGame()
 	var buf bytes.Buffer
 	enc := gob.NewEncoder(&buf)
 	if err := enc.Encode(policy); err != nil {
@@ -388,3 +1221,62 @@ func TestMarshalStrategy(t *testing.T) {
 		}
 	})
 }
+
+func TestPoker_PublicChanceSamplingCFR(t *testing.T) {
+	publicRoot := NewPublicGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewPublicChanceSampling(policy)
+	nIter := 2000
+	var ev float32
+	for i := 0; i < nIter; i++ {
+		value := opt.Run(publicRoot)
+		if policy.Iter()%2 == 1 {
+			value = -value // Value for player 0.
+		}
+
+		ev += value
+		policy.Update()
+	}
+
+	// The value of Kuhn poker for the first player is -1/18.
+	if ev /= float32(nIter); ev > -0.05 || ev < -0.06 {
+		t.Errorf("expected game value near -1/18, got %v", ev)
+	}
+
+	// The trained policies should apply to the full game tree.
+	root := NewGame()
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() == cfr.PlayerNodeType && policy.GetPolicy(node).IsEmpty() {
+			t.Errorf("expected trained policy for node: %v", node)
+		}
+	})
+}
+
+func TestPublicNode_TerminalChildUtilities(t *testing.T) {
+	opponentReach := []float32{0.1, 0.25, 0.3}
+	root := NewPublicGame()
+	for _, node := range []*PublicNode{root, root.GetChild(0).(*PublicNode), root.GetChild(1).(*PublicNode),
+		root.GetChild(0).GetChild(1).(*PublicNode)} {
+		for i := 0; i < node.NumChildren(); i++ {
+			for player := 0; player < 2; player++ {
+				actual := make([]float32, 3)
+				if !node.TerminalChildUtilities(i, player, opponentReach, actual) {
+					if strings.HasSuffix(node.GetChild(i).(*PublicNode).history, "bc") {
+						t.Errorf("%v: expected fold to be short-circuited", node)
+					}
+
+					continue
+				}
+
+				expected := make([]float32, 3)
+				node.GetChild(i).TerminalUtilities(player, opponentReach, expected)
+				for s := range expected {
+					if math.Abs(float64(actual[s]-expected[s])) > 1e-6 {
+						t.Errorf("%v: expected utilities %v for child %d, got %v", node, expected, i, actual)
+						break
+					}
+				}
+			}
+		}
+	}
+}
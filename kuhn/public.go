@@ -0,0 +1,178 @@
+package kuhn
+
+import (
+	"fmt"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// PublicNode implements cfr.PublicTreeNode for Kuhn Poker.
+// Each player's private state is the Card they were dealt.
+//
+// InfoSets have the same keys as those of the corresponding PokerNodes,
+// so that a strategy profile trained on the public tree can be used
+// with the full game tree.
+type PublicNode struct {
+	parent   *PublicNode
+	children []PublicNode
+	history  string
+}
+
+// NewPublicGame returns the root of the public tree for Kuhn Poker,
+// after both players have been dealt their cards.
+func NewPublicGame() *PublicNode {
+	return &PublicNode{}
+}
+
+// String implements fmt.Stringer.
+func (k PublicNode) String() string {
+	return fmt.Sprintf("Player %v's turn. History: %5s", k.Player(), k.history)
+}
+
+// Type implements cfr.PublicTreeNode.
+func (k *PublicNode) Type() cfr.NodeType {
+	if k.isTerminal() {
+		return cfr.TerminalNodeType
+	}
+
+	return cfr.PlayerNodeType
+}
+
+func (k *PublicNode) isTerminal() bool {
+	switch k.history {
+	case "cc", "cbc", "cbb", "bc", "bb":
+		return true
+	}
+
+	return false
+}
+
+// Close implements cfr.PublicTreeNode.
+func (k *PublicNode) Close() {
+	k.children = nil
+}
+
+// NumChildren implements cfr.PublicTreeNode.
+func (k *PublicNode) NumChildren() int {
+	if k.isTerminal() {
+		return 0
+	}
+
+	return 2
+}
+
+// GetChild implements cfr.PublicTreeNode.
+func (k *PublicNode) GetChild(i int) cfr.PublicTreeNode {
+	if k.children == nil {
+		for _, choice := range []byte{Check, Bet} {
+			k.children = append(k.children, PublicNode{
+				parent:  k,
+				history: k.history + string([]byte{choice}),
+			})
+		}
+	}
+
+	return &k.children[i]
+}
+
+// SampleChild implements cfr.PublicTreeNode.
+// Kuhn Poker has no public chance events.
+func (k *PublicNode) SampleChild() (cfr.PublicTreeNode, float64) {
+	panic("kuhn: public tree has no chance nodes")
+}
+
+// Player implements cfr.PublicTreeNode.
+func (k *PublicNode) Player() int {
+	return len(k.history) % 2
+}
+
+// NumPrivateStates implements cfr.PublicTreeNode.
+func (k *PublicNode) NumPrivateStates(player int) int {
+	return len(cardStr)
+}
+
+// PrivateStateProbabilities implements cfr.PublicTreeNode.
+func (k *PublicNode) PrivateStateProbabilities(player int) []float32 {
+	return []float32{1.0 / 3, 1.0 / 3, 1.0 / 3}
+}
+
+// InfoSet implements cfr.PublicTreeNode.
+func (k *PublicNode) InfoSet(privateState int) cfr.InfoSet {
+	return &pokerInfoSet{
+		history: string([]byte{Random, Random}) + k.history,
+		card:    Card(privateState).String(),
+	}
+}
+
+// TerminalUtilities implements cfr.PublicTreeNode.
+func (k *PublicNode) TerminalUtilities(player int, opponentReach, utilities []float32) {
+	// Cards are dealt without replacement, so given one player's card
+	// the other player's card is one of the two remaining with probability 1/2,
+	// rather than the prior probability 1/3.
+	const correlation = 1.5
+
+	for i := range utilities {
+		utilities[i] = 0
+		for j, p := range opponentReach {
+			if i == j {
+				continue // Both players can't be dealt the same card.
+			}
+
+			utilities[i] += correlation * p * k.utility(player, Card(i), Card(j))
+		}
+	}
+}
+
+// TerminalChildUtilities implements cfr.PublicTerminalChildNode. When the acting
+// player folds, the utility of each private state depends only on the total reach
+// of the opponent's other private states, so it is computed in linear time.
+func (k *PublicNode) TerminalChildUtilities(i, player int, opponentReach, utilities []float32) bool {
+	if i != 0 || (k.history != "b" && k.history != "cb") {
+		return false
+	}
+
+	// The acting player folds, and the other player wins.
+	u := float32(1.0)
+	if k.Player() == player {
+		u = -1.0
+	}
+
+	var total float32
+	for _, p := range opponentReach {
+		total += p
+	}
+
+	// See TerminalUtilities.
+	const correlation = 1.5
+	for s := range utilities {
+		utilities[s] = correlation * u * (total - opponentReach[s])
+	}
+
+	return true
+}
+
+func (k *PublicNode) utility(player int, card, opponentCard Card) float32 {
+	switch k.history {
+	case "cbc", "bc":
+		// Last player folded. The player whose turn it would be wins.
+		if k.Player() == player {
+			return 1.0
+		}
+
+		return -1.0
+	case "cc":
+		// Showdown with no bets.
+		if card > opponentCard {
+			return 1.0
+		}
+
+		return -1.0
+	}
+
+	// Showdown with 1 bet.
+	if card > opponentCard {
+		return 2.0
+	}
+
+	return -2.0
+}
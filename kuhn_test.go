@@ -0,0 +1,110 @@
+package cfr_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+type logger interface {
+	Logf(string, ...interface{})
+}
+
+func testCFR(t *testing.T, opt cfr.Runner, policy cfr.StrategyProfile, nIter int) {
+	root := runCFR(t, opt, policy, nIter)
+	seen := make(map[string]struct{})
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		if _, ok := seen[key]; ok {
+			return
+		}
+
+		actionProbs := policy.GetPolicy(node).GetAverageStrategy()
+		if actionProbs != nil {
+			t.Logf("%6s: check=%.2f bet=%.2f", node, actionProbs[0], actionProbs[1])
+		}
+
+		seen[key] = struct{}{}
+	})
+}
+
+// runCFR runs nIter iterations of opt on Kuhn poker, updating
+// policy after each, and returns the root of the game.
+func runCFR(log logger, opt cfr.Runner, policy cfr.StrategyProfile, nIter int) cfr.GameTreeNode {
+	root := kuhn.NewGame()
+	var expectedValue float32
+	for i := 1; i <= nIter; i++ {
+		expectedValue += opt.Run(root)
+		if nIter/10 > 0 && i%(nIter/10) == 0 {
+			log.Logf("[iter=%d] Expected game value: %.4f", i, expectedValue/float32(i))
+		}
+
+		policy.Update()
+	}
+
+	return root
+}
+
+func testMarshalRoundTrip(t *testing.T, policy *cfr.PolicyTable) {
+	root := kuhn.NewGame()
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(policy); err != nil {
+		t.Error(err)
+	}
+
+	dec := gob.NewDecoder(&buf)
+	var reloaded cfr.PolicyTable
+	if err := dec.Decode(&reloaded); err != nil {
+		t.Error(err)
+	}
+
+	// Verify that current strategy and average strategy are unchanged
+	// after marshalling round trip.
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		p1 := policy.GetPolicy(node).GetStrategy()
+		p2 := reloaded.GetPolicy(node).GetStrategy()
+		if len(p1) != len(p2) {
+			t.Errorf("expected %v, got %v", p1, p2)
+		} else {
+			for i := 0; i < len(p1); i++ {
+				if p1[i] != p2[i] {
+					t.Errorf("expected %v, got %v", p1, p2)
+					break
+				}
+			}
+		}
+
+		avgStrat1 := policy.GetPolicy(node).GetAverageStrategy()
+		avgStrat2 := reloaded.GetPolicy(node).GetAverageStrategy()
+		if !reflect.DeepEqual(avgStrat1, avgStrat2) {
+			t.Errorf("expected %v, got %v", avgStrat1, avgStrat2)
+		}
+	})
+}
+
+// kuhnHistory returns the history of a Kuhn poker InfoSet, including the deal.
+func kuhnHistory(is cfr.InfoSet) string {
+	history, _, _ := strings.Cut(is.Key(), "-")
+	return history
+}
+
+// kuhnCard returns the card of the player of a Kuhn poker InfoSet.
+func kuhnCard(is cfr.InfoSet) kuhn.Card {
+	_, card, _ := strings.Cut(is.Key(), "-")
+	return kuhn.Card(strings.Index("JQK", card))
+}
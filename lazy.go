@@ -0,0 +1,182 @@
+package cfr
+
+import (
+	"encoding/binary"
+
+	"github.com/timpalpant/go-cfr/internal/f32"
+)
+
+// LazyCFR implements a variant of Lazy-CFR (Zhou et al., 2018):
+// https://arxiv.org/abs/1810.04433
+//
+// Like vanilla CFR it performs a full-width traversal with simultaneous updates,
+// but the traversal of a subtree is deferred while the reach probability mass it
+// has accumulated since it was last traversed is below a threshold. Instead, the
+// counterfactual value from its last traversal is reused, and each player's
+// reach probability is added to a running total. Once the total for either player
+// exceeds the threshold, the subtree is traversed once with the accumulated reach
+// probabilities, which applies the deferred regret and strategy updates in a single
+// batch. This is exact as long as the strategies within the subtree do not change
+// while it is deferred, and otherwise they lag by at most the deferral period.
+//
+// Each deferred subtree is identified by its path of child indices from the root,
+// so Run must always be called with the same root. Memory usage grows with the
+// number of deferred subtrees, which is controlled by the threshold.
+type LazyCFR struct {
+	strategyProfile StrategyProfile
+	slicePool       SlicePool
+	threshold       float32
+
+	deferred map[string]*deferredSubtree
+	path     []byte
+}
+
+// deferredSubtree is the state of a subtree whose traversal is being deferred.
+type deferredSubtree struct {
+	// Reach probabilities (including chance) accumulated since the
+	// subtree was last traversed.
+	reachP0, reachP1 float32
+	// Expected value of the subtree for player 0 at its last traversal.
+	ev float32
+}
+
+// NewLazy returns a new LazyCFR runner that defers traversal of subtrees whose
+// accumulated reach probability (including chance) is below threshold.
+// With a threshold of 0, it is equivalent to vanilla CFR.
+func NewLazy(strategyProfile StrategyProfile, threshold float32) *LazyCFR {
+	return &LazyCFR{
+		strategyProfile: strategyProfile,
+		slicePool:       NewFloatSlicePool(SlicePoolParams{}),
+		threshold:       threshold,
+		deferred:        make(map[string]*deferredSubtree),
+	}
+}
+
+// SetSlicePool sets the pool used to allocate temporary slices during
+// traversal. A single pool may be shared by multiple runners.
+func (c *LazyCFR) SetSlicePool(pool SlicePool) {
+	c.slicePool = pool
+}
+
+// NumDeferred returns the number of subtrees whose traversal is currently deferred.
+func (c *LazyCFR) NumDeferred() int {
+	return len(c.deferred)
+}
+
+func (c *LazyCFR) Run(node GameTreeNode) float32 {
+	c.path = c.path[:0]
+	return c.runHelper(node, node.Player(), 1.0, 1.0)
+}
+
+// runHelper returns the expected value of node for lastPlayer. As in a deferred
+// traversal, reachP0 and reachP1 include the chance reach probability.
+func (c *LazyCFR) runHelper(node GameTreeNode, lastPlayer int, reachP0, reachP1 float32) float32 {
+	var ev float32
+	if node.Type() == TerminalNodeType {
+		ev = float32(terminalUtility(node, lastPlayer))
+	} else {
+		ev = getSign(lastPlayer, 0) * c.runLazy(node, reachP0, reachP1)
+	}
+
+	node.Close()
+	return ev
+}
+
+// runLazy returns the expected value of node for player 0,
+// traversing it only if its accumulated reach exceeds the threshold.
+func (c *LazyCFR) runLazy(node GameTreeNode, reachP0, reachP1 float32) float32 {
+	key := string(c.path)
+	shouldDefer := max(reachP0, reachP1) < c.threshold
+	if d, ok := c.deferred[key]; ok {
+		d.reachP0 += reachP0
+		d.reachP1 += reachP1
+		if max(d.reachP0, d.reachP1) < c.threshold {
+			return d.ev
+		}
+
+		reachP0, reachP1 = d.reachP0, d.reachP1
+		delete(c.deferred, key)
+	}
+
+	var ev float32
+	switch node.Type() {
+	case ChanceNodeType:
+		ev = c.handleChanceNode(node, reachP0, reachP1)
+	default:
+		ev = getSign(node.Player(), 0) * c.handlePlayerNode(node, reachP0, reachP1)
+	}
+
+	if shouldDefer {
+		c.deferred[key] = &deferredSubtree{ev: ev}
+	}
+
+	return ev
+}
+
+func (c *LazyCFR) handleChanceNode(node GameTreeNode, reachP0, reachP1 float32) float32 {
+	children := prefetch(c.strategyProfile, node)
+	defer children.Close()
+	var expectedValue float32
+	for i := 0; i < node.NumChildren(); i++ {
+		p := float32(node.GetChildProbability(i))
+		child := children.GetChild(i)
+		expectedValue += p * c.runChild(child, i, 0, p*reachP0, p*reachP1)
+	}
+
+	return expectedValue
+}
+
+func (c *LazyCFR) handlePlayerNode(node GameTreeNode, reachP0, reachP1 float32) float32 {
+	player := node.Player()
+	nChildren := node.NumChildren()
+	if nChildren == 1 {
+		// Optimization to skip trivial nodes with no real choice.
+		child := node.GetChild(0)
+		return c.runChild(child, 0, player, reachP0, reachP1)
+	}
+
+	policy := c.strategyProfile.GetPolicy(node)
+	children := prefetch(c.strategyProfile, node)
+	defer children.Close()
+	strategy := policy.GetStrategy()
+	regrets := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(regrets)
+	var cfValue float32
+	for i := 0; i < nChildren; i++ {
+		child := children.GetChild(i)
+		p := strategy[i]
+		var util float32
+		if player == 0 {
+			util = c.runChild(child, i, player, p*reachP0, reachP1)
+		} else {
+			util = c.runChild(child, i, player, reachP0, p*reachP1)
+		}
+
+		regrets[i] = util
+		cfValue += p * util
+	}
+
+	// Transform action utilities into instantaneous regrets by
+	// subtracting out the expected utility over all possible actions.
+	f32.AddConst(-cfValue, regrets)
+	counterFactualP := counterFactualProb(player, reachP0, reachP1, 1.0)
+	ones := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(ones)
+	for i := range ones {
+		ones[i] = 1.0
+	}
+	policy.AddRegret(counterFactualP, ones, regrets)
+	reachP := reachProb(player, reachP0, reachP1, 1.0)
+	policy.AddStrategyWeight(reachP)
+	return cfValue
+}
+
+// runChild extends the current path with the index of the ith child
+// for the duration of its traversal.
+func (c *LazyCFR) runChild(child GameTreeNode, i, lastPlayer int, reachP0, reachP1 float32) float32 {
+	n := len(c.path)
+	c.path = binary.AppendUvarint(c.path, uint64(i))
+	ev := c.runHelper(child, lastPlayer, reachP0, reachP1)
+	c.path = c.path[:n]
+	return ev
+}
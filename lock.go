@@ -0,0 +1,98 @@
+package cfr
+
+import (
+	"strings"
+	"sync"
+)
+
+// SetLockedPrefixes locks the policies of all InfoSets whose keys begin with any
+// of the given prefixes, replacing any previously locked prefixes. Calling it with
+// no prefixes unlocks all policies. It must not be called during traversal.
+//
+// Locked policies are read-only: the regrets and strategy weights added to them
+// during traversal are discarded, and they are not changed by Update. They play
+// their average strategy as of when they were locked, rather than their current
+// strategy. This allows different regions of a game (e.g. streets of poker) to
+// be solved in stages within a single PolicyTable: once a region has converged,
+// it may be locked while training the others against it.
+//
+// Locked prefixes are not saved by MarshalBinary.
+func (pt *PolicyTable) SetLockedPrefixes(prefixes ...string) {
+	pt.lockedPrefixes = prefixes
+	pt.lockedPolicies = &sync.Map{}
+}
+
+// SetLockedInfoSets locks the policies of the InfoSets with the given keys,
+// replacing any previously locked keys, in the same way as SetLockedPrefixes.
+// Calling it with no keys unlocks them; prefixes locked by SetLockedPrefixes
+// remain locked, and vice versa. It must not be called during traversal.
+func (pt *PolicyTable) SetLockedInfoSets(keys ...string) {
+	pt.lockedKeys = make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		pt.lockedKeys[key] = struct{}{}
+	}
+
+	pt.lockedPolicies = &sync.Map{}
+}
+
+// isLocked returns true if the given InfoSet key is locked.
+func (pt *PolicyTable) isLocked(key string) bool {
+	if _, ok := pt.lockedKeys[key]; ok {
+		return true
+	}
+
+	for _, prefix := range pt.lockedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getLockedPolicy returns the locked policy for the given node,
+// or nil if its InfoSet is not locked.
+func (pt *PolicyTable) getLockedPolicy(node GameTreeNode) NodePolicy {
+	key := nodeKey(node)
+	if !pt.isLocked(key) {
+		return nil
+	}
+
+	if lp, ok := pt.lockedPolicies.Load(key); ok {
+		return lp.(*lockedPolicy)
+	}
+
+	var np NodePolicy
+	if pt.buffers != nil {
+		np = pt.getBufferedPolicy(node)
+	} else {
+		np = pt.lookupPolicy(node)
+	}
+
+	lp, _ := pt.lockedPolicies.LoadOrStore(key, &lockedPolicy{
+		NodePolicy: np,
+		strategy:   np.GetAverageStrategy(),
+	})
+
+	return lp.(*lockedPolicy)
+}
+
+// lockedPolicy is a read-only NodePolicy that plays a fixed strategy.
+type lockedPolicy struct {
+	NodePolicy
+	strategy []float32
+}
+
+func (p *lockedPolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {}
+
+func (p *lockedPolicy) GetStrategy() []float32 {
+	return p.strategy
+}
+
+func (p *lockedPolicy) CopyStrategy(dst []float32) {
+	copy(dst, p.strategy)
+}
+
+func (p *lockedPolicy) UpdateBaseline(w float32, action int, value float32) {}
+
+func (p *lockedPolicy) AddStrategyWeight(w float32) {}
@@ -0,0 +1,71 @@
+package cfr_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestPolicyTable_LockedPrefixes(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	runCFR(t, opt, policy, 100)
+
+	// Lock the subtree after player 0 checks.
+	policy.SetLockedPrefixes("rrc")
+	locked := make(map[string][]float32)
+	unlocked := make(map[string][]float32)
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		if strings.HasPrefix(key, "rrc") {
+			locked[key] = policy.GetPolicy(node).GetAverageStrategy()
+		} else {
+			unlocked[key] = policy.GetPolicy(node).GetAverageStrategy()
+		}
+	})
+
+	runCFR(t, opt, policy, 100)
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		p := policy.GetPolicy(node)
+		if expected, ok := locked[key]; ok {
+			if !reflect.DeepEqual(p.GetAverageStrategy(), expected) {
+				t.Errorf("%s: expected locked average strategy %v, got %v", key, expected, p.GetAverageStrategy())
+			}
+
+			if !reflect.DeepEqual(p.GetStrategy(), expected) {
+				t.Errorf("%s: expected locked policy to play average strategy %v, got %v", key, expected, p.GetStrategy())
+			}
+		} else if reflect.DeepEqual(p.GetAverageStrategy(), unlocked[key]) {
+			t.Errorf("%s: expected unlocked average strategy to change", key)
+		}
+	})
+
+	policy.SetLockedPrefixes()
+	runCFR(t, opt, policy, 100)
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		if expected, ok := locked[key]; ok {
+			if reflect.DeepEqual(policy.GetPolicy(node).GetAverageStrategy(), expected) {
+				t.Errorf("%s: expected average strategy to change after unlocking", key)
+			}
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package cfr
+
+import (
+	"github.com/timpalpant/go-cfr/internal/f32"
+)
+
+// marginEntryKey is the InfoSet key of the opponent's entry decision in the
+// max-margin gadget.
+const marginEntryKey = "maxmargin-gadget"
+
+// MaxMarginParams are the parameters of SolveMaxMargin and SolveReachMaxMargin.
+type MaxMarginParams struct {
+	// Number of iterations of CFR used to solve the gadget game.
+	Iterations int
+	// DiscountParams of the PolicyTable used to solve the gadget game.
+	DiscountParams DiscountParams
+	// Number of sampled traversals of the blueprint that are averaged to compute
+	// the counterfactual values of the opponent. It only needs to be greater than
+	// one if the subgame has public chance events. Zero is treated as one.
+	EvaluationSamples int
+}
+
+// SolveMaxMargin refines the strategy of a blueprint StrategyProfile in the public
+// subgame rooted at node for player with max-margin subgame solving (Moravčík et al.,
+// 2016), given the player's range at the node under the blueprint: the reach
+// probability of each of their private states (including their prior probabilities).
+// It returns a new PolicyTable in which the strategy of player (but not of their
+// opponent) may be used in place of the blueprint within the subgame.
+//
+// The margin of each private state of the opponent is the amount by which their
+// counterfactual value at the root of the subgame is reduced relative to the
+// blueprint. The refined strategy maximizes the minimum margin, by solving a gadget
+// game in which the opponent first chooses the private state in which to enter the
+// subgame and receives their (negated) margin. If the blueprint is not exploitable
+// within the subgame, the minimum margin is zero and the refined strategy is no more
+// exploitable than the blueprint; otherwise, it may be substantially less.
+func SolveMaxMargin(blueprint StrategyProfile, node PublicTreeNode, player int, reach []float32, params MaxMarginParams) *PolicyTable {
+	opponent := 1 - player
+	pool := NewFloatSlicePool(SlicePoolParams{})
+	blueprintValues := make([]float32, node.NumPrivateStates(opponent))
+	evaluateSubgame(blueprint, node, opponent, node.PrivateStateProbabilities(opponent),
+		reach, blueprintValues, params.EvaluationSamples, pool)
+	return solveMarginGadget(node, player, reach, blueprintValues, params, pool)
+}
+
+// SolveReachMaxMargin refines the strategy of a blueprint StrategyProfile for player
+// in the public subgame reached from root by the given sequence of actions, with
+// Reach-Maxmargin subgame solving (Brown & Sandholm, 2017). It returns a new
+// PolicyTable in which the strategy of player (but not of their opponent) may be
+// used in place of the blueprint within the subgame.
+//
+// It is as SolveMaxMargin, except that the margin of each private state of the
+// opponent is measured relative to their blueprint counterfactual value plus the
+// value they gave up (their gift) by choosing the actions on the path rather than
+// their best alternatives according to the blueprint. Since the refined strategy
+// may then be more exploitable within the subgame than the blueprint, it is able
+// to exploit earlier mistakes of the opponent while remaining safe.
+//
+// Each gift may be shared by all subgames that follow the opponent's decision, so
+// only the fraction of it corresponding to the player's reach of this subgame
+// (relative to their reach of the decision) is added. Gifts of decisions before
+// public chance events are not used. The player's range at the subgame is computed
+// from the blueprint. Each gift requires evaluating the blueprint on the subtrees
+// of the opponent's alternative actions.
+func SolveReachMaxMargin(blueprint StrategyProfile, root PublicTreeNode, actions []int, player int, params MaxMarginParams) *PolicyTable {
+	opponent := 1 - player
+	pool := NewFloatSlicePool(SlicePoolParams{})
+	reach := append([]float32(nil), root.PrivateStateProbabilities(player)...)
+	var gifts []pathGift
+	node := root
+	for _, action := range actions {
+		switch {
+		case node.Type() == ChanceNodeType:
+			gifts = nil
+		case node.Player() == player:
+			for s := range reach {
+				strategy := blueprint.GetPolicy(&privateStateNode{node, s}).GetAverageStrategy()
+				reach[s] *= strategy[action]
+			}
+		default:
+			gifts = append(gifts, pathGift{
+				values:    opponentGifts(blueprint, node, action, reach, params.EvaluationSamples, pool),
+				reachMass: f32.Sum(reach),
+			})
+		}
+
+		node = node.GetChild(action)
+	}
+
+	alternativeValues := make([]float32, node.NumPrivateStates(opponent))
+	evaluateSubgame(blueprint, node, opponent, node.PrivateStateProbabilities(opponent),
+		reach, alternativeValues, params.EvaluationSamples, pool)
+	reachMass := f32.Sum(reach)
+	for _, gift := range gifts {
+		if gift.reachMass <= 0 {
+			continue
+		}
+
+		for s, g := range gift.values {
+			alternativeValues[s] += g * reachMass / gift.reachMass
+		}
+	}
+
+	return solveMarginGadget(node, player, reach, alternativeValues, params, pool)
+}
+
+// pathGift is the gift of an opponent decision on the path to a subgame,
+// and the total reach of the player at the decision.
+type pathGift struct {
+	values    []float32
+	reachMass float32
+}
+
+// opponentGifts returns the gift of each private state of the opponent acting at node
+// when they choose the given action: the difference between the blueprint counterfactual
+// value of their best action and that of the chosen action.
+func opponentGifts(blueprint StrategyProfile, node PublicTreeNode, action int, reach []float32, nSamples int, pool SlicePool) []float32 {
+	opponent := node.Player()
+	nStates := node.NumPrivateStates(opponent)
+	opponentReach := node.PrivateStateProbabilities(opponent)
+	actionValues := make([][]float32, node.NumChildren())
+	for a := range actionValues {
+		actionValues[a] = make([]float32, nStates)
+		evaluateSubgame(blueprint, node.GetChild(a), opponent, opponentReach,
+			reach, actionValues[a], nSamples, pool)
+	}
+
+	gifts := make([]float32, nStates)
+	for s := range gifts {
+		best := actionValues[action][s]
+		for a := range actionValues {
+			best = max(best, actionValues[a][s])
+		}
+
+		gifts[s] = best - actionValues[action][s]
+	}
+
+	return gifts
+}
+
+// solveMarginGadget solves the max-margin gadget game for player in the public subgame
+// rooted at node, given their range and the alternative payoff of each private state
+// of the opponent, relative to which margins are measured.
+//
+// Since the player's InfoSets are the same whichever private state the opponent
+// enters in, the entries of the gadget game are traversed all at once, with the
+// opponent's range at the root of the subgame given by their entry strategy.
+func solveMarginGadget(node PublicTreeNode, player int, reach, alternativeValues []float32, params MaxMarginParams, pool SlicePool) *PolicyTable {
+	pt := NewPolicyTable(params.DiscountParams)
+	solver := NewPublicChanceSampling(pt)
+	solver.SetSlicePool(pool)
+	entry := &marginEntryNode{nStates: len(alternativeValues)}
+	opponentReach := make([]float32, len(alternativeValues))
+	values := make([]float32, len(alternativeValues))
+	playerValues := make([]float32, len(reach))
+	regrets := make([]float32, len(alternativeValues))
+	ones := make([]float32, len(alternativeValues))
+	for i := range ones {
+		ones[i] = 1.0
+	}
+
+	for i := 0; i < params.Iterations; i++ {
+		entryPolicy := pt.GetPolicy(entry)
+		entryPolicy.CopyStrategy(opponentReach)
+		solver.traversingPlayer = pt.Iter() % 2
+		if solver.traversingPlayer == player {
+			solver.runHelper(node, reach, opponentReach, playerValues)
+		} else {
+			solver.runHelper(node, opponentReach, reach, values)
+
+			// The opponent receives the negated margin of the private state they enter in.
+			var value float32
+			for s, v := range values {
+				regrets[s] = v - alternativeValues[s]
+				value += opponentReach[s] * regrets[s]
+			}
+
+			for s := range regrets {
+				regrets[s] -= value
+			}
+
+			entryPolicy.AddRegret(1.0, ones, regrets)
+			entryPolicy.AddStrategyWeight(1.0)
+		}
+
+		pt.Update()
+	}
+
+	return pt
+}
+
+// marginEntryNode is the opponent's decision of which private state to enter
+// the subgame in, in the max-margin gadget game. It only implements the
+// methods needed to look up its policy in a PolicyTable.
+type marginEntryNode struct {
+	GameTreeNode
+	nStates int
+}
+
+func (n *marginEntryNode) Type() NodeType {
+	return PlayerNodeType
+}
+
+func (n *marginEntryNode) Player() int {
+	return 0
+}
+
+func (n *marginEntryNode) NumChildren() int {
+	return n.nStates
+}
+
+func (n *marginEntryNode) InfoSet(player int) InfoSet {
+	is := gadgetInfoSet(marginEntryKey)
+	return &is
+}
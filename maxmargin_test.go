@@ -0,0 +1,103 @@
+package cfr_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestMaxMarginSubgameSolving(t *testing.T) {
+	params := cfr.MaxMarginParams{Iterations: 1000}
+	blueprintExploitability, refinedExploitability := testSubgameRefinement(t,
+		func(blueprint cfr.StrategyProfile, action, player int, reach []float32) *cfr.PolicyTable {
+			subgame := kuhn.NewPublicGame().GetChild(action)
+			return cfr.SolveMaxMargin(blueprint, subgame, player, reach, params)
+		})
+
+	if refinedExploitability > blueprintExploitability {
+		t.Errorf("expected refined strategy to be less exploitable than blueprint (%v), got %v",
+			blueprintExploitability, refinedExploitability)
+	}
+}
+
+func TestReachMaxMarginSubgameSolving(t *testing.T) {
+	params := cfr.MaxMarginParams{Iterations: 1000}
+	maxMargin := make(map[[2]int][]float32)
+	reach := make(map[[2]int][]float32)
+	blueprintExploitability, refinedExploitability := testSubgameRefinement(t,
+		func(blueprint cfr.StrategyProfile, action, player int, ranges []float32) *cfr.PolicyTable {
+			publicRoot := kuhn.NewPublicGame()
+			refined := cfr.SolveReachMaxMargin(blueprint, publicRoot, []int{action}, player, params)
+			baseline := cfr.SolveMaxMargin(blueprint, publicRoot.GetChild(action), player, ranges, params)
+
+			// Compare the strategies of the player at their first decision in the subgame.
+			node := kuhn.NewGame().GetChild(0).GetChild(action)
+			if node.Player() != player {
+				node = node.GetChild(1)
+			}
+
+			key := [2]int{action, player}
+			reach[key] = refined.GetPolicy(node).GetAverageStrategy()
+			maxMargin[key] = baseline.GetPolicy(node).GetAverageStrategy()
+			return refined
+		})
+
+	if refinedExploitability > blueprintExploitability {
+		t.Errorf("expected refined strategy to be less exploitable than blueprint (%v), got %v",
+			blueprintExploitability, refinedExploitability)
+	}
+
+	// Player 0 has no opponent decisions before the subgames, so there are no gifts.
+	for key, strategy := range reach {
+		if player := key[1]; player == 0 && !reflect.DeepEqual(strategy, maxMargin[key]) {
+			t.Errorf("expected same strategy as max-margin for player 0, got %v and %v", strategy, maxMargin[key])
+		}
+	}
+}
+
+// testSubgameRefinement refines an undertrained blueprint in the subgames following
+// the first action of player 0 for each player with the given function, and returns
+// the exploitability of the blueprint and that of the combined refined strategy.
+func testSubgameRefinement(t *testing.T, refine func(blueprint cfr.StrategyProfile, action, player int, reach []float32) *cfr.PolicyTable) (float64, float64) {
+	publicRoot := kuhn.NewPublicGame()
+	blueprint := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewPublicChanceSampling(blueprint)
+	for i := 0; i < 20; i++ {
+		opt.Run(publicRoot)
+		blueprint.Update()
+	}
+
+	rootStrategies := make(map[kuhn.Card][]float32)
+	tree.Visit(kuhn.NewGame(), func(node cfr.GameTreeNode) {
+		if node.Type() == cfr.PlayerNodeType && node.Parent().Type() == cfr.ChanceNodeType {
+			card := kuhnCard(node.InfoSet(0))
+			rootStrategies[card] = blueprint.GetPolicy(node).GetAverageStrategy()
+		}
+	})
+
+	profile := &cfrdProfile{PolicyTable: blueprint, subgames: make(map[byte]*[2]*cfr.PolicyTable)}
+	for a := 0; a < publicRoot.NumChildren(); a++ {
+		var ranges [2][]float32
+		ranges[0] = make([]float32, len(rootStrategies))
+		for card, strategy := range rootStrategies {
+			ranges[0][card] = strategy[a] / 3
+		}
+		ranges[1] = publicRoot.PrivateStateProbabilities(1)
+
+		var refined [2]*cfr.PolicyTable
+		for player := range refined {
+			refined[player] = refine(blueprint, a, player, ranges[player])
+		}
+
+		subgame := publicRoot.GetChild(a)
+		profile.subgames[kuhnHistory(subgame.InfoSet(0))[2]] = &refined
+	}
+
+	blueprintExploitability := cfr.Exploitability(kuhn.NewGame(), blueprint)
+	refinedExploitability := cfr.Exploitability(kuhn.NewGame(), profile)
+	t.Logf("Exploitability: blueprint %v, refined %v", blueprintExploitability, refinedExploitability)
+	return blueprintExploitability, refinedExploitability
+}
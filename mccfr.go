@@ -17,33 +17,148 @@ type Sampler interface {
 	Sample(GameTreeNode, NodePolicy) []float32
 }
 
+// ChanceSampler is an optional interface that may be implemented by a Sampler
+// to select which children of chance nodes are traversed by MCCFR.
+// By default, a single child is sampled with node.SampleChild.
+type ChanceSampler interface {
+	// SampleChance returns a vector of sampling probabilities for the
+	// children of the given chance node, with the same semantics as Sample.
+	// The value of each traversed child is corrected by p/q, where p is
+	// its chance probability and q its sampling probability.
+	SampleChance(GameTreeNode) []float32
+}
+
+// OpponentSampler is an optional interface that may be implemented by a Sampler
+// to select which actions of the non-traversing player are traversed by MCCFR.
+// By default, a single action is sampled according to the current strategy.
+type OpponentSampler interface {
+	// SampleOpponent returns a vector of sampling probabilities for the
+	// children of the given node, with the same semantics as Sample.
+	// The value of each traversed child is corrected by p/q, where p is
+	// its probability under the current strategy and q its sampling probability.
+	SampleOpponent(GameTreeNode, NodePolicy) []float32
+}
+
+// AdaptiveSampler is an optional interface that may be implemented by a Sampler
+// that adapts to the noise of the regret estimates of MCCFR.
+type AdaptiveSampler interface {
+	// ObserveRegrets is called with the instantaneous regrets estimated at
+	// each node of the traversing player, before they are accumulated with
+	// the given weight. The slice must not be retained.
+	ObserveRegrets(node GameTreeNode, weight float32, regrets []float32)
+	// EndIteration is called at the end of each call to Run.
+	EndIteration()
+}
+
+// MCCFR implements Monte Carlo CFR with alternating updates (by default, see
+// SetPlayerSchedule). The Sampler determines which actions of the traversing
+// player are explored, and may optionally implement ChanceSampler and
+// OpponentSampler to also control the traversal of chance and opponent nodes.
 type MCCFR struct {
 	strategyProfile StrategyProfile
 	sampler         Sampler
 
-	slicePool *floatSlicePool
-	mapPool   *keyIntMapPool
-	rng       *rand.Rand
+	arena *arena
+	rng   *rand.Rand
 
+	schedule         PlayerSchedule
 	traversingPlayer int
+	utilityScale     UtilityScale
 	sampledActions   map[string]int
+	// Product of the p/q corrections of all ChanceSampler and OpponentSampler
+	// nodes, and of the probabilities of all enumerated chance outcomes, on
+	// the path to the current node.
+	weight float32
+
+	// Chance nodes with at most this many children are enumerated.
+	maxEnumeratedOutcomes int
+	// If true, the values of unsampled actions of the traversing player are
+	// estimated by probes rather than taken to be zero.
+	probing bool
+	// If true, the values of actions of the traversing player
+	// are corrected with the baselines of their policies.
+	baselineCorrection bool
 }
 
 func NewMCCFR(strategyProfile StrategyProfile, sampler Sampler) *MCCFR {
 	return &MCCFR{
 		strategyProfile: strategyProfile,
 		sampler:         sampler,
-		slicePool:       &floatSlicePool{},
-		mapPool:         &keyIntMapPool{},
+		arena:           &arena{},
 		rng:             rand.New(rand.NewSource(rand.Int63())),
 	}
 }
 
+// Seed sets the seed of the random number generator used to sample
+// actions from the current strategy.
+func (c *MCCFR) Seed(seed int64) {
+	c.rng.Seed(seed)
+}
+
+// SetPlayerSchedule sets the PlayerSchedule of the runner.
+func (c *MCCFR) SetPlayerSchedule(schedule PlayerSchedule) {
+	c.schedule = schedule
+}
+
+// SetUtilityScale sets the scale used to normalize the utilities of each player.
+// Utilities are divided by the scale of the traversing player, and the values
+// returned by Run are rescaled to the units of the game.
+func (c *MCCFR) SetUtilityScale(scale UtilityScale) {
+	c.utilityScale = scale
+}
+
+// SetChanceEnumerationThreshold sets the maximum number of children of chance
+// nodes that are enumerated, rather than sampled, if the Sampler is not a
+// ChanceSampler. All outcomes of such nodes are traversed and their values
+// weighted by their probabilities, as in vanilla CFR, while chance nodes with
+// more children are still sampled. With a sampler that explores several actions
+// (such as a RobustSampler), enumerating chance nodes with only a few outcomes
+// substantially reduces the variance of each traversal at little cost.
+// Zero (the default) samples all chance nodes.
+func (c *MCCFR) SetChanceEnumerationThreshold(maxOutcomes int) {
+	c.maxEnumeratedOutcomes = maxOutcomes
+}
+
+// SetProbing enables or disables probing, as in Generalized MCCFR (Gibson et al.,
+// 2012). The value of each action of the traversing player that is not sampled
+// is estimated by a probe, a single trajectory sampled according to the current
+// strategy, rather than taken to be zero. Since every action then has an estimated
+// value, sampled values are no longer importance-weighted by the probability of
+// sampling the actions of the traversing player below them.
+func (c *MCCFR) SetProbing(enabled bool) {
+	c.probing = enabled
+}
+
+// SetBaselineCorrection enables or disables correcting the sampled values of the
+// traversing player's actions with the per-action baselines of their policies, as
+// in VR-MCCFR. Each sampled action i has the value b_i + (u_i - b_i)/q_i, where u_i
+// is its sampled value, b_i its baseline and q_i its sampling probability, and each
+// unsampled action has the value of its baseline, in place of a probe. The estimates
+// remain unbiased, but their variance depends on the difference between the values
+// and the baselines (which track them) rather than on the values themselves.
+// As with probing, sampled values are not otherwise importance-weighted.
+// The decay of the baselines is set by DiscountParams.BaselineDecay.
+func (c *MCCFR) SetBaselineCorrection(enabled bool) {
+	c.baselineCorrection = enabled
+}
+
+// Run performs a traversal from node for each player of the current
+// iteration's schedule, and returns the mean of their values.
 func (c *MCCFR) Run(node GameTreeNode) float32 {
-	iter := c.strategyProfile.Iter()
-	c.traversingPlayer = int(iter % 2)
-	c.sampledActions = c.mapPool.alloc()
-	defer c.mapPool.free(c.sampledActions)
+	if as, ok := c.sampler.(AdaptiveSampler); ok {
+		defer as.EndIteration()
+	}
+
+	return runScheduled(c.schedule, c.strategyProfile.Iter(), func(player int) float32 {
+		c.traversingPlayer = player
+		return c.run(node) * c.utilityScale.get(player)
+	})
+}
+
+func (c *MCCFR) run(node GameTreeNode) float32 {
+	c.sampledActions = c.arena.allocMap()
+	c.weight = 1.0
+	defer c.arena.reset()
 	return c.runHelper(node, node.Player(), 1.0)
 }
 
@@ -51,7 +166,10 @@ func (c *MCCFR) runHelper(node GameTreeNode, lastPlayer int, sampleProb float32)
 	var ev float32
 	switch node.Type() {
 	case TerminalNodeType:
-		ev = float32(node.Utility(lastPlayer)) / sampleProb
+		ev = float32(terminalUtility(node, lastPlayer)) / c.utilityScale.get(c.traversingPlayer)
+		if !c.probing && !c.baselineCorrection {
+			ev /= sampleProb
+		}
 	case ChanceNodeType:
 		ev = c.handleChanceNode(node, lastPlayer, sampleProb)
 	default:
@@ -64,11 +182,61 @@ func (c *MCCFR) runHelper(node GameTreeNode, lastPlayer int, sampleProb float32)
 }
 
 func (c *MCCFR) handleChanceNode(node GameTreeNode, lastPlayer int, sampleProb float32) float32 {
+	if cs, ok := c.sampler.(ChanceSampler); ok {
+		return c.handleChanceSamplerNode(cs, node, lastPlayer, sampleProb)
+	}
+
+	if node.NumChildren() <= c.maxEnumeratedOutcomes {
+		return c.handleEnumeratedChanceNode(node, lastPlayer, sampleProb)
+	}
+
 	child, _ := node.SampleChild()
 	// Sampling probabilities cancel out in the calculation of counterfactual value.
 	return c.runHelper(child, lastPlayer, sampleProb)
 }
 
+func (c *MCCFR) handleChanceSamplerNode(cs ChanceSampler, node GameTreeNode, lastPlayer int, sampleProb float32) float32 {
+	nChildren := node.NumChildren()
+	qs := c.arena.alloc(nChildren)
+	copy(qs, cs.SampleChance(node))
+
+	var ev float32
+	weight := c.weight
+	for i, q := range qs {
+		if q > 0 {
+			p := float32(node.GetChildProbability(i))
+			child := node.GetChild(i)
+			c.weight = weight * p / q
+			ev += (p / q) * c.runHelper(child, lastPlayer, sampleProb)
+		}
+	}
+
+	c.weight = weight
+	c.arena.free(qs)
+	return ev
+}
+
+// handleEnumeratedChanceNode traverses all children of a chance node. Since the
+// outcomes are not sampled, their probabilities no longer cancel out and must be
+// included in the weight of regret and strategy updates below them.
+func (c *MCCFR) handleEnumeratedChanceNode(node GameTreeNode, lastPlayer int, sampleProb float32) float32 {
+	weight := c.weight
+	var ev float32
+	for i := 0; i < node.NumChildren(); i++ {
+		p := float32(node.GetChildProbability(i))
+		if p == 0 {
+			continue
+		}
+
+		child := node.GetChild(i)
+		c.weight = weight * p
+		ev += p * c.runHelper(child, lastPlayer, sampleProb)
+	}
+
+	c.weight = weight
+	return ev
+}
+
 func (c *MCCFR) handlePlayerNode(node GameTreeNode, sampleProb float32) float32 {
 	if node.Player() == c.traversingPlayer {
 		return c.handleTraversingPlayerNode(node, sampleProb)
@@ -87,17 +255,32 @@ func (c *MCCFR) handleTraversingPlayerNode(node GameTreeNode, sampleProb float32
 	}
 
 	policy := c.strategyProfile.GetPolicy(node)
-	qs := c.slicePool.alloc(nChildren)
+	children := prefetch(c.strategyProfile, node)
+	defer children.Close()
+	qs := c.arena.alloc(nChildren)
 	copy(qs, c.sampler.Sample(node, policy))
-	regrets := c.slicePool.alloc(nChildren)
+	regrets := c.arena.alloc(nChildren)
 	oldSampledActions := c.sampledActions
-	c.sampledActions = c.mapPool.alloc()
+	c.sampledActions = c.arena.allocMap()
+	var baseline []float32
+	if c.baselineCorrection {
+		baseline = policy.GetBaseline()
+	}
 
 	for i, q := range qs {
-		child := node.GetChild(i)
 		var util float32
-		if q > 0 {
-			util = c.runHelper(child, player, q*sampleProb)
+		switch {
+		case q > 0:
+			util = c.runHelper(children.GetChild(i), player, q*sampleProb)
+			if baseline != nil {
+				u := util
+				util = baseline[i] + (u-baseline[i])/q
+				policy.UpdateBaseline(1.0/q, i, u)
+			}
+		case baseline != nil:
+			util = baseline[i]
+		case c.probing:
+			util = c.probe(children.GetChild(i), player)
 		}
 
 		regrets[i] = util
@@ -105,11 +288,12 @@ func (c *MCCFR) handleTraversingPlayerNode(node GameTreeNode, sampleProb float32
 
 	cfValue := f32.DotUnitary(policy.GetStrategy(), regrets)
 	f32.AddConst(-cfValue, regrets)
-	policy.AddRegret(1.0/sampleProb, qs, regrets)
+	observeRegrets(c.sampler, node, c.weight/sampleProb, regrets)
+	policy.AddRegret(c.weight/sampleProb, qs, regrets)
 
-	c.slicePool.free(qs)
-	c.slicePool.free(regrets)
-	c.mapPool.free(c.sampledActions)
+	c.arena.freeMap(c.sampledActions)
+	c.arena.free(regrets)
+	c.arena.free(qs)
 	c.sampledActions = oldSampledActions
 	return cfValue
 }
@@ -122,7 +306,11 @@ func (c *MCCFR) handleSampledPlayerNode(node GameTreeNode, sampleProb float32) f
 	// Update average strategy for this node.
 	// We perform "stochastic" updates as described in the MC-CFR paper.
 	if sampleProb > 0 {
-		policy.AddStrategyWeight(1.0 / sampleProb)
+		policy.AddStrategyWeight(c.weight / sampleProb)
+	}
+
+	if os, ok := c.sampler.(OpponentSampler); ok {
+		return c.handleOpponentSamplerNode(os, node, policy, sampleProb)
 	}
 
 	// Sampling probabilities cancel out in the calculation of counterfactual value,
@@ -131,6 +319,56 @@ func (c *MCCFR) handleSampledPlayerNode(node GameTreeNode, sampleProb float32) f
 	return c.runHelper(child, node.Player(), sampleProb)
 }
 
+func (c *MCCFR) handleOpponentSamplerNode(os OpponentSampler, node GameTreeNode, policy NodePolicy, sampleProb float32) float32 {
+	player := node.Player()
+	nChildren := node.NumChildren()
+	qs := c.arena.alloc(nChildren)
+	copy(qs, os.SampleOpponent(node, policy))
+	strategy := policy.GetStrategy()
+
+	var ev float32
+	weight := c.weight
+	for i, q := range qs {
+		if q > 0 {
+			child := node.GetChild(i)
+			c.weight = weight * strategy[i] / q
+			ev += (strategy[i] / q) * c.runHelper(child, player, sampleProb)
+		}
+	}
+
+	c.weight = weight
+	c.arena.free(qs)
+	return ev
+}
+
+// probe returns the value of a single trajectory from node, sampled according
+// to the current strategy of both players, without updating their policies.
+func (c *MCCFR) probe(node GameTreeNode, player int) float32 {
+	var ev float32
+	switch node.Type() {
+	case TerminalNodeType:
+		ev = float32(terminalUtility(node, player)) / c.utilityScale.get(c.traversingPlayer)
+	case ChanceNodeType:
+		child, _ := node.SampleChild()
+		ev = c.probe(child, player)
+	default:
+		policy := c.strategyProfile.GetPolicy(node)
+		selected := sampleOne(policy.GetStrategy(), c.rng.Float32())
+		ev = c.probe(node.GetChild(selected), player)
+	}
+
+	node.Close()
+	return ev
+}
+
+// observeRegrets passes the regrets estimated at node to sampler,
+// if it is an AdaptiveSampler.
+func observeRegrets(sampler Sampler, node GameTreeNode, weight float32, regrets []float32) {
+	if as, ok := sampler.(AdaptiveSampler); ok {
+		as.ObserveRegrets(node, weight, regrets)
+	}
+}
+
 func getOrSample(sampledActions map[string]int, node GameTreeNode, policy NodePolicy, rng *rand.Rand) int {
 	key := nodeKey(node)
 	selected, ok := sampledActions[key]
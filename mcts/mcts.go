@@ -0,0 +1,244 @@
+// Package mcts implements Information Set Monte Carlo Tree Search (Cowling,
+// Powley & Whitehouse, 2012) on the same cfr.GameTreeNode interface as the CFR
+// runners, so that MCTS may be used as a baseline for CFR, or to estimate the
+// value of leaf nodes in depth-limited solving, without a second game adapter.
+package mcts
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/rollout"
+	"github.com/timpalpant/go-cfr/sampling"
+)
+
+// DefaultExploration is the UCT exploration constant used when Params.Exploration is zero.
+var DefaultExploration = math.Sqrt2
+
+// Params are the parameters of a Solver.
+type Params struct {
+	// Exploration constant of the UCT selection rule. Utilities are not
+	// normalized, so it should be on the order of their range.
+	Exploration float64
+	// Strategy followed by both players beyond the search tree. If nil,
+	// actions are chosen uniformly at random.
+	Rollout rollout.Strategy
+}
+
+// Solver implements single-observer IS-MCTS, in which the search tree is a tree
+// of InfoSets rather than of nodes. Each iteration determinizes the game by
+// sampling chance outcomes as it descends, selects actions at InfoSets already in
+// the tree with UCT, adds the first InfoSet encountered that is not in the tree,
+// and then completes the game with a rollout. The utility of the terminal node is
+// then backed up to the action taken at each InfoSet, for the player acting there.
+//
+// Since InfoSets are keyed by cfr.InfoSet.Key, a single Solver may be used to
+// search from many nodes of the same game. A Solver is not safe for concurrent use.
+type Solver struct {
+	params Params
+	rng    *rand.Rand
+
+	infoSets map[string]*infoSetStats
+	path     []pathStep
+	visited  []cfr.GameTreeNode
+}
+
+// infoSetStats are the statistics of the actions of a single InfoSet.
+type infoSetStats struct {
+	visits       []int
+	totalRewards []float64
+	totalVisits  int
+}
+
+// pathStep is an action taken at an InfoSet in the search tree.
+type pathStep struct {
+	stats  *infoSetStats
+	player int
+	action int
+}
+
+// New returns a new Solver with the given parameters.
+func New(params Params) *Solver {
+	if params.Exploration == 0 {
+		params.Exploration = DefaultExploration
+	}
+
+	if params.Rollout == nil {
+		params.Rollout = rollout.Uniform
+	}
+
+	return &Solver{
+		params:   params,
+		rng:      rand.New(rand.NewSource(rand.Int63())),
+		infoSets: make(map[string]*infoSetStats),
+	}
+}
+
+// Seed sets the seed of the random number generator used to sample
+// chance outcomes and actions, so that searches may be reproduced.
+func (s *Solver) Seed(seed int64) {
+	s.rng.Seed(seed)
+}
+
+// NumInfoSets returns the number of InfoSets in the search tree.
+func (s *Solver) NumInfoSets() int {
+	return len(s.infoSets)
+}
+
+// Search performs n iterations from node, and returns the mean
+// utility of each player in the simulated games.
+func (s *Solver) Search(node cfr.GameTreeNode, n int) [2]float64 {
+	var result [2]float64
+	for i := 0; i < n; i++ {
+		u := s.Run(node)
+		result[0] += u[0]
+		result[1] += u[1]
+	}
+
+	result[0] /= float64(n)
+	result[1] /= float64(n)
+	return result
+}
+
+// Run performs a single iteration from node, and returns the utility of each
+// player in the simulated game. The node itself is not closed, but all of its
+// descendants visited during the iteration are.
+func (s *Solver) Run(node cfr.GameTreeNode) [2]float64 {
+	s.path = s.path[:0]
+	s.visited = s.visited[:0]
+	expanded := false
+	for node.Type() != cfr.TerminalNodeType {
+		var action int
+		switch {
+		case node.Type() == cfr.ChanceNodeType:
+			action = s.sampleChance(node)
+		case expanded:
+			action = sampling.SampleOne(s.params.Rollout(node), s.rng.Float32())
+		default:
+			player := node.Player()
+			key := node.InfoSet(player).Key()
+			stats, ok := s.infoSets[key]
+			if !ok {
+				stats = newInfoSetStats(node.NumChildren())
+				s.infoSets[key] = stats
+				expanded = true
+			}
+
+			action = s.selectAction(stats)
+			s.path = append(s.path, pathStep{stats, player, action})
+		}
+
+		child := node.GetChild(action)
+		s.visited = append(s.visited, child)
+		node = child
+	}
+
+	u := [2]float64{node.Utility(0), node.Utility(1)}
+	for _, step := range s.path {
+		step.stats.visits[step.action]++
+		step.stats.totalRewards[step.action] += u[step.player]
+		step.stats.totalVisits++
+	}
+
+	// Close deepest nodes first, as required by cfr.SharedStateNode.
+	for i := len(s.visited) - 1; i >= 0; i-- {
+		s.visited[i].Close()
+	}
+
+	return u
+}
+
+// Strategy returns the probability of choosing each action at the InfoSet of
+// the player acting at node: the fraction of visits to each action during the
+// search. Actions at InfoSets that were never visited are chosen uniformly.
+// It may be used with cfr.ComputeBestResponse to evaluate the search.
+func (s *Solver) Strategy(node cfr.GameTreeNode) []float32 {
+	n := node.NumChildren()
+	result := make([]float32, n)
+	stats, ok := s.infoSets[node.InfoSet(node.Player()).Key()]
+	if !ok || stats.totalVisits == 0 {
+		for i := range result {
+			result[i] = 1.0 / float32(n)
+		}
+
+		return result
+	}
+
+	for i, visits := range stats.visits {
+		result[i] = float32(visits) / float32(stats.totalVisits)
+	}
+
+	return result
+}
+
+// BestAction returns the most visited action at the InfoSet of
+// the player acting at node.
+func (s *Solver) BestAction(node cfr.GameTreeNode) int {
+	strategy := s.Strategy(node)
+	best := 0
+	for i, p := range strategy {
+		if p > strategy[best] {
+			best = i
+		}
+	}
+
+	return best
+}
+
+// NewLeafEvaluator returns a cfr.LeafEvaluator that estimates the value of a node
+// by the mean utility of n iterations of search from it with s.
+func NewLeafEvaluator(s *Solver, n int) cfr.LeafEvaluator {
+	return cfr.LeafEvaluatorFunc(func(node cfr.GameTreeNode, player int) float32 {
+		return float32(s.Search(node, n)[player])
+	})
+}
+
+func newInfoSetStats(nActions int) *infoSetStats {
+	return &infoSetStats{
+		visits:       make([]int, nActions),
+		totalRewards: make([]float64, nActions),
+	}
+}
+
+// selectAction chooses an action at an InfoSet in the search tree: an untried
+// action at random if there are any, and otherwise the action maximizing UCT.
+func (s *Solver) selectAction(stats *infoSetStats) int {
+	var untried []int
+	for i, visits := range stats.visits {
+		if visits == 0 {
+			untried = append(untried, i)
+		}
+	}
+
+	if len(untried) > 0 {
+		return untried[s.rng.Intn(len(untried))]
+	}
+
+	logN := math.Log(float64(stats.totalVisits))
+	best, bestValue := 0, math.Inf(-1)
+	for i, visits := range stats.visits {
+		n := float64(visits)
+		value := stats.totalRewards[i]/n + s.params.Exploration*math.Sqrt(logN/n)
+		if value > bestValue {
+			best, bestValue = i, value
+		}
+	}
+
+	return best
+}
+
+// sampleChance samples a child of the given chance node according to its probabilities.
+func (s *Solver) sampleChance(node cfr.GameTreeNode) int {
+	x := s.rng.Float64()
+	n := node.NumChildren()
+	var cumProb float64
+	for i := 0; i < n; i++ {
+		cumProb += node.GetChildProbability(i)
+		if cumProb > x {
+			return i
+		}
+	}
+
+	return n - 1
+}
@@ -0,0 +1,57 @@
+package mcts
+
+import (
+	"math"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/rollout"
+)
+
+func TestSolver_Kuhn(t *testing.T) {
+	root := kuhn.NewGame()
+	s := New(Params{})
+	s.Seed(123)
+	ev := s.Search(root, 100000)
+	if ev[0] != -ev[1] {
+		t.Errorf("expected zero-sum utilities, got %v", ev)
+	}
+
+	// All 12 InfoSets of Kuhn poker are reached.
+	if s.NumInfoSets() != 12 {
+		t.Errorf("expected 12 InfoSets, got %d", s.NumInfoSets())
+	}
+
+	// IS-MCTS does not converge to an equilibrium, but should be
+	// much less exploitable than playing uniformly at random.
+	uniform := exploitability(root, rollout.Uniform)
+	searched := exploitability(root, s.Strategy)
+	t.Logf("Exploitability: uniform = %.4f, IS-MCTS = %.4f", uniform, searched)
+	if searched >= uniform/2 {
+		t.Errorf("expected search to reduce exploitability, got %v (uniform: %v)", searched, uniform)
+	}
+
+	// With a King, player 0 should call a bet.
+	node := root.GetChild(int(kuhn.King)).GetChild(int(kuhn.Jack)).GetChild(0).GetChild(1)
+	if action := s.BestAction(node); action != 1 {
+		t.Errorf("expected %v to call, got action %d (strategy: %v)", node, action, s.Strategy(node))
+	}
+}
+
+func TestNewLeafEvaluator(t *testing.T) {
+	root := kuhn.NewGame()
+	s := New(Params{})
+	s.Seed(42)
+	evaluator := NewLeafEvaluator(s, 1000)
+	v0, v1 := evaluator.LeafValue(root, 0), evaluator.LeafValue(root, 1)
+	if math.Abs(float64(v0+v1)) > 0.2 {
+		t.Errorf("expected approximately zero-sum values, got %v and %v", v0, v1)
+	}
+}
+
+func exploitability(root cfr.GameTreeNode, strategy func(node cfr.GameTreeNode) []float32) float64 {
+	br0 := cfr.ComputeBestResponse(root, 0, strategy)
+	br1 := cfr.ComputeBestResponse(root, 1, strategy)
+	return (br0.Value() + br1.Value()) / 2
+}
@@ -0,0 +1,86 @@
+package cfr
+
+import (
+	"sync"
+)
+
+// Minibatch wraps one or more runners of a sampling CFR algorithm to perform a
+// minibatch of sampled traversals on each call to Run, so that the next Update
+// of the strategy profile accumulates the regrets of all of them. This reduces
+// the variance of each iteration's regrets, compared to a single sampled
+// trajectory, and corresponds to how samples are collected for Deep CFR.
+//
+// All traversals of a minibatch are made in the same iteration, and so (for
+// runners that alternate the traversing player by iteration) for the same player.
+// Since the regrets of all traversals are summed, the accumulated regrets grow in
+// proportion to the size of the minibatch. This does not affect regret matching,
+// which is invariant to their scale, but the step sizes of other regret minimizers
+// (see DiscountParams.Minimizer) may need to be scaled accordingly.
+type Minibatch struct {
+	runners   []Runner
+	batchSize int
+	roots     []GameTreeNode
+}
+
+// NewMinibatch returns a new Minibatch that performs batchSize traversals per Run.
+// If more than one runner is given, traversals are divided among them and run in
+// parallel, one goroutine per runner. Each runner must then have its own sampler,
+// and they must all accumulate into the same GuardedProfile. Since game trees are
+// not generally safe for concurrent use, each should also traverse its own copy
+// of the game tree (see SetRoots).
+func NewMinibatch(batchSize int, runners ...Runner) *Minibatch {
+	return &Minibatch{
+		runners:   runners,
+		batchSize: batchSize,
+	}
+}
+
+// SetRoots sets the root of the game tree traversed by each of the parallel
+// runners, in place of the root passed to Run.
+func (m *Minibatch) SetRoots(roots ...GameTreeNode) {
+	m.roots = roots
+}
+
+// Run performs a minibatch of traversals from root, and returns the mean
+// of their expected values.
+func (m *Minibatch) Run(root GameTreeNode) float32 {
+	if len(m.runners) == 1 {
+		var total float32
+		for i := 0; i < m.batchSize; i++ {
+			total += m.runners[0].Run(root)
+		}
+
+		return total / float32(m.batchSize)
+	}
+
+	totals := make([]float32, len(m.runners))
+	var wg sync.WaitGroup
+	for i, runner := range m.runners {
+		// Divide the traversals as evenly as possible among runners.
+		n := m.batchSize / len(m.runners)
+		if i < m.batchSize%len(m.runners) {
+			n++
+		}
+
+		runnerRoot := root
+		if m.roots != nil {
+			runnerRoot = m.roots[i]
+		}
+
+		wg.Add(1)
+		go func(i int, runner Runner, root GameTreeNode, n int) {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				totals[i] += runner.Run(root)
+			}
+		}(i, runner, runnerRoot, n)
+	}
+
+	wg.Wait()
+	var total float32
+	for _, t := range totals {
+		total += t
+	}
+
+	return total / float32(m.batchSize)
+}
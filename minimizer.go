@@ -0,0 +1,122 @@
+package cfr
+
+import (
+	"math"
+)
+
+// LocalRegretMinimizer computes the current strategy of each InfoSet from its
+// accumulated regrets, in place of regret matching. Since CFR only requires that
+// the regret of every InfoSet is minimized locally, any online learning algorithm
+// with sublinear regret may be used.
+//
+// Minimizers are saved along with a PolicyTable, so custom implementations
+// must be registered with gob.Register.
+type LocalRegretMinimizer interface {
+	// Strategy replaces the accumulated regret of each action in x with the
+	// (unnormalized) weight with which it should be played on the given iteration.
+	// Dominated actions are then excluded, and the weights normalized. If all
+	// weights are zero, the remaining actions are played uniformly.
+	Strategy(iter int, x []float32)
+	// Optimistic returns true if the regrets passed to Strategy should include
+	// the last iteration's instantaneous regrets, as a prediction of the next.
+	Optimistic() bool
+}
+
+// stepSize returns the step size scale / t^decay on iteration t.
+func stepSize(scale, decay float32, t int) float32 {
+	if decay == 0 {
+		return scale
+	}
+
+	return scale / float32(math.Pow(float64(t), float64(decay)))
+}
+
+// HedgeMinimizer implements Hedge (exponential weights), which is equivalent to
+// follow-the-regularized-leader (FTRL) with an entropy regularizer, and to
+// online mirror descent (OMD) with the KL divergence. Each action is played in
+// proportion to exp(η_t R), where R is its accumulated regret and the step size
+// η_t = StepSize / t^StepDecay on iteration t. The usual schedule is η_t = η / sqrt(t)
+// (StepDecay = 0.5), where η should be chosen relative to the range of utilities.
+type HedgeMinimizer struct {
+	StepSize  float32
+	StepDecay float32
+	// Use optimistic Hedge, with the last iteration's regrets as a prediction.
+	UsePrediction bool
+}
+
+// Strategy implements LocalRegretMinimizer.
+func (m HedgeMinimizer) Strategy(iter int, x []float32) {
+	eta := stepSize(m.StepSize, m.StepDecay, iter)
+	// Subtract the max regret so that the largest weight is exp(0) = 1.
+	maxRegret := x[0]
+	for _, r := range x[1:] {
+		maxRegret = max(maxRegret, r)
+	}
+
+	for i, r := range x {
+		x[i] = float32(math.Exp(float64(eta * (r - maxRegret))))
+	}
+}
+
+// Optimistic implements LocalRegretMinimizer.
+func (m HedgeMinimizer) Optimistic() bool {
+	return m.UsePrediction
+}
+
+// FTRLMinimizer implements follow-the-regularized-leader with a Euclidean
+// regularizer: the current strategy is the projection of η_t R onto the
+// probability simplex, where R is the vector of accumulated regrets and the
+// step size η_t = StepSize / t^StepDecay on iteration t.
+//
+// With UsePrediction and a constant step size (StepDecay = 0), this is optimistic
+// FTRL, whose regret grows more slowly when the opponents' strategies are stable.
+type FTRLMinimizer struct {
+	StepSize  float32
+	StepDecay float32
+	// Use optimistic FTRL, with the last iteration's regrets as a prediction.
+	UsePrediction bool
+}
+
+// Strategy implements LocalRegretMinimizer.
+func (m FTRLMinimizer) Strategy(iter int, x []float32) {
+	eta := stepSize(m.StepSize, m.StepDecay, iter)
+	for i := range x {
+		x[i] *= eta
+	}
+
+	projectSimplex(x)
+}
+
+// Optimistic implements LocalRegretMinimizer.
+func (m FTRLMinimizer) Optimistic() bool {
+	return m.UsePrediction
+}
+
+// projectSimplex replaces x with its Euclidean projection onto the probability
+// simplex, max(x_i - τ, 0) for the τ at which the result sums to 1. τ is found
+// by repeatedly excluding the components that fall below it (Michelot, 1986),
+// which terminates after at most len(x) passes and does not allocate.
+func projectSimplex(x []float32) {
+	tau := float32(math.Inf(-1))
+	for {
+		var sum float32
+		n := 0
+		for _, xi := range x {
+			if xi > tau {
+				sum += xi
+				n++
+			}
+		}
+
+		next := (sum - 1) / float32(n)
+		if next <= tau {
+			break
+		}
+
+		tau = next
+	}
+
+	for i, xi := range x {
+		x[i] = max(xi-tau, 0)
+	}
+}
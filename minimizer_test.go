@@ -0,0 +1,30 @@
+package cfr
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProjectSimplex(t *testing.T) {
+	testCases := []struct {
+		x        []float32
+		expected []float32
+	}{
+		{[]float32{0.2, 0.3, 0.5}, []float32{0.2, 0.3, 0.5}},
+		{[]float32{0, 0, 0, 0}, []float32{0.25, 0.25, 0.25, 0.25}},
+		{[]float32{1, 2, 3}, []float32{0, 0, 1}},
+		{[]float32{1, 1.5, -4}, []float32{0.25, 0.75, 0}},
+		{[]float32{-2, -3}, []float32{1, 0}},
+	}
+
+	for _, tc := range testCases {
+		x := append([]float32(nil), tc.x...)
+		projectSimplex(x)
+		for i := range x {
+			if math.Abs(float64(x[i]-tc.expected[i])) > 1e-6 {
+				t.Errorf("projection of %v: expected %v, got %v", tc.x, tc.expected, x)
+				break
+			}
+		}
+	}
+}
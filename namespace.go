@@ -0,0 +1,125 @@
+package cfr
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/timpalpant/go-cfr/internal/policy"
+)
+
+// namespaceSep separates the name of a namespace from the InfoSet keys within it.
+// Namespace names must not contain it.
+const namespaceSep = "\x00"
+
+// PolicyNamespace is a view of a PolicyTable restricted to a single namespace,
+// in which the key of every InfoSet is prefixed with the name of the namespace.
+// Several games, abstractions or variants of a bot may therefore be trained and
+// served from a single PolicyTable (and a single saved artifact) without their
+// InfoSet keys colliding.
+//
+// All other methods, including Update, Iter and MarshalBinary, apply to the
+// entire PolicyTable, so all namespaces share the same iteration.
+type PolicyNamespace struct {
+	*PolicyTable
+	prefix string
+}
+
+// Namespace returns a view of the policies within the given namespace.
+func (pt *PolicyTable) Namespace(name string) *PolicyNamespace {
+	return &PolicyNamespace{
+		PolicyTable: pt,
+		prefix:      name + namespaceSep,
+	}
+}
+
+// GetPolicy implements StrategyProfile.
+func (ns *PolicyNamespace) GetPolicy(node GameTreeNode) NodePolicy {
+	return ns.PolicyTable.GetPolicy(&namespacedNode{node, ns.prefix})
+}
+
+// RangeSums is as PolicyTable.RangeSums, but only for
+// the InfoSets within the namespace, with their keys unprefixed.
+func (ns *PolicyNamespace) RangeSums(f func(key string, regretSum, strategySum []float32) bool) {
+	ns.PolicyTable.RangeSums(func(key string, regretSum, strategySum []float32) bool {
+		if !strings.HasPrefix(key, ns.prefix) {
+			return true
+		}
+
+		return f(strings.TrimPrefix(key, ns.prefix), regretSum, strategySum)
+	})
+}
+
+// Namespaces returns the names of all namespaces with policies in the table, sorted.
+func (pt *PolicyTable) Namespaces() []string {
+	seen := make(map[string]struct{})
+	pt.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+		if name, _, ok := strings.Cut(key, namespaceSep); ok {
+			seen[name] = struct{}{}
+		}
+
+		return true
+	})
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ExportNamespace returns a new PolicyTable containing a copy of the policies
+// within the given namespace, with their keys unprefixed, so that it may be
+// used to play (or saved) on its own.
+func (pt *PolicyTable) ExportNamespace(name string) (*PolicyTable, error) {
+	prefix := name + namespaceSep
+	return TransferPolicyTable(pt, pt.params, func(key string) []string {
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		return []string{strings.TrimPrefix(key, prefix)}
+	})
+}
+
+// DeleteNamespace removes all policies within the given namespace from the table.
+// It must not be called during traversal.
+func (pt *PolicyTable) DeleteNamespace(name string) {
+	prefix := name + namespaceSep
+	policiesByKey := pt.newMap(pt.policiesByKey.Len())
+	pt.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+		if !strings.HasPrefix(key, prefix) {
+			policiesByKey.Put(key, p)
+		} else {
+			delete(pt.mayNeedUpdate, p)
+			if pt.buffers != nil {
+				delete(pt.buffers.wrappers, p)
+			}
+		}
+
+		return true
+	})
+
+	pt.policiesByKey = policiesByKey
+	numInfosets.Set(int64(pt.policiesByKey.Len()))
+}
+
+// namespacedNode prefixes the InfoSet keys of a node.
+type namespacedNode struct {
+	GameTreeNode
+	prefix string
+}
+
+func (n *namespacedNode) InfoSet(player int) InfoSet {
+	return namespacedInfoSet{n.GameTreeNode.InfoSet(player), n.prefix}
+}
+
+type namespacedInfoSet struct {
+	InfoSet
+	prefix string
+}
+
+func (is namespacedInfoSet) Key() string {
+	return is.prefix + is.InfoSet.Key()
+}
@@ -0,0 +1,69 @@
+package cfr_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestPolicyTable_Namespaces(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	a, b := policy.Namespace("a"), policy.Namespace("b")
+	standalone := cfr.NewPolicyTable(cfr.DiscountParams{})
+	optA, optB, opt := cfr.New(a), cfr.New(b), cfr.New(standalone)
+	for i := 0; i < 1000; i++ {
+		optA.Run(root)
+		// Namespace b is trained for fewer iterations, so
+		// its policies differ from those of namespace a.
+		if i < 10 {
+			optB.Run(root)
+		}
+
+		opt.Run(root)
+		policy.Update()
+		standalone.Update()
+	}
+
+	if names := policy.Namespaces(); !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("expected namespaces [a b], got %v", names)
+	}
+
+	// Training in namespace b does not affect namespace a, which is
+	// therefore identical to a table trained on its own.
+	if exploitability := cfr.Exploitability(root, a); exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+
+	exported, err := policy.ExportNamespace("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		expected := standalone.GetPolicy(node).GetAverageStrategy()
+		if got := a.GetPolicy(node).GetAverageStrategy(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%v: expected average strategy %v, got %v", node, expected, got)
+		}
+
+		if got := exported.GetPolicy(node).GetAverageStrategy(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%v: expected exported average strategy %v, got %v", node, expected, got)
+		}
+
+		if reflect.DeepEqual(b.GetPolicy(node).GetAverageStrategy(), expected) {
+			t.Errorf("%v: expected average strategy of namespace b to differ", node)
+		}
+	})
+
+	policy.DeleteNamespace("a")
+	if names := policy.Namespaces(); !reflect.DeepEqual(names, []string{"b"}) {
+		t.Errorf("expected namespaces [b] after deletion, got %v", names)
+	}
+}
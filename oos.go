@@ -10,10 +10,10 @@ type OnlineOutcomeSamplingCFR struct {
 	strategyProfile StrategyProfile
 	sampler         Sampler
 
-	slicePool *floatSlicePool
-	mapPool   *keyIntMapPool
-	rng       *rand.Rand
+	arena *arena
+	rng   *rand.Rand
 
+	schedule         PlayerSchedule
 	traversingPlayer int
 	sampledActions   map[string]int
 }
@@ -22,17 +22,28 @@ func NewOnlineOutcomeSamplingCFR(strategyProfile StrategyProfile, sampler Sample
 	return &OnlineOutcomeSamplingCFR{
 		strategyProfile: strategyProfile,
 		sampler:         sampler,
-		slicePool:       &floatSlicePool{},
-		mapPool:         &keyIntMapPool{},
+		arena:           &arena{},
 		rng:             rand.New(rand.NewSource(rand.Int63())),
 	}
 }
 
+// SetPlayerSchedule sets the PlayerSchedule of the runner.
+func (c *OnlineOutcomeSamplingCFR) SetPlayerSchedule(schedule PlayerSchedule) {
+	c.schedule = schedule
+}
+
+// Run performs a traversal from node for each player of the current
+// iteration's schedule, and returns the mean of their values.
 func (c *OnlineOutcomeSamplingCFR) Run(node GameTreeNode) float32 {
-	iter := c.strategyProfile.Iter()
-	c.traversingPlayer = int(iter % 2)
-	c.sampledActions = c.mapPool.alloc()
-	defer c.mapPool.free(c.sampledActions)
+	return runScheduled(c.schedule, c.strategyProfile.Iter(), func(player int) float32 {
+		c.traversingPlayer = player
+		return c.run(node)
+	})
+}
+
+func (c *OnlineOutcomeSamplingCFR) run(node GameTreeNode) float32 {
+	c.sampledActions = c.arena.allocMap()
+	defer c.arena.reset()
 	return c.runHelper(node, node.Player(), 1.0)
 }
 
@@ -40,7 +51,7 @@ func (c *OnlineOutcomeSamplingCFR) runHelper(node GameTreeNode, lastPlayer int,
 	var ev float32
 	switch node.Type() {
 	case TerminalNodeType:
-		ev = float32(node.Utility(lastPlayer)) / sampleProb
+		ev = float32(terminalUtility(node, lastPlayer)) / sampleProb
 	case ChanceNodeType:
 		ev = c.handleChanceNode(node, lastPlayer, sampleProb)
 	default:
@@ -77,16 +88,16 @@ func (c *OnlineOutcomeSamplingCFR) handleTraversingPlayerNode(node GameTreeNode,
 
 	policy := c.strategyProfile.GetPolicy(node)
 	isNew := policy.IsEmpty()
-	qs := c.slicePool.alloc(nChildren)
+	qs := c.arena.alloc(nChildren)
 	copy(qs, c.sampler.Sample(node, policy))
-	regrets := c.slicePool.alloc(nChildren)
+	regrets := c.arena.alloc(nChildren)
 	oldSampledActions := c.sampledActions
-	c.sampledActions = c.mapPool.alloc()
+	c.sampledActions = c.arena.allocMap()
 	strategy := policy.GetStrategy()
 	for i, q := range qs {
-		child := node.GetChild(i)
 		var util float32
 		if q > 0 {
+			child := node.GetChild(i)
 			if isNew {
 				util = c.randomRollout(child, player, q*sampleProb)
 			} else {
@@ -101,9 +112,9 @@ func (c *OnlineOutcomeSamplingCFR) handleTraversingPlayerNode(node GameTreeNode,
 	f32.AddConst(-cfValue, regrets)
 	policy.AddRegret(1.0/sampleProb, qs, regrets)
 
-	c.slicePool.free(qs)
-	c.slicePool.free(regrets)
-	c.mapPool.free(c.sampledActions)
+	c.arena.freeMap(c.sampledActions)
+	c.arena.free(regrets)
+	c.arena.free(qs)
 	c.sampledActions = oldSampledActions
 	return cfValue
 }
@@ -121,7 +132,7 @@ func (c *OnlineOutcomeSamplingCFR) randomRollout(node GameTreeNode, player int,
 		defer node.Close()
 	}
 
-	return float32(x * node.Utility(player))
+	return float32(x * terminalUtility(node, player))
 }
 
 // Sample player action according to strategy, do not update policy.
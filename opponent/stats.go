@@ -0,0 +1,214 @@
+// Package opponent collects statistics on the observed play of an opponent
+// and turns them into a model that can be traversed like any other
+// cfr.StrategyProfile.
+package opponent
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sync"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// Stats records how often each action was observed to be chosen
+// at each (abstract) InfoSet, identified by its Key().
+//
+// It is safe to call Observe concurrently from multiple goroutines.
+type Stats struct {
+	mx     sync.Mutex
+	counts map[string][]float32
+}
+
+// NewStats returns an empty collection of opponent statistics.
+func NewStats() *Stats {
+	return &Stats{
+		counts: make(map[string][]float32),
+	}
+}
+
+// Observe records that the acting player at the given node chose
+// the given action (child index).
+func (s *Stats) Observe(node cfr.GameTreeNode, action int) {
+	nChildren := node.NumChildren()
+	if action < 0 || action >= nChildren {
+		panic(fmt.Errorf("observed action %d but node has %d children: %v",
+			action, nChildren, node))
+	}
+
+	key := node.InfoSet(node.Player()).Key()
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	counts, ok := s.counts[key]
+	if !ok {
+		counts = make([]float32, nChildren)
+		s.counts[key] = counts
+	} else if len(counts) != nChildren {
+		panic(fmt.Errorf("infoset has n_actions=%v but node has n_children=%v: %v",
+			len(counts), nChildren, node))
+	}
+
+	counts[action]++
+}
+
+// NumObservations returns the total number of actions observed
+// at the InfoSet with the given key.
+func (s *Stats) NumObservations(key string) int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	var total float32
+	for _, c := range s.counts[key] {
+		total += c
+	}
+
+	return int(total)
+}
+
+// Len returns the number of distinct InfoSets that have been observed.
+func (s *Stats) Len() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return len(s.counts)
+}
+
+// Model returns a snapshot of the current statistics as an opponent model.
+// Each observed action frequency is smoothed by adding prior pseudo-counts
+// to every action, so that unobserved actions retain some probability.
+// InfoSets that were never observed are modeled as uniform random.
+func (s *Stats) Model(prior float32) *Model {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	counts := make(map[string][]float32, len(s.counts))
+	for key, c := range s.counts {
+		counts[key] = append([]float32(nil), c...)
+	}
+
+	return &Model{
+		Prior:  prior,
+		Counts: counts,
+	}
+}
+
+// Model is a fixed opponent model built from observed action frequencies.
+// It implements cfr.StrategyProfile, but cannot be trained: regret and
+// strategy updates are ignored, and GetStrategy and GetAverageStrategy both
+// return the observed (smoothed) action frequencies.
+type Model struct {
+	Prior  float32
+	Counts map[string][]float32
+}
+
+// GetPolicy implements cfr.StrategyProfile.
+func (m *Model) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
+	key := node.InfoSet(node.Player()).Key()
+	counts := m.Counts[key]
+	return &modelPolicy{
+		strategy: frequencies(counts, node.NumChildren(), m.Prior),
+		isEmpty:  counts == nil,
+	}
+}
+
+// Update implements cfr.StrategyProfile. It is a no-op.
+func (m *Model) Update() {}
+
+// Iter implements cfr.StrategyProfile.
+func (m *Model) Iter() int {
+	return 1
+}
+
+// Close implements io.Closer.
+func (m *Model) Close() error {
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (m *Model) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(m.Prior); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(m.Counts); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (m *Model) UnmarshalBinary(buf []byte) error {
+	r := bytes.NewReader(buf)
+	dec := gob.NewDecoder(r)
+	if err := dec.Decode(&m.Prior); err != nil {
+		return err
+	}
+
+	return dec.Decode(&m.Counts)
+}
+
+func frequencies(counts []float32, nActions int, prior float32) []float32 {
+	result := make([]float32, nActions)
+	var total float32
+	for i := range result {
+		result[i] = prior
+		if i < len(counts) {
+			result[i] += counts[i]
+		}
+
+		total += result[i]
+	}
+
+	if total > 0 {
+		for i := range result {
+			result[i] /= total
+		}
+	} else {
+		for i := range result {
+			result[i] = 1.0 / float32(nActions)
+		}
+	}
+
+	return result
+}
+
+// modelPolicy implements cfr.NodePolicy for a fixed opponent model.
+type modelPolicy struct {
+	strategy []float32
+	isEmpty  bool
+}
+
+func (p *modelPolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {}
+
+func (p *modelPolicy) GetStrategy() []float32 {
+	return p.strategy
+}
+
+func (p *modelPolicy) CopyStrategy(dst []float32) {
+	copy(dst, p.strategy)
+}
+
+func (p *modelPolicy) GetBaseline() []float32 {
+	return make([]float32, len(p.strategy))
+}
+
+func (p *modelPolicy) UpdateBaseline(w float32, action int, value float32) {}
+
+func (p *modelPolicy) AddStrategyWeight(w float32) {}
+
+func (p *modelPolicy) GetAverageStrategy() []float32 {
+	return p.strategy
+}
+
+func (p *modelPolicy) CopyAverageStrategy(dst []float32) {
+	copy(dst, p.strategy)
+}
+
+func (p *modelPolicy) IsEmpty() bool {
+	return p.isEmpty
+}
+
+func init() {
+	gob.Register(&Model{})
+}
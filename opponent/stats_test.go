@@ -0,0 +1,42 @@
+package opponent
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestStats_Model(t *testing.T) {
+	root := kuhn.NewGame()
+	var node cfr.GameTreeNode = root
+	for node.Type() != cfr.PlayerNodeType {
+		node = node.GetChild(0)
+	}
+
+	stats := NewStats()
+	for i := 0; i < 3; i++ {
+		stats.Observe(node, 1)
+	}
+	stats.Observe(node, 0)
+
+	key := node.InfoSet(node.Player()).Key()
+	if n := stats.NumObservations(key); n != 4 {
+		t.Errorf("expected %d observations, got %d", 4, n)
+	}
+
+	model := stats.Model(1.0)
+	strat := model.GetPolicy(node).GetStrategy()
+	expected := []float32{2.0 / 6, 4.0 / 6}
+	for i := range expected {
+		if strat[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, strat)
+			break
+		}
+	}
+
+	unseen := node.GetChild(0)
+	if p := model.GetPolicy(unseen); !p.IsEmpty() || p.GetStrategy()[0] != 0.5 {
+		t.Errorf("expected uniform strategy for unobserved infoset, got %v", p.GetStrategy())
+	}
+}
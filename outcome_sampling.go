@@ -0,0 +1,153 @@
+package cfr
+
+import (
+	"math/rand"
+)
+
+// OutcomeSamplingCFR implements outcome sampling MC-CFR (Lanctot et al., 2009).
+// A single trajectory is sampled each iteration: chance and opponent actions are
+// sampled according to their probabilities, and the traversing player's actions
+// are sampled with epsilon-greedy exploration. The traversing player alternates
+// between iterations.
+type OutcomeSamplingCFR struct {
+	strategyProfile StrategyProfile
+	explorationEps  float32
+	slicePool       SlicePool
+	rng             *rand.Rand
+
+	schedule         PlayerSchedule
+	traversingPlayer int
+}
+
+func NewOutcomeSampling(strategyProfile StrategyProfile, explorationEps float32) *OutcomeSamplingCFR {
+	return &OutcomeSamplingCFR{
+		strategyProfile: strategyProfile,
+		explorationEps:  explorationEps,
+		slicePool:       NewFloatSlicePool(SlicePoolParams{}),
+		rng:             rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// SetSlicePool sets the pool used to allocate temporary slices during
+// traversal. A single pool may be shared by multiple runners.
+func (c *OutcomeSamplingCFR) SetSlicePool(pool SlicePool) {
+	c.slicePool = pool
+}
+
+// SetExplorationEps sets the probability with which the traversing player's
+// actions are sampled uniformly at random on subsequent iterations.
+func (c *OutcomeSamplingCFR) SetExplorationEps(explorationEps float32) {
+	c.explorationEps = explorationEps
+}
+
+// SetPlayerSchedule sets the PlayerSchedule of the runner.
+func (c *OutcomeSamplingCFR) SetPlayerSchedule(schedule PlayerSchedule) {
+	c.schedule = schedule
+}
+
+// Run performs a traversal from node for each player of the current
+// iteration's schedule, and returns the mean of their values.
+func (c *OutcomeSamplingCFR) Run(node GameTreeNode) float32 {
+	return runScheduled(c.schedule, c.strategyProfile.Iter(), func(player int) float32 {
+		c.traversingPlayer = player
+		return c.run(node)
+	})
+}
+
+func (c *OutcomeSamplingCFR) run(node GameTreeNode) float32 {
+	ev, tailProb := c.runHelper(node, node.Player(), 1.0)
+	return ev * tailProb
+}
+
+// runHelper returns the sampled value of the node for lastPlayer, and the
+// probability with which the traversing player plays to the sampled terminal
+// node from here. Opponent and chance probabilities are omitted from both
+// since they cancel with their sampling probabilities.
+func (c *OutcomeSamplingCFR) runHelper(node GameTreeNode, lastPlayer int, sampleProb float32) (float32, float32) {
+	var ev, tailProb float32
+	switch node.Type() {
+	case TerminalNodeType:
+		ev, tailProb = float32(terminalUtility(node, lastPlayer))/sampleProb, 1.0
+	case ChanceNodeType:
+		ev, tailProb = c.handleChanceNode(node, lastPlayer, sampleProb)
+	default:
+		sgn := getSign(lastPlayer, node.Player())
+		ev, tailProb = c.handlePlayerNode(node, sampleProb)
+		ev *= sgn
+	}
+
+	node.Close()
+	return ev, tailProb
+}
+
+func (c *OutcomeSamplingCFR) handleChanceNode(node GameTreeNode, lastPlayer int, sampleProb float32) (float32, float32) {
+	child, _ := node.SampleChild()
+	// Sampling probabilities cancel out in the calculation of counterfactual value.
+	return c.runHelper(child, lastPlayer, sampleProb)
+}
+
+func (c *OutcomeSamplingCFR) handlePlayerNode(node GameTreeNode, sampleProb float32) (float32, float32) {
+	player := node.Player()
+	if node.NumChildren() == 1 {
+		// Optimization to skip trivial nodes with no real choice.
+		child := node.GetChild(0)
+		return c.runHelper(child, player, sampleProb)
+	}
+
+	if player == c.traversingPlayer {
+		return c.handleTraversingPlayerNode(node, sampleProb)
+	}
+
+	return c.handleSampledPlayerNode(node, sampleProb)
+}
+
+func (c *OutcomeSamplingCFR) handleTraversingPlayerNode(node GameTreeNode, sampleProb float32) (float32, float32) {
+	player := node.Player()
+	nChildren := node.NumChildren()
+	policy := c.strategyProfile.GetPolicy(node)
+	strategy := policy.GetStrategy()
+
+	// Sample one action with epsilon-greedy exploration.
+	var selected int
+	if c.rng.Float32() < c.explorationEps {
+		selected = c.rng.Intn(nChildren)
+	} else {
+		selected = sampleOne(strategy, c.rng.Float32())
+	}
+
+	q := c.explorationEps/float32(nChildren) + (1.0-c.explorationEps)*strategy[selected]
+	child := node.GetChild(selected)
+	util, tailProb := c.runHelper(child, player, q*sampleProb)
+
+	// The sampled counterfactual value of the selected action is util weighted
+	// by the tail probability, and that of the node is the same further weighted
+	// by the probability of selecting the action. All other actions have zero
+	// sampled value.
+	w := util * tailProb
+	regrets := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(regrets)
+	for i := range regrets {
+		regrets[i] = -w * strategy[selected]
+	}
+	regrets[selected] += w
+
+	qs := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(qs)
+	qs[selected] = q
+	policy.AddRegret(1.0, qs, regrets)
+
+	return util, strategy[selected] * tailProb
+}
+
+// Sample player action according to strategy, do not update regrets.
+func (c *OutcomeSamplingCFR) handleSampledPlayerNode(node GameTreeNode, sampleProb float32) (float32, float32) {
+	policy := c.strategyProfile.GetPolicy(node)
+
+	// Update average strategy for this node.
+	// We perform "stochastic" updates as described in the MC-CFR paper.
+	policy.AddStrategyWeight(1.0 / sampleProb)
+
+	selected := sampleOne(policy.GetStrategy(), c.rng.Float32())
+	child := node.GetChild(selected)
+	return c.runHelper(child, node.Player(), sampleProb)
+}
@@ -1,23 +1,89 @@
 package cfr
 
 import (
+	"encoding/gob"
 	"math"
+
+	"github.com/timpalpant/go-cfr/internal/policy"
 )
 
+func init() {
+	gob.Register(DCFRSchedule{})
+	gob.Register(HedgeMinimizer{})
+	gob.Register(FTRLMinimizer{})
+}
+
+// DiscountSchedule computes the factors by which accumulated positive regrets,
+// negative regrets and strategy sums are multiplied at the end of each iteration.
+//
+// Schedules are saved along with a PolicyTable, so custom implementations
+// must be registered with gob.Register.
+type DiscountSchedule interface {
+	GetDiscountFactors(iter int) (positive, negative, sum float32)
+}
+
 // DiscountParams modify how regret is accumulated.
 // An empty DiscountParams is valid and corresponds to traditional
 // (MC)CFR without weighting.
 type DiscountParams struct {
-	UseRegretMatchingPlus bool    // CFR+
-	LinearWeighting       bool    // Linear CFR
-	DiscountAlpha         float32 // Discounted CFR
-	DiscountBeta          float32 // Discounted CFR
-	DiscountGamma         float32 // Discounted CFR
+	UseRegretMatchingPlus bool // CFR+
+	// Predictive CFR+ (PCFR+): regret matching on the accumulated regrets plus
+	// the last iteration's instantaneous regrets, as a prediction of the next.
+	// Implies UseRegretMatchingPlus. See: https://arxiv.org/abs/2007.14358
+	UsePredictiveRegretMatching bool
+	LinearWeighting             bool    // Linear CFR
+	DiscountAlpha               float32 // Discounted CFR (zero disables)
+	DiscountBeta                float32 // Discounted CFR (zero disables)
+	DiscountGamma               float32 // Discounted CFR (zero disables)
+
+	// If set, Schedule replaces LinearWeighting and DiscountAlpha/Beta/Gamma.
+	// Negative regrets are still discarded if UseRegretMatchingPlus is set.
+	Schedule DiscountSchedule
+
+	// The weight of each new sample in the exponential moving average of the
+	// per-action baseline values used as control variates by VR-MCCFR.
+	// Smaller values reduce the noise of the baseline, at the cost of tracking
+	// changes in the strategy more slowly. Zero uses the default of 0.5.
+	BaselineDecay float32
+
+	// If positive, actions are excluded from regret matching at an InfoSet once
+	// they have been strictly dominated by another action in every sample of at
+	// least this many, reducing the effective branching factor late in training.
+	// Only samples in which all actions are evaluated exactly are counted, as by
+	// vanilla CFR, chance sampling and for the traversing player in external sampling.
+	DominanceMinSamples int
+
+	// Dynamic thresholding (Brown, Kroer & Sandholm, 2017): if nonzero, on
+	// iteration t actions whose probability in the current strategy is less than
+	// DynamicThreshold / sqrt(t) are played with probability zero, and the strategy
+	// is renormalized. This reduces the number of actions explored by traversals,
+	// and since the threshold decreases, actions whose regrets recover are restored.
+	// See: https://www.cs.cmu.edu/~noamb/papers/17-AAAI-Thresholding.pdf
+	DynamicThreshold float32
+
+	// The local regret minimizer used at each InfoSet, or regret matching if nil.
+	// Discounting, prediction and dominance checking apply to any minimizer,
+	// though discarding negative regrets (as in CFR+) is not useful with Hedge.
+	Minimizer LocalRegretMinimizer
+
+	// Accumulate regrets and strategy sums with compensated (Kahan) summation.
+	// This costs some speed and memory, but matters for very long runs in which
+	// small per-iteration increments would otherwise vanish against large sums.
+	CompensatedSummation bool
 }
 
 // Gets the discount factors as configured by the parameters for the
 // various CFR weighting schemes: CFR+, linear CFR, etc.
 func (p DiscountParams) GetDiscountFactors(iter int) (positive, negative, sum float32) {
+	if p.Schedule != nil {
+		positive, negative, sum = p.Schedule.GetDiscountFactors(iter)
+		if p.UseRegretMatchingPlus || p.UsePredictiveRegretMatching {
+			negative = 0.0
+		}
+
+		return
+	}
+
 	positive = float32(1.0)
 	negative = float32(1.0)
 	sum = float32(1.0)
@@ -29,7 +95,7 @@ func (p DiscountParams) GetDiscountFactors(iter int) (positive, negative, sum fl
 		sum = float32(iter) / float32(iter+1)
 	}
 
-	if p.UseRegretMatchingPlus {
+	if p.UseRegretMatchingPlus || p.UsePredictiveRegretMatching {
 		negative = 0.0 // No negative regrets.
 	}
 
@@ -53,3 +119,61 @@ func (p DiscountParams) GetDiscountFactors(iter int) (positive, negative, sum fl
 
 	return
 }
+
+// GetThreshold returns the probability below which actions are removed from the
+// current strategy played on the given iteration, or zero if dynamic
+// thresholding is disabled.
+func (p DiscountParams) GetThreshold(iter int) float32 {
+	if p.DynamicThreshold == 0 {
+		return 0
+	}
+
+	return p.DynamicThreshold / float32(math.Sqrt(float64(iter)))
+}
+
+// UsesPrediction returns true if policies must keep the last iteration's
+// instantaneous regrets as a prediction of the next iteration's regrets.
+func (p DiscountParams) UsesPrediction() bool {
+	if p.UsePredictiveRegretMatching {
+		return true
+	}
+
+	return p.Minimizer != nil && p.Minimizer.Optimistic()
+}
+
+// policyParams returns the parameters shared by all policies of a table.
+func (p DiscountParams) policyParams() policy.Params {
+	params := policy.Params{BaselineDecay: p.BaselineDecay}
+	if p.DominanceMinSamples > 0 {
+		params.DominanceMinSamples = uint32(p.DominanceMinSamples)
+	}
+
+	return params
+}
+
+// DCFRSchedule implements the discounting of Discounted CFR (Brown & Sandholm, 2019).
+// On iteration t, accumulated positive regrets are multiplied by t^α / (t^α + 1),
+// negative regrets by t^β / (t^β + 1), and strategy sums by (t / (t+1))^γ.
+//
+// Unlike the DiscountAlpha/Beta/Gamma fields of DiscountParams, zero is a
+// meaningful value of each parameter: β=0 halves negative regrets every iteration.
+type DCFRSchedule struct {
+	Alpha float64
+	Beta  float64
+	Gamma float64
+}
+
+// DefaultDCFRSchedule has the parameters recommended by Brown & Sandholm
+// (α=3/2, β=0, γ=2), which performed consistently better than CFR+.
+var DefaultDCFRSchedule = DCFRSchedule{Alpha: 1.5, Beta: 0, Gamma: 2}
+
+// GetDiscountFactors implements DiscountSchedule.
+func (s DCFRSchedule) GetDiscountFactors(iter int) (positive, negative, sum float32) {
+	t := float64(iter)
+	x := math.Pow(t, s.Alpha)
+	positive = float32(x / (x + 1.0))
+	x = math.Pow(t, s.Beta)
+	negative = float32(x / (x + 1.0))
+	sum = float32(math.Pow(t/(t+1), s.Gamma))
+	return
+}
@@ -0,0 +1,171 @@
+// Package play implements an interactive console in which a human plays
+// a game against a trained strategy, for sanity checking its behavior.
+package play
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/sampling"
+)
+
+// Console plays games between a human, who enters their actions on In,
+// and a bot following the average strategy of a StrategyProfile.
+type Console struct {
+	bot   cfr.StrategyProfile
+	human int
+	in    *bufio.Scanner
+	out   io.Writer
+	rng   *rand.Rand
+
+	// ActionNames returns the names of the actions at a player node.
+	// If nil, actions are named by their child index.
+	ActionNames func(node cfr.GameTreeNode) []string
+	// ShowStrategy prints the bot's probability distribution over actions
+	// at each of its decisions.
+	ShowStrategy bool
+}
+
+// NewConsole returns a new Console in which the human acts as the given player,
+// reading actions from in and writing the state of the game to out.
+func NewConsole(bot cfr.StrategyProfile, human int, in io.Reader, out io.Writer) *Console {
+	return &Console{
+		bot:   bot,
+		human: human,
+		in:    bufio.NewScanner(in),
+		out:   out,
+		rng:   rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Seed sets the seed of the random number generator used to sample
+// chance outcomes and the bot's actions.
+func (c *Console) Seed(seed int64) {
+	c.rng.Seed(seed)
+}
+
+// Play plays a single game from node to a terminal node, and returns the
+// human's utility. The human is only shown their own InfoSet while the game
+// is in progress; the full terminal node is shown when it is over.
+// It returns io.EOF if the input ends before the game is over.
+func (c *Console) Play(node cfr.GameTreeNode) (float64, error) {
+	var visited []cfr.GameTreeNode
+	defer func() {
+		for i := len(visited) - 1; i >= 0; i-- {
+			visited[i].Close()
+		}
+	}()
+
+	for node.Type() != cfr.TerminalNodeType {
+		var action int
+		var err error
+		switch {
+		case node.Type() == cfr.ChanceNodeType:
+			action = c.sampleChance(node)
+		case node.NumChildren() == 1:
+			action = 0
+		case node.Player() == c.human:
+			action, err = c.readAction(node)
+		default:
+			action = c.botAction(node)
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		node = node.GetChild(action)
+		visited = append(visited, node)
+	}
+
+	u := node.Utility(c.human)
+	fmt.Fprintf(c.out, "Game over: %v\n", node)
+	fmt.Fprintf(c.out, "Your utility: %v\n", u)
+	return u, nil
+}
+
+func (c *Console) readAction(node cfr.GameTreeNode) (int, error) {
+	names := c.actionNames(node)
+	fmt.Fprintf(c.out, "Your turn. InfoSet: %v\n", node.InfoSet(c.human).Key())
+	for {
+		fmt.Fprintf(c.out, "Choose an action [%s]: ", strings.Join(names, ", "))
+		if !c.in.Scan() {
+			if err := c.in.Err(); err != nil {
+				return 0, err
+			}
+
+			return 0, io.EOF
+		}
+
+		if action, ok := parseAction(c.in.Text(), names); ok {
+			return action, nil
+		}
+
+		fmt.Fprintf(c.out, "Invalid action: %q\n", c.in.Text())
+	}
+}
+
+// parseAction parses an action given by name or child index.
+func parseAction(s string, names []string) (int, bool) {
+	s = strings.TrimSpace(s)
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			return i, true
+		}
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 || i >= len(names) {
+		return 0, false
+	}
+
+	return i, true
+}
+
+func (c *Console) botAction(node cfr.GameTreeNode) int {
+	strategy := c.bot.GetPolicy(node).GetAverageStrategy()
+	names := c.actionNames(node)
+	if c.ShowStrategy {
+		fmt.Fprint(c.out, "Bot strategy:")
+		for i, p := range strategy {
+			fmt.Fprintf(c.out, " %s=%.3f", names[i], p)
+		}
+		fmt.Fprintln(c.out)
+	}
+
+	action := sampling.SampleOne(strategy, c.rng.Float32())
+	fmt.Fprintf(c.out, "Bot chooses: %s\n", names[action])
+	return action
+}
+
+func (c *Console) sampleChance(node cfr.GameTreeNode) int {
+	x := c.rng.Float64()
+	n := node.NumChildren()
+	var cumProb float64
+	for i := 0; i < n; i++ {
+		cumProb += node.GetChildProbability(i)
+		if cumProb > x {
+			return i
+		}
+	}
+
+	return n - 1
+}
+
+func (c *Console) actionNames(node cfr.GameTreeNode) []string {
+	if c.ActionNames != nil {
+		return c.ActionNames(node)
+	}
+
+	names := make([]string, node.NumChildren())
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+	}
+
+	return names
+}
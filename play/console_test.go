@@ -0,0 +1,73 @@
+package play
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func kuhnActions(node cfr.GameTreeNode) []string {
+	return []string{"check", "bet"}
+}
+
+func TestPlay(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	// Player 0 always bets, so the game ends after the bot's response.
+	in := strings.NewReader("foo\n5\nbet\n")
+	var out bytes.Buffer
+	console := NewConsole(policy, 0, in, &out)
+	console.ActionNames = kuhnActions
+	console.ShowStrategy = true
+	console.Seed(123)
+	u, err := console.Play(kuhn.NewGame())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log(out.String())
+	if u != 1 && u != 2 && u != -2 {
+		t.Errorf("unexpected utility after betting: %v", u)
+	}
+
+	if n := strings.Count(out.String(), "Invalid action"); n != 2 {
+		t.Errorf("expected 2 invalid actions, got %d", n)
+	}
+
+	if !strings.Contains(out.String(), "Bot strategy: check=0.500 bet=0.500") {
+		t.Error("expected bot strategy to be shown")
+	}
+}
+
+func TestPlay_EOF(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	console := NewConsole(policy, 0, strings.NewReader(""), io.Discard)
+	if _, err := console.Play(kuhn.NewGame()); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestParseAction(t *testing.T) {
+	names := []string{"check", "bet"}
+	for _, tc := range []struct {
+		input    string
+		expected int
+		ok       bool
+	}{
+		{"check", 0, true},
+		{" BET ", 1, true},
+		{"1", 1, true},
+		{"2", 0, false},
+		{"-1", 0, false},
+		{"fold", 0, false},
+	} {
+		action, ok := parseAction(tc.input, names)
+		if ok != tc.ok || action != tc.expected {
+			t.Errorf("parseAction(%q) = (%d, %v), expected (%d, %v)",
+				tc.input, action, ok, tc.expected, tc.ok)
+		}
+	}
+}
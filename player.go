@@ -0,0 +1,135 @@
+package cfr
+
+import (
+	"math/rand"
+)
+
+// SearchParams are the parameters of the real-time search of a Player.
+type SearchParams struct {
+	// SubgameRoot returns the root of the subgame that is searched when the player
+	// is to act at node: an ancestor of node (or node itself) below which the game
+	// may be solved independently of the rest of it, such as the chance node at the
+	// start of the current round. If nil, the root of the game is used.
+	SubgameRoot func(node GameTreeNode) GameTreeNode
+	// Number of iterations of CFR used to solve the subgame.
+	Iterations int
+	// DiscountParams of the PolicyTable used to solve the subgame.
+	DiscountParams DiscountParams
+	// If one of the leaf evaluators is set, the subgame is only expanded to
+	// MaxDepth player actions below the node at which the player is to act (see
+	// CFR.SetDepthLimit), and the values of nodes at the depth limit are estimated
+	// by the evaluator. Otherwise, the subgame is re-solved to its terminal nodes.
+	MaxDepth                 int
+	LeafEvaluator            LeafEvaluator
+	MultiValuedLeafEvaluator MultiValuedLeafEvaluator
+}
+
+// Player plays a game according to a precomputed blueprint StrategyProfile,
+// optionally refined by real-time search (see SetSearch). It combines a trained
+// blueprint with subgame solving behind a single call at each decision.
+type Player struct {
+	blueprint StrategyProfile
+	search    *SearchParams
+	rng       *rand.Rand
+}
+
+// NewPlayer returns a new Player that plays the average strategy of blueprint.
+func NewPlayer(blueprint StrategyProfile) *Player {
+	return &Player{
+		blueprint: blueprint,
+		rng:       rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Seed sets the seed of the random number generator used to sample actions.
+func (p *Player) Seed(seed int64) {
+	p.rng.Seed(seed)
+}
+
+// SetSearch enables real-time search with the given parameters, or disables it
+// if params is nil. With search enabled, the subgame containing each decision is
+// solved with CFR before acting, and the player plays the average strategy of the
+// solution at their InfoSet. If the InfoSet is not reached in the solution (for
+// example, if the actions leading to it have zero probability), the blueprint
+// strategy is played instead.
+//
+// Search is unsafe: the strategies of both players in the subgame are re-solved,
+// rather than constrained to be consistent with the blueprint above the subgame, so
+// the subgame root should be chosen so that the ranges of both players at it do
+// not depend on how they have played before it. The subgame is traversed from its
+// root, so nodes on the path to the decision may be closed and expanded again.
+func (p *Player) SetSearch(params *SearchParams) {
+	p.search = params
+}
+
+// Strategy returns the probability distribution over actions played
+// at node, at which the player is to act.
+func (p *Player) Strategy(node GameTreeNode) []float32 {
+	if p.search == nil {
+		return p.blueprint.GetPolicy(node).GetAverageStrategy()
+	}
+
+	solution := p.solveSubgame(node)
+	policy := solution.GetPolicy(node)
+	if policy.IsEmpty() {
+		return p.blueprint.GetPolicy(node).GetAverageStrategy()
+	}
+
+	return policy.GetAverageStrategy()
+}
+
+// Act returns the index of the action sampled from the player's strategy at node.
+func (p *Player) Act(node GameTreeNode) int {
+	if node.NumChildren() == 1 {
+		return 0
+	}
+
+	return sampleOne(p.Strategy(node), p.rng.Float32())
+}
+
+// solveSubgame solves the subgame containing node with CFR.
+func (p *Player) solveSubgame(node GameTreeNode) *PolicyTable {
+	root := gameRoot(node)
+	if p.search.SubgameRoot != nil {
+		root = p.search.SubgameRoot(node)
+	}
+
+	pt := NewPolicyTable(p.search.DiscountParams)
+	solver := New(pt)
+	// The depth limit is relative to the root of the subgame.
+	maxDepth := numDecisions(node) - numDecisions(root) + p.search.MaxDepth
+	if p.search.MultiValuedLeafEvaluator != nil {
+		solver.SetMultiValuedDepthLimit(maxDepth, p.search.MultiValuedLeafEvaluator)
+	} else if p.search.LeafEvaluator != nil {
+		solver.SetDepthLimit(maxDepth, p.search.LeafEvaluator)
+	}
+
+	for i := 0; i < p.search.Iterations; i++ {
+		solver.Run(root)
+		pt.Update()
+	}
+
+	return pt
+}
+
+// gameRoot returns the root of the game containing node.
+func gameRoot(node GameTreeNode) GameTreeNode {
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		node = parent
+	}
+
+	return node
+}
+
+// numDecisions returns the number of player actions above node that are counted
+// by the depth limit of CFR: those of ancestors with more than one child.
+func numDecisions(node GameTreeNode) int {
+	n := 0
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		if parent.Type() == PlayerNodeType && parent.NumChildren() > 1 {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,62 @@
+package cfr_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+// expectedUtility returns the expected utility of player from node
+// when both players play according to strategy.
+func TestPlayer(t *testing.T) {
+	blueprint := cfr.NewPolicyTable(cfr.DiscountParams{})
+	runCFR(t, cfr.New(blueprint), blueprint, 100)
+
+	// Player 1 holds a Jack, facing a bet from player 0 (who holds a Queen).
+	root := kuhn.NewGame()
+	node := root.GetChild(1).GetChild(0).GetChild(1)
+	if node.InfoSet(node.Player()).Key() != "rrb-J" {
+		t.Fatalf("unexpected node: %v", node)
+	}
+
+	player := cfr.NewPlayer(blueprint)
+	player.Seed(123)
+	expected := blueprint.GetPolicy(node).GetAverageStrategy()
+	if strategy := player.Strategy(node); !reflect.DeepEqual(strategy, expected) {
+		t.Errorf("expected blueprint strategy %v without search, got %v", expected, strategy)
+	}
+
+	// With a Jack, player 1 should always fold to a bet. Re-solving the
+	// game from the root converges to this much faster than the blueprint.
+	player.SetSearch(&cfr.SearchParams{Iterations: 1000})
+	if strategy := player.Strategy(node); strategy[0] < 0.99 {
+		t.Errorf("expected to fold after re-solving, got %v", strategy)
+	}
+
+	if action := player.Act(node); action != 0 {
+		t.Errorf("expected to fold after re-solving, got action %d", action)
+	}
+
+	// Depth-limited search of the decisions of player 1,
+	// with the values of player 0's responses given by the blueprint.
+	var nLeaves int
+	player.SetSearch(&cfr.SearchParams{
+		SubgameRoot: func(node cfr.GameTreeNode) cfr.GameTreeNode { return root },
+		Iterations:  1000,
+		MaxDepth:    1,
+		LeafEvaluator: cfr.LeafEvaluatorFunc(func(node cfr.GameTreeNode, player int) float32 {
+			nLeaves++
+			return float32(expectedUtility(averageStrategy(blueprint), node, player))
+		}),
+	})
+
+	if strategy := player.Strategy(node); strategy[0] < 0.99 {
+		t.Errorf("expected to fold after depth-limited search, got %v", strategy)
+	}
+
+	if nLeaves == 0 {
+		t.Error("expected depth-limited search to evaluate leaves")
+	}
+}
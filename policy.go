@@ -5,6 +5,8 @@ import (
 	"encoding/gob"
 	"expvar"
 	"fmt"
+	"io"
+	"sync"
 
 	"github.com/timpalpant/go-cfr/internal/policy"
 )
@@ -21,36 +23,165 @@ func init() {
 // regrets and strategy sums for each InfoSet, which is looked up by its Key().
 type PolicyTable struct {
 	params DiscountParams
-	iter   int
+	// Parameters of params that are shared by all policies.
+	policyParams policy.Params
+	iter         int
+	// The weight of strategies accumulated during the current iteration,
+	// relative to the first iteration. See Update.
+	strategyWeight float64
 
 	// Map of InfoSet Key -> the policy for that infoset.
-	policiesByKey map[string]*policy.Policy
+	policiesByKey policy.Map
 	mayNeedUpdate map[*policy.Policy]struct{}
+	compact       bool
+	// If true, keys of the compact map are prefix-compressed.
+	prefixCompressed bool
+	// Non-nil if the table is double-buffered. See NewDoubleBufferedPolicyTable.
+	buffers *doubleBuffer
+
+	// Initial strategies of new policies. See SetHeuristic.
+	heuristic       Heuristic
+	heuristicWeight float32
+
+	// InfoSet key prefixes and keys whose policies are read-only, and the cached
+	// read-only policies of their InfoSets. See SetLockedPrefixes and SetLockedInfoSets.
+	lockedPrefixes []string
+	lockedKeys     map[string]struct{}
+	lockedPolicies *sync.Map
 }
 
+// Heuristic returns an initial strategy for the InfoSet of the given node,
+// which must be a probability distribution over its children.
+type Heuristic func(node GameTreeNode) []float32
+
 // NewPolicyTable creates a new PolicyTable with the given DiscountParams.
 func NewPolicyTable(params DiscountParams) *PolicyTable {
 	return &PolicyTable{
-		params:        params,
-		iter:          1,
-		policiesByKey: make(map[string]*policy.Policy),
-		mayNeedUpdate: make(map[*policy.Policy]struct{}),
+		params:         params,
+		policyParams:   params.policyParams(),
+		iter:           1,
+		strategyWeight: 1.0,
+		policiesByKey:  policy.NewBuiltinMap(0),
+		mayNeedUpdate:  make(map[*policy.Policy]struct{}),
 	}
 }
 
+// NewCompactPolicyTable creates a new PolicyTable with the given DiscountParams
+// that stores policies in an open-addressing hash table with all InfoSet keys
+// stored inline, rather than in the built-in map. This substantially reduces
+// memory overhead for tables with very many InfoSets.
+func NewCompactPolicyTable(params DiscountParams) *PolicyTable {
+	pt := NewPolicyTable(params)
+	pt.policiesByKey = policy.NewCompactMap(0)
+	pt.compact = true
+	return pt
+}
+
+// NewPrefixCompressedPolicyTable creates a new compact PolicyTable (see
+// NewCompactPolicyTable) that also compresses the InfoSet keys it stores by
+// their common prefixes, for games in which the keys themselves use more memory
+// than the policies. Lookups are slower, since keys must be decoded to compare them.
+func NewPrefixCompressedPolicyTable(params DiscountParams) *PolicyTable {
+	pt := NewCompactPolicyTable(params)
+	pt.policiesByKey = policy.NewPrefixCompressedMap(0)
+	pt.prefixCompressed = true
+	return pt
+}
+
+func (pt *PolicyTable) newMap(capacity int) policy.Map {
+	if pt.prefixCompressed {
+		return policy.NewPrefixCompressedMap(capacity)
+	} else if pt.compact {
+		return policy.NewCompactMap(capacity)
+	}
+
+	return policy.NewBuiltinMap(capacity)
+}
+
 // Update performs regret matching for all nodes within this strategy profile that have
 // been touched since the lapt call to Update().
+//
+// Rather than discounting the strategy sums of touched nodes, the strategy weight
+// accumulated on each iteration is multiplied by the inverse of the cumulative
+// discount since the first iteration (e.g. by t+1 for Linear CFR). This is equivalent
+// when every node is touched on every iteration, as in vanilla CFR, but remains
+// correct for Monte Carlo runners that only touch the nodes they sample.
 func (pt *PolicyTable) Update() {
+	if pt.buffers != nil {
+		pt.updateBuffered()
+		return
+	}
+
 	discountPos, discountNeg, discountSum := pt.params.GetDiscountFactors(pt.iter)
+	threshold := pt.params.GetThreshold(pt.iter + 1)
+	pt.strategyWeight /= float64(discountSum)
+	strategyWeight := float32(pt.strategyWeight)
 	for p := range pt.mayNeedUpdate {
-		p.NextStrategy(discountPos, discountNeg, discountSum)
+		p.ScaleStrategyWeight(strategyWeight)
+		p.NextStrategy(discountPos, discountNeg, 1.0, pt.params.Minimizer, pt.iter+1)
+		if threshold > 0 {
+			p.ApplyThreshold(threshold)
+		}
+
 		delete(pt.mayNeedUpdate, p)
 	}
 
 	pt.iter++
 }
 
+// SetDiscountParams changes the DiscountParams used by subsequent calls to Update,
+// for example to switch from DCFR to CFR+ once training has reached a steady state.
+// Accumulated strategy sums keep their weighting. Compensated summation and
+// prediction (for predictive regret matching or an optimistic minimizer) are
+// enabled for existing policies if newly set, but are not disabled once enabled.
+// Likewise, dominance checking applies to existing policies if
+// DominanceMinSamples is set, but is not disabled.
+func (pt *PolicyTable) SetDiscountParams(params DiscountParams) {
+	enableCompensation := params.CompensatedSummation && !pt.params.CompensatedSummation
+	enablePrediction := params.UsesPrediction() && !pt.params.UsesPrediction()
+	enableDominance := params.DominanceMinSamples > 0 && pt.params.DominanceMinSamples <= 0
+	if enableCompensation || enablePrediction || enableDominance {
+		pt.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+			if enableCompensation {
+				p.EnableCompensatedSummation()
+			}
+
+			if enablePrediction {
+				p.EnablePrediction()
+			}
+
+			if enableDominance {
+				p.EnableDominanceCheck()
+			}
+
+			return true
+		})
+	}
+
+	pt.params = params
+	pt.policyParams = params.policyParams()
+}
+
+// SetHeuristic seeds the policies of InfoSets that are subsequently
+// encountered for the first time with the strategy returned by h, rather than
+// the uniform distribution. Their regrets are initialized to regretWeight times
+// the heuristic strategy, so that it continues to be played until regrets against
+// it accumulate. Larger weights trust the heuristic for longer, and should be
+// chosen relative to the range of utilities in the game.
+//
+// The heuristic is not saved by MarshalBinary, and must be set again to apply
+// to new InfoSets of a reloaded PolicyTable.
+func (pt *PolicyTable) SetHeuristic(h Heuristic, regretWeight float32) {
+	pt.heuristic = h
+	pt.heuristicWeight = regretWeight
+}
+
 func (pt *PolicyTable) Iter() int {
+	if pt.buffers != nil {
+		pt.buffers.mx.RLock()
+		defer pt.buffers.mx.RUnlock()
+	}
+
 	return pt.iter
 }
 
@@ -58,6 +189,17 @@ func (pt *PolicyTable) Close() error {
 	return nil
 }
 
+// RangeSums calls f with the cumulative regrets and strategy sums of each InfoSet
+// in the table, until f returns false, without creating or modifying any policies.
+// Strategy sums are scaled by the strategy weight of the table (see Update), so
+// they are proportional to, but not equal to, the discounted sums. The slices must
+// not be modified or retained. It must not be called during traversal.
+func (pt *PolicyTable) RangeSums(f func(key string, regretSum, strategySum []float32) bool) {
+	pt.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+		return f(key, p.GetRegretSum(), p.GetStrategySum())
+	})
+}
+
 func nodeKey(node GameTreeNode) string {
 	p := node.Player()
 	is := node.InfoSet(p)
@@ -65,21 +207,64 @@ func nodeKey(node GameTreeNode) string {
 }
 
 func (pt *PolicyTable) GetPolicy(node GameTreeNode) NodePolicy {
+	if len(pt.lockedPrefixes) > 0 || len(pt.lockedKeys) > 0 {
+		if lp := pt.getLockedPolicy(node); lp != nil {
+			return lp
+		}
+	}
+
+	if pt.buffers != nil {
+		return pt.getBufferedPolicy(node)
+	}
+
+	np := pt.lookupPolicy(node)
+	pt.mayNeedUpdate[np] = struct{}{}
+	return np
+}
+
+// lookupPolicy returns the policy for the given node, creating it if necessary.
+func (pt *PolicyTable) lookupPolicy(node GameTreeNode) *policy.Policy {
 	key := nodeKey(node)
-	np, ok := pt.policiesByKey[key]
+	np, ok := pt.policiesByKey.Get(key)
 	if !ok {
-		np = policy.New(node.NumChildren())
-		pt.policiesByKey[key] = np
-		numInfosets.Set(int64(len(pt.policiesByKey)))
+		np = pt.newPolicy(node.NumChildren())
+		if pt.heuristic != nil {
+			strategy := pt.heuristic(node)
+			if cn, ok := node.(canonicalNode); ok {
+				strategy = cn.toCanonical(strategy)
+			}
+
+			np.Initialize(strategy, pt.heuristicWeight)
+		}
+
+		pt.policiesByKey.Put(key, np)
+		numInfosets.Set(int64(pt.policiesByKey.Len()))
 	} else if np.NumActions() != node.NumChildren() {
 		panic(fmt.Errorf("strategy has n_actions=%v but node has n_children=%v: %v",
 			np.NumActions(), node.NumChildren(), node))
 	}
 
-	pt.mayNeedUpdate[np] = struct{}{}
 	return np
 }
 
+func (pt *PolicyTable) newPolicy(nActions int) *policy.Policy {
+	p := policy.New(nActions)
+	if pt.params.CompensatedSummation {
+		p.EnableCompensatedSummation()
+	}
+
+	if pt.params.UsesPrediction() {
+		p.EnablePrediction()
+	}
+
+	if pt.params.DominanceMinSamples > 0 {
+		p.EnableDominanceCheck()
+	}
+
+	p.SetParams(&pt.policyParams)
+	return p
+}
+
 // UnmarshalBinary implements encoding.BinaryUnmarshaler.
 func (pt *PolicyTable) UnmarshalBinary(buf []byte) error {
 	r := bytes.NewReader(buf)
@@ -88,6 +273,8 @@ func (pt *PolicyTable) UnmarshalBinary(buf []byte) error {
 		return err
 	}
 
+	pt.policyParams = pt.params.policyParams()
+
 	if err := dec.Decode(&pt.iter); err != nil {
 		return err
 	}
@@ -97,7 +284,7 @@ func (pt *PolicyTable) UnmarshalBinary(buf []byte) error {
 		return err
 	}
 
-	pt.policiesByKey = make(map[string]*policy.Policy, nStrategies)
+	policiesByKey := policy.NewBuiltinMap(nStrategies)
 	for i := 0; i < nStrategies; i++ {
 		var key string
 		if err := dec.Decode(&key); err != nil {
@@ -109,15 +296,62 @@ func (pt *PolicyTable) UnmarshalBinary(buf []byte) error {
 			return err
 		}
 
-		pt.policiesByKey[key] = &p
+		p.SetParams(&pt.policyParams)
+		if pt.params.DominanceMinSamples > 0 {
+			p.EnableDominanceCheck()
+		}
+
+		policiesByKey.Put(key, &p)
+	}
+
+	// Tables encoded before compact storage was added end here.
+	pt.compact = false
+	if err := dec.Decode(&pt.compact); err != nil && err != io.EOF {
+		return err
+	}
+
+	// Tables encoded before strategy weighting was added discounted their
+	// strategy sums, which is equivalent to a current strategy weight of 1.
+	pt.strategyWeight = 1.0
+	if err := dec.Decode(&pt.strategyWeight); err != nil && err != io.EOF {
+		return err
+	}
+
+	var doubleBuffered bool
+	if err := dec.Decode(&doubleBuffered); err != nil && err != io.EOF {
+		return err
+	}
+
+	pt.prefixCompressed = false
+	if err := dec.Decode(&pt.prefixCompressed); err != nil && err != io.EOF {
+		return err
+	}
+
+	pt.policiesByKey = policiesByKey
+	if pt.compact {
+		pt.policiesByKey = pt.newMap(nStrategies)
+		policiesByKey.Range(func(key string, p *policy.Policy) bool {
+			pt.policiesByKey.Put(key, p)
+			return true
+		})
 	}
 
 	pt.mayNeedUpdate = make(map[*policy.Policy]struct{})
+	pt.buffers = nil
+	if doubleBuffered {
+		pt.buffers = newDoubleBuffer()
+	}
+
 	return nil
 }
 
 // MarshalBinary implements encoding.BinaryMarshaler.
 func (pt *PolicyTable) MarshalBinary() ([]byte, error) {
+	if pt.buffers != nil {
+		pt.buffers.mx.RLock()
+		defer pt.buffers.mx.RUnlock()
+	}
+
 	var buf bytes.Buffer
 	enc := gob.NewEncoder(&buf)
 	if err := enc.Encode(pt.params); err != nil {
@@ -128,18 +362,38 @@ func (pt *PolicyTable) MarshalBinary() ([]byte, error) {
 		return nil, err
 	}
 
-	if err := enc.Encode(len(pt.policiesByKey)); err != nil {
+	if err := enc.Encode(pt.policiesByKey.Len()); err != nil {
 		return nil, err
 	}
 
-	for key, p := range pt.policiesByKey {
-		if err := enc.Encode(key); err != nil {
-			return nil, err
+	var err error
+	pt.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+		if err = enc.Encode(key); err != nil {
+			return false
 		}
 
-		if err := enc.Encode(p); err != nil {
-			return nil, err
-		}
+		err = enc.Encode(p)
+		return err == nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(pt.compact); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(pt.strategyWeight); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(pt.buffers != nil); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(pt.prefixCompressed); err != nil {
+		return nil, err
 	}
 
 	return buf.Bytes(), nil
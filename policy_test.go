@@ -0,0 +1,97 @@
+package cfr_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestHeuristicInitialization(t *testing.T) {
+	// Mostly bet or call with a King, and check or fold with a Jack or Queen.
+	heuristic := func(node cfr.GameTreeNode) []float32 {
+		switch kuhnCard(node.InfoSet(node.Player())) {
+		case kuhn.King:
+			return []float32{0.2, 0.8}
+		case kuhn.Jack:
+			return []float32{0.8, 0.2}
+		default:
+			return []float32{0.7, 0.3}
+		}
+	}
+
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	policy.SetHeuristic(heuristic, 1)
+	root := kuhn.NewGame()
+	node := root.GetChild(int(kuhn.King)).GetChild(0)
+	if strategy := policy.GetPolicy(node).GetStrategy(); !reflect.DeepEqual(strategy, []float32{0.2, 0.8}) {
+		t.Errorf("expected heuristic initial strategy, got %v", strategy)
+	}
+
+	uniform := cfr.NewPolicyTable(cfr.DiscountParams{})
+	runCFR(t, cfr.New(uniform), uniform, 50)
+	runCFR(t, cfr.New(policy), policy, 50)
+	seeded := cfr.Exploitability(root, policy)
+	unseeded := cfr.Exploitability(root, uniform)
+	t.Logf("Exploitability after 50 iterations: heuristic = %.4f, uniform = %.4f", seeded, unseeded)
+	if seeded >= unseeded {
+		t.Errorf("expected heuristic initialization to converge faster")
+	}
+
+	runCFR(t, cfr.New(policy), policy, 10000)
+	if exploitability := cfr.Exploitability(root, policy); exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
+
+func TestCompactPolicyTableCFR(t *testing.T) {
+	policy := cfr.NewCompactPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	testCFR(t, opt, policy, 10000)
+	testMarshalRoundTrip(t, policy)
+}
+
+func TestPrefixCompressedPolicyTableCFR(t *testing.T) {
+	policy := cfr.NewPrefixCompressedPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	testCFR(t, opt, policy, 10000)
+	testMarshalRoundTrip(t, policy)
+	if exploitability := cfr.Exploitability(kuhn.NewGame(), policy); exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
+
+func TestDominatedActionElimination(t *testing.T) {
+	// Seed a large regret for folding a King, which is always dominated by calling.
+	heuristic := func(node cfr.GameTreeNode) []float32 {
+		if node.InfoSet(node.Player()).Key() == "rrb-K" {
+			return []float32{1, 0}
+		}
+
+		return []float32{0.5, 0.5}
+	}
+
+	root := kuhn.NewGame()
+	node := root.GetChild(int(kuhn.Jack)).GetChild(1).GetChild(1)
+	for _, minSamples := range []int{0, 10} {
+		policy := cfr.NewPolicyTable(cfr.DiscountParams{DominanceMinSamples: minSamples})
+		policy.SetHeuristic(heuristic, 20)
+		opt := cfr.New(policy)
+		runCFR(t, opt, policy, 10)
+		strategy := policy.GetPolicy(node).GetStrategy()
+		t.Logf("Strategy with min samples %d: %v", minSamples, strategy)
+		if minSamples == 0 && strategy[0] == 0 {
+			t.Errorf("expected seeded regret to play folding without elimination")
+		} else if minSamples != 0 && strategy[0] != 0 {
+			t.Errorf("expected dominated fold to be eliminated, got %v", strategy)
+		}
+
+		runCFR(t, opt, policy, 10000)
+		if exploitability := cfr.Exploitability(root, policy); exploitability > 0.01 {
+			t.Errorf("expected exploitability near 0, got %v", exploitability)
+		}
+
+		testMarshalRoundTrip(t, policy)
+	}
+}
@@ -1,5 +1,147 @@
 package cfr
 
+import (
+	"sort"
+	"sync"
+)
+
+// SlicePool allocates the temporary float32 slices used during traversal.
+type SlicePool interface {
+	// Alloc returns a zeroed slice of length n.
+	Alloc(n int) []float32
+	// Free returns a slice obtained from Alloc to the pool.
+	Free(s []float32)
+}
+
+// SlicePoolParams configure a FloatSlicePool. An empty SlicePoolParams
+// is valid and corresponds to a single unbounded free list.
+type SlicePoolParams struct {
+	// SizeClasses are the capacities that pooled slices are rounded up to.
+	// Slices longer than the largest size class are not pooled.
+	// If empty, all slices share a single free list and are grown as needed.
+	SizeClasses []int
+	// MaxRetained limits the number of free slices retained in each
+	// size class. Additional freed slices are released to the garbage
+	// collector. Zero means unlimited.
+	MaxRetained int
+	// WarmUp is the number of slices to preallocate in each size class.
+	WarmUp int
+}
+
+// SlicePoolStats are usage statistics for a FloatSlicePool.
+type SlicePoolStats struct {
+	Allocs    int64 // Total number of calls to Alloc.
+	Hits      int64 // Number of calls to Alloc served from a free list.
+	Frees     int64 // Total number of calls to Free.
+	Discarded int64 // Number of freed slices that were not retained.
+	Retained  int   // Number of free slices currently retained.
+}
+
+// FloatSlicePool is a configurable SlicePool. It is safe for concurrent use,
+// so a single pool may be shared by multiple runners.
+type FloatSlicePool struct {
+	mx          sync.Mutex
+	sizeClasses []int
+	maxRetained int
+	classes     []floatSlicePool
+	stats       SlicePoolStats
+}
+
+// NewFloatSlicePool returns a new FloatSlicePool with the given params.
+func NewFloatSlicePool(params SlicePoolParams) *FloatSlicePool {
+	sizeClasses := append([]int(nil), params.SizeClasses...)
+	sort.Ints(sizeClasses)
+	nClasses := len(sizeClasses)
+	if nClasses == 0 {
+		nClasses = 1
+	}
+
+	p := &FloatSlicePool{
+		sizeClasses: sizeClasses,
+		maxRetained: params.MaxRetained,
+		classes:     make([]floatSlicePool, nClasses),
+	}
+
+	for i, size := range sizeClasses {
+		for j := 0; j < params.WarmUp; j++ {
+			p.classes[i].free(make([]float32, 0, size))
+		}
+	}
+
+	p.stats.Retained = len(sizeClasses) * params.WarmUp
+	return p
+}
+
+// Alloc implements SlicePool.
+func (p *FloatSlicePool) Alloc(n int) []float32 {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.stats.Allocs++
+	i := p.allocClass(n)
+	if i < 0 {
+		return make([]float32, n)
+	}
+
+	if len(p.classes[i].pool) > 0 {
+		p.stats.Hits++
+		p.stats.Retained--
+	} else if p.sizeClasses != nil {
+		return make([]float32, n, p.sizeClasses[i])
+	}
+
+	return p.classes[i].alloc(n)
+}
+
+// Free implements SlicePool.
+func (p *FloatSlicePool) Free(s []float32) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.stats.Frees++
+	i := p.freeClass(cap(s))
+	if i < 0 || (p.maxRetained > 0 && len(p.classes[i].pool) >= p.maxRetained) {
+		p.stats.Discarded++
+		return
+	}
+
+	p.classes[i].free(s)
+	p.stats.Retained++
+}
+
+// Stats returns the current usage statistics of the pool.
+func (p *FloatSlicePool) Stats() SlicePoolStats {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return p.stats
+}
+
+// allocClass returns the smallest size class that can hold n elements,
+// or -1 if n is larger than all size classes.
+func (p *FloatSlicePool) allocClass(n int) int {
+	if p.sizeClasses == nil {
+		return 0
+	}
+
+	i := sort.SearchInts(p.sizeClasses, n)
+	if i == len(p.sizeClasses) {
+		return -1
+	}
+
+	return i
+}
+
+// freeClass returns the largest size class that a slice with the given capacity
+// can serve, or -1 if it is smaller than all size classes.
+func (p *FloatSlicePool) freeClass(capacity int) int {
+	if capacity == 0 {
+		return -1
+	} else if p.sizeClasses == nil {
+		return 0
+	}
+
+	i := sort.SearchInts(p.sizeClasses, capacity+1)
+	return i - 1
+}
+
 type floatSlicePool struct {
 	pool [][]float32
 }
@@ -43,3 +185,85 @@ func (p *keyIntMapPool) free(m map[string]int) {
 
 	p.pool = append(p.pool, m)
 }
+
+// arena is an iteration-scoped allocator for the temporary slices and maps
+// used during a traversal.
+//
+// Slices are carved sequentially from a single backing block, so there is no
+// per-allocation garbage. Freeing the most recent allocation (as is natural
+// during a recursive traversal) makes its space immediately reusable; any
+// other outstanding allocations are released all at once by reset, which
+// should be called at the end of each iteration. If the block is exhausted
+// during an iteration, allocations fall back to the heap and the block is
+// grown at the next reset, so that it quickly reaches a steady-state size.
+//
+// Maps must be freed in LIFO order, and are cleared and retained for reuse.
+type arena struct {
+	block    []float32
+	offset   int
+	overflow int
+
+	maps  []map[string]int
+	nMaps int
+}
+
+func (a *arena) alloc(n int) []float32 {
+	if a.offset+n > len(a.block) {
+		a.overflow += n
+		return make([]float32, n)
+	}
+
+	s := a.block[a.offset : a.offset+n : a.offset+n]
+	a.offset += n
+	for i := range s {
+		s[i] = 0 // memclr
+	}
+
+	return s
+}
+
+func (a *arena) free(s []float32) {
+	n := cap(s)
+	if n > 0 && n <= a.offset && &a.block[a.offset-n] == &s[:1][0] {
+		a.offset -= n
+	}
+}
+
+func (a *arena) allocMap() map[string]int {
+	if a.nMaps < len(a.maps) {
+		m := a.maps[a.nMaps]
+		a.nMaps++
+		return m
+	}
+
+	m := make(map[string]int)
+	a.maps = append(a.maps, m)
+	a.nMaps++
+	return m
+}
+
+func (a *arena) freeMap(m map[string]int) {
+	for k := range m {
+		delete(m, k)
+	}
+
+	if a.nMaps > 0 {
+		a.nMaps--
+	}
+}
+
+func (a *arena) reset() {
+	a.offset = 0
+	if a.overflow > 0 {
+		a.block = make([]float32, 2*(len(a.block)+a.overflow))
+		a.overflow = 0
+	}
+
+	for _, m := range a.maps[:a.nMaps] {
+		for k := range m {
+			delete(m, k)
+		}
+	}
+
+	a.nMaps = 0
+}
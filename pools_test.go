@@ -21,3 +21,38 @@ func BenchmarkKeyIntMapPoolAllocFree(b *testing.B) {
 		pool.free(v)
 	}
 }
+
+func BenchmarkArenaAllocFree(b *testing.B) {
+	a := &arena{}
+	for i := 0; i < b.N; i++ {
+		v := a.alloc(10)
+		m := a.allocMap()
+		a.freeMap(m)
+		a.free(v)
+		a.reset()
+	}
+}
+
+func TestFloatSlicePool_SizeClasses(t *testing.T) {
+	pool := NewFloatSlicePool(SlicePoolParams{
+		SizeClasses: []int{8, 2, 4},
+		MaxRetained: 1,
+		WarmUp:      1,
+	})
+
+	v := pool.Alloc(3)
+	if len(v) != 3 || cap(v) != 4 {
+		t.Errorf("expected len=3 cap=4, got len=%d cap=%d", len(v), cap(v))
+	}
+
+	w := pool.Alloc(3)
+	pool.Free(v)
+	pool.Free(w)
+	large := pool.Alloc(100)
+	pool.Free(large)
+
+	expected := SlicePoolStats{Allocs: 3, Hits: 1, Frees: 3, Discarded: 2, Retained: 3}
+	if stats := pool.Stats(); stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+}
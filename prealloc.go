@@ -0,0 +1,115 @@
+package cfr
+
+import (
+	"github.com/timpalpant/go-cfr/internal/policy"
+)
+
+// TreeStats are (estimated) statistics about a game tree that may be used
+// to preallocate memory before training.
+type TreeStats struct {
+	NumInfoSets  int // Number of distinct player InfoSets.
+	MaxBranching int // Max number of children of any player node.
+	MaxDepth     int // Max depth of any node, with the root at depth 0.
+	KeyBytes     int // Total length of the keys of all distinct InfoSets.
+}
+
+// Approximate sizes (in bytes) of the data structures in a PolicyTable.
+const (
+	policyStructSize        = 6*24 + 8 // Slice headers and current strategy weight.
+	builtinMapEntryOverhead = 48       // String header, pointer, and bucket overhead.
+	compactMapEntryOverhead = 40       // Slot (at max load factor) and value pointer.
+	mayNeedUpdateOverhead   = 16
+	encodedEntryOverhead    = 16 // Gob framing of each key and policy.
+)
+
+// PolicyTableMemory returns the approximate number of bytes of memory used by a
+// PolicyTable with the given params once it contains all InfoSets in the tree.
+// It assumes that every InfoSet has MaxBranching actions, and so is an
+// overestimate for trees with varying branching factor.
+func (s TreeStats) PolicyTableMemory(params DiscountParams, compact bool) int64 {
+	perInfoSet := policyStructSize + 4*numPolicyVectors(params)*s.MaxBranching + mayNeedUpdateOverhead
+	if compact {
+		perInfoSet += compactMapEntryOverhead
+	} else {
+		perInfoSet += builtinMapEntryOverhead
+	}
+
+	return int64(s.NumInfoSets)*int64(perInfoSet) + int64(s.KeyBytes)
+}
+
+// PolicyTableEncodedSize returns the approximate number of bytes needed
+// to serialize a PolicyTable with the given params once it contains all
+// InfoSets in the tree, e.g. in a checkpoint or an on-disk store.
+// Like PolicyTableMemory, it is an overestimate for trees with varying
+// branching factor.
+func (s TreeStats) PolicyTableEncodedSize(params DiscountParams) int64 {
+	perInfoSet := 4*(numPolicyVectors(params)*s.MaxBranching+1) + encodedEntryOverhead
+	if params.CompensatedSummation || params.UsesPrediction() || params.DominanceMinSamples > 0 {
+		perInfoSet += 12 // Format header.
+	}
+
+	if params.DominanceMinSamples > 0 {
+		perInfoSet += 4 // Number of samples.
+	}
+
+	return int64(s.NumInfoSets)*int64(perInfoSet) + int64(s.KeyBytes)
+}
+
+// numPolicyVectors returns the number of float32 vectors of length
+// nActions that are kept for each InfoSet.
+func numPolicyVectors(params DiscountParams) int {
+	n := 4 // Current strategy, baseline, regret sum, strategy sum.
+	if params.CompensatedSummation {
+		n += 2
+	}
+
+	if params.UsesPrediction() {
+		n++ // Prediction.
+	}
+
+	if params.DominanceMinSamples > 0 {
+		n += 2 // Regret bounds.
+	}
+
+	return n
+}
+
+// SlicePoolParams returns parameters for a FloatSlicePool with a size class
+// for each power of two up to the max branching factor, warmed up with
+// enough slices for a traversal to the max depth of the tree.
+func (s TreeStats) SlicePoolParams() SlicePoolParams {
+	var sizeClasses []int
+	for size := 1; ; size *= 2 {
+		sizeClasses = append(sizeClasses, size)
+		if size >= s.MaxBranching {
+			break
+		}
+	}
+
+	return SlicePoolParams{
+		SizeClasses: sizeClasses,
+		WarmUp:      2 * (s.MaxDepth + 1),
+	}
+}
+
+// Preallocate resizes the PolicyTable to hold the number of InfoSets
+// in the given tree statistics without incremental growth.
+func (pt *PolicyTable) Preallocate(stats TreeStats) {
+	if stats.NumInfoSets <= pt.policiesByKey.Len() {
+		return
+	}
+
+	policiesByKey := pt.newMap(stats.NumInfoSets)
+	pt.policiesByKey.Range(func(key string, p *policy.Policy) bool {
+		policiesByKey.Put(key, p)
+		return true
+	})
+
+	mayNeedUpdate := make(map[*policy.Policy]struct{}, stats.NumInfoSets)
+	for p := range pt.mayNeedUpdate {
+		mayNeedUpdate[p] = struct{}{}
+	}
+
+	pt.policiesByKey = policiesByKey
+	pt.mayNeedUpdate = mayNeedUpdate
+}
@@ -0,0 +1,233 @@
+package cfr
+
+import (
+	"sync"
+)
+
+// DefaultPrefetchConcurrency is the default maximum number of policies
+// that a PrefetchingProfile requests concurrently in the background.
+const DefaultPrefetchConcurrency = 16
+
+// Prefetcher is an optional interface that may be implemented by a
+// StrategyProfile to be notified that the policies for the children of
+// a node will be requested soon.
+type Prefetcher interface {
+	// Prefetch is called with the children of a node before they are traversed.
+	// It returns a function that is called once they have been, after which any
+	// policies that were prefetched but not requested must be discarded, and the
+	// children must no longer be accessed.
+	Prefetch(children []GameTreeNode) (release func())
+}
+
+// prefetchedChildren are the children of a node, expanded in order to prefetch
+// their policies if the strategy profile is a Prefetcher. Runners get children
+// from it rather than from the node, so that the same children are traversed
+// rather than being built again.
+type prefetchedChildren struct {
+	node     GameTreeNode
+	children []GameTreeNode
+	release  func()
+}
+
+// prefetch notifies the strategy profile, if it is a Prefetcher, that the
+// policies for the children of node will be requested soon. Close must be
+// called on the result once they have been traversed. Children of nodes that
+// share state cannot be opened concurrently, and are not prefetched.
+func prefetch(profile StrategyProfile, node GameTreeNode) prefetchedChildren {
+	p, ok := profile.(Prefetcher)
+	if !ok {
+		return prefetchedChildren{node: node}
+	}
+
+	if s, ok := node.(SharedStateNode); ok && s.SharesState() {
+		return prefetchedChildren{node: node}
+	}
+
+	children := make([]GameTreeNode, node.NumChildren())
+	for i := range children {
+		children[i] = node.GetChild(i)
+	}
+
+	return prefetchedChildren{
+		node:     node,
+		children: children,
+		release:  p.Prefetch(children),
+	}
+}
+
+// GetChild returns the ith child of the node. Each child may only be gotten once.
+func (pc prefetchedChildren) GetChild(i int) GameTreeNode {
+	if pc.children == nil {
+		return pc.node.GetChild(i)
+	}
+
+	child := pc.children[i]
+	pc.children[i] = nil
+	return child
+}
+
+// Close releases the prefetched policies, and closes the
+// children that were expanded but never gotten by the runner.
+func (pc prefetchedChildren) Close() {
+	if pc.release != nil {
+		pc.release()
+	}
+
+	for _, child := range pc.children {
+		if child != nil {
+			child.Close()
+		}
+	}
+}
+
+// PrefetchingProfile wraps a StrategyProfile whose GetPolicy may block on I/O,
+// such as a profile that is stored on disk or behind a remote service.
+// When the children of a node are prefetched, their policies are requested
+// from the underlying profile in the background, so that the latency of loading
+// later children is hidden behind the traversal of the first.
+//
+// Up to DefaultPrefetchConcurrency policies are requested concurrently (see
+// SetMaxConcurrency), in addition to those requested by the traversal itself,
+// so the underlying profile must be safe for concurrent use unless SetSerialized
+// is called. Prefetched policies are only kept until the children of the node
+// for which they were prefetched have been traversed, so a child that is pruned
+// or deferred by the runner does not leave behind a stale policy. Before
+// prefetching, the type, number of children, and InfoSet of each child are
+// computed in the calling goroutine, and thereafter must be safe to read
+// concurrently from the prefetching goroutines.
+type PrefetchingProfile struct {
+	StrategyProfile
+
+	// Bounds the number of concurrent background requests.
+	sem chan struct{}
+	// Serializes all calls to the underlying profile, if set.
+	serialized bool
+	profileMx  sync.Mutex
+
+	mx      sync.Mutex
+	pending map[string]*pendingPolicy
+}
+
+// pendingPolicy is a prefetched policy. The request is made at most once,
+// either in the background or by the traversal if it gets there first,
+// and is skipped if the policy is discarded before it was started.
+type pendingPolicy struct {
+	once   sync.Once
+	policy NodePolicy
+}
+
+// NewPrefetchingProfile returns a new PrefetchingProfile wrapping the given profile.
+func NewPrefetchingProfile(profile StrategyProfile) *PrefetchingProfile {
+	return &PrefetchingProfile{
+		StrategyProfile: profile,
+		sem:             make(chan struct{}, DefaultPrefetchConcurrency),
+		pending:         make(map[string]*pendingPolicy),
+	}
+}
+
+// SetMaxConcurrency sets the maximum number of policies requested concurrently
+// in the background. It must be called before the profile is used.
+func (p *PrefetchingProfile) SetMaxConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	p.sem = make(chan struct{}, n)
+}
+
+// SetSerialized sets whether all calls to the underlying profile, including
+// those made by the traversal itself, are serialized, for profiles that are
+// not safe for concurrent use. Latency is then only hidden behind the work of
+// the traversal, not behind other requests.
+func (p *PrefetchingProfile) SetSerialized(serialized bool) {
+	p.serialized = serialized
+}
+
+// Prefetch implements Prefetcher. It asynchronously requests the policies
+// of all children that are player nodes with more than one action.
+func (p *PrefetchingProfile) Prefetch(children []GameTreeNode) (release func()) {
+	started := make(map[string]*pendingPolicy)
+	for _, child := range children {
+		if child.Type() != PlayerNodeType || child.NumChildren() <= 1 {
+			continue
+		}
+
+		key := child.InfoSet(child.Player()).Key()
+		p.mx.Lock()
+		if _, ok := p.pending[key]; ok {
+			p.mx.Unlock()
+			continue
+		}
+
+		pp := &pendingPolicy{}
+		p.pending[key] = pp
+		p.mx.Unlock()
+
+		started[key] = pp
+		go func(child GameTreeNode) {
+			p.sem <- struct{}{}
+			defer func() { <-p.sem }()
+			p.fetch(pp, child)
+		}(child)
+	}
+
+	return func() { p.discard(started) }
+}
+
+// GetPolicy implements StrategyProfile. If the policy for the node was
+// prefetched, it waits for the pending request to complete, or makes
+// the request itself if it has not yet started.
+func (p *PrefetchingProfile) GetPolicy(node GameTreeNode) NodePolicy {
+	key := node.InfoSet(node.Player()).Key()
+	p.mx.Lock()
+	pp, ok := p.pending[key]
+	if ok {
+		delete(p.pending, key)
+	}
+	p.mx.Unlock()
+
+	if !ok {
+		return p.getPolicy(node)
+	}
+
+	p.fetch(pp, node)
+	if pp.policy == nil {
+		// Discarded by another traversal before it was requested.
+		return p.getPolicy(node)
+	}
+
+	return pp.policy
+}
+
+// fetch requests the policy for node into pp, unless it has already been
+// requested or discarded, and waits for any request in progress to complete.
+func (p *PrefetchingProfile) fetch(pp *pendingPolicy, node GameTreeNode) {
+	pp.once.Do(func() { pp.policy = p.getPolicy(node) })
+}
+
+// getPolicy requests the policy for node from the underlying profile.
+func (p *PrefetchingProfile) getPolicy(node GameTreeNode) NodePolicy {
+	if p.serialized {
+		p.profileMx.Lock()
+		defer p.profileMx.Unlock()
+	}
+
+	return p.StrategyProfile.GetPolicy(node)
+}
+
+// discard removes the given prefetched policies that have not been requested
+// by the traversal from the pending set, cancels those that have not yet been
+// requested from the underlying profile, and waits for those in progress.
+func (p *PrefetchingProfile) discard(prefetched map[string]*pendingPolicy) {
+	p.mx.Lock()
+	for key, pp := range prefetched {
+		if p.pending[key] == pp {
+			delete(p.pending, key)
+		}
+	}
+	p.mx.Unlock()
+
+	for _, pp := range prefetched {
+		pp.once.Do(func() {})
+	}
+}
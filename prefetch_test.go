@@ -0,0 +1,87 @@
+package cfr_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+// slowStore is a StrategyProfile whose GetPolicy is safe for concurrent use
+// but slow, as for a store on disk, and which records how it was called.
+type slowStore struct {
+	*cfr.PolicyTable
+	latency time.Duration
+
+	mx            sync.Mutex
+	calls         int
+	active        int
+	maxConcurrent int
+}
+
+func (s *slowStore) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
+	s.mx.Lock()
+	s.calls++
+	s.active++
+	if s.active > s.maxConcurrent {
+		s.maxConcurrent = s.active
+	}
+	s.mx.Unlock()
+
+	time.Sleep(s.latency)
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.active--
+	return s.PolicyTable.GetPolicy(node)
+}
+
+func runPrefetching(t *testing.T, serialized bool) (*slowStore, time.Duration) {
+	root := kuhn.NewGame()
+	store := &slowStore{
+		PolicyTable: cfr.NewPolicyTable(cfr.DiscountParams{}),
+		latency:     time.Millisecond,
+	}
+	policy := cfr.NewPrefetchingProfile(store)
+	policy.SetSerialized(serialized)
+	opt := cfr.New(policy)
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+	elapsed := time.Since(start)
+
+	// No prefetched policies outlive the traversal, so every
+	// request after it goes to the underlying profile.
+	node := root.GetChild(0)
+	calls := store.calls
+	policy.GetPolicy(node)
+	if store.calls != calls+1 {
+		t.Errorf("expected policy to be requested from the underlying profile after traversal")
+	}
+
+	return store, elapsed
+}
+
+func TestPrefetchingProfile(t *testing.T) {
+	serialized, serializedTime := runPrefetching(t, true)
+	if serialized.maxConcurrent != 1 {
+		t.Errorf("expected serialized calls to the underlying profile, got %d concurrent calls",
+			serialized.maxConcurrent)
+	}
+
+	concurrent, concurrentTime := runPrefetching(t, false)
+	t.Logf("serialized: %v, concurrent: %v (max %d concurrent calls)",
+		serializedTime, concurrentTime, concurrent.maxConcurrent)
+	if concurrent.maxConcurrent <= 1 {
+		t.Errorf("expected concurrent calls to the underlying profile to overlap")
+	}
+
+	if concurrentTime >= serializedTime {
+		t.Errorf("expected concurrent fetches to be faster than serialized (%v), took %v",
+			serializedTime, concurrentTime)
+	}
+}
@@ -0,0 +1,161 @@
+// Package profiling captures CPU, heap and execution trace profiles over a
+// window of training iterations, so that a long-running job can be profiled
+// without attaching an external profiler.
+package profiling
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// Params describes which profiles to capture, and when.
+type Params struct {
+	// Profiles are captured for iterations in [StartIter, EndIter).
+	StartIter int
+	EndIter   int
+	// Directory in which profiles are written.
+	Dir string
+
+	CPU   bool // Capture a CPU profile over the window.
+	Heap  bool // Write a heap profile at the end of the window.
+	Trace bool // Capture an execution trace over the window.
+}
+
+// Profiler starts and stops runtime profiles as training
+// enters and leaves the configured window of iterations.
+//
+// Profiler is not safe for concurrent use.
+type Profiler struct {
+	params Params
+
+	running   bool
+	done      bool
+	cpuFile   *os.File
+	traceFile *os.File
+}
+
+// New returns a new Profiler with the given params.
+func New(params Params) *Profiler {
+	return &Profiler{params: params}
+}
+
+// Observe must be called with the current iteration (e.g. the Iter() of the
+// strategy profile) before each iteration of training. Profiling is started
+// when iter reaches StartIter and stopped when it reaches EndIter.
+func (p *Profiler) Observe(iter int) error {
+	if p.done {
+		return nil
+	}
+
+	if !p.running && iter >= p.params.StartIter && iter < p.params.EndIter {
+		return p.start()
+	}
+
+	if p.running && iter >= p.params.EndIter {
+		return p.stop()
+	}
+
+	return nil
+}
+
+// Close stops any profiles that are still being captured, for example
+// if training ended before the end of the window.
+func (p *Profiler) Close() error {
+	if !p.running {
+		return nil
+	}
+
+	return p.stop()
+}
+
+func (p *Profiler) start() error {
+	if p.params.CPU {
+		f, err := os.Create(p.path("cpu", "pprof"))
+		if err != nil {
+			return err
+		}
+
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return err
+		}
+
+		p.cpuFile = f
+	}
+
+	if p.params.Trace {
+		f, err := os.Create(p.path("trace", "out"))
+		if err != nil {
+			p.stopCPU()
+			return err
+		}
+
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			p.stopCPU()
+			return err
+		}
+
+		p.traceFile = f
+	}
+
+	p.running = true
+	return nil
+}
+
+func (p *Profiler) stop() error {
+	p.running = false
+	p.done = true
+
+	err := p.stopCPU()
+	if p.traceFile != nil {
+		trace.Stop()
+		if closeErr := p.traceFile.Close(); err == nil {
+			err = closeErr
+		}
+		p.traceFile = nil
+	}
+
+	if p.params.Heap {
+		if heapErr := p.writeHeapProfile(); err == nil {
+			err = heapErr
+		}
+	}
+
+	return err
+}
+
+func (p *Profiler) stopCPU() error {
+	if p.cpuFile == nil {
+		return nil
+	}
+
+	pprof.StopCPUProfile()
+	err := p.cpuFile.Close()
+	p.cpuFile = nil
+	return err
+}
+
+func (p *Profiler) writeHeapProfile() error {
+	f, err := os.Create(p.path("heap", "pprof"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	runtime.GC() // Get up-to-date statistics.
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func (p *Profiler) path(kind, ext string) string {
+	name := fmt.Sprintf("%s-%d-%d.%s", kind, p.params.StartIter, p.params.EndIter, ext)
+	return filepath.Join(p.params.Dir, name)
+}
@@ -0,0 +1,57 @@
+package profiling
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestProfiler(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cfr-profiling-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	p := New(Params{
+		StartIter: 5,
+		EndIter:   10,
+		Dir:       tmpDir,
+		CPU:       true,
+		Heap:      true,
+		Trace:     true,
+	})
+
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	for i := 0; i < 20; i++ {
+		if err := p.Observe(policy.Iter()); err != nil {
+			t.Fatal(err)
+		}
+
+		if i == 7 && !p.running {
+			t.Error("expected profiler to be running within the window")
+		}
+
+		opt.Run(root)
+		policy.Update()
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"cpu-5-10.pprof", "heap-5-10.pprof", "trace-5-10.out"} {
+		fi, err := os.Stat(filepath.Join(tmpDir, name))
+		if err != nil {
+			t.Error(err)
+		} else if fi.Size() == 0 {
+			t.Errorf("expected non-empty profile: %s", name)
+		}
+	}
+}
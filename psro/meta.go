@@ -0,0 +1,108 @@
+package psro
+
+// MetaSolver computes a meta-strategy for each player: a probability distribution
+// over their strategies in the population, given the payoffs of the meta-game.
+// payoffs[i][j] is the expected utility of player 0 when they play their ith
+// strategy and player 1 plays their jth strategy; the game is zero-sum.
+type MetaSolver interface {
+	Solve(payoffs [][]float64) [2][]float64
+}
+
+// UniformMetaSolver weights all strategies in the population equally.
+// With exact best responses, PSRO is then fictitious play over the population.
+type UniformMetaSolver struct{}
+
+// Solve implements MetaSolver.
+func (UniformMetaSolver) Solve(payoffs [][]float64) [2][]float64 {
+	return [2][]float64{
+		uniform(len(payoffs)),
+		uniform(len(payoffs[0])),
+	}
+}
+
+// RegretMatchingMetaSolver approximates a Nash equilibrium of the meta-game by
+// the average strategies of regret matching+ in self-play. With exact best
+// responses, PSRO is then the Double Oracle algorithm (McMahan et al., 2003).
+type RegretMatchingMetaSolver struct {
+	// Number of iterations of regret matching.
+	Iterations int
+}
+
+// Solve implements MetaSolver.
+func (s RegretMatchingMetaSolver) Solve(payoffs [][]float64) [2][]float64 {
+	n0, n1 := len(payoffs), len(payoffs[0])
+	regrets := [2][]float64{make([]float64, n0), make([]float64, n1)}
+	sums := [2][]float64{make([]float64, n0), make([]float64, n1)}
+	values := [2][]float64{make([]float64, n0), make([]float64, n1)}
+	for t := 1; t <= s.Iterations; t++ {
+		x := regretMatching(regrets[0])
+		y := regretMatching(regrets[1])
+		for i := range values[0] {
+			values[0][i] = 0
+			for j, p := range y {
+				values[0][i] += p * payoffs[i][j]
+			}
+		}
+
+		for j := range values[1] {
+			values[1][j] = 0
+			for i, p := range x {
+				values[1][j] -= p * payoffs[i][j]
+			}
+		}
+
+		for player, strategy := range [2][]float64{x, y} {
+			var ev float64
+			for i, p := range strategy {
+				ev += p * values[player][i]
+			}
+
+			for i, v := range values[player] {
+				regrets[player][i] = max(regrets[player][i]+v-ev, 0)
+				// Linear averaging converges faster with regret matching+.
+				sums[player][i] += float64(t) * strategy[i]
+			}
+		}
+	}
+
+	return [2][]float64{normalize(sums[0]), normalize(sums[1])}
+}
+
+// regretMatching returns the strategy proportional to the positive regrets.
+func regretMatching(regrets []float64) []float64 {
+	result := make([]float64, len(regrets))
+	for i, r := range regrets {
+		result[i] = max(r, 0)
+	}
+
+	return normalize(result)
+}
+
+// normalize scales x to sum to one in place, or sets it to
+// uniform if it sums to zero, and returns it.
+func normalize(x []float64) []float64 {
+	var total float64
+	for _, xi := range x {
+		total += xi
+	}
+
+	if total == 0 {
+		copy(x, uniform(len(x)))
+		return x
+	}
+
+	for i := range x {
+		x[i] /= total
+	}
+
+	return x
+}
+
+func uniform(n int) []float64 {
+	result := make([]float64, n)
+	for i := range result {
+		result[i] = 1.0 / float64(n)
+	}
+
+	return result
+}
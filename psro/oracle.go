@@ -0,0 +1,77 @@
+package psro
+
+import (
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/rollout"
+)
+
+// Oracle computes a (possibly approximate) best response for player in the
+// game rooted at root, when the opponent plays the given strategy.
+type Oracle interface {
+	BestResponse(root cfr.GameTreeNode, player int, opponent rollout.Strategy) rollout.Strategy
+}
+
+// BestResponseOracle computes exact best responses with cfr.ComputeBestResponse,
+// which requires a full traversal of the game tree.
+type BestResponseOracle struct{}
+
+// BestResponse implements Oracle.
+func (BestResponseOracle) BestResponse(root cfr.GameTreeNode, player int, opponent rollout.Strategy) rollout.Strategy {
+	br := cfr.ComputeBestResponse(root, player, opponent)
+	return func(node cfr.GameTreeNode) []float32 {
+		strategy := make([]float32, node.NumChildren())
+		strategy[br.Action(node)] = 1.0
+		return strategy
+	}
+}
+
+// CFROracle computes approximate best responses with external sampling MCCFR,
+// in which the opponent plays their fixed strategy and only the regrets of player
+// are updated. The best response is the average strategy of player after the given
+// number of iterations, which is accumulated on the traversals of the opponent.
+type CFROracle struct {
+	Iterations int
+}
+
+// BestResponse implements Oracle.
+func (o CFROracle) BestResponse(root cfr.GameTreeNode, player int, opponent rollout.Strategy) rollout.Strategy {
+	pt := cfr.NewPolicyTable(cfr.DiscountParams{})
+	profile := &fixedOpponentProfile{pt, player, opponent}
+	opt := cfr.NewExternalSampling(profile)
+	for i := 0; i < o.Iterations; i++ {
+		opt.Run(root)
+		pt.Update()
+	}
+
+	return rollout.AverageStrategy(pt)
+}
+
+// fixedOpponentProfile is a cfr.StrategyProfile in which the opponent
+// of player plays a fixed strategy.
+type fixedOpponentProfile struct {
+	*cfr.PolicyTable
+	player   int
+	opponent rollout.Strategy
+}
+
+func (p *fixedOpponentProfile) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
+	policy := p.PolicyTable.GetPolicy(node)
+	if node.Player() == p.player {
+		return policy
+	}
+
+	return &fixedPolicy{policy, p.opponent(node)}
+}
+
+// fixedPolicy is a cfr.NodePolicy that always plays the given strategy.
+type fixedPolicy struct {
+	cfr.NodePolicy
+	strategy []float32
+}
+
+func (p *fixedPolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {}
+func (p *fixedPolicy) AddStrategyWeight(w float32)                                    {}
+func (p *fixedPolicy) GetStrategy() []float32                                         { return p.strategy }
+func (p *fixedPolicy) CopyStrategy(dst []float32)                                     { copy(dst, p.strategy) }
+func (p *fixedPolicy) GetAverageStrategy() []float32                                  { return p.strategy }
+func (p *fixedPolicy) CopyAverageStrategy(dst []float32)                              { copy(dst, p.strategy) }
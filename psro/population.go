@@ -0,0 +1,154 @@
+package psro
+
+import (
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/rollout"
+)
+
+// Population is the set of strategies found so far for each player.
+type Population struct {
+	strategies [2][]rollout.Strategy
+}
+
+// Add adds a strategy for player to the population, and returns its index.
+func (p *Population) Add(player int, s rollout.Strategy) int {
+	p.strategies[player] = append(p.strategies[player], s)
+	return len(p.strategies[player]) - 1
+}
+
+// Len returns the number of strategies of player in the population.
+func (p *Population) Len(player int) int {
+	return len(p.strategies[player])
+}
+
+// Strategy returns the ith strategy of player.
+func (p *Population) Strategy(player, i int) rollout.Strategy {
+	return p.strategies[player][i]
+}
+
+// Mixture returns the behavioral strategy of player that is equivalent to playing
+// the ith strategy of the population with probability weights[i], for the game
+// rooted at root. At each InfoSet, the strategies are weighted by the probability
+// with which they reach it, so the mixture is computed with a traversal of the
+// entire game tree. InfoSets that no weighted strategy reaches are uniform.
+func (p *Population) Mixture(root cfr.GameTreeNode, player int, weights []float64) rollout.Strategy {
+	strategies := p.strategies[player][:len(weights)]
+	reach := make([]float64, len(strategies))
+	copy(reach, weights)
+	m := &mixer{
+		player:     player,
+		strategies: strategies,
+		mixed:      make(map[string][]float32),
+	}
+
+	m.walk(root, reach)
+	return func(node cfr.GameTreeNode) []float32 {
+		if s, ok := m.mixed[node.InfoSet(node.Player()).Key()]; ok {
+			return s
+		}
+
+		return rollout.Uniform(node)
+	}
+}
+
+type mixer struct {
+	player     int
+	strategies []rollout.Strategy
+	// Map of InfoSet key -> mixed strategy.
+	mixed map[string][]float32
+}
+
+// walk traverses the subtree rooted at node, where reach is the weight of each
+// strategy times the probability with which it reaches node.
+func (m *mixer) walk(node cfr.GameTreeNode, reach []float64) {
+	if allZero(reach) {
+		// No strategy reaches the subtree, so all of its InfoSets are uniform.
+		node.Close()
+		return
+	}
+
+	switch node.Type() {
+	case cfr.TerminalNodeType:
+	case cfr.ChanceNodeType:
+		for i := 0; i < node.NumChildren(); i++ {
+			m.walk(node.GetChild(i), reach)
+		}
+	default:
+		if node.Player() != m.player {
+			for i := 0; i < node.NumChildren(); i++ {
+				m.walk(node.GetChild(i), reach)
+			}
+
+			break
+		}
+
+		strategies := make([][]float32, len(m.strategies))
+		for k, s := range m.strategies {
+			if reach[k] > 0 {
+				strategies[k] = s(node)
+			}
+		}
+
+		// With perfect recall, the reach of each strategy is the
+		// same for all nodes in the InfoSet.
+		key := node.InfoSet(m.player).Key()
+		if _, ok := m.mixed[key]; !ok {
+			m.mixed[key] = mix(strategies, reach, node.NumChildren())
+		}
+
+		childReach := make([]float64, len(reach))
+		for i := 0; i < node.NumChildren(); i++ {
+			for k, s := range strategies {
+				childReach[k] = 0
+				if s != nil {
+					childReach[k] = reach[k] * float64(s[i])
+				}
+			}
+
+			m.walk(node.GetChild(i), childReach)
+		}
+	}
+
+	node.Close()
+}
+
+// mix returns the average of the given strategies, weighted by reach.
+func mix(strategies [][]float32, reach []float64, nActions int) []float32 {
+	result := make([]float32, nActions)
+	var total float64
+	for k, s := range strategies {
+		if s == nil {
+			continue
+		}
+
+		for i, p := range s {
+			result[i] += float32(reach[k] * float64(p))
+		}
+
+		total += reach[k]
+	}
+
+	if total == 0 {
+		for i := range result {
+			result[i] = 1.0 / float32(nActions)
+		}
+
+		return result
+	}
+
+	for i := range result {
+		result[i] /= float32(total)
+	}
+
+	return result
+}
+
+func allZero(x []float64) bool {
+	for _, xi := range x {
+		if xi != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,138 @@
+// Package psro implements Policy-Space Response Oracles (Lanctot et al., 2017),
+// which generalizes the Double Oracle algorithm to extensive-form games.
+//
+// PSRO maintains a population of strategies for each player. On each iteration,
+// a MetaSolver computes a meta-strategy (a distribution over the population) for
+// each player from the payoffs of the meta-game among the strategies, and an Oracle
+// computes a best response of each player to the opponent's meta-strategy, which
+// is added to the population. With exact best responses and a Nash meta-solver,
+// the meta-strategies converge to a Nash equilibrium of the game.
+//
+// Meta-game payoffs, meta-strategies and exploitability are computed exactly with
+// traversals of the entire game tree.
+package psro
+
+import (
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/rollout"
+)
+
+// Solver is the PSRO driver.
+type Solver struct {
+	root   cfr.GameTreeNode
+	oracle Oracle
+	meta   MetaSolver
+
+	population Population
+	// payoffs[i][j] is the expected utility of player 0 when they play their
+	// ith strategy and player 1 plays their jth strategy.
+	payoffs [][]float64
+	// The meta-strategy of each player.
+	weights [2][]float64
+}
+
+// New returns a new PSRO Solver for the game rooted at root, whose population
+// initially contains the given strategy for each player (for example, rollout.Uniform).
+func New(root cfr.GameTreeNode, oracle Oracle, meta MetaSolver, initial [2]rollout.Strategy) *Solver {
+	s := &Solver{
+		root:   root,
+		oracle: oracle,
+		meta:   meta,
+	}
+
+	s.population.Add(0, initial[0])
+	s.population.Add(1, initial[1])
+	s.payoffs = [][]float64{{expectedUtility(root, initial[0], initial[1])}}
+	s.weights = meta.Solve(s.payoffs)
+	return s
+}
+
+// Population returns the population of strategies.
+func (s *Solver) Population() *Population {
+	return &s.population
+}
+
+// Payoffs returns the payoffs of the meta-game for player 0.
+// The returned slices must not be modified.
+func (s *Solver) Payoffs() [][]float64 {
+	return s.payoffs
+}
+
+// MetaStrategy returns the current meta-strategy of player: the probability
+// with which they play each strategy in the population.
+func (s *Solver) MetaStrategy(player int) []float64 {
+	return s.weights[player]
+}
+
+// Strategy returns the behavioral strategy of player that is equivalent
+// to their current meta-strategy.
+func (s *Solver) Strategy(player int) rollout.Strategy {
+	return s.population.Mixture(s.root, player, s.weights[player])
+}
+
+// Iterate computes a best response of each player to the current meta-strategy
+// of the other, adds them to the population, and solves the extended meta-game.
+func (s *Solver) Iterate() {
+	var brs [2]rollout.Strategy
+	for player := range brs {
+		opponent := s.Strategy(1 - player)
+		brs[player] = s.oracle.BestResponse(s.root, player, opponent)
+	}
+
+	i := s.population.Add(0, brs[0])
+	j := s.population.Add(1, brs[1])
+
+	// Extend the payoff matrix with a new row and column.
+	row := make([]float64, j+1)
+	for k := 0; k <= j; k++ {
+		row[k] = expectedUtility(s.root, brs[0], s.population.Strategy(1, k))
+	}
+
+	for k := 0; k < i; k++ {
+		s.payoffs[k] = append(s.payoffs[k], expectedUtility(s.root, s.population.Strategy(0, k), brs[1]))
+	}
+
+	s.payoffs = append(s.payoffs, row)
+	s.weights = s.meta.Solve(s.payoffs)
+}
+
+// Exploitability returns the average over both players of the value of an exact
+// best response to the other player's current meta-strategy.
+func (s *Solver) Exploitability() float64 {
+	strategies := [2]rollout.Strategy{s.Strategy(0), s.Strategy(1)}
+	strategy := func(node cfr.GameTreeNode) []float32 {
+		return strategies[node.Player()](node)
+	}
+
+	br0 := cfr.ComputeBestResponse(s.root, 0, strategy)
+	br1 := cfr.ComputeBestResponse(s.root, 1, strategy)
+	return (br0.Value() + br1.Value()) / 2
+}
+
+// expectedUtility returns the expected utility of player 0 from node
+// when player 0 plays s0 and player 1 plays s1.
+func expectedUtility(node cfr.GameTreeNode, s0, s1 rollout.Strategy) float64 {
+	var ev float64
+	switch node.Type() {
+	case cfr.TerminalNodeType:
+		ev = node.Utility(0)
+	case cfr.ChanceNodeType:
+		for i := 0; i < node.NumChildren(); i++ {
+			ev += node.GetChildProbability(i) * expectedUtility(node.GetChild(i), s0, s1)
+		}
+	default:
+		strategy := s0
+		if node.Player() == 1 {
+			strategy = s1
+		}
+
+		for i, p := range strategy(node) {
+			if p > 0 {
+				ev += float64(p) * expectedUtility(node.GetChild(i), s0, s1)
+			}
+		}
+	}
+
+	node.Close()
+	return ev
+}
@@ -0,0 +1,77 @@
+package psro
+
+import (
+	"math"
+	"testing"
+
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/rollout"
+)
+
+func TestDoubleOracle_Kuhn(t *testing.T) {
+	root := kuhn.NewGame()
+	uniform := [2]rollout.Strategy{rollout.Uniform, rollout.Uniform}
+	s := New(root, BestResponseOracle{}, RegretMatchingMetaSolver{Iterations: 10000}, uniform)
+	initial := s.Exploitability()
+	for i := 0; i < 20; i++ {
+		s.Iterate()
+	}
+
+	exploitability := s.Exploitability()
+	t.Logf("Exploitability: initial = %.4f, after 20 iterations = %.4f", initial, exploitability)
+	if exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+
+	if n := s.Population().Len(0); n != 21 {
+		t.Errorf("expected 21 strategies for player 0, got %d", n)
+	}
+
+	// The value of the meta-game approaches the value of Kuhn poker.
+	var value float64
+	payoffs := s.Payoffs()
+	for i, x := range s.MetaStrategy(0) {
+		for j, y := range s.MetaStrategy(1) {
+			value += x * y * payoffs[i][j]
+		}
+	}
+
+	if math.Abs(value+1.0/18) > 0.01 {
+		t.Errorf("expected meta-game value near -1/18, got %v", value)
+	}
+}
+
+func TestPSRO_CFROracle(t *testing.T) {
+	root := kuhn.NewGame()
+	uniform := [2]rollout.Strategy{rollout.Uniform, rollout.Uniform}
+	s := New(root, CFROracle{Iterations: 2000}, UniformMetaSolver{}, uniform)
+	initial := s.Exploitability()
+	for i := 0; i < 10; i++ {
+		s.Iterate()
+	}
+
+	exploitability := s.Exploitability()
+	t.Logf("Exploitability: initial = %.4f, after 10 iterations = %.4f", initial, exploitability)
+	if exploitability > initial/2 {
+		t.Errorf("expected exploitability to decrease, got %v (initial: %v)", exploitability, initial)
+	}
+}
+
+func TestRegretMatchingMetaSolver(t *testing.T) {
+	// Rock-paper-scissors has the uniform equilibrium.
+	payoffs := [][]float64{
+		{0, -1, 1},
+		{1, 0, -1},
+		{-1, 1, 0},
+	}
+
+	weights := RegretMatchingMetaSolver{Iterations: 10000}.Solve(payoffs)
+	for player, w := range weights {
+		for _, p := range w {
+			if math.Abs(p-1.0/3) > 0.01 {
+				t.Errorf("expected uniform meta-strategy for player %d, got %v", player, w)
+				break
+			}
+		}
+	}
+}
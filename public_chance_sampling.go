@@ -0,0 +1,293 @@
+package cfr
+
+import (
+	"fmt"
+
+	"github.com/timpalpant/go-cfr/internal/f32"
+)
+
+// PublicTreeNode is a node in the public tree of a two-player game. Each node
+// represents all game states that share the same public history. Private chance
+// outcomes (such as each player's hole cards) are not nodes of the tree; instead
+// each player has a fixed set of private states, and the InfoSet of a player node
+// is determined by its public history and the acting player's private state.
+type PublicTreeNode interface {
+	// Type returns the type of this node. Chance nodes are public chance events.
+	Type() NodeType
+	// Release resources held by this node (including any children).
+	Close()
+
+	// The number of direct children of this node.
+	NumChildren() int
+	// Get the ith child of this node.
+	GetChild(i int) PublicTreeNode
+	// Sample a single child of this public chance node according to
+	// the probability distribution over its children.
+	SampleChild() (child PublicTreeNode, p float64)
+
+	// Player returns the acting player at a player node.
+	Player() int
+	// NumPrivateStates returns the number of private states of the given player.
+	NumPrivateStates(player int) int
+	// PrivateStateProbabilities returns the prior probability of each of the
+	// given player's private states.
+	PrivateStateProbabilities(player int) []float32
+	// InfoSet returns the InfoSet of the acting player at a player node,
+	// when they are in the given private state.
+	InfoSet(privateState int) InfoSet
+	// TerminalUtilities sets utilities[i] to the counterfactual utility of a
+	// terminal node for player in private state i: the sum over the opponent's
+	// private states j of opponentReach[j] times player's utility when the
+	// players are in states i and j.
+	//
+	// opponentReach includes the prior probability of each of the opponent's
+	// private states. Pairs of private states that cannot occur together must
+	// be excluded, and if private states are not independent, each pair must be
+	// reweighted by P(i, j) / (P(i) * P(j)).
+	TerminalUtilities(player int, opponentReach, utilities []float32)
+}
+
+// PublicTerminalChildNode is an optional interface that may be implemented by a
+// player node of the public tree whose terminal children have counterfactual
+// utilities that can be computed from the reach probabilities of the opponent more
+// efficiently than by TerminalUtilities. For example, when a player folds, the
+// utility does not depend on the private states of the players (except through
+// card removal), so the utility of each private state may be computed from the
+// total reach of the opponent, rather than by summing over all pairs of states.
+// Such children are not constructed by PublicChanceSamplingCFR, or by the
+// public-tree evaluation of CFRD and subgame solving.
+type PublicTerminalChildNode interface {
+	// TerminalChildUtilities sets utilities to the counterfactual utilities of
+	// child i, as TerminalUtilities, and returns true, if the child is terminal
+	// and its utilities are known. Otherwise it returns false, and the child is
+	// constructed with GetChild as usual.
+	TerminalChildUtilities(i, player int, opponentReach, utilities []float32) bool
+}
+
+// PublicChanceSamplingCFR implements Public Chance Sampling CFR (Johanson et al., 2012).
+// Each iteration samples a single outcome at public chance nodes, and updates
+// all private states of the traversing player at once by passing vectors of
+// reach probabilities and counterfactual values through the traversal.
+// The traversing player alternates between iterations, unless set otherwise
+// with SetPlayerSchedule.
+//
+// The StrategyProfile is provided with GameTreeNodes for each (node, private state)
+// that only implement Type, Player, InfoSet and NumChildren. This is sufficient
+// for PolicyTable and other tabular profiles.
+type PublicChanceSamplingCFR struct {
+	strategyProfile StrategyProfile
+	slicePool       SlicePool
+
+	schedule         PlayerSchedule
+	traversingPlayer int
+
+	// If non-nil, it is called at each node before traversal, and if it returns
+	// true, it has set the counterfactual values of the node instead. See CFRD.
+	leafValues func(node PublicTreeNode, reach, opponentReach, values []float32) bool
+}
+
+func NewPublicChanceSampling(strategyProfile StrategyProfile) *PublicChanceSamplingCFR {
+	return &PublicChanceSamplingCFR{
+		strategyProfile: strategyProfile,
+		slicePool:       NewFloatSlicePool(SlicePoolParams{}),
+	}
+}
+
+// SetSlicePool sets the pool used to allocate temporary slices during
+// traversal. A single pool may be shared by multiple runners.
+func (c *PublicChanceSamplingCFR) SetSlicePool(pool SlicePool) {
+	c.slicePool = pool
+}
+
+// SetPlayerSchedule sets the PlayerSchedule of the runner.
+func (c *PublicChanceSamplingCFR) SetPlayerSchedule(schedule PlayerSchedule) {
+	c.schedule = schedule
+}
+
+// Run performs one iteration of PCS-CFR on the public tree rooted at node,
+// with a traversal for each player of the current iteration's schedule, and
+// returns the mean of the sampled expected values of the game for the traversing players.
+func (c *PublicChanceSamplingCFR) Run(node PublicTreeNode) float32 {
+	return runScheduled(c.schedule, c.strategyProfile.Iter(), func(player int) float32 {
+		c.traversingPlayer = player
+		return c.run(node)
+	})
+}
+
+func (c *PublicChanceSamplingCFR) run(node PublicTreeNode) float32 {
+	opponent := 1 - c.traversingPlayer
+
+	reach := c.alloc(node.PrivateStateProbabilities(c.traversingPlayer))
+	defer c.slicePool.Free(reach)
+	opponentReach := c.alloc(node.PrivateStateProbabilities(opponent))
+	defer c.slicePool.Free(opponentReach)
+
+	values := c.slicePool.Alloc(len(reach))
+	defer c.slicePool.Free(values)
+	c.runHelper(node, reach, opponentReach, values)
+	return f32.DotUnitary(reach, values)
+}
+
+func (c *PublicChanceSamplingCFR) alloc(src []float32) []float32 {
+	dst := c.slicePool.Alloc(len(src))
+	copy(dst, src)
+	return dst
+}
+
+// runHelper sets values to the counterfactual values of node for each private
+// state of the traversing player.
+func (c *PublicChanceSamplingCFR) runHelper(node PublicTreeNode, reach, opponentReach, values []float32) {
+	if c.leafValues != nil && c.leafValues(node, reach, opponentReach, values) {
+		node.Close()
+		return
+	}
+
+	switch node.Type() {
+	case TerminalNodeType:
+		node.TerminalUtilities(c.traversingPlayer, opponentReach, values)
+	case ChanceNodeType:
+		child, _ := node.SampleChild()
+		// Sampling probabilities cancel out in the calculation of counterfactual value.
+		c.runHelper(child, reach, opponentReach, values)
+	default:
+		if node.Player() == c.traversingPlayer {
+			c.handleTraversingPlayerNode(node, reach, opponentReach, values)
+		} else {
+			c.handleOpponentNode(node, reach, opponentReach, values)
+		}
+	}
+
+	node.Close()
+}
+
+// childHelper sets values to the counterfactual values of child a of node,
+// without constructing it if it is a known terminal child.
+func (c *PublicChanceSamplingCFR) childHelper(node PublicTreeNode, a int, reach, opponentReach, values []float32) {
+	if tn, ok := node.(PublicTerminalChildNode); ok && tn.TerminalChildUtilities(a, c.traversingPlayer, opponentReach, values) {
+		return
+	}
+
+	c.runHelper(node.GetChild(a), reach, opponentReach, values)
+}
+
+func (c *PublicChanceSamplingCFR) handleTraversingPlayerNode(node PublicTreeNode, reach, opponentReach, values []float32) {
+	nChildren := node.NumChildren()
+	nStates := len(reach)
+	policies := make([]NodePolicy, nStates)
+	for s := range policies {
+		policies[s] = c.strategyProfile.GetPolicy(&privateStateNode{node, s})
+	}
+
+	childReach := c.slicePool.Alloc(nStates)
+	defer c.slicePool.Free(childReach)
+	childValues := c.slicePool.Alloc(nChildren * nStates)
+	defer c.slicePool.Free(childValues)
+	for i := range values {
+		values[i] = 0
+	}
+
+	for a := 0; a < nChildren; a++ {
+		for s, policy := range policies {
+			childReach[s] = reach[s] * policy.GetStrategy()[a]
+		}
+
+		actionValues := childValues[a*nStates : (a+1)*nStates]
+		c.childHelper(node, a, childReach, opponentReach, actionValues)
+		for s, policy := range policies {
+			values[s] += policy.GetStrategy()[a] * actionValues[s]
+		}
+	}
+
+	regrets := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(regrets)
+	ones := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(ones)
+	for a := range ones {
+		ones[a] = 1.0
+	}
+
+	for s, policy := range policies {
+		for a := range regrets {
+			regrets[a] = childValues[a*nStates+s] - values[s]
+		}
+
+		policy.AddRegret(1.0, ones, regrets)
+		policy.AddStrategyWeight(reach[s])
+	}
+}
+
+func (c *PublicChanceSamplingCFR) handleOpponentNode(node PublicTreeNode, reach, opponentReach, values []float32) {
+	nChildren := node.NumChildren()
+	nStates := len(opponentReach)
+	policies := make([]NodePolicy, nStates)
+	for s := range policies {
+		policies[s] = c.strategyProfile.GetPolicy(&privateStateNode{node, s})
+	}
+
+	childReach := c.slicePool.Alloc(nStates)
+	defer c.slicePool.Free(childReach)
+	childValues := c.slicePool.Alloc(len(values))
+	defer c.slicePool.Free(childValues)
+	for i := range values {
+		values[i] = 0
+	}
+
+	for a := 0; a < nChildren; a++ {
+		for s, policy := range policies {
+			childReach[s] = opponentReach[s] * policy.GetStrategy()[a]
+		}
+
+		c.childHelper(node, a, reach, childReach, childValues)
+		f32.Add(values, childValues)
+	}
+}
+
+// privateStateNode adapts a player node of the public tree, together with
+// a private state of the acting player, to the GameTreeNode interface
+// so that its policy can be looked up in a StrategyProfile.
+type privateStateNode struct {
+	node         PublicTreeNode
+	privateState int
+}
+
+func (n *privateStateNode) Type() NodeType {
+	return PlayerNodeType
+}
+
+func (n *privateStateNode) Player() int {
+	return n.node.Player()
+}
+
+func (n *privateStateNode) NumChildren() int {
+	return n.node.NumChildren()
+}
+
+func (n *privateStateNode) InfoSet(player int) InfoSet {
+	return n.node.InfoSet(n.privateState)
+}
+
+func (n *privateStateNode) Close() {}
+
+func (n *privateStateNode) String() string {
+	return fmt.Sprintf("%v [private state %d]", n.node, n.privateState)
+}
+
+func (n *privateStateNode) GetChild(i int) GameTreeNode {
+	panic("cfr: GetChild is not supported by public chance sampling")
+}
+
+func (n *privateStateNode) Parent() GameTreeNode {
+	panic("cfr: Parent is not supported by public chance sampling")
+}
+
+func (n *privateStateNode) GetChildProbability(i int) float64 {
+	panic("cfr: GetChildProbability is not supported by public chance sampling")
+}
+
+func (n *privateStateNode) SampleChild() (GameTreeNode, float64) {
+	panic("cfr: SampleChild is not supported by public chance sampling")
+}
+
+func (n *privateStateNode) Utility(player int) float64 {
+	panic("cfr: Utility is not supported by public chance sampling")
+}
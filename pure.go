@@ -0,0 +1,152 @@
+package cfr
+
+import (
+	"math/rand"
+)
+
+// ActionCounter is an optional interface that may be implemented by a NodePolicy
+// to accumulate its average strategy by counting sampled actions, rather than by
+// adding the current strategy with AddStrategyWeight.
+type ActionCounter interface {
+	AddActionCount(action int)
+}
+
+// PureCFR implements Pure CFR (Gibson, 2014): http://richardggibson.appspot.com/static/work/thesis-phd/thesis-phd-paper.pdf
+//
+// Each iteration samples a pure strategy profile, in which every InfoSet plays a
+// single action drawn from its current strategy, along with a single outcome
+// at each chance node. All actions of the traversing player are expanded, and
+// the regret of each is the difference between its utility and that of the
+// sampled action. Since every value is the utility of a single terminal history,
+// regrets are integer-valued in games with integer utilities and may be accumulated
+// in a PurePolicyTable. The traversing player alternates between iterations.
+//
+// The average strategy is updated at nodes of the other player, which are reached
+// with probability proportional to that player's own reach: if the policy implements
+// ActionCounter the sampled action is counted, and otherwise AddStrategyWeight(1) is used.
+type PureCFR struct {
+	strategyProfile StrategyProfile
+	slicePool       SlicePool
+	rng             *rand.Rand
+
+	schedule         PlayerSchedule
+	traversingPlayer int
+	// The sampled action of each InfoSet in the current iteration.
+	sampled map[string]int
+}
+
+func NewPure(strategyProfile StrategyProfile) *PureCFR {
+	return &PureCFR{
+		strategyProfile: strategyProfile,
+		slicePool:       NewFloatSlicePool(SlicePoolParams{}),
+		rng:             rand.New(rand.NewSource(rand.Int63())),
+		sampled:         make(map[string]int),
+	}
+}
+
+// SetSlicePool sets the pool used to allocate temporary slices during
+// traversal. A single pool may be shared by multiple runners.
+func (c *PureCFR) SetSlicePool(pool SlicePool) {
+	c.slicePool = pool
+}
+
+// SetPlayerSchedule sets the PlayerSchedule of the runner.
+func (c *PureCFR) SetPlayerSchedule(schedule PlayerSchedule) {
+	c.schedule = schedule
+}
+
+// Run performs a traversal from node for each player of the current
+// iteration's schedule, and returns the mean of their values.
+func (c *PureCFR) Run(node GameTreeNode) float32 {
+	return runScheduled(c.schedule, c.strategyProfile.Iter(), func(player int) float32 {
+		c.traversingPlayer = player
+		return c.run(node)
+	})
+}
+
+func (c *PureCFR) run(node GameTreeNode) float32 {
+	clear(c.sampled)
+	return c.runHelper(node, node.Player())
+}
+
+func (c *PureCFR) runHelper(node GameTreeNode, lastPlayer int) float32 {
+	var ev float32
+	switch node.Type() {
+	case TerminalNodeType:
+		ev = float32(terminalUtility(node, lastPlayer))
+	case ChanceNodeType:
+		ev = c.handleChanceNode(node, lastPlayer)
+	default:
+		sgn := getSign(lastPlayer, node.Player())
+		ev = sgn * c.handlePlayerNode(node)
+	}
+
+	if Debug {
+		assertUtilityBounds(node, float64(ev))
+	}
+
+	node.Close()
+	return ev
+}
+
+func (c *PureCFR) handleChanceNode(node GameTreeNode, lastPlayer int) float32 {
+	child, _ := node.SampleChild()
+	// Sampling probabilities cancel out in the calculation of counterfactual value.
+	return c.runHelper(child, lastPlayer)
+}
+
+func (c *PureCFR) handlePlayerNode(node GameTreeNode) float32 {
+	player := node.Player()
+	nChildren := node.NumChildren()
+	if nChildren == 1 {
+		// Optimization to skip trivial nodes with no real choice.
+		child := node.GetChild(0)
+		return c.runHelper(child, player)
+	}
+
+	policy := c.strategyProfile.GetPolicy(node)
+	selected := c.sampleAction(node, policy)
+	if player != c.traversingPlayer {
+		if counter, ok := policy.(ActionCounter); ok {
+			counter.AddActionCount(selected)
+		} else {
+			policy.AddStrategyWeight(1.0)
+		}
+
+		child := node.GetChild(selected)
+		return c.runHelper(child, player)
+	}
+
+	regrets := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(regrets)
+	for i := 0; i < nChildren; i++ {
+		child := node.GetChild(i)
+		regrets[i] = c.runHelper(child, player)
+	}
+
+	value := regrets[selected]
+	for i := range regrets {
+		regrets[i] -= value
+	}
+
+	ones := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(ones)
+	for i := range ones {
+		ones[i] = 1.0
+	}
+	policy.AddRegret(1.0, ones, regrets)
+	return value
+}
+
+// sampleAction returns the action of the node's InfoSet in the sampled
+// pure strategy profile of the current iteration.
+func (c *PureCFR) sampleAction(node GameTreeNode, policy NodePolicy) int {
+	key := node.InfoSet(node.Player()).Key()
+	if selected, ok := c.sampled[key]; ok {
+		return selected
+	}
+
+	selected := sampleOne(policy.GetStrategy(), c.rng.Float32())
+	c.sampled[key] = selected
+	return selected
+}
@@ -0,0 +1,196 @@
+package cfr
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+)
+
+func init() {
+	gob.Register(&PurePolicyTable{})
+}
+
+// PurePolicyTable is a StrategyProfile for Pure CFR that stores the regrets
+// and average strategy of each InfoSet as integers: regrets are rounded to
+// the nearest int32, and the average strategy is accumulated by counting the
+// actions sampled by PureCFR. This uses a fraction of the memory of a PolicyTable,
+// and is exact for games with integer utilities.
+//
+// Regrets and counts saturate at the limits of int32 rather than overflowing.
+// Since it cannot represent weighted strategy sums, AddStrategyWeight panics,
+// and it can only be used with a runner that supports ActionCounter.
+type PurePolicyTable struct {
+	iter int
+
+	// Map of InfoSet Key -> the policy for that infoset.
+	policiesByKey map[string]*purePolicy
+}
+
+// NewPurePolicyTable creates a new, empty PurePolicyTable.
+func NewPurePolicyTable() *PurePolicyTable {
+	return &PurePolicyTable{
+		iter:          1,
+		policiesByKey: make(map[string]*purePolicy),
+	}
+}
+
+// Update implements StrategyProfile. Current strategies are computed
+// from the integer regrets on demand, so it only advances the iteration.
+func (pt *PurePolicyTable) Update() {
+	pt.iter++
+}
+
+func (pt *PurePolicyTable) Iter() int {
+	return pt.iter
+}
+
+func (pt *PurePolicyTable) Close() error {
+	return nil
+}
+
+func (pt *PurePolicyTable) GetPolicy(node GameTreeNode) NodePolicy {
+	key := nodeKey(node)
+	p, ok := pt.policiesByKey[key]
+	if !ok {
+		p = newPurePolicy(node.NumChildren())
+		pt.policiesByKey[key] = p
+	} else if len(p.RegretSum) != node.NumChildren() {
+		panic(fmt.Errorf("strategy has n_actions=%v but node has n_children=%v: %v",
+			len(p.RegretSum), node.NumChildren(), node))
+	}
+
+	return p
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (pt *PurePolicyTable) UnmarshalBinary(buf []byte) error {
+	r := bytes.NewReader(buf)
+	dec := gob.NewDecoder(r)
+	if err := dec.Decode(&pt.iter); err != nil {
+		return err
+	}
+
+	pt.policiesByKey = nil
+	return dec.Decode(&pt.policiesByKey)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (pt *PurePolicyTable) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(pt.iter); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(pt.policiesByKey); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// purePolicy implements NodePolicy and ActionCounter with integer
+// regret sums and action counts. Fields are exported for gob.
+type purePolicy struct {
+	RegretSum    []int32
+	ActionCounts []int32
+}
+
+func newPurePolicy(nActions int) *purePolicy {
+	return &purePolicy{
+		RegretSum:    make([]int32, nActions),
+		ActionCounts: make([]int32, nActions),
+	}
+}
+
+func (p *purePolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {
+	for i, x := range instantaneousRegrets {
+		p.RegretSum[i] = saturatingAddInt32(p.RegretSum[i], float64(w)*float64(x))
+	}
+}
+
+func (p *purePolicy) GetStrategy() []float32 {
+	strategy := make([]float32, len(p.RegretSum))
+	p.CopyStrategy(strategy)
+	return strategy
+}
+
+// CopyStrategy computes the current strategy by regret matching.
+func (p *purePolicy) CopyStrategy(dst []float32) {
+	var total int64
+	for _, x := range p.RegretSum {
+		if x > 0 {
+			total += int64(x)
+		}
+	}
+
+	for i, x := range p.RegretSum {
+		if total == 0 {
+			dst[i] = 1.0 / float32(len(dst))
+		} else if x > 0 {
+			dst[i] = float32(float64(x) / float64(total))
+		} else {
+			dst[i] = 0
+		}
+	}
+}
+
+func (p *purePolicy) GetBaseline() []float32 {
+	return make([]float32, len(p.RegretSum))
+}
+
+func (p *purePolicy) UpdateBaseline(w float32, action int, value float32) {}
+
+func (p *purePolicy) AddStrategyWeight(w float32) {
+	panic(fmt.Errorf("cfr: PurePolicyTable cannot accumulate weighted strategies, use PureCFR"))
+}
+
+// AddActionCount implements ActionCounter.
+func (p *purePolicy) AddActionCount(action int) {
+	p.ActionCounts[action] = saturatingAddInt32(p.ActionCounts[action], 1)
+}
+
+func (p *purePolicy) GetAverageStrategy() []float32 {
+	strategy := make([]float32, len(p.ActionCounts))
+	p.CopyAverageStrategy(strategy)
+	return strategy
+}
+
+func (p *purePolicy) CopyAverageStrategy(dst []float32) {
+	var total int64
+	for _, n := range p.ActionCounts {
+		total += int64(n)
+	}
+
+	for i, n := range p.ActionCounts {
+		if total == 0 {
+			dst[i] = 1.0 / float32(len(dst))
+		} else {
+			dst[i] = float32(float64(n) / float64(total))
+		}
+	}
+}
+
+func (p *purePolicy) IsEmpty() bool {
+	for i := range p.RegretSum {
+		if p.RegretSum[i] != 0 || p.ActionCounts[i] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// saturatingAddInt32 returns x + y rounded to the nearest integer,
+// clamped to the range of int32.
+func saturatingAddInt32(x int32, y float64) int32 {
+	sum := float64(x) + math.Round(y)
+	if sum > math.MaxInt32 {
+		return math.MaxInt32
+	} else if sum < math.MinInt32 {
+		return math.MinInt32
+	}
+
+	return int32(sum)
+}
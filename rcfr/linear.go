@@ -0,0 +1,163 @@
+package rcfr
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// FeatureFunc returns the feature vector of the given action of infoSet.
+// All feature vectors must have the same length.
+type FeatureFunc func(infoSet cfr.InfoSet, action int) []float32
+
+// LinearRegressor is a Regressor that predicts the cumulative regret of each
+// action as a linear function of its features, fit by ridge regression.
+type LinearRegressor struct {
+	features FeatureFunc
+	l2       float64
+	weights  []float64
+}
+
+// NewLinearRegressor returns a new LinearRegressor with the given features
+// and L2 regularization strength, which must be positive if the features of
+// the samples may be linearly dependent.
+func NewLinearRegressor(features FeatureFunc, l2 float64) *LinearRegressor {
+	return &LinearRegressor{
+		features: features,
+		l2:       l2,
+	}
+}
+
+// Fit implements Regressor.
+func (m *LinearRegressor) Fit(samples []Sample) {
+	var xtx [][]float64
+	var xty []float64
+	for _, sample := range samples {
+		for action, y := range sample.Regrets {
+			x := m.features(sample.InfoSet, action)
+			if xtx == nil {
+				xtx = make([][]float64, len(x))
+				for i := range xtx {
+					xtx[i] = make([]float64, len(x))
+					xtx[i][i] = m.l2
+				}
+
+				xty = make([]float64, len(x))
+			} else if len(x) != len(xty) {
+				panic(fmt.Errorf("feature vector has length %d, expected %d", len(x), len(xty)))
+			}
+
+			for i, xi := range x {
+				if xi == 0 {
+					continue
+				}
+
+				for j, xj := range x {
+					xtx[i][j] += float64(xi) * float64(xj)
+				}
+
+				xty[i] += float64(xi) * float64(y)
+			}
+		}
+	}
+
+	if xtx != nil {
+		m.weights = solve(xtx, xty)
+	}
+}
+
+// Predict implements Regressor.
+func (m *LinearRegressor) Predict(infoSet cfr.InfoSet, nActions int) []float32 {
+	result := make([]float32, nActions)
+	if m.weights == nil {
+		return result
+	}
+
+	for action := range result {
+		var y float64
+		for i, x := range m.features(infoSet, action) {
+			y += m.weights[i] * float64(x)
+		}
+
+		result[action] = float32(y)
+	}
+
+	return result
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// The FeatureFunc is not saved.
+func (m *LinearRegressor) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(m.l2); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(m.weights); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// The FeatureFunc of m is kept.
+func (m *LinearRegressor) UnmarshalBinary(buf []byte) error {
+	dec := gob.NewDecoder(bytes.NewReader(buf))
+	if err := dec.Decode(&m.l2); err != nil {
+		return err
+	}
+
+	m.weights = nil
+	return dec.Decode(&m.weights)
+}
+
+// solve returns the solution x of a*x = b by Gaussian elimination with
+// partial pivoting. Both a and b are overwritten. Singular directions of a,
+// which have no effect on the fit, are given zero weight.
+func solve(a [][]float64, b []float64) []float64 {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		if a[col][col] == 0 {
+			continue
+		}
+
+		for row := col + 1; row < n; row++ {
+			f := a[row][col] / a[col][col]
+			for j := col; j < n; j++ {
+				a[row][j] -= f * a[col][j]
+			}
+
+			b[row] -= f * b[col]
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		if a[row][row] == 0 {
+			continue
+		}
+
+		sum := b[row]
+		for j := row + 1; j < n; j++ {
+			sum -= a[row][j] * x[j]
+		}
+
+		x[row] = sum / a[row][row]
+	}
+
+	return x
+}
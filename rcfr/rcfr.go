@@ -0,0 +1,282 @@
+// Package rcfr implements Regression CFR (Waugh et al., 2015), in which the
+// cumulative regrets of each InfoSet are approximated by a regressor rather
+// than stored in a table.
+package rcfr
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sort"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/internal/f32"
+)
+
+// Regressor approximates the cumulative regrets of the actions of each InfoSet.
+type Regressor interface {
+	// Fit fits the regressor to the given samples, replacing any previous fit.
+	Fit(samples []Sample)
+	// Predict returns the predicted cumulative regret of each action of infoSet.
+	// It is called before the first Fit, and should then predict zero regrets.
+	Predict(infoSet cfr.InfoSet, nActions int) []float32
+}
+
+// Sample is a single example on which a Regressor is fit: the target
+// cumulative regret of each action of an InfoSet.
+type Sample struct {
+	InfoSet cfr.InfoSet
+	Regrets []float32
+}
+
+// RCFR implements cfr.StrategyProfile, and may be used with any of the CFR
+// runners in place of a PolicyTable. The current strategy of each InfoSet is
+// given by regret matching on the cumulative regrets predicted by a Regressor.
+// When Update is called, the regressor is refit to the predicted regrets plus
+// the instantaneous regrets accumulated during the iteration, for each InfoSet
+// that was visited. It is therefore best suited to runners that traverse the
+// entire tree on each iteration (such as vanilla CFR), or to regressors that
+// generalize well to InfoSets that were not sampled.
+//
+// The average strategy is accumulated in a table, as in the original RCFR.
+// InfoSets are retained until the next call to Update, so they must remain
+// valid after their node is closed. Since every InfoSet looked up with GetPolicy
+// is refit, strategies should be evaluated with the profile returned by ReadOnly.
+//
+// RCFR is not safe for concurrent use.
+type RCFR struct {
+	regressor Regressor
+	// Policies of the InfoSets visited on the current iteration, by key.
+	policies     map[string]*rcfrPolicy
+	strategySums map[string][]float32
+	iter         int
+}
+
+// New returns a new RCFR that approximates cumulative regrets with regressor.
+func New(regressor Regressor) *RCFR {
+	return &RCFR{
+		regressor:    regressor,
+		policies:     make(map[string]*rcfrPolicy),
+		strategySums: make(map[string][]float32),
+		iter:         1,
+	}
+}
+
+// GetPolicy implements cfr.StrategyProfile. The InfoSet of node is added to
+// those refit on the next call to Update.
+func (r *RCFR) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
+	infoSet := node.InfoSet(node.Player())
+	key := infoSet.Key()
+	if p, ok := r.policies[key]; ok {
+		return p
+	}
+
+	strategySum, ok := r.strategySums[key]
+	if !ok {
+		strategySum = make([]float32, node.NumChildren())
+		r.strategySums[key] = strategySum
+	}
+
+	p := r.newPolicy(node, infoSet, strategySum)
+	r.policies[key] = p
+	return p
+}
+
+func (r *RCFR) newPolicy(node cfr.GameTreeNode, infoSet cfr.InfoSet, strategySum []float32) *rcfrPolicy {
+	nChildren := node.NumChildren()
+	predicted := r.regressor.Predict(infoSet, nChildren)
+	return &rcfrPolicy{
+		infoSet:     infoSet,
+		predicted:   predicted,
+		regrets:     make([]float32, nChildren),
+		strategy:    regretMatching(predicted),
+		strategySum: strategySum,
+		isEmpty:     r.iter == 1,
+	}
+}
+
+// ReadOnly returns a view of r for evaluating its strategies (for example,
+// with cfr.Exploitability), whose GetPolicy does not add InfoSets to those
+// refit on the next call to Update, nor to the table of strategy sums.
+// The policies it returns must not be updated. All other methods apply to r.
+func (r *RCFR) ReadOnly() cfr.StrategyProfile {
+	return readOnlyRCFR{r}
+}
+
+type readOnlyRCFR struct {
+	*RCFR
+}
+
+// GetPolicy implements cfr.StrategyProfile.
+func (r readOnlyRCFR) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
+	infoSet := node.InfoSet(node.Player())
+	key := infoSet.Key()
+	if p, ok := r.policies[key]; ok {
+		return p
+	}
+
+	strategySum, ok := r.strategySums[key]
+	if !ok {
+		strategySum = make([]float32, node.NumChildren())
+	}
+
+	return r.newPolicy(node, infoSet, strategySum)
+}
+
+// Update implements cfr.StrategyProfile.
+func (r *RCFR) Update() {
+	// Samples are sorted by key so that the fit is reproducible.
+	keys := make([]string, 0, len(r.policies))
+	for key := range r.policies {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	samples := make([]Sample, 0, len(keys))
+	for _, key := range keys {
+		p := r.policies[key]
+		target := make([]float32, len(p.regrets))
+		copy(target, p.predicted)
+		f32.Add(target, p.regrets)
+		samples = append(samples, Sample{
+			InfoSet: p.infoSet,
+			Regrets: target,
+		})
+	}
+
+	if len(samples) > 0 {
+		r.regressor.Fit(samples)
+	}
+
+	r.policies = make(map[string]*rcfrPolicy)
+	r.iter++
+}
+
+// Iter implements cfr.StrategyProfile.
+func (r *RCFR) Iter() int {
+	return r.iter
+}
+
+// Close implements io.Closer.
+func (r *RCFR) Close() error {
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The Regressor must be
+// gob-encodable. Regrets accumulated since the last Update are not saved.
+func (r *RCFR) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(r.regressor); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(r.strategySums); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(r.iter); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The Regressor is
+// decoded into the one with which the RCFR was created, which must be a pointer.
+func (r *RCFR) UnmarshalBinary(buf []byte) error {
+	dec := gob.NewDecoder(bytes.NewReader(buf))
+	if err := dec.Decode(r.regressor); err != nil {
+		return err
+	}
+
+	if err := dec.Decode(&r.strategySums); err != nil {
+		return err
+	}
+
+	if err := dec.Decode(&r.iter); err != nil {
+		return err
+	}
+
+	r.policies = make(map[string]*rcfrPolicy)
+	return nil
+}
+
+type rcfrPolicy struct {
+	infoSet cfr.InfoSet
+	// Cumulative regrets predicted by the regressor at the start of the
+	// iteration, and the instantaneous regrets accumulated since.
+	predicted   []float32
+	regrets     []float32
+	strategy    []float32
+	strategySum []float32
+	isEmpty     bool
+}
+
+func (p *rcfrPolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {
+	f32.AxpyUnitary(w, instantaneousRegrets, p.regrets)
+}
+
+func (p *rcfrPolicy) GetStrategy() []float32 {
+	return p.strategy
+}
+
+func (p *rcfrPolicy) CopyStrategy(dst []float32) {
+	copy(dst, p.strategy)
+}
+
+func (p *rcfrPolicy) GetBaseline() []float32 {
+	return make([]float32, len(p.strategy))
+}
+
+func (p *rcfrPolicy) UpdateBaseline(w float32, action int, value float32) {}
+
+func (p *rcfrPolicy) AddStrategyWeight(w float32) {
+	f32.AxpyUnitary(w, p.strategy, p.strategySum)
+}
+
+func (p *rcfrPolicy) GetAverageStrategy() []float32 {
+	result := make([]float32, len(p.strategySum))
+	p.CopyAverageStrategy(result)
+	return result
+}
+
+func (p *rcfrPolicy) CopyAverageStrategy(dst []float32) {
+	total := f32.Sum(p.strategySum)
+	if total <= 0 {
+		for i := range dst {
+			dst[i] = 1.0 / float32(len(dst))
+		}
+
+		return
+	}
+
+	copy(dst, p.strategySum)
+	f32.ScalUnitary(1.0/total, dst)
+}
+
+func (p *rcfrPolicy) IsEmpty() bool {
+	return p.isEmpty
+}
+
+// regretMatching returns the strategy proportional to the positive part
+// of regrets, or the uniform strategy if no regret is positive.
+func regretMatching(regrets []float32) []float32 {
+	strategy := make([]float32, len(regrets))
+	var total float32
+	for i, r := range regrets {
+		if r > 0 {
+			strategy[i] = r
+			total += r
+		}
+	}
+
+	if total > 0 {
+		f32.ScalUnitary(1.0/total, strategy)
+	} else {
+		for i := range strategy {
+			strategy[i] = 1.0 / float32(len(strategy))
+		}
+	}
+
+	return strategy
+}
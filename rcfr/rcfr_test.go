@@ -0,0 +1,93 @@
+package rcfr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+// tabularFeatures returns one-hot features of each action of each InfoSet in the
+// game rooted at root, with which a LinearRegressor is equivalent to a table.
+func tabularFeatures(root cfr.GameTreeNode) FeatureFunc {
+	index := make(map[string]int)
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		if _, ok := index[key]; !ok {
+			index[key] = len(index)
+		}
+	})
+
+	return func(infoSet cfr.InfoSet, action int) []float32 {
+		x := make([]float32, 2*len(index))
+		x[2*index[infoSet.Key()]+action] = 1
+		return x
+	}
+}
+
+func runRCFR(rcfr *RCFR, root cfr.GameTreeNode, nIter int) {
+	opt := cfr.New(rcfr)
+	for i := 0; i < nIter; i++ {
+		opt.Run(root)
+		rcfr.Update()
+	}
+}
+
+func TestRCFR_TabularFeatures(t *testing.T) {
+	root := kuhn.NewGame()
+	rcfr := New(NewLinearRegressor(tabularFeatures(root), 1e-6))
+	runRCFR(rcfr, root, 1000)
+
+	exploitability := cfr.Exploitability(root, rcfr.ReadOnly())
+	t.Logf("Exploitability after 1000 iterations: %.4f", exploitability)
+	if exploitability > 0.01 {
+		t.Errorf("exploitability too high: %.4f", exploitability)
+	}
+
+	// Evaluation does not add InfoSets to the next fit.
+	if len(rcfr.policies) != 0 {
+		t.Errorf("expected no InfoSets to refit after evaluation, got %d", len(rcfr.policies))
+	}
+}
+
+func TestRCFR_MarshalBinary(t *testing.T) {
+	root := kuhn.NewGame()
+	features := tabularFeatures(root)
+	rcfr := New(NewLinearRegressor(features, 1e-6))
+	runRCFR(rcfr, root, 100)
+
+	buf, err := rcfr.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := New(NewLinearRegressor(features, 0))
+	if err := restored.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Iter() != rcfr.Iter() {
+		t.Errorf("expected iteration %d, got %d", rcfr.Iter(), restored.Iter())
+	}
+
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		p, q := rcfr.GetPolicy(node), restored.GetPolicy(node)
+		if !reflect.DeepEqual(p.GetStrategy(), q.GetStrategy()) {
+			t.Errorf("expected strategy %v, got %v", p.GetStrategy(), q.GetStrategy())
+		}
+
+		if !reflect.DeepEqual(p.GetAverageStrategy(), q.GetAverageStrategy()) {
+			t.Errorf("expected average strategy %v, got %v", p.GetAverageStrategy(), q.GetAverageStrategy())
+		}
+	})
+}
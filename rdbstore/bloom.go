@@ -0,0 +1,73 @@
+package rdbstore
+
+import (
+	"math"
+)
+
+// bloomFilter is a probabilistic set of keys with no false negatives,
+// used to skip disk reads for keys that have never been stored.
+type bloomFilter struct {
+	bits    []uint64
+	nBits   uint64
+	nHashes int
+}
+
+// newBloomFilter returns an empty bloomFilter sized to hold n keys with the
+// given false positive rate.
+func newBloomFilter(n int, falsePositiveRate float64) *bloomFilter {
+	if n < 1 {
+		n = 1
+	}
+
+	nBits := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	if nBits < 64 {
+		nBits = 64
+	}
+
+	nHashes := int(math.Round(float64(nBits) / float64(n) * math.Ln2))
+	if nHashes < 1 {
+		nHashes = 1
+	}
+
+	return &bloomFilter{
+		bits:    make([]uint64, (nBits+63)/64),
+		nBits:   nBits,
+		nHashes: nHashes,
+	}
+}
+
+func (f *bloomFilter) add(key string) {
+	h1, h2 := bloomHashes(key)
+	for i := 0; i < f.nHashes; i++ {
+		bit := (h1 + uint64(i)*h2) % f.nBits
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// mayContain returns false if key was definitely never added to the filter.
+func (f *bloomFilter) mayContain(key string) bool {
+	h1, h2 := bloomHashes(key)
+	for i := 0; i < f.nHashes; i++ {
+		bit := (h1 + uint64(i)*h2) % f.nBits
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bloomHashes returns two independent hashes of key for double hashing:
+// the 64-bit FNV-1a hash, and a second hash derived from it.
+func bloomHashes(key string) (uint64, uint64) {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211
+	}
+
+	h2 := h ^ (h >> 33)
+	h2 *= 0xff51afd7ed558ccd
+	h2 ^= h2 >> 33
+	return h, h2 | 1
+}
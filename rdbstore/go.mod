@@ -0,0 +1,17 @@
+module github.com/timpalpant/go-cfr/rdbstore
+
+go 1.21
+
+require (
+	github.com/tecbot/gorocksdb v0.0.0-20191217155057-f0fad39f321c
+	github.com/timpalpant/go-cfr v0.0.0-20261015053216-408960a76215
+)
+
+require github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b // indirect
+
+// The rdbstore module is developed in the same repository as go-cfr, and uses
+// its internal packages. The replace directive builds it against the adjacent
+// version of the core module during development, but is ignored by consumers,
+// so the required version above must be bumped to a core commit containing
+// any APIs that rdbstore comes to depend on.
+replace github.com/timpalpant/go-cfr => ../
@@ -3,6 +3,9 @@
 //
 // These implementations are substantially slower than the corresponding in-memory
 // components but can scale to games that do not fit in memory.
+//
+// rdbstore is a separate Go module, so that users of the core go-cfr packages
+// do not need cgo or the RocksDB libraries to build their programs.
 package rdbstore
 
 import (
@@ -14,6 +17,13 @@ type Params struct {
 	Options      *rocksdb.Options
 	ReadOptions  *rocksdb.ReadOptions
 	WriteOptions *rocksdb.WriteOptions
+
+	// If BloomFilterKeys > 0, PolicyTable keeps an in-memory Bloom filter
+	// of the keys it has stored, sized for the given number of keys and
+	// false positive rate, so that lookups of new InfoSets do not need to
+	// read from disk.
+	BloomFilterKeys              int
+	BloomFilterFalsePositiveRate float64
 }
 
 func DefaultParams(path string) Params {
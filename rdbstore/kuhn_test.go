@@ -3,6 +3,8 @@ package rdbstore
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/timpalpant/go-cfr"
@@ -29,6 +31,102 @@ func TestVanilla(t *testing.T) {
 	testCFR(t, opt, policy, 1000)
 }
 
+func TestPrefetchingVanilla(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cfr-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	params := DefaultParams(tmpDir)
+	defer params.Close()
+	policy, err := New(params, cfr.DiscountParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefetcher := cfr.NewPrefetchingProfile(policy)
+	defer prefetcher.Close()
+
+	opt := cfr.New(prefetcher)
+	testCFR(t, opt, prefetcher, 1000)
+}
+
+func TestPolicyTable_Namespaces(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cfr-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	params := DefaultParams(filepath.Join(tmpDir, "policy"))
+	defer params.Close()
+	policy, err := New(params, cfr.DiscountParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer policy.Close()
+
+	root := kuhn.NewGame()
+	a, b := policy.Namespace("a"), policy.Namespace("b")
+	optA, optB := cfr.New(a), cfr.New(b)
+	for i := 0; i < 100; i++ {
+		optA.Run(root)
+		// Namespace b is trained for fewer iterations, so
+		// its policies differ from those of namespace a.
+		if i < 10 {
+			optB.Run(root)
+		}
+
+		policy.Update()
+	}
+
+	names, err := policy.Namespaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("expected namespaces [a b], got %v", names)
+	}
+
+	exportParams := DefaultParams(filepath.Join(tmpDir, "export"))
+	defer exportParams.Close()
+	exported, err := policy.ExportNamespace("a", exportParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exported.Close()
+
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		expected := a.GetPolicy(node).GetAverageStrategy()
+		if got := exported.GetPolicy(node).GetAverageStrategy(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%v: expected exported average strategy %v, got %v", node, expected, got)
+		}
+
+		if reflect.DeepEqual(b.GetPolicy(node).GetAverageStrategy(), expected) {
+			t.Errorf("%v: expected average strategy of namespace b to differ", node)
+		}
+	})
+
+	if err := policy.DeleteNamespace("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err = policy.Namespaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(names, []string{"b"}) {
+		t.Errorf("expected namespaces [b] after deletion, got %v", names)
+	}
+}
+
 func BenchmarkVanilla(b *testing.B) {
 	tmpDir, err := ioutil.TempDir("", "cfr-test-")
 	if err != nil {
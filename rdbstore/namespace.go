@@ -0,0 +1,165 @@
+package rdbstore
+
+import (
+	"bytes"
+	"strings"
+
+	rocksdb "github.com/tecbot/gorocksdb"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// namespaceSep separates the name of a namespace from the InfoSet keys within it,
+// as in cfr.PolicyTable. Namespace names must not contain it.
+const namespaceSep = "\x00"
+
+// PolicyNamespace is a view of a PolicyTable restricted to a single namespace,
+// in which the key of every InfoSet is prefixed with the name of the namespace,
+// as with cfr.PolicyNamespace. Several games, abstractions or variants of a bot
+// may therefore be trained and served from a single database.
+//
+// All other methods, including Update and Iter, apply to the entire
+// PolicyTable, so all namespaces share the same iteration.
+type PolicyNamespace struct {
+	*PolicyTable
+	prefix string
+}
+
+// Namespace returns a view of the policies within the given namespace.
+func (pt *PolicyTable) Namespace(name string) *PolicyNamespace {
+	return &PolicyNamespace{
+		PolicyTable: pt,
+		prefix:      name + namespaceSep,
+	}
+}
+
+// GetPolicy implements cfr.StrategyProfile.
+func (ns *PolicyNamespace) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
+	return ns.PolicyTable.GetPolicy(&namespacedNode{node, ns.prefix})
+}
+
+// RangeSums is as PolicyTable.RangeSums, but only for
+// the InfoSets within the namespace, with their keys unprefixed.
+func (ns *PolicyNamespace) RangeSums(f func(key string, regretSum, strategySum []float32) bool) {
+	ns.PolicyTable.RangeSums(func(key string, regretSum, strategySum []float32) bool {
+		if !strings.HasPrefix(key, ns.prefix) {
+			return true
+		}
+
+		return f(strings.TrimPrefix(key, ns.prefix), regretSum, strategySum)
+	})
+}
+
+// Namespaces returns the names of all namespaces with policies in the table, sorted.
+func (pt *PolicyTable) Namespaces() ([]string, error) {
+	var names []string
+	it := pt.db.NewIterator(pt.params.ReadOptions)
+	defer it.Close()
+	for it.SeekToFirst(); it.Valid(); {
+		key := it.Key()
+		name, _, ok := strings.Cut(string(key.Data()), namespaceSep)
+		key.Free()
+		if !ok {
+			it.Next()
+			continue
+		}
+
+		names = append(names, name)
+		// Skip the remaining keys of the namespace, which all sort before
+		// the name followed by the byte after namespaceSep.
+		it.Seek([]byte(name + "\x01"))
+	}
+
+	return names, it.Err()
+}
+
+// ExportNamespace returns a new PolicyTable, in a new database with the given
+// params, containing a copy of the policies within the given namespace with
+// their keys unprefixed, so that it may be used to play (or saved) on its own.
+// It must not be called during traversal.
+func (pt *PolicyTable) ExportNamespace(name string, params Params) (*PolicyTable, error) {
+	result, err := New(params, pt.discounts)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := []byte(name + namespaceSep)
+	it := pt.db.NewIterator(pt.params.ReadOptions)
+	defer it.Close()
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		key, value := it.Key(), it.Value()
+		unprefixed := bytes.TrimPrefix(key.Data(), prefix)
+		err := result.db.Put(result.params.WriteOptions, unprefixed, value.Data())
+		if err == nil && result.knownKeys != nil {
+			result.knownKeys.add(string(unprefixed))
+		}
+
+		key.Free()
+		value.Free()
+		if err != nil {
+			result.Close()
+			return nil, err
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	result.iter = pt.iter
+	result.strategyWeight = pt.strategyWeight
+	return result, nil
+}
+
+// DeleteNamespace removes all policies within the given namespace from the table.
+// It must not be called during traversal.
+func (pt *PolicyTable) DeleteNamespace(name string) error {
+	prefix := []byte(name + namespaceSep)
+	wb := rocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	it := pt.db.NewIterator(pt.params.ReadOptions)
+	defer it.Close()
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		key := it.Key()
+		wb.Delete(key.Data())
+		key.Free()
+	}
+
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if err := pt.db.Write(pt.params.WriteOptions, wb); err != nil {
+		return err
+	}
+
+	pt.mx.Lock()
+	for key := range pt.mayNeedUpdate {
+		if strings.HasPrefix(key, string(prefix)) {
+			delete(pt.mayNeedUpdate, key)
+		}
+	}
+	pt.mx.Unlock()
+	return nil
+}
+
+// namespacedNode prefixes the InfoSet keys of a node.
+type namespacedNode struct {
+	cfr.GameTreeNode
+	prefix string
+}
+
+func (n *namespacedNode) InfoSet(player int) cfr.InfoSet {
+	return namespacedInfoSet{n.GameTreeNode.InfoSet(player), n.prefix}
+}
+
+type namespacedInfoSet struct {
+	cfr.InfoSet
+	prefix string
+}
+
+func (is namespacedInfoSet) Key() string {
+	return is.prefix + is.InfoSet.Key()
+}
@@ -3,6 +3,8 @@ package rdbstore
 import (
 	"bytes"
 	"encoding/gob"
+	"io"
+	"sync"
 
 	rocksdb "github.com/tecbot/gorocksdb"
 
@@ -22,10 +24,20 @@ func init() {
 type PolicyTable struct {
 	params    Params
 	discounts cfr.DiscountParams
-
-	db            *rocksdb.DB
-	iter          int
+	// Parameters of discounts that are shared by all policies.
+	policyParams policy.Params
+
+	db   *rocksdb.DB
+	iter int
+	// The weight of strategies accumulated during the current iteration,
+	// as in cfr.PolicyTable.
+	strategyWeight float64
+
+	// Guards mayNeedUpdate and knownKeys, so that GetPolicy is safe to
+	// call concurrently (e.g. from a cfr.PrefetchingProfile).
+	mx            sync.Mutex
 	mayNeedUpdate map[string]struct{}
+	knownKeys     *bloomFilter
 }
 
 // New creates a new PolicyTable backed by a LevelDB database at the given path.
@@ -35,13 +47,52 @@ func New(params Params, discounts cfr.DiscountParams) (*PolicyTable, error) {
 		return nil, err
 	}
 
-	return &PolicyTable{
-		params:        params,
-		discounts:     discounts,
-		db:            db,
-		iter:          1,
-		mayNeedUpdate: make(map[string]struct{}),
-	}, nil
+	pt := &PolicyTable{
+		params:         params,
+		discounts:      discounts,
+		policyParams:   newPolicyParams(discounts),
+		db:             db,
+		iter:           1,
+		strategyWeight: 1.0,
+		mayNeedUpdate:  make(map[string]struct{}),
+	}
+
+	if err := pt.loadBloomFilter(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return pt, nil
+}
+
+// newPolicyParams returns the parameters of discounts that are shared by all policies.
+func newPolicyParams(discounts cfr.DiscountParams) policy.Params {
+	params := policy.Params{BaselineDecay: discounts.BaselineDecay}
+	if discounts.DominanceMinSamples > 0 {
+		params.DominanceMinSamples = uint32(discounts.DominanceMinSamples)
+	}
+
+	return params
+}
+
+// loadBloomFilter initializes the Bloom filter of known keys (if enabled)
+// from all keys that are already stored in the database.
+func (pt *PolicyTable) loadBloomFilter() error {
+	if pt.params.BloomFilterKeys <= 0 {
+		pt.knownKeys = nil
+		return nil
+	}
+
+	pt.knownKeys = newBloomFilter(pt.params.BloomFilterKeys, pt.params.BloomFilterFalsePositiveRate)
+	it := pt.db.NewIterator(pt.params.ReadOptions)
+	defer it.Close()
+	for it.SeekToFirst(); it.Valid(); it.Next() {
+		key := it.Key()
+		pt.knownKeys.add(string(key.Data()))
+		key.Free()
+	}
+
+	return it.Err()
 }
 
 // MarshalBinary implements encoding.BinaryMarshaler.
@@ -61,6 +112,18 @@ func (pt *PolicyTable) MarshalBinary() ([]byte, error) {
 		return nil, err
 	}
 
+	if err := enc.Encode(pt.params.BloomFilterKeys); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(pt.params.BloomFilterFalsePositiveRate); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Encode(pt.strategyWeight); err != nil {
+		return nil, err
+	}
+
 	return buf.Bytes(), nil
 }
 
@@ -81,10 +144,27 @@ func (pt *PolicyTable) UnmarshalBinary(buf []byte) error {
 		return err
 	}
 
+	pt.policyParams = newPolicyParams(pt.discounts)
+
 	if err := dec.Decode(&pt.iter); err != nil {
 		return err
 	}
 
+	// Tables encoded before Bloom filters were added end here.
+	if err := dec.Decode(&pt.params.BloomFilterKeys); err != nil && err != io.EOF {
+		return err
+	}
+
+	if err := dec.Decode(&pt.params.BloomFilterFalsePositiveRate); err != nil && err != io.EOF {
+		return err
+	}
+
+	// Tables encoded before strategy weighting was added end here.
+	pt.strategyWeight = 1.0
+	if err := dec.Decode(&pt.strategyWeight); err != nil && err != io.EOF {
+		return err
+	}
+
 	pt.params.Options.SetCreateIfMissing(false)
 	db, err := rocksdb.OpenDb(pt.params.Options, pt.params.Path)
 	if err != nil {
@@ -92,7 +172,8 @@ func (pt *PolicyTable) UnmarshalBinary(buf []byte) error {
 	}
 
 	pt.db = db
-	return nil
+	pt.mayNeedUpdate = make(map[string]struct{})
+	return pt.loadBloomFilter()
 }
 
 // Close implements io.Closer.
@@ -101,6 +182,33 @@ func (pt *PolicyTable) Close() error {
 	return nil
 }
 
+// RangeSums calls f with the cumulative regrets and strategy sums of each InfoSet
+// stored in the table, until f returns false, as with cfr.PolicyTable.RangeSums.
+// Policies touched since the last call to Update may not yet be stored.
+func (pt *PolicyTable) RangeSums(f func(key string, regretSum, strategySum []float32) bool) {
+	it := pt.db.NewIterator(pt.params.ReadOptions)
+	defer it.Close()
+	for it.SeekToFirst(); it.Valid(); it.Next() {
+		key, value := it.Key(), it.Value()
+		k := string(key.Data())
+		var p policy.Policy
+		err := p.UnmarshalBinary(value.Data())
+		key.Free()
+		value.Free()
+		if err != nil {
+			panic(err)
+		}
+
+		if !f(k, p.GetRegretSum(), p.GetStrategySum()) {
+			return
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		panic(err)
+	}
+}
+
 // Iter implements cfr.StrategyProfile.
 func (pt *PolicyTable) Iter() int {
 	return pt.iter
@@ -109,10 +217,22 @@ func (pt *PolicyTable) Iter() int {
 // Update implements cfr.StrategyProfile.
 func (pt *PolicyTable) Update() {
 	discountPos, discountNeg, discountSum := pt.discounts.GetDiscountFactors(pt.iter)
+	threshold := pt.discounts.GetThreshold(pt.iter + 1)
+	pt.strategyWeight /= float64(discountSum)
+	strategyWeight := float32(pt.strategyWeight)
 
 	for key := range pt.mayNeedUpdate {
 		p := pt.getPolicyByKey(key)
-		p.NextStrategy(discountPos, discountNeg, discountSum)
+		if p == nil { // Never saved, or removed by a Scrubber.
+			delete(pt.mayNeedUpdate, key)
+			continue
+		}
+
+		p.ScaleStrategyWeight(strategyWeight)
+		p.NextStrategy(discountPos, discountNeg, 1.0, pt.discounts.Minimizer, pt.iter+1)
+		if threshold > 0 {
+			p.ApplyThreshold(threshold)
+		}
 
 		lPolicy := &ldbPolicy{
 			Policy: p,
@@ -131,12 +251,40 @@ func (pt *PolicyTable) Update() {
 // GetPolicy implements cfr.StrategyProfile.
 func (pt *PolicyTable) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
 	key := node.InfoSet(node.Player()).Key()
-	p := pt.getPolicyByKey(key)
+	pt.mx.Lock()
+	mayContain := pt.knownKeys == nil || pt.knownKeys.mayContain(key)
+	pt.mx.Unlock()
+
+	var p *policy.Policy
+	if mayContain {
+		p = pt.getPolicyByKey(key)
+	}
+
 	if p == nil {
 		p = policy.New(node.NumChildren())
+		if pt.discounts.CompensatedSummation {
+			p.EnableCompensatedSummation()
+		}
+
+		if pt.discounts.UsesPrediction() {
+			p.EnablePrediction()
+		}
+	}
+
+	if pt.discounts.DominanceMinSamples > 0 {
+		p.EnableDominanceCheck()
+	}
+
+	p.SetParams(&pt.policyParams)
+
+	// The policy will be saved on the next call to Update, if not before.
+	pt.mx.Lock()
+	if pt.knownKeys != nil {
+		pt.knownKeys.add(key)
 	}
 
 	pt.mayNeedUpdate[key] = struct{}{}
+	pt.mx.Unlock()
 	return &ldbPolicy{
 		Policy: p,
 		db:     pt.db,
@@ -158,6 +306,7 @@ func (pt *PolicyTable) getPolicyByKey(key string) *policy.Policy {
 			panic(err)
 		}
 
+		policy.SetParams(&pt.policyParams)
 		return policy
 	}
 
@@ -0,0 +1,169 @@
+package rdbstore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	rocksdb "github.com/tecbot/gorocksdb"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/internal/policy"
+)
+
+// snapshotIterFile is the name of the file within a snapshot
+// that records the iteration at which it was taken.
+const snapshotIterFile = "CFR_ITER"
+
+// Snapshot writes a consistent snapshot of the PolicyTable to the given directory,
+// which must not exist, for serving by read-only replicas (see OpenReplica).
+// Snapshots are RocksDB checkpoints: the immutable files of the database are
+// hard-linked rather than copied when dir is on the same filesystem, so taking
+// a snapshot is cheap. The directory may then be copied to other machines.
+//
+// It must not be called during traversal, since policies are written as
+// they are updated.
+func (pt *PolicyTable) Snapshot(dir string) error {
+	checkpoint, err := pt.db.NewCheckpoint()
+	if err != nil {
+		return err
+	}
+	defer checkpoint.Destroy()
+
+	if err := checkpoint.CreateCheckpoint(dir, 0); err != nil {
+		return err
+	}
+
+	iter := []byte(strconv.Itoa(pt.iter))
+	return ioutil.WriteFile(filepath.Join(dir, snapshotIterFile), iter, 0644)
+}
+
+// Replica is a read-only cfr.StrategyProfile that serves the policies of a
+// snapshot of a PolicyTable (see PolicyTable.Snapshot), so that evaluation and
+// serving never contend with the writes of training. A replica may be refreshed
+// to a newer snapshot while it is in use.
+//
+// Regret and strategy updates to its policies are ignored.
+type Replica struct {
+	opts     *rocksdb.Options
+	readOpts *rocksdb.ReadOptions
+
+	mx   sync.RWMutex
+	db   *rocksdb.DB
+	iter int
+}
+
+// OpenReplica opens the snapshot in the given directory as a Replica.
+func OpenReplica(dir string) (*Replica, error) {
+	r := &Replica{
+		opts:     rocksdb.NewDefaultOptions(),
+		readOpts: rocksdb.NewDefaultReadOptions(),
+	}
+
+	if err := r.Refresh(dir); err != nil {
+		r.opts.Destroy()
+		r.readOpts.Destroy()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Refresh switches the replica to serve the snapshot in the given directory,
+// which is usually newer than the one it is serving. Policies returned before
+// Refresh are unaffected.
+func (r *Replica) Refresh(dir string) error {
+	buf, err := ioutil.ReadFile(filepath.Join(dir, snapshotIterFile))
+	if err != nil {
+		return err
+	}
+
+	iter, err := strconv.Atoi(strings.TrimSpace(string(buf)))
+	if err != nil {
+		return fmt.Errorf("invalid snapshot iteration: %v", err)
+	}
+
+	db, err := rocksdb.OpenDbForReadOnly(r.opts, dir, false)
+	if err != nil {
+		return err
+	}
+
+	r.mx.Lock()
+	prev := r.db
+	r.db = db
+	r.iter = iter
+	r.mx.Unlock()
+
+	if prev != nil {
+		prev.Close()
+	}
+
+	return nil
+}
+
+// GetPolicy implements cfr.StrategyProfile. InfoSets that are not in the
+// snapshot have an empty policy, which plays uniformly at random.
+func (r *Replica) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
+	key := node.InfoSet(node.Player()).Key()
+	r.mx.RLock()
+	buf, err := r.db.GetBytes(r.readOpts, []byte(key))
+	r.mx.RUnlock()
+	if err != nil {
+		panic(err)
+	}
+
+	if len(buf) == 0 {
+		return replicaPolicy{policy.New(node.NumChildren())}
+	}
+
+	p := &policy.Policy{}
+	if err := p.UnmarshalBinary(buf); err != nil {
+		panic(err)
+	}
+
+	return replicaPolicy{p}
+}
+
+// Update implements cfr.StrategyProfile. It is a no-op.
+func (r *Replica) Update() {}
+
+// Iter implements cfr.StrategyProfile. It returns the iteration
+// of the PolicyTable when the snapshot was taken.
+func (r *Replica) Iter() int {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	return r.iter
+}
+
+// Close implements io.Closer.
+func (r *Replica) Close() error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.db.Close()
+	r.opts.Destroy()
+	r.readOpts.Destroy()
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Replicas are
+// opened from snapshots and cannot be saved themselves.
+func (r *Replica) MarshalBinary() ([]byte, error) {
+	return nil, fmt.Errorf("rdbstore: cannot marshal a Replica")
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (r *Replica) UnmarshalBinary(buf []byte) error {
+	return fmt.Errorf("rdbstore: cannot unmarshal a Replica")
+}
+
+// replicaPolicy is a read-only cfr.NodePolicy.
+type replicaPolicy struct {
+	*policy.Policy
+}
+
+func (p replicaPolicy) AddRegret(w float32, samplingQ, instantaneousRegrets []float32) {}
+func (p replicaPolicy) AddStrategyWeight(w float32)                                    {}
+func (p replicaPolicy) UpdateBaseline(w float32, action int, value float32)            {}
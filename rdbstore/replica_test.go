@@ -0,0 +1,80 @@
+package rdbstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestReplica(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cfr-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	params := DefaultParams(filepath.Join(tmpDir, "policy"))
+	defer params.Close()
+	policy, err := New(params, cfr.DiscountParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer policy.Close()
+
+	opt := cfr.New(policy)
+	runCFR(t, opt, policy, 10)
+	snapshot := filepath.Join(tmpDir, "snapshot-10")
+	if err := policy.Snapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	replica, err := OpenReplica(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replica.Close()
+
+	checkReplica(t, replica, policy)
+
+	// Training continues without affecting the replica until it is refreshed.
+	runCFR(t, opt, policy, 10)
+	if replica.Iter() != 11 {
+		t.Errorf("expected replica at iteration 11, got %d", replica.Iter())
+	}
+
+	snapshot = filepath.Join(tmpDir, "snapshot-20")
+	if err := policy.Snapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replica.Refresh(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	checkReplica(t, replica, policy)
+}
+
+func checkReplica(t *testing.T, replica *Replica, policy *PolicyTable) {
+	if replica.Iter() != policy.Iter() {
+		t.Errorf("expected replica at iteration %d, got %d", policy.Iter(), replica.Iter())
+	}
+
+	tree.Visit(kuhn.NewGame(), func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		expected := policy.GetPolicy(node).GetAverageStrategy()
+		p := replica.GetPolicy(node)
+		p.AddRegret(1.0, []float32{1, 1}, []float32{1, -1})
+		if got := p.GetAverageStrategy(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%v: expected average strategy %v, got %v", node, expected, got)
+		}
+	})
+}
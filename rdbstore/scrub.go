@@ -0,0 +1,274 @@
+package rdbstore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"expvar"
+	"sync"
+	"time"
+
+	rocksdb "github.com/tecbot/gorocksdb"
+
+	"github.com/timpalpant/go-cfr/deepcfr"
+	"github.com/timpalpant/go-cfr/internal/policy"
+)
+
+var (
+	scrubPasses        = expvar.NewInt("rdbstore_scrub_passes")
+	scrubbedEntries    = expvar.NewInt("rdbstore_scrubbed_entries")
+	corruptEntries     = expvar.NewInt("rdbstore_corrupt_entries")
+	quarantinedEntries = expvar.NewInt("rdbstore_quarantined_entries")
+	scrubErrors        = expvar.NewInt("rdbstore_scrub_errors")
+)
+
+// scrubBatchSize is the number of entries validated between checks
+// of the rate limit and for requests to stop.
+const scrubBatchSize = 100
+
+// ScrubParams configures a Scrubber.
+type ScrubParams struct {
+	// Time to wait after each complete pass over the database before starting the next.
+	Interval time.Duration
+	// Maximum number of entries validated per second, so that scrubbing does not
+	// compete with training for disk bandwidth. Zero means unlimited.
+	EntriesPerSecond int
+	// If non-empty, corrupt entries are copied to a RocksDB database at this path
+	// (which is created if necessary) before they are removed, so that they may
+	// be inspected later.
+	QuarantinePath string
+}
+
+// ScrubStats summarizes a single pass of a Scrubber over the database.
+type ScrubStats struct {
+	// Number of entries validated.
+	Scrubbed int
+	// Number of entries that could not be decoded, and were removed.
+	Corrupt int
+}
+
+// Scrubber is a low-priority background task that periodically iterates over all
+// entries of a RocksDB-backed store and validates that they can be decoded, so that
+// silent corruption during long runs is discovered (and repaired) when it happens
+// rather than at export. Progress is reported with expvar metrics.
+//
+// Corrupt entries are repaired by removing them, after optionally copying them to
+// a quarantine database. For a PolicyTable, the policy of the InfoSet is reset and
+// will be relearned. For a ReservoirBuffer, the sample is dropped.
+//
+// Repair is best-effort when the store is written concurrently with scrubbing: an
+// entry that is rewritten between being validated and removed may be lost.
+// A Scrubber must be stopped before its store is closed.
+type Scrubber struct {
+	db       *rocksdb.DB
+	params   ScrubParams
+	validate func(value []byte) error
+	// Called with the key of each corrupt entry after it is removed.
+	onRemove func(key string)
+
+	readOpts  *rocksdb.ReadOptions
+	writeOpts *rocksdb.WriteOptions
+
+	mx         sync.Mutex
+	quarantine *rocksdb.DB
+	stop       chan struct{}
+	done       chan struct{}
+	stopOnce   sync.Once
+}
+
+func newScrubber(db *rocksdb.DB, params ScrubParams, validate func([]byte) error, onRemove func(string)) *Scrubber {
+	// Avoid evicting the working set of training from the block cache.
+	readOpts := rocksdb.NewDefaultReadOptions()
+	readOpts.SetFillCache(false)
+
+	return &Scrubber{
+		db:        db,
+		params:    params,
+		validate:  validate,
+		onRemove:  onRemove,
+		readOpts:  readOpts,
+		writeOpts: rocksdb.NewDefaultWriteOptions(),
+	}
+}
+
+// NewScrubber returns a Scrubber that validates the policies of the PolicyTable.
+func (pt *PolicyTable) NewScrubber(params ScrubParams) *Scrubber {
+	validate := func(value []byte) error {
+		var p policy.Policy
+		return p.UnmarshalBinary(value)
+	}
+
+	onRemove := func(key string) {
+		pt.mx.Lock()
+		defer pt.mx.Unlock()
+		delete(pt.mayNeedUpdate, key)
+	}
+
+	return newScrubber(pt.db, params, validate, onRemove)
+}
+
+// NewScrubber returns a Scrubber that validates the samples of the ReservoirBuffer.
+func (b *ReservoirBuffer) NewScrubber(params ScrubParams) *Scrubber {
+	validate := func(value []byte) error {
+		var sample deepcfr.Sample
+		return gob.NewDecoder(bytes.NewReader(value)).Decode(&sample)
+	}
+
+	return newScrubber(b.db, params, validate, nil)
+}
+
+// Start begins scrubbing in a background goroutine, until Stop is called.
+func (s *Scrubber) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run()
+}
+
+// Stop stops background scrubbing, waits for it to exit, and releases the
+// resources held by the Scrubber. Subsequent calls do nothing.
+func (s *Scrubber) Stop() {
+	s.stopOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+			<-s.done
+			s.stop = nil
+		}
+
+		s.readOpts.Destroy()
+		s.writeOpts.Destroy()
+		if s.quarantine != nil {
+			s.quarantine.Close()
+		}
+	})
+}
+
+func (s *Scrubber) run() {
+	defer close(s.done)
+	for {
+		if _, err := s.scrub(s.stop); err != nil {
+			scrubErrors.Add(1)
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(s.params.Interval):
+		}
+	}
+}
+
+// ScrubOnce performs a single complete pass over the database in the
+// calling goroutine. It must not be called while the Scrubber is started.
+func (s *Scrubber) ScrubOnce() (ScrubStats, error) {
+	return s.scrub(nil)
+}
+
+func (s *Scrubber) scrub(stop <-chan struct{}) (ScrubStats, error) {
+	var stats ScrubStats
+	var corrupt []string
+	start := time.Now()
+	it := s.db.NewIterator(s.readOpts)
+	defer it.Close()
+	for it.SeekToFirst(); it.Valid(); it.Next() {
+		key, value := it.Key(), it.Value()
+		if err := s.validate(value.Data()); err != nil {
+			corrupt = append(corrupt, string(key.Data()))
+		}
+		key.Free()
+		value.Free()
+
+		stats.Scrubbed++
+		scrubbedEntries.Add(1)
+		if stats.Scrubbed%scrubBatchSize == 0 {
+			select {
+			case <-stop:
+				return stats, nil
+			default:
+			}
+
+			s.throttle(start, stats.Scrubbed)
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return stats, err
+	}
+
+	for _, key := range corrupt {
+		removed, err := s.repair([]byte(key))
+		if err != nil {
+			return stats, err
+		}
+
+		if removed {
+			stats.Corrupt++
+			corruptEntries.Add(1)
+		}
+	}
+
+	scrubPasses.Add(1)
+	return stats, nil
+}
+
+// throttle sleeps as necessary to limit the rate of validation
+// to params.EntriesPerSecond.
+func (s *Scrubber) throttle(start time.Time, n int) {
+	if s.params.EntriesPerSecond <= 0 {
+		return
+	}
+
+	expected := time.Duration(n) * time.Second / time.Duration(s.params.EntriesPerSecond)
+	if elapsed := time.Since(start); elapsed < expected {
+		time.Sleep(expected - elapsed)
+	}
+}
+
+// repair quarantines and removes the entry with the given key, if it is still corrupt.
+func (s *Scrubber) repair(key []byte) (bool, error) {
+	value, err := s.db.GetBytes(s.readOpts, key)
+	if err != nil {
+		return false, err
+	}
+
+	if value == nil || s.validate(value) == nil {
+		return false, nil // Rewritten since it was validated.
+	}
+
+	if s.params.QuarantinePath != "" {
+		if err := s.quarantineEntry(key, value); err != nil {
+			return false, err
+		}
+	}
+
+	if err := s.db.Delete(s.writeOpts, key); err != nil {
+		return false, err
+	}
+
+	if s.onRemove != nil {
+		s.onRemove(string(key))
+	}
+
+	return true, nil
+}
+
+func (s *Scrubber) quarantineEntry(key, value []byte) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.quarantine == nil {
+		opts := rocksdb.NewDefaultOptions()
+		defer opts.Destroy()
+		opts.SetCreateIfMissing(true)
+		db, err := rocksdb.OpenDb(opts, s.params.QuarantinePath)
+		if err != nil {
+			return err
+		}
+
+		s.quarantine = db
+	}
+
+	if err := s.quarantine.Put(s.writeOpts, key, value); err != nil {
+		return err
+	}
+
+	quarantinedEntries.Add(1)
+	return nil
+}
@@ -0,0 +1,79 @@
+package rdbstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rocksdb "github.com/tecbot/gorocksdb"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+func TestScrubber(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "cfr-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	params := DefaultParams(filepath.Join(tmpDir, "policy"))
+	defer params.Close()
+	policy, err := New(params, cfr.DiscountParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer policy.Close()
+
+	opt := cfr.New(policy)
+	runCFR(t, opt, policy, 10)
+
+	corruptKey := []byte("corrupt")
+	if err := policy.db.Put(params.WriteOptions, corruptKey, []byte{0xff, 0xff, 0xff}); err != nil {
+		t.Fatal(err)
+	}
+
+	quarantinePath := filepath.Join(tmpDir, "quarantine")
+	scrubber := policy.NewScrubber(ScrubParams{QuarantinePath: quarantinePath})
+	stats, err := scrubber.ScrubOnce()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Kuhn poker has 12 InfoSets.
+	if stats.Scrubbed != 13 || stats.Corrupt != 1 {
+		t.Errorf("expected 13 scrubbed and 1 corrupt entry, got %+v", stats)
+	}
+
+	if value, err := policy.db.GetBytes(params.ReadOptions, corruptKey); err != nil || value != nil {
+		t.Errorf("expected corrupt entry to be removed, got %v (err=%v)", value, err)
+	}
+
+	stats, err = scrubber.ScrubOnce()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Scrubbed != 12 || stats.Corrupt != 0 {
+		t.Errorf("expected 12 scrubbed and no corrupt entries, got %+v", stats)
+	}
+
+	scrubber.Start()
+	runCFR(t, opt, policy, 100)
+	scrubber.Stop()
+	// Stopping again must not release its resources twice.
+	scrubber.Stop()
+
+	opts := rocksdb.NewDefaultOptions()
+	defer opts.Destroy()
+	quarantine, err := rocksdb.OpenDb(opts, quarantinePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer quarantine.Close()
+
+	if value, err := quarantine.GetBytes(params.ReadOptions, corruptKey); err != nil || len(value) != 3 {
+		t.Errorf("expected corrupt entry to be quarantined, got %v (err=%v)", value, err)
+	}
+}
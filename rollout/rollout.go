@@ -0,0 +1,159 @@
+// Package rollout simulates complete games from any node of a game tree
+// with both players following given strategies. It is the basis for probing,
+// evaluation against fixed opponents, and variance-reduced value estimates.
+package rollout
+
+import (
+	"math/rand"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/sampling"
+)
+
+// Strategy returns the probability of choosing each child of a player node.
+// The returned slice may be reused by the Strategy between calls.
+type Strategy func(node cfr.GameTreeNode) []float32
+
+// CurrentStrategy returns a Strategy that plays the current strategy of
+// the given profile.
+func CurrentStrategy(profile cfr.StrategyProfile) Strategy {
+	return func(node cfr.GameTreeNode) []float32 {
+		return profile.GetPolicy(node).GetStrategy()
+	}
+}
+
+// AverageStrategy returns a Strategy that plays the average strategy of
+// the given profile.
+func AverageStrategy(profile cfr.StrategyProfile) Strategy {
+	return func(node cfr.GameTreeNode) []float32 {
+		return profile.GetPolicy(node).GetAverageStrategy()
+	}
+}
+
+// Uniform is a Strategy that chooses all actions with equal probability.
+func Uniform(node cfr.GameTreeNode) []float32 {
+	n := node.NumChildren()
+	p := make([]float32, n)
+	for i := range p {
+		p[i] = 1.0 / float32(n)
+	}
+
+	return p
+}
+
+// Step is a single action taken during a simulated game.
+type Step struct {
+	// Whether the action was taken by chance.
+	Chance bool
+	// The acting player, if the action was not taken by chance.
+	Player int
+	// The InfoSet key of the acting player, if the action was not taken by chance.
+	InfoSetKey string
+	// The index of the child that was chosen.
+	Action int
+	// The probability with which the action was chosen.
+	Probability float64
+}
+
+// Trajectory is the result of a simulated game.
+type Trajectory struct {
+	Steps []Step
+	// The utility of the terminal node for each player.
+	Utilities [2]float64
+}
+
+// Simulator simulates games with each player following a given Strategy.
+type Simulator struct {
+	strategies [2]Strategy
+	rng        *rand.Rand
+}
+
+// New returns a new Simulator in which player 0 follows s0 and player 1 follows s1.
+func New(s0, s1 Strategy) *Simulator {
+	return &Simulator{
+		strategies: [2]Strategy{s0, s1},
+		rng:        rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Seed sets the seed of the random number generator used to sample
+// chance outcomes and player actions, so that simulations may be reproduced.
+func (s *Simulator) Seed(seed int64) {
+	s.rng.Seed(seed)
+}
+
+// Simulate plays a single game from node to a terminal node and returns
+// its trajectory. The node itself is not closed, but all of its descendants
+// visited during the simulation are.
+func (s *Simulator) Simulate(node cfr.GameTreeNode) Trajectory {
+	var result Trajectory
+	var visited []cfr.GameTreeNode
+	for node.Type() != cfr.TerminalNodeType {
+		var step Step
+		if node.Type() == cfr.ChanceNodeType {
+			step.Chance = true
+			step.Action, step.Probability = s.sampleChance(node)
+		} else {
+			step.Player = node.Player()
+			step.InfoSetKey = node.InfoSet(step.Player).Key()
+			strategy := s.strategies[step.Player](node)
+			step.Action = sampling.SampleOne(strategy, s.rng.Float32())
+			step.Probability = float64(strategy[step.Action])
+		}
+
+		child := node.GetChild(step.Action)
+		result.Steps = append(result.Steps, step)
+		visited = append(visited, child)
+		node = child
+	}
+
+	result.Utilities[0] = node.Utility(0)
+	result.Utilities[1] = node.Utility(1)
+
+	// Close deepest nodes first, as required by cfr.SharedStateNode.
+	for i := len(visited) - 1; i >= 0; i-- {
+		visited[i].Close()
+	}
+
+	return result
+}
+
+// ExpectedUtilities estimates the expected utility of each player from node
+// by averaging over n simulated games.
+func (s *Simulator) ExpectedUtilities(node cfr.GameTreeNode, n int) [2]float64 {
+	var result [2]float64
+	for i := 0; i < n; i++ {
+		t := s.Simulate(node)
+		result[0] += t.Utilities[0]
+		result[1] += t.Utilities[1]
+	}
+
+	result[0] /= float64(n)
+	result[1] /= float64(n)
+	return result
+}
+
+// sampleChance samples a child of the given chance node according to
+// its probability distribution, and returns its index and probability.
+func (s *Simulator) sampleChance(node cfr.GameTreeNode) (int, float64) {
+	x := s.rng.Float64()
+	n := node.NumChildren()
+	var cumProb float64
+	for i := 0; i < n; i++ {
+		p := node.GetChildProbability(i)
+		cumProb += p
+		if cumProb > x {
+			return i, p
+		}
+	}
+
+	return n - 1, node.GetChildProbability(n - 1)
+}
+
+// NewLeafEvaluator returns a cfr.LeafEvaluator that estimates the value of
+// a node by averaging the utilities of n games simulated from it with s.
+func NewLeafEvaluator(s *Simulator, n int) cfr.LeafEvaluator {
+	return cfr.LeafEvaluatorFunc(func(node cfr.GameTreeNode, player int) float32 {
+		return float32(s.ExpectedUtilities(node, n)[player])
+	})
+}
@@ -0,0 +1,93 @@
+package rollout
+
+import (
+	"math"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestSimulate(t *testing.T) {
+	root := kuhn.NewGame()
+	sim := New(Uniform, Uniform)
+	sim.Seed(123)
+	for i := 0; i < 100; i++ {
+		traj := sim.Simulate(root)
+		if len(traj.Steps) < 4 {
+			t.Errorf("expected at least 4 steps, got %d: %v", len(traj.Steps), traj)
+		}
+
+		if traj.Utilities[0] != -traj.Utilities[1] {
+			t.Errorf("expected zero-sum utilities, got %v", traj.Utilities)
+		}
+
+		for _, step := range traj.Steps {
+			if !step.Chance && step.Probability != 0.5 {
+				t.Errorf("expected uniform action probability, got %v", step)
+			}
+		}
+	}
+}
+
+func TestSimulate_Reproducible(t *testing.T) {
+	root := kuhn.NewGame()
+	sim := New(Uniform, Uniform)
+	sim.Seed(42)
+	first := sim.Simulate(root)
+	sim.Seed(42)
+	second := sim.Simulate(root)
+	if len(first.Steps) != len(second.Steps) {
+		t.Fatalf("expected identical trajectories, got %v and %v", first, second)
+	}
+
+	for i := range first.Steps {
+		if first.Steps[i] != second.Steps[i] {
+			t.Errorf("expected identical trajectories, got %v and %v", first, second)
+		}
+	}
+}
+
+func TestExpectedUtilities(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	vanilla := cfr.New(policy)
+	for i := 0; i < 1000; i++ {
+		vanilla.Run(root)
+		policy.Update()
+	}
+
+	sim := New(AverageStrategy(policy), AverageStrategy(policy))
+	sim.Seed(123)
+	ev := sim.ExpectedUtilities(root, 100000)
+	// The value of Kuhn poker for player 0 is -1/18.
+	if math.Abs(ev[0]+1.0/18) > 0.02 {
+		t.Errorf("expected game value near -1/18, got %v", ev)
+	}
+}
+
+func TestNewLeafEvaluator(t *testing.T) {
+	root := kuhn.NewGame()
+	sim := New(Uniform, Uniform)
+	sim.Seed(123)
+	evaluator := NewLeafEvaluator(sim, 10000)
+	v0, v1 := evaluator.LeafValue(root, 0), evaluator.LeafValue(root, 1)
+	if math.Abs(float64(v0+v1)) > 0.05 {
+		t.Errorf("expected zero-sum values, got %v and %v", v0, v1)
+	}
+
+	// Depth-limited CFR with rollouts at the leaves still finds a
+	// strategy for the first action.
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	opt.SetDepthLimit(1, NewLeafEvaluator(sim, 100))
+	for i := 0; i < 100; i++ {
+		opt.Run(root)
+		policy.Update()
+	}
+
+	node := root.GetChild(int(kuhn.King)).GetChild(0)
+	if strategy := policy.GetPolicy(node).GetAverageStrategy(); strategy[1] < 0.5 {
+		t.Errorf("expected King to bet against a uniform opponent, got %v", strategy)
+	}
+}
@@ -0,0 +1,99 @@
+package sampling
+
+import (
+	"github.com/timpalpant/go-cfr"
+)
+
+// AdaptiveKParams are the parameters of an AdaptiveKSampler.
+type AdaptiveKParams struct {
+	// Range of the number of actions sampled.
+	MinK, MaxK int
+	// If the (smoothed) variance of the regret estimates of an iteration
+	// is above HighVariance, k is increased by one for the next iteration.
+	// If it is below LowVariance, k is decreased by one. Both are in units
+	// of squared utility, and depend on the game.
+	LowVariance, HighVariance float64
+	// Weight of the previous smoothed variance in the exponential moving
+	// average of the variance of each iteration. Zero disables smoothing.
+	Decay float64
+}
+
+// AdaptiveKSampler implements cfr.Sampler and cfr.AdaptiveSampler by wrapping a
+// RobustSampler and adapting its k on each iteration according to the observed
+// variance of the regret estimates: sampling more actions when they are noisy, and
+// fewer when they are not, so that k need not be tuned by hand to trade off the
+// cost of each iteration against its noise.
+//
+// The variance of an iteration is estimated as the mean square of all weighted
+// regret estimates observed during it, since instantaneous regrets have zero
+// expectation under the current strategy. The k of the wrapped RobustSampler is
+// overwritten, and so has no effect if it has a KFunc.
+type AdaptiveKSampler struct {
+	rs     *RobustSampler
+	params AdaptiveKParams
+
+	// Sum of squared regret estimates and number of
+	// estimates observed in the current iteration.
+	sumSq float64
+	n     int
+	// Smoothed variance, if any iteration has been observed.
+	variance float64
+	observed bool
+}
+
+// NewAdaptiveKSampler returns a new AdaptiveKSampler that adapts the k of rs.
+// The initial k of rs is clamped to [params.MinK, params.MaxK].
+func NewAdaptiveKSampler(rs *RobustSampler, params AdaptiveKParams) *AdaptiveKSampler {
+	rs.k = min(max(rs.k, params.MinK), params.MaxK)
+	return &AdaptiveKSampler{
+		rs:     rs,
+		params: params,
+	}
+}
+
+// K returns the number of actions that are currently sampled.
+func (a *AdaptiveKSampler) K() int {
+	return a.rs.k
+}
+
+// Variance returns the smoothed variance of the regret estimates
+// of the previous iterations.
+func (a *AdaptiveKSampler) Variance() float64 {
+	return a.variance
+}
+
+// Sample implements cfr.Sampler.
+func (a *AdaptiveKSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
+	return a.rs.Sample(node, policy)
+}
+
+// ObserveRegrets implements cfr.AdaptiveSampler.
+func (a *AdaptiveKSampler) ObserveRegrets(node cfr.GameTreeNode, weight float32, regrets []float32) {
+	for _, r := range regrets {
+		x := float64(weight * r)
+		a.sumSq += x * x
+	}
+
+	a.n += len(regrets)
+}
+
+// EndIteration implements cfr.AdaptiveSampler.
+func (a *AdaptiveKSampler) EndIteration() {
+	if a.n == 0 {
+		return
+	}
+
+	variance := a.sumSq / float64(a.n)
+	a.sumSq, a.n = 0, 0
+	if a.observed {
+		variance = a.params.Decay*a.variance + (1-a.params.Decay)*variance
+	}
+
+	a.variance = variance
+	a.observed = true
+	if variance > a.params.HighVariance && a.rs.k < a.params.MaxK {
+		a.rs.k++
+	} else if variance < a.params.LowVariance && a.rs.k > a.params.MinK {
+		a.rs.k--
+	}
+}
@@ -0,0 +1,61 @@
+package sampling
+
+import (
+	"testing"
+)
+
+func TestAdaptiveKSampler(t *testing.T) {
+	s := NewAdaptiveKSampler(NewRobustSampler(10), AdaptiveKParams{
+		MinK:         1,
+		MaxK:         3,
+		LowVariance:  1.0,
+		HighVariance: 4.0,
+	})
+
+	if s.K() != 3 {
+		t.Fatalf("expected initial k to be clamped to 3, got %d", s.K())
+	}
+
+	node := &fakeNode{nChildren: 5}
+	iterate := func(weight float32, regrets []float32) {
+		s.ObserveRegrets(node, weight, regrets)
+		s.EndIteration()
+	}
+
+	// Mean square of 0.5 * [2, -2] is 1: within the target range.
+	iterate(0.5, []float32{2, -2})
+	if s.K() != 3 || s.Variance() != 1.0 {
+		t.Errorf("expected k = 3 and variance 1, got k = %d and variance %v", s.K(), s.Variance())
+	}
+
+	for _, expected := range []int{2, 1, 1} {
+		iterate(0.1, []float32{1, -1})
+		if s.K() != expected {
+			t.Errorf("expected k to decrease to %d, got %d", expected, s.K())
+		}
+	}
+
+	for _, expected := range []int{2, 3, 3} {
+		iterate(10, []float32{1, -1})
+		if s.K() != expected {
+			t.Errorf("expected k to increase to %d, got %d", expected, s.K())
+		}
+	}
+
+	// Iterations without any observations do not change k.
+	s.EndIteration()
+	if s.K() != 3 {
+		t.Errorf("expected k to be unchanged, got %d", s.K())
+	}
+
+	nSampled := 0
+	for _, p := range s.Sample(node, nil) {
+		if p > 0 {
+			nSampled++
+		}
+	}
+
+	if nSampled != 3 {
+		t.Errorf("expected 3 sampled actions, got %d", nSampled)
+	}
+}
@@ -5,17 +5,30 @@ import (
 
 	"github.com/timpalpant/go-cfr"
 	"github.com/timpalpant/go-cfr/internal/f32"
-	"github.com/timpalpant/go-cfr/internal/policy"
 )
 
+// AverageStrategyParams are the parameters of average strategy sampling.
+// Each action a is sampled with probability
+//
+//	max(Epsilon, (Beta + Tau*s(a)) / (Beta + sum(s)))
+//
+// where s is the accumulated (unnormalized) average strategy.
 type AverageStrategyParams struct {
+	// Minimum probability of sampling any action.
 	Epsilon float32
-	Tau     float32
-	Beta    float32
+	// Emphasis on sampling actions with high average strategy probability.
+	Tau float32
+	// Bonus that causes all actions to be sampled early in training,
+	// before the average strategy is reliable.
+	Beta float32
 }
 
 // AverageStrategySampler implements cfr.Sampler by sampling some player actions
-// according to the current average strategy strategy.
+// according to the current average strategy, as in average strategy
+// sampling MC-CFR. See: https://papers.nips.cc/paper/4569-efficient-monte-carlo-counterfactual-regret-minimization-in-games-with-many-player-actions.pdf
+//
+// Each action is sampled independently. If the NodePolicy does not expose its
+// accumulated strategy sum, all actions are sampled (as in external sampling).
 type AverageStrategySampler struct {
 	params AverageStrategyParams
 	rng    *rand.Rand
@@ -29,16 +42,28 @@ func NewAverageStrategySampler(params AverageStrategyParams) *AverageStrategySam
 	}
 }
 
-func (as *AverageStrategySampler) Sample(node cfr.GameTreeNode, pol cfr.NodePolicy) []float32 {
+type strategySummer interface {
+	GetStrategySum() []float32
+}
+
+func (as *AverageStrategySampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
 	nChildren := node.NumChildren()
 	as.p = extend(as.p, nChildren)
 
-	x := as.rng.Float32()
-	s := pol.(*policy.Policy).GetStrategySum()
+	ss, ok := policy.(strategySummer)
+	if !ok {
+		for i := range as.p {
+			as.p[i] = 1.0
+		}
+
+		return as.p
+	}
+
+	s := ss.GetStrategySum()
 	sSum := f32.Sum(s)
 	for i := range as.p {
 		rho := computeRho(s[i], sSum, as.params)
-		if x < rho {
+		if as.rng.Float32() < rho {
 			as.p[i] = minF32(rho, 1.0)
 		} else {
 			as.p[i] = 0
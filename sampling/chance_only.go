@@ -0,0 +1,28 @@
+package sampling
+
+import "github.com/timpalpant/go-cfr"
+
+// ChanceOnlySampler implements cfr.Sampler and cfr.OpponentSampler by
+// traversing all actions of both players, so that only chance nodes are sampled.
+// With cfr.MCCFR, this is chance sampling CFR with alternating updates.
+type ChanceOnlySampler struct {
+	p []float32
+}
+
+func NewChanceOnlySampler() *ChanceOnlySampler {
+	return &ChanceOnlySampler{}
+}
+
+func (cs *ChanceOnlySampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
+	nChildren := node.NumChildren()
+	for len(cs.p) < nChildren {
+		cs.p = append(cs.p, 1.0)
+	}
+
+	return cs.p[:nChildren]
+}
+
+// SampleOpponent implements cfr.OpponentSampler.
+func (cs *ChanceOnlySampler) SampleOpponent(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
+	return cs.Sample(node, policy)
+}
@@ -47,7 +47,7 @@ func (os *MultiOutcomeSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePoli
 	}
 
 	q := os.pool.alloc(nChildren)
-	copy(q, policy.GetStrategy())
+	policy.CopyStrategy(q)
 	f32.AddConst(os.eps/float32(nChildren), q)
 	f32.ScalUnitary(1.0/f32.Sum(q), q) // Renormalize.
 
@@ -21,6 +21,11 @@ func NewOutcomeSampler(explorationEps float32) *OutcomeSampler {
 	}
 }
 
+// Seed sets the seed of the random number generator used to sample actions.
+func (os *OutcomeSampler) Seed(seed int64) {
+	os.rng.Seed(seed)
+}
+
 func (os *OutcomeSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
 	nChildren := node.NumChildren()
 
@@ -0,0 +1,99 @@
+package sampling
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// RegretPruningSampler implements cfr.Sampler by wrapping another Sampler
+// and pruning actions whose accumulated regret is below a threshold.
+// See: https://arxiv.org/abs/1805.08195 and the Pluribus supplement.
+//
+// Regret estimates in the sampling runners are noisy, so an action is only
+// pruned once its regret is confidently below the threshold: the regret sum r
+// after n samples must be below the threshold by more than s*sqrt(n), where s
+// is the spread of the instantaneous regrets of the action observed in those
+// samples. Equivalently, the mean regret per sample r/n must be below threshold/n
+// by more than s/sqrt(n), a margin that narrows as samples accumulate. The samples
+// are those tracked for dominance checking, so actions are only pruned in policies
+// with dominance checking enabled (see cfr.DiscountParams.DominanceMinSamples).
+//
+// Even then an action is never pruned outright: it is still traversed with
+// probability explore, and its sampling probability is scaled by explore so
+// that the resulting counterfactual value estimates remain unbiased.
+type RegretPruningSampler struct {
+	sampler   cfr.Sampler
+	threshold float32
+	explore   float32
+	rng       *rand.Rand
+	p         []float32
+
+	numPruned int
+}
+
+type regretSummer interface {
+	GetRegretSum() []float32
+}
+
+type regretBounder interface {
+	GetRegretBounds() (lo, hi []float32, numSamples uint32)
+}
+
+// NewRegretPruningSampler returns a new RegretPruningSampler that prunes the
+// actions sampled by sampler when their regret is confidently below threshold.
+func NewRegretPruningSampler(sampler cfr.Sampler, threshold, explore float32) *RegretPruningSampler {
+	return &RegretPruningSampler{
+		sampler:   sampler,
+		threshold: threshold,
+		explore:   explore,
+		rng:       rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Seed sets the seed of the random number generator used to explore pruned actions.
+func (rp *RegretPruningSampler) Seed(seed int64) {
+	rp.rng.Seed(seed)
+}
+
+// NumPruned returns the number of times an action has been pruned.
+func (rp *RegretPruningSampler) NumPruned() int {
+	return rp.numPruned
+}
+
+func (rp *RegretPruningSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
+	q := rp.sampler.Sample(node, policy)
+	rp.p = extend(rp.p, len(q))
+	copy(rp.p, q)
+
+	rs, ok := policy.(regretSummer)
+	if !ok || policy.IsEmpty() {
+		return rp.p
+	}
+
+	rb, ok := policy.(regretBounder)
+	if !ok {
+		return rp.p
+	}
+
+	lo, hi, n := rb.GetRegretBounds()
+	if n == 0 {
+		return rp.p
+	}
+
+	sqrtN := float32(math.Sqrt(float64(n)))
+	regrets := rs.GetRegretSum()
+	for i, r := range regrets {
+		if rp.p[i] > 0 && r+(hi[i]-lo[i])*sqrtN < rp.threshold {
+			rp.numPruned++
+			if rp.rng.Float32() < rp.explore {
+				rp.p[i] *= rp.explore
+			} else {
+				rp.p[i] = 0
+			}
+		}
+	}
+
+	return rp.p
+}
@@ -1,31 +1,98 @@
 package sampling
 
 import (
+	"math"
 	"math/rand"
+	"reflect"
 
 	"github.com/timpalpant/go-cfr"
 )
 
 // RobustSampler implements cfr.Sampler by sampling a fixed number of actions
-// uniformly randomly.
+// uniformly randomly, or (if weighted) with probability according to the
+// current strategy.
 type RobustSampler struct {
 	p   []float32
 	k   int
 	rng *rand.Rand
+
+	weighted bool
+	eps      float32
+	w        []float32
+
+	kFn func(player, depth int) int
+	// The most recently sampled node and those of its ancestors that were
+	// sampled, with their depths, used to find the depth of the next node.
+	path []sampledNode
+}
+
+type sampledNode struct {
+	node  cfr.GameTreeNode
+	depth int
 }
 
-func NewRobustSampler(k int) *RobustSampler {
-	return &RobustSampler{
+// RobustSamplerOption configures a RobustSampler.
+type RobustSamplerOption func(rs *RobustSampler)
+
+// WithKFunc sets a function that returns the number of actions to sample at each
+// node, given its player and depth (the number of its ancestors, with the root at
+// depth 0), in place of the fixed k. This is useful in games where the branching
+// factor differs greatly between players or over the course of the game.
+// Values less than 1 are treated as 1.
+//
+// Depths are found from the nodes previously sampled on the path to each node, so
+// finding them is cheap for runners that traverse the tree depth-first.
+func WithKFunc(k func(player, depth int) int) RobustSamplerOption {
+	return func(rs *RobustSampler) {
+		rs.kFn = k
+	}
+}
+
+// NewRobustSampler returns a RobustSampler that samples k actions uniformly
+// at random at each node.
+func NewRobustSampler(k int, opts ...RobustSamplerOption) *RobustSampler {
+	rs := &RobustSampler{
 		k:   k,
 		rng: rand.New(rand.NewSource(rand.Int63())),
 	}
+
+	for _, opt := range opts {
+		opt(rs)
+	}
+
+	return rs
+}
+
+// Seed sets the seed of the random number generator used to sample actions.
+func (rs *RobustSampler) Seed(seed int64) {
+	rs.rng.Seed(seed)
+}
+
+// NewWeightedRobustSampler returns a RobustSampler that samples k distinct actions
+// with probability proportional to the current strategy mixed with exploration eps,
+// rather than uniformly, so that fewer traversals are spent on clearly bad actions.
+//
+// Each action i is sampled with probability min(1, c*x_i), where x is the mixed
+// strategy and c is chosen so that exactly k actions are sampled, and these
+// probabilities are returned for the importance corrections.
+// With eps = 1, every action is sampled with probability k/n, as with NewRobustSampler.
+func NewWeightedRobustSampler(k int, explorationEps float32, opts ...RobustSamplerOption) *RobustSampler {
+	rs := NewRobustSampler(k, opts...)
+	rs.weighted = true
+	rs.eps = explorationEps
+	return rs
 }
 
 func (rs *RobustSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
 	nChildren := node.NumChildren()
 	rs.p = extend(rs.p, nChildren)
 
-	if nChildren <= rs.k {
+	k := rs.k
+	if rs.kFn != nil {
+		k = max(rs.kFn(node.Player(), rs.depth(node)), 1)
+	}
+
+	if nChildren <= k {
 		for i := range rs.p[:nChildren] {
 			rs.p[i] = 1.0
 		}
@@ -33,11 +100,15 @@ func (rs *RobustSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []
 		return rs.p[:nChildren]
 	}
 
-	for i := 0; i < rs.k; i++ {
-		rs.p[i] = float32(rs.k) / float32(nChildren)
+	if rs.weighted {
+		return rs.sampleWeighted(policy, k)
+	}
+
+	for i := 0; i < k; i++ {
+		rs.p[i] = float32(k) / float32(nChildren)
 	}
 
-	for i := rs.k; i < nChildren; i++ {
+	for i := k; i < nChildren; i++ {
 		rs.p[i] = 0
 	}
 
@@ -47,3 +118,101 @@ func (rs *RobustSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []
 
 	return rs.p
 }
+
+// sampleWeighted samples k actions with systematic sampling, which selects
+// exactly k of them with the inclusion probabilities given by inclusionProbs.
+func (rs *RobustSampler) sampleWeighted(policy cfr.NodePolicy, k int) []float32 {
+	nChildren := len(rs.p)
+	rs.w = extend(rs.w, nChildren)
+	for i, p := range policy.GetStrategy() {
+		rs.w[i] = rs.eps/float32(nChildren) + (1.0-rs.eps)*p
+	}
+
+	inclusionProbs(rs.w, k)
+
+	// Action i is selected if one of the points u, u+1, ..., u+k-1 falls
+	// within its interval of length w[i] <= 1.
+	u := float64(rs.rng.Float32())
+	var cumProb float64
+	for i, w := range rs.w {
+		start := cumProb
+		cumProb += float64(w)
+		if math.Floor(cumProb-u) > math.Floor(start-u) {
+			rs.p[i] = w
+		} else {
+			rs.p[i] = 0
+		}
+	}
+
+	return rs.p
+}
+
+// depth returns the number of ancestors of node. Its ancestors are followed only
+// until one that was previously sampled is found on the path, whose depth is known.
+func (rs *RobustSampler) depth(node cfr.GameTreeNode) int {
+	d := 0
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		d++
+		if i := rs.findOnPath(parent); i >= 0 {
+			d += rs.path[i].depth
+			rs.path = append(rs.path[:i+1], sampledNode{node, d})
+			return d
+		}
+	}
+
+	rs.path = append(rs.path[:0], sampledNode{node, d})
+	return d
+}
+
+// findOnPath returns the index of node in the path of sampled nodes, or -1.
+// Nodes whose type is not comparable are never found.
+func (rs *RobustSampler) findOnPath(node cfr.GameTreeNode) int {
+	if !reflect.TypeOf(node).Comparable() {
+		return -1
+	}
+
+	for i := len(rs.path) - 1; i >= 0; i-- {
+		if reflect.TypeOf(rs.path[i].node) == reflect.TypeOf(node) && rs.path[i].node == node {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// inclusionProbs replaces the probability distribution x with the probabilities
+// min(1, c*x_i) that each element is included in a sample of size k < len(x),
+// where c is chosen so that they sum to k.
+func inclusionProbs(x []float32, k int) {
+	for {
+		var sum float32
+		nRemaining := k
+		for _, xi := range x {
+			if xi >= 1 {
+				nRemaining--
+			} else {
+				sum += xi
+			}
+		}
+
+		if sum == 0 {
+			return
+		}
+
+		c := float32(nRemaining) / sum
+		capped := false
+		for i, xi := range x {
+			if xi < 1 {
+				x[i] = xi * c
+				if x[i] >= 1 {
+					x[i] = 1
+					capped = true
+				}
+			}
+		}
+
+		if !capped {
+			return
+		}
+	}
+}
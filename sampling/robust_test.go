@@ -0,0 +1,124 @@
+package sampling
+
+import (
+	"math"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+type fakeNode struct {
+	cfr.GameTreeNode
+	nChildren int
+	player    int
+	parent    *fakeNode
+}
+
+func (n *fakeNode) NumChildren() int { return n.nChildren }
+func (n *fakeNode) Player() int      { return n.player }
+
+func (n *fakeNode) Parent() cfr.GameTreeNode {
+	if n.parent == nil {
+		return nil
+	}
+
+	return n.parent
+}
+
+type fakePolicy struct {
+	cfr.NodePolicy
+	strategy []float32
+}
+
+func (p *fakePolicy) GetStrategy() []float32 { return p.strategy }
+
+func TestInclusionProbs(t *testing.T) {
+	x := []float32{0.6, 0.2, 0.1, 0.1}
+	inclusionProbs(x, 2)
+	expected := []float32{1, 0.5, 0.25, 0.25}
+	for i := range x {
+		if math.Abs(float64(x[i]-expected[i])) > 1e-6 {
+			t.Errorf("expected inclusion probabilities %v, got %v", expected, x)
+			break
+		}
+	}
+}
+
+func TestWeightedRobustSampler(t *testing.T) {
+	strategy := []float32{0.7, 0.2, 0.05, 0.05}
+	node := &fakeNode{nChildren: len(strategy)}
+	policy := &fakePolicy{strategy: strategy}
+	k := 2
+	s := NewWeightedRobustSampler(k, 0.2)
+
+	nSamples := 100000
+	counts := make([]int, len(strategy))
+	for i := 0; i < nSamples; i++ {
+		p := s.Sample(node, policy)
+		nSampled := 0
+		for j, pj := range p {
+			if pj > 0 {
+				counts[j]++
+				nSampled++
+			}
+		}
+
+		if nSampled != k {
+			t.Fatalf("expected %d sampled actions, got %d: %v", k, nSampled, p)
+		}
+	}
+
+	w := []float32{0.61, 0.21, 0.09, 0.09}
+	inclusionProbs(w, k)
+	for j, c := range counts {
+		freq := float32(c) / float32(nSamples)
+		if math.Abs(float64(freq-w[j])) > 0.01 {
+			t.Errorf("action %d: expected to be sampled with probability %v, got %v", j, w[j], freq)
+		}
+	}
+}
+
+func TestRobustSampler_KFunc(t *testing.T) {
+	root := &fakeNode{nChildren: 5}
+	child := &fakeNode{nChildren: 5, player: 1, parent: root}
+	grandchild := &fakeNode{nChildren: 5, player: 0, parent: child}
+	policy := &fakePolicy{strategy: []float32{0.2, 0.2, 0.2, 0.2, 0.2}}
+
+	s := NewRobustSampler(1, WithKFunc(func(player, depth int) int {
+		return 1 + player + depth
+	}))
+
+	// Nodes are sampled in random order, so their depths are not
+	// always found from the previously sampled node.
+	for node, expected := range map[*fakeNode]int{root: 1, child: 3, grandchild: 3} {
+		nSampled := 0
+		for _, p := range s.Sample(node, policy) {
+			if p > 0 {
+				nSampled++
+			}
+		}
+
+		if nSampled != expected {
+			t.Errorf("expected %d sampled actions for player %d, got %d", expected, node.player, nSampled)
+		}
+	}
+}
+
+func TestRobustSampler_KFuncNonPositive(t *testing.T) {
+	node := &fakeNode{nChildren: 5}
+	policy := &fakePolicy{strategy: []float32{0.2, 0.2, 0.2, 0.2, 0.2}}
+	s := NewRobustSampler(1, WithKFunc(func(player, depth int) int {
+		return 0
+	}))
+
+	nSampled := 0
+	for _, p := range s.Sample(node, policy) {
+		if p > 0 {
+			nSampled++
+		}
+	}
+
+	if nSampled != 1 {
+		t.Errorf("expected 1 sampled action, got %d", nSampled)
+	}
+}
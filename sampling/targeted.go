@@ -0,0 +1,123 @@
+package sampling
+
+import (
+	"math/rand"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+// TargetedSampler implements cfr.Sampler, cfr.ChanceSampler and cfr.OpponentSampler
+// to bias the outcomes sampled by MCCFR towards targeted subtrees of the game,
+// for example the situations observed in live play, as in the targeting of
+// Online Outcome Sampling (Lisý, Lanctot & Bowling, 2015).
+//
+// A single outcome is sampled at every node. With probability delta, it is sampled
+// from the children for which target returns true (those in or leading to a targeted
+// subtree), and otherwise from all children: chance outcomes according to their
+// probability, opponent actions according to the current strategy, and actions
+// of the traversing player as for OutcomeSampler with exploration eps.
+// MCCFR corrects the values of sampled outcomes by their sampling probability,
+// so regrets and average strategies remain unbiased estimates over the whole game,
+// while iterations concentrate on the targeted subtrees.
+//
+// Targeting requires evaluating target on all children of every sampled node.
+// Each child is closed after evaluating target.
+type TargetedSampler struct {
+	target func(node cfr.GameTreeNode) bool
+	delta  float32
+	eps    float32
+	rng    *rand.Rand
+
+	dist     []float32
+	targeted []bool
+	p        []float32
+}
+
+// NewTargetedSampler returns a new TargetedSampler that samples from the children
+// for which target returns true with probability delta, and with exploration eps
+// at nodes of the traversing player. Delta must be less than 1, so that every
+// outcome may still be sampled.
+func NewTargetedSampler(target func(node cfr.GameTreeNode) bool, delta, explorationEps float32) *TargetedSampler {
+	return &TargetedSampler{
+		target: target,
+		delta:  delta,
+		eps:    explorationEps,
+		rng:    rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Seed sets the seed of the random number generator used to sample actions.
+func (ts *TargetedSampler) Seed(seed int64) {
+	ts.rng.Seed(seed)
+}
+
+// Sample implements cfr.Sampler.
+func (ts *TargetedSampler) Sample(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
+	nChildren := node.NumChildren()
+	ts.dist = extend(ts.dist, nChildren)
+	strategy := policy.GetStrategy()
+	for i, p := range strategy {
+		ts.dist[i] = ts.eps/float32(nChildren) + (1.0-ts.eps)*p
+	}
+
+	return ts.sampleTargeted(node)
+}
+
+// SampleChance implements cfr.ChanceSampler.
+func (ts *TargetedSampler) SampleChance(node cfr.GameTreeNode) []float32 {
+	nChildren := node.NumChildren()
+	ts.dist = extend(ts.dist, nChildren)
+	for i := range ts.dist {
+		ts.dist[i] = float32(node.GetChildProbability(i))
+	}
+
+	return ts.sampleTargeted(node)
+}
+
+// SampleOpponent implements cfr.OpponentSampler.
+func (ts *TargetedSampler) SampleOpponent(node cfr.GameTreeNode, policy cfr.NodePolicy) []float32 {
+	ts.dist = extend(ts.dist, node.NumChildren())
+	copy(ts.dist, policy.GetStrategy())
+	return ts.sampleTargeted(node)
+}
+
+// sampleTargeted samples a single child of node from the mixture of ts.dist and
+// ts.dist restricted to the targeted children, and returns its sampling probability.
+func (ts *TargetedSampler) sampleTargeted(node cfr.GameTreeNode) []float32 {
+	nChildren := len(ts.dist)
+	ts.targeted = ts.targeted[:0]
+	var targetedProb float32
+	for i := 0; i < nChildren; i++ {
+		child := node.GetChild(i)
+		isTargeted := ts.target(child)
+		child.Close()
+		ts.targeted = append(ts.targeted, isTargeted)
+		if isTargeted {
+			targetedProb += ts.dist[i]
+		}
+	}
+
+	// Targeting has no effect if none (or all) of the children are targeted.
+	delta := ts.delta
+	if targetedProb == 0 {
+		delta = 0
+	}
+
+	for i, p := range ts.dist {
+		q := (1.0 - delta) * p
+		if ts.targeted[i] && delta > 0 {
+			q += delta * p / targetedProb
+		}
+
+		ts.dist[i] = q
+	}
+
+	selected := SampleOne(ts.dist, ts.rng.Float32())
+	ts.p = extend(ts.p, nChildren)
+	for i := range ts.p {
+		ts.p[i] = 0
+	}
+
+	ts.p[selected] = ts.dist[selected]
+	return ts.p
+}
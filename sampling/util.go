@@ -27,7 +27,8 @@ func SampleChanceNode(node cfr.GameTreeNode) (cfr.GameTreeNode, float64) {
 			cumProb, node, n))
 	}
 
-	return node.GetChild(n - 1), node.GetChildProbability(n - 1)
+	p := node.GetChildProbability(n - 1)
+	return node.GetChild(n - 1), p
 }
 
 // SampleOne returns the first element i of pv where sum(pv[:i]) > x.
@@ -0,0 +1,50 @@
+package cfr
+
+// PlayerSchedule returns the players whose regrets are updated (the traversing
+// players) on the given iteration. Runners that traverse for a single player at
+// a time perform one traversal for each of them, in order, all reading the same
+// current strategies since the strategy profile is not updated in between.
+// The returned slice must not be modified, and must not be empty.
+//
+// Runners that support schedules use AlternatingUpdates unless another
+// schedule is set with their SetPlayerSchedule method.
+type PlayerSchedule func(iter int) []int
+
+var (
+	alternatingPlayers = [2][]int{{0}, {1}}
+	bothPlayers        = []int{0, 1}
+)
+
+// AlternatingUpdates is the default PlayerSchedule, which updates player 0
+// on even iterations and player 1 on odd iterations.
+func AlternatingUpdates(iter int) []int {
+	return alternatingPlayers[iter%2]
+}
+
+// SimultaneousUpdates is a PlayerSchedule that updates both players on every
+// iteration. It generally converges more slowly than alternating updates per
+// traversal, but is the setting of much of the theoretical analysis of CFR.
+func SimultaneousUpdates(iter int) []int {
+	return bothPlayers
+}
+
+// runScheduled calls traverse for each player of the given iteration of schedule
+// (or AlternatingUpdates if nil), and returns the mean of the returned values,
+// or 0 if the schedule returns no players.
+func runScheduled(schedule PlayerSchedule, iter int, traverse func(player int) float32) float32 {
+	if schedule == nil {
+		schedule = AlternatingUpdates
+	}
+
+	players := schedule(iter)
+	if len(players) == 0 {
+		return 0
+	}
+
+	var total float32
+	for _, player := range players {
+		total += traverse(player)
+	}
+
+	return total / float32(len(players))
+}
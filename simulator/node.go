@@ -0,0 +1,188 @@
+// Package simulator adapts step-based game simulators, which advance a single
+// mutable game state by applying actions, to the cfr.GameTreeNode interface.
+// This allows existing game engines to be used with CFR without rewriting
+// them as game trees.
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/sampling"
+)
+
+// Simulator is a game engine holding the mutable state of a single game.
+// Its methods describing the current state have the same semantics as
+// the corresponding methods of cfr.GameTreeNode.
+type Simulator interface {
+	Type() cfr.NodeType
+	Player() int
+	InfoSet(player int) cfr.InfoSet
+	Utility(player int) float64
+
+	// NumActions returns the number of actions available in the current state.
+	NumActions() int
+	// ActionProbability returns the probability of the ith action.
+	// It is only called in chance states.
+	ActionProbability(i int) float64
+	// Apply advances the game by taking the ith action in the current state.
+	Apply(i int)
+	// Clone returns a deep copy of the simulator. Applying actions to the
+	// copy must not affect the original, and vice versa.
+	Clone() Simulator
+}
+
+// Undoer is an optional interface that may be implemented by a Simulator
+// to take back the most recently applied action that has not yet been undone.
+type Undoer interface {
+	Undo()
+}
+
+// Node implements cfr.GameTreeNode for the state of a Simulator.
+//
+// If the simulator implements Undoer, all nodes share a single simulator:
+// a child is created by applying its action, and closing it undoes the action.
+// Only one child of each node may then be open at a time, as described by
+// cfr.SharedStateNode.
+//
+// Otherwise, each node owns its own copy of the simulator. Children are
+// created lazily by cloning the parent's simulator and applying the
+// corresponding action, and are released by Close.
+type Node struct {
+	parent   *Node
+	sim      Simulator
+	children []Node
+
+	undoer  Undoer
+	applied bool // Whether this node's action must be undone when it is closed.
+}
+
+// NewGame returns the root node of the game tree starting from the current
+// state of sim. The returned node takes ownership of sim, which must not be
+// modified by the caller afterwards.
+func NewGame(sim Simulator) *Node {
+	undoer, _ := sim.(Undoer)
+	return &Node{sim: sim, undoer: undoer}
+}
+
+// Simulator returns the simulator in the state represented by this node.
+// It must not be modified.
+func (n *Node) Simulator() Simulator {
+	return n.sim
+}
+
+// SharesState implements cfr.SharedStateNode.
+func (n *Node) SharesState() bool {
+	return n.undoer != nil
+}
+
+// String implements fmt.Stringer.
+func (n *Node) String() string {
+	return fmt.Sprint(n.sim)
+}
+
+// Type implements cfr.GameTreeNode.
+func (n *Node) Type() cfr.NodeType {
+	return n.sim.Type()
+}
+
+// Close implements cfr.GameTreeNode.
+func (n *Node) Close() {
+	if n.applied {
+		n.undoer.Undo()
+		n.applied = false
+	}
+
+	n.children = nil
+}
+
+// NumChildren implements cfr.GameTreeNode.
+func (n *Node) NumChildren() int {
+	return n.sim.NumActions()
+}
+
+// GetChild implements cfr.GameTreeNode.
+func (n *Node) GetChild(i int) cfr.GameTreeNode {
+	if n.undoer != nil {
+		return n.applyChild(i)
+	}
+
+	if n.children == nil {
+		n.buildChildren()
+	}
+
+	child := &n.children[i]
+	if child.sim == nil {
+		child.sim = n.sim.Clone()
+		child.sim.Apply(i)
+	}
+
+	return child
+}
+
+// Parent implements cfr.GameTreeNode.
+func (n *Node) Parent() cfr.GameTreeNode {
+	if n.parent == nil {
+		return nil
+	}
+
+	return n.parent
+}
+
+// GetChildProbability implements cfr.GameTreeNode.
+func (n *Node) GetChildProbability(i int) float64 {
+	return n.sim.ActionProbability(i)
+}
+
+// SampleChild implements cfr.GameTreeNode.
+func (n *Node) SampleChild() (cfr.GameTreeNode, float64) {
+	return sampling.SampleChanceNode(n)
+}
+
+// Player implements cfr.GameTreeNode.
+func (n *Node) Player() int {
+	return n.sim.Player()
+}
+
+// InfoSet implements cfr.GameTreeNode.
+func (n *Node) InfoSet(player int) cfr.InfoSet {
+	return n.sim.InfoSet(player)
+}
+
+// Utility implements cfr.GameTreeNode.
+func (n *Node) Utility(player int) float64 {
+	return n.sim.Utility(player)
+}
+
+// applyChild advances the shared simulator to the ith child of this node.
+// A single child node is reused for all children, since only one may be open.
+func (n *Node) applyChild(i int) *Node {
+	if n.children == nil {
+		n.children = make([]Node, 1)
+	}
+
+	child := &n.children[0]
+	if child.applied {
+		panic(fmt.Errorf("simulator: child %d requested before closing previous child of node: %v", i, n))
+	}
+
+	n.sim.Apply(i)
+	*child = Node{
+		parent:  n,
+		sim:     n.sim,
+		undoer:  n.undoer,
+		applied: true,
+	}
+
+	return child
+}
+
+// buildChildren allocates (but does not populate) the children of this node.
+// Each child's simulator is cloned on first access, so that sampling
+// algorithms only pay for the children they actually visit.
+func (n *Node) buildChildren() {
+	n.children = make([]Node, n.sim.NumActions())
+	for i := range n.children {
+		n.children[i].parent = n
+	}
+}
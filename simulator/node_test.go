@@ -0,0 +1,232 @@
+package simulator
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+// kuhnSim is a step-based implementation of Kuhn poker.
+type kuhnSim struct {
+	cards   []int
+	history string
+}
+
+func (k *kuhnSim) Type() cfr.NodeType {
+	switch {
+	case len(k.cards) < 2:
+		return cfr.ChanceNodeType
+	case k.isTerminal():
+		return cfr.TerminalNodeType
+	}
+
+	return cfr.PlayerNodeType
+}
+
+func (k *kuhnSim) isTerminal() bool {
+	switch k.history {
+	case "cc", "cbc", "cbb", "bc", "bb":
+		return true
+	}
+
+	return false
+}
+
+func (k *kuhnSim) Player() int {
+	if len(k.cards) < 2 {
+		return -1
+	}
+
+	return len(k.history) % 2
+}
+
+type infoSet string
+
+func (s infoSet) Key() string                     { return string(s) }
+func (s infoSet) MarshalBinary() ([]byte, error)  { return []byte(s), nil }
+func (s *infoSet) UnmarshalBinary(b []byte) error { *s = infoSet(b); return nil }
+
+func (k *kuhnSim) InfoSet(player int) cfr.InfoSet {
+	s := infoSet(string(rune('0'+k.cards[player])) + "-" + k.history)
+	return &s
+}
+
+func (k *kuhnSim) Utility(player int) float64 {
+	winner := 0
+	if k.cards[1] > k.cards[0] {
+		winner = 1
+	}
+
+	var stake float64
+	switch k.history {
+	case "cbc", "bc":
+		// Last player folded, so the player to act wins the ante.
+		winner = len(k.history) % 2
+		stake = 1
+	case "cc":
+		stake = 1
+	default:
+		stake = 2
+	}
+
+	if player == winner {
+		return stake
+	}
+
+	return -stake
+}
+
+func (k *kuhnSim) NumActions() int {
+	switch {
+	case len(k.cards) < 2:
+		return 3 - len(k.cards)
+	case k.isTerminal():
+		return 0
+	}
+
+	return 2
+}
+
+func (k *kuhnSim) ActionProbability(i int) float64 {
+	return 1.0 / float64(k.NumActions())
+}
+
+func (k *kuhnSim) Apply(i int) {
+	if len(k.cards) < 2 {
+		for card := 0; card < 3; card++ {
+			if len(k.cards) == 1 && card == k.cards[0] {
+				continue
+			}
+
+			if i == 0 {
+				k.cards = append(k.cards, card)
+				return
+			}
+
+			i--
+		}
+	}
+
+	k.history += string("cb"[i])
+}
+
+func (k *kuhnSim) Clone() Simulator {
+	return &kuhnSim{
+		cards:   append([]int(nil), k.cards...),
+		history: k.history,
+	}
+}
+
+func TestNode_GameTree(t *testing.T) {
+	root := NewGame(&kuhnSim{})
+
+	if n := tree.CountNodes(root); n != 58 {
+		t.Errorf("expected %d nodes, got %d", 58, n)
+	}
+
+	if n := tree.CountTerminalNodes(root); n != 30 {
+		t.Errorf("expected %d terminal nodes, got %d", 30, n)
+	}
+
+	if n := tree.CountInfoSets(root); n != 12 {
+		t.Errorf("expected %d info sets, got %d", 12, n)
+	}
+}
+
+func TestNode_Clone(t *testing.T) {
+	root := NewGame(&kuhnSim{})
+	child := root.GetChild(1).(*Node)
+	if child.Parent() != root {
+		t.Error("expected child's parent to be root")
+	}
+
+	if len(root.Simulator().(*kuhnSim).cards) != 0 {
+		t.Error("applying an action to a child modified its parent")
+	}
+
+	if cards := child.Simulator().(*kuhnSim).cards; len(cards) != 1 || cards[0] != 1 {
+		t.Errorf("expected child to be dealt card 1, got %v", cards)
+	}
+}
+
+func TestNode_CFR(t *testing.T) {
+	root := NewGame(&kuhnSim{})
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	var ev float32
+	nIter := 1000
+	for i := 0; i < nIter; i++ {
+		ev += opt.Run(root)
+		policy.Update()
+	}
+
+	// The value of Kuhn poker is 1/18 (for the second player).
+	if ev /= float32(nIter); ev < 0.045 || ev > 0.065 {
+		t.Errorf("expected game value near 1/18, got %v", ev)
+	}
+}
+
+// kuhnUndoSim is kuhnSim with support for undoing actions.
+type kuhnUndoSim struct {
+	kuhnSim
+}
+
+func (k *kuhnUndoSim) Undo() {
+	if len(k.history) > 0 {
+		k.history = k.history[:len(k.history)-1]
+	} else {
+		k.cards = k.cards[:len(k.cards)-1]
+	}
+}
+
+func TestNode_UndoGameTree(t *testing.T) {
+	root := NewGame(&kuhnUndoSim{})
+	if !root.SharesState() {
+		t.Error("expected node with Undoer to share state")
+	}
+
+	if n := tree.CountNodes(root); n != 58 {
+		t.Errorf("expected %d nodes, got %d", 58, n)
+	}
+
+	if n := tree.CountInfoSets(root); n != 12 {
+		t.Errorf("expected %d info sets, got %d", 12, n)
+	}
+
+	if sim := root.Simulator().(*kuhnUndoSim); len(sim.cards) != 0 || sim.history != "" {
+		t.Errorf("expected simulator to be restored to initial state, got %+v", sim)
+	}
+}
+
+type runner interface {
+	Run(node cfr.GameTreeNode) float32
+}
+
+func TestNode_UndoRunners(t *testing.T) {
+	for name, newRunner := range map[string]func(cfr.StrategyProfile) runner{
+		"vanilla": func(p cfr.StrategyProfile) runner {
+			return cfr.New(p)
+		},
+		"external_sampling": func(p cfr.StrategyProfile) runner {
+			return cfr.NewExternalSampling(p)
+		},
+		"outcome_sampling": func(p cfr.StrategyProfile) runner {
+			return cfr.NewOutcomeSampling(p, 0.6)
+		},
+	} {
+		sim := &kuhnUndoSim{}
+		root := NewGame(sim)
+		policy := cfr.NewPrefetchingProfile(cfr.NewPolicyTable(cfr.DiscountParams{}))
+		policy.SetSerialized(true)
+		opt := newRunner(policy)
+		for i := 0; i < 100; i++ {
+			opt.Run(root)
+			policy.Update()
+		}
+
+		if len(sim.cards) != 0 || sim.history != "" {
+			t.Errorf("%s: expected simulator to be restored to initial state, got %+v", name, sim)
+		}
+	}
+}
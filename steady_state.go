@@ -0,0 +1,165 @@
+package cfr
+
+import (
+	"sync"
+)
+
+// SteadyStateParams control when a SteadyStateProfile considers training to have
+// reached a steady state.
+type SteadyStateParams struct {
+	// Smoothing factor of the exponential moving average of the per-iteration
+	// change in current strategies, in (0, 1]. Noisier (e.g. Monte Carlo) runners
+	// need more smoothing. Defaults to 0.01.
+	Smoothing float64
+	// The moving average must stay below Tolerance for Patience consecutive
+	// iterations. Defaults to 0.01 and 100, respectively.
+	Tolerance float64
+	Patience  int
+	// Steady state is not detected before MinIter, since early on strategies
+	// may change little between iterations simply because they are uniform.
+	MinIter int
+}
+
+func (p SteadyStateParams) withDefaults() SteadyStateParams {
+	if p.Smoothing == 0 {
+		p.Smoothing = 0.01
+	}
+
+	if p.Tolerance == 0 {
+		p.Tolerance = 0.01
+	}
+
+	if p.Patience == 0 {
+		p.Patience = 100
+	}
+
+	return p
+}
+
+// SteadyStateProfile wraps a StrategyProfile to detect when regrets have stabilized,
+// and calls a callback once when they have. This can be used to switch discounting
+// (e.g. from DCFR to CFR+ with PolicyTable.SetDiscountParams) or to reduce exploration
+// (with OutcomeSamplingCFR.SetExplorationEps) without restarting training.
+//
+// Stability is measured by the mean L1 distance between the current strategy
+// of each InfoSet touched during an iteration and its current strategy when it
+// was touched during the previous iteration, which is smoothed by an exponential
+// moving average over iterations. InfoSets are identified by key, so the wrapped
+// profile may return a new NodePolicy on each call to GetPolicy.
+type SteadyStateProfile struct {
+	StrategyProfile
+	params        SteadyStateParams
+	onSteadyState func(iter int)
+
+	mx sync.Mutex
+	// Current strategies of the InfoSets touched during this and the previous
+	// iteration, by key, and the total change in those touched during both.
+	touched     map[string][]float32
+	previous    map[string][]float32
+	totalChange float64
+	numChanged  int
+	avgChange   float64
+	stableIters int
+	steady      bool
+}
+
+// NewSteadyStateProfile returns a new SteadyStateProfile wrapping the given profile,
+// which calls onSteadyState with the current iteration once steady state is reached.
+func NewSteadyStateProfile(profile StrategyProfile, params SteadyStateParams, onSteadyState func(iter int)) *SteadyStateProfile {
+	return &SteadyStateProfile{
+		StrategyProfile: profile,
+		params:          params.withDefaults(),
+		onSteadyState:   onSteadyState,
+		touched:         make(map[string][]float32),
+		avgChange:       1.0,
+	}
+}
+
+// GetPolicy implements StrategyProfile. It is safe to call concurrently
+// if the wrapped StrategyProfile is.
+func (p *SteadyStateProfile) GetPolicy(node GameTreeNode) NodePolicy {
+	policy := p.StrategyProfile.GetPolicy(node)
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if p.steady {
+		return policy
+	}
+
+	key := node.InfoSet(node.Player()).Key()
+	if _, ok := p.touched[key]; !ok {
+		strategy := make([]float32, node.NumChildren())
+		policy.CopyStrategy(strategy)
+		p.touched[key] = strategy
+		if before, ok := p.previous[key]; ok {
+			p.totalChange += l1Distance(before, strategy)
+			p.numChanged++
+		}
+	}
+
+	return policy
+}
+
+// Update implements StrategyProfile.
+func (p *SteadyStateProfile) Update() {
+	p.StrategyProfile.Update()
+
+	p.mx.Lock()
+	if p.steady {
+		p.mx.Unlock()
+		return
+	}
+
+	p.previous, p.touched = p.touched, make(map[string][]float32)
+	if p.numChanged == 0 {
+		p.mx.Unlock()
+		return
+	}
+
+	change := p.totalChange / float64(p.numChanged)
+	p.totalChange, p.numChanged = 0, 0
+	p.avgChange += p.params.Smoothing * (change - p.avgChange)
+	if p.avgChange < p.params.Tolerance {
+		p.stableIters++
+	} else {
+		p.stableIters = 0
+	}
+
+	iter := p.Iter()
+	p.steady = iter >= p.params.MinIter && p.stableIters >= p.params.Patience
+	p.mx.Unlock()
+
+	if p.steady && p.onSteadyState != nil {
+		p.onSteadyState(iter)
+	}
+}
+
+// AvgChange returns the current moving average of the per-iteration change
+// in current strategies.
+func (p *SteadyStateProfile) AvgChange() float64 {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return p.avgChange
+}
+
+// IsSteady returns true if steady state has been reached.
+func (p *SteadyStateProfile) IsSteady() bool {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return p.steady
+}
+
+// l1Distance returns the L1 distance between strategies x and y.
+func l1Distance(x, y []float32) float64 {
+	var total float64
+	for i := range x {
+		d := float64(x[i] - y[i])
+		if d < 0 {
+			d = -d
+		}
+
+		total += d
+	}
+
+	return total
+}
@@ -0,0 +1,80 @@
+package cfr_test
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+)
+
+func TestSteadyStateSwitchToCFRPlus(t *testing.T) {
+	params := cfr.DiscountParams{Schedule: cfr.DefaultDCFRSchedule}
+	policy := cfr.NewPolicyTable(params)
+	switchedAt := 0
+	profile := cfr.NewSteadyStateProfile(policy, cfr.SteadyStateParams{
+		Smoothing: 0.1,
+		Tolerance: 5e-3,
+		Patience:  10,
+	}, func(iter int) {
+		switchedAt = iter
+		policy.SetDiscountParams(cfr.DiscountParams{UseRegretMatchingPlus: true})
+	})
+
+	opt := cfr.New(profile)
+	testCFR(t, opt, profile, 10000)
+	if !profile.IsSteady() {
+		t.Fatalf("expected steady state, avg change = %v", profile.AvgChange())
+	}
+
+	t.Logf("Switched to CFR+ at iter %d", switchedAt)
+	if switchedAt == 0 || switchedAt >= 10000 {
+		t.Errorf("expected to switch during training, got iter %d", switchedAt)
+	}
+}
+
+// snapshotProfile returns a new NodePolicy on each call to GetPolicy, whose
+// current strategy is a copy taken at the time of the call, as a profile
+// stored on disk would.
+type snapshotProfile struct {
+	*cfr.PolicyTable
+}
+
+type snapshotPolicy struct {
+	cfr.NodePolicy
+	strategy []float32
+}
+
+func (p snapshotProfile) GetPolicy(node cfr.GameTreeNode) cfr.NodePolicy {
+	policy := p.PolicyTable.GetPolicy(node)
+	strategy := make([]float32, node.NumChildren())
+	policy.CopyStrategy(strategy)
+	return &snapshotPolicy{policy, strategy}
+}
+
+func (p *snapshotPolicy) GetStrategy() []float32 {
+	return p.strategy
+}
+
+func (p *snapshotPolicy) CopyStrategy(dst []float32) {
+	copy(dst, p.strategy)
+}
+
+func TestSteadyStateSnapshotPolicies(t *testing.T) {
+	steadyAt := func(wrap func(*cfr.PolicyTable) cfr.StrategyProfile) int {
+		policy := cfr.NewPolicyTable(cfr.DiscountParams{Schedule: cfr.DefaultDCFRSchedule})
+		steadyAt := 0
+		profile := cfr.NewSteadyStateProfile(wrap(policy), cfr.SteadyStateParams{
+			Smoothing: 0.1,
+			Tolerance: 5e-3,
+			Patience:  10,
+		}, func(iter int) { steadyAt = iter })
+
+		runCFR(t, cfr.New(profile), profile, 5000)
+		return steadyAt
+	}
+
+	expected := steadyAt(func(pt *cfr.PolicyTable) cfr.StrategyProfile { return pt })
+	got := steadyAt(func(pt *cfr.PolicyTable) cfr.StrategyProfile { return snapshotProfile{pt} })
+	if expected == 0 || got != expected {
+		t.Errorf("expected steady state at iter %d with snapshot policies, got %d", expected, got)
+	}
+}
@@ -0,0 +1,132 @@
+package cfr
+
+import (
+	"time"
+)
+
+// Runner is the interface implemented by all CFR algorithms.
+type Runner interface {
+	Run(node GameTreeNode) float32
+}
+
+// Number of times per budget that SolveFor checks the clock.
+const checksPerBudget = 100
+
+// Trainer repeatedly runs a CFR algorithm on a single game tree, updating its
+// strategy profile after each iteration.
+type Trainer struct {
+	root    GameTreeNode
+	profile StrategyProfile
+	runner  Runner
+
+	// Adaptive checkpointing. See SetCheckpointer.
+	save           func(StrategyProfile) error
+	maxOverhead    float64
+	lastCheckpoint time.Time
+	checkpointCost time.Duration
+}
+
+// NewTrainer returns a new Trainer that trains the given profile with runner
+// on the game tree rooted at root.
+func NewTrainer(root GameTreeNode, profile StrategyProfile, runner Runner) *Trainer {
+	return &Trainer{
+		root:    root,
+		profile: profile,
+		runner:  runner,
+	}
+}
+
+// SetCheckpointer enables adaptive checkpointing, in which save is called with the
+// profile between iterations as often as possible while the time spent saving is
+// at most maxOverhead (e.g. 0.1) of the time spent training, which must be positive.
+// The interval between checkpoints therefore adapts to the cost of saving the
+// profile, which grows as it accumulates InfoSets. A nil save disables checkpointing.
+func (t *Trainer) SetCheckpointer(save func(StrategyProfile) error, maxOverhead float64) {
+	t.save = save
+	t.maxOverhead = maxOverhead
+	t.lastCheckpoint = time.Now()
+	t.checkpointCost = 0
+}
+
+// Train runs nIter iterations. It returns an error if a checkpoint fails.
+func (t *Trainer) Train(nIter int) error {
+	for i := 0; i < nIter; i++ {
+		t.runner.Run(t.root)
+		t.profile.Update()
+		if err := t.maybeCheckpoint(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Checkpoint saves the profile with the checkpointer, if any.
+func (t *Trainer) Checkpoint() error {
+	if t.save == nil {
+		return nil
+	}
+
+	start := time.Now()
+	err := t.save(t.profile)
+	t.lastCheckpoint = time.Now()
+	t.checkpointCost = t.lastCheckpoint.Sub(start)
+	return err
+}
+
+// maybeCheckpoint saves a checkpoint if enough time has been spent training since
+// the last one that the cost of the last checkpoint is within the max overhead.
+func (t *Trainer) maybeCheckpoint() error {
+	if t.save == nil {
+		return nil
+	}
+
+	interval := time.Duration(float64(t.checkpointCost) / t.maxOverhead)
+	if time.Since(t.lastCheckpoint) < interval {
+		return nil
+	}
+
+	return t.Checkpoint()
+}
+
+// SolveFor runs as many iterations as fit in the given wall-clock budget,
+// and returns the strategy profile, whose average strategy is the best
+// available solution. It is intended for real-time (e.g. subgame) solving,
+// where a strategy is required by a deadline.
+//
+// Iterations are run in batches, after each of which the profile is in a
+// consistent state. The size of each batch is adapted to the observed time
+// per iteration, so that the clock is checked about 100 times per budget, and
+// no batch is started that is expected to overrun the budget. The budget may
+// therefore be exceeded by at most the variation in the time of one batch.
+// At least one iteration is always run. Checkpoints (see SetCheckpointer)
+// count against the budget, and training stops if one fails.
+func (t *Trainer) SolveFor(budget time.Duration) (StrategyProfile, error) {
+	start := time.Now()
+	deadline := start.Add(budget)
+	batchSize := 1
+	nIter := 0
+	for {
+		if err := t.Train(batchSize); err != nil {
+			return t.profile, err
+		}
+
+		nIter += batchSize
+
+		now := time.Now()
+		perIter := now.Sub(start) / time.Duration(nIter)
+		batchSize = 1
+		if perIter > 0 {
+			batchSize = max(int(budget/checksPerBudget/perIter), 1)
+		}
+
+		remaining := deadline.Sub(now)
+		if remaining < time.Duration(batchSize)*perIter {
+			batchSize = int(remaining / max(perIter, 1))
+		}
+
+		if batchSize <= 0 {
+			return t.profile, nil
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package cfr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestTrainer_Checkpointer(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	trainer := cfr.NewTrainer(kuhn.NewGame(), policy, cfr.New(policy))
+
+	var iters []int
+	var saveTime, maxCost time.Duration
+	trainer.SetCheckpointer(func(profile cfr.StrategyProfile) error {
+		start := time.Now()
+		iters = append(iters, profile.Iter())
+		time.Sleep(time.Millisecond)
+		cost := time.Since(start)
+		saveTime += cost
+		maxCost = max(maxCost, cost)
+		return nil
+	}, 0.1)
+
+	start := time.Now()
+	if err := trainer.Train(2000); err != nil {
+		t.Fatal(err)
+	}
+
+	elapsed := time.Since(start)
+	if len(iters) < 2 {
+		t.Fatalf("expected multiple checkpoints, got %d", len(iters))
+	}
+
+	// The first checkpoint is saved before its cost is known.
+	if saveTime > time.Duration(0.1*float64(elapsed))+maxCost {
+		t.Errorf("expected at most 10%% of %v to be spent checkpointing, got %v in %d checkpoints",
+			elapsed, saveTime, len(iters))
+	}
+}
+
+func TestTrainer_SolveFor(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	trainer := cfr.NewTrainer(root, policy, cfr.New(policy))
+
+	budget := 500 * time.Millisecond
+	start := time.Now()
+	profile, err := trainer.SolveFor(budget)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elapsed := time.Since(start)
+	t.Logf("Ran %d iterations in %v", profile.Iter(), elapsed)
+	if elapsed > budget+100*time.Millisecond {
+		t.Errorf("expected to return within budget of %v, took %v", budget, elapsed)
+	}
+
+	if exploitability := cfr.Exploitability(root, profile); exploitability > 0.01 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
@@ -0,0 +1,188 @@
+package cfr
+
+import (
+	"fmt"
+
+	"github.com/timpalpant/go-cfr/internal/policy"
+)
+
+// KeyTranslation maps the key of an InfoSet in an old game (or abstraction)
+// to the keys of the InfoSets in the new game that it corresponds to.
+// Returning no keys drops the old InfoSet.
+type KeyTranslation func(oldKey string) (newKeys []string)
+
+// TransferPolicyTable initializes a new PolicyTable with the given params
+// from the accumulated regrets and strategy sums of an old PolicyTable, so that
+// a change to the game rules or abstraction does not require training from scratch.
+//
+// When an old InfoSet is split into several new InfoSets, its regrets and
+// strategy sums are divided evenly between them. When several old InfoSets
+// are merged into a single new InfoSet, their regrets and strategy sums are summed,
+// since counterfactual regret is additive over the histories in an InfoSet.
+// Old and new InfoSets must have the same number of actions.
+func TransferPolicyTable(old *PolicyTable, params DiscountParams, translate KeyTranslation) (*PolicyTable, error) {
+	pt := NewPolicyTable(params)
+	pt.iter = old.iter
+	pt.strategyWeight = old.strategyWeight
+	var err error
+	old.policiesByKey.Range(func(oldKey string, oldPolicy *policy.Policy) bool {
+		newKeys := translate(oldKey)
+		w := 1.0 / float32(len(newKeys))
+		for _, newKey := range newKeys {
+			np, ok := pt.policiesByKey.Get(newKey)
+			if !ok {
+				np = pt.newPolicy(oldPolicy.NumActions())
+				pt.policiesByKey.Put(newKey, np)
+			} else if np.NumActions() != oldPolicy.NumActions() {
+				err = fmt.Errorf("cannot merge infoset %q with n_actions=%v into %q with n_actions=%v",
+					oldKey, oldPolicy.NumActions(), newKey, np.NumActions())
+				return false
+			}
+
+			np.AddWeighted(w, oldPolicy)
+		}
+
+		return true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	numInfosets.Set(int64(pt.policiesByKey.Len()))
+	return pt, nil
+}
+
+// KeyCoarsening maps the key of an InfoSet in a refined abstraction to the
+// key of the InfoSet of a coarser abstraction that contains it.
+type KeyCoarsening func(newKey string) (oldKey string)
+
+// refinedInfoSet accumulates the reach of an InfoSet of a refined abstraction.
+type refinedInfoSet struct {
+	oldKey   string
+	nActions int
+	// Reach of the InfoSet by the opponent and chance (its counterfactual
+	// reach), and by the acting player and chance.
+	cfReach, reach float64
+}
+
+// RefinePolicyTable initializes a new PolicyTable with the given params for a refined
+// abstraction of the game rooted at root, in which each InfoSet of the old (coarser)
+// abstraction is split into the InfoSets given by coarsen. This avoids restarting
+// training from scratch when an abstraction is refined.
+//
+// Counterfactual regret is additive over the histories in an InfoSet, each weighted
+// by its counterfactual reach. The regrets of each old InfoSet are therefore divided
+// between its new InfoSets in proportion to their counterfactual reach, and its
+// strategy sums in proportion to the reach of the acting player, rather than evenly
+// as by TransferPolicyTable. Both are computed with the average strategy of the
+// old PolicyTable, by enumerating the game tree rooted at root, which must be the
+// refined game. New InfoSets with no reach share evenly in their old InfoSet.
+func RefinePolicyTable(old *PolicyTable, root GameTreeNode, params DiscountParams, coarsen KeyCoarsening) (*PolicyTable, error) {
+	refined := make(map[string]*refinedInfoSet)
+	if err := collectRefinedReach(old, root, coarsen, [2]float64{1, 1}, 1, refined); err != nil {
+		return nil, err
+	}
+
+	// Total reach and number of new InfoSets of each old InfoSet.
+	type total struct {
+		cfReach, reach float64
+		n              int
+	}
+	totals := make(map[string]*total)
+	for _, is := range refined {
+		t, ok := totals[is.oldKey]
+		if !ok {
+			t = &total{}
+			totals[is.oldKey] = t
+		}
+
+		t.cfReach += is.cfReach
+		t.reach += is.reach
+		t.n++
+	}
+
+	pt := NewPolicyTable(params)
+	pt.iter = old.iter
+	pt.strategyWeight = old.strategyWeight
+	for newKey, is := range refined {
+		oldPolicy, ok := old.policiesByKey.Get(is.oldKey)
+		if !ok {
+			continue
+		}
+
+		t := totals[is.oldKey]
+		np := pt.newPolicy(is.nActions)
+		np.AddWeightedSums(
+			share(is.cfReach, t.cfReach, t.n), oldPolicy.GetRegretSum(),
+			share(is.reach, t.reach, t.n), oldPolicy.GetStrategySum())
+		pt.policiesByKey.Put(newKey, np)
+	}
+
+	numInfosets.Set(int64(pt.policiesByKey.Len()))
+	return pt, nil
+}
+
+// share returns the fraction x of total, or 1/n if total is zero.
+func share(x, total float64, n int) float32 {
+	if total <= 0 {
+		return 1.0 / float32(n)
+	}
+
+	return float32(x / total)
+}
+
+// collectRefinedReach enumerates the game tree rooted at node, accumulating the
+// reach of each of its InfoSets with the average strategies of the old PolicyTable.
+func collectRefinedReach(old *PolicyTable, node GameTreeNode, coarsen KeyCoarsening, reach [2]float64, reachChance float64, refined map[string]*refinedInfoSet) error {
+	defer node.Close()
+	switch node.Type() {
+	case TerminalNodeType:
+		return nil
+	case ChanceNodeType:
+		for i := 0; i < node.NumChildren(); i++ {
+			p := node.GetChildProbability(i)
+			if err := collectRefinedReach(old, node.GetChild(i), coarsen, reach, p*reachChance, refined); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	player := node.Player()
+	nChildren := node.NumChildren()
+	if nChildren == 1 {
+		// Nodes with no real choice have no policy.
+		return collectRefinedReach(old, node.GetChild(0), coarsen, reach, reachChance, refined)
+	}
+
+	newKey := node.InfoSet(player).Key()
+	is, ok := refined[newKey]
+	if !ok {
+		is = &refinedInfoSet{oldKey: coarsen(newKey), nActions: nChildren}
+		refined[newKey] = is
+	}
+
+	strategy := uniformStrategy(nChildren)
+	if oldPolicy, ok := old.policiesByKey.Get(is.oldKey); ok {
+		if oldPolicy.NumActions() != nChildren {
+			return fmt.Errorf("cannot refine infoset %q with n_actions=%v into %q with n_actions=%v",
+				is.oldKey, oldPolicy.NumActions(), newKey, nChildren)
+		}
+
+		strategy = oldPolicy.GetAverageStrategy()
+	}
+
+	is.cfReach += reach[1-player] * reachChance
+	is.reach += reach[player] * reachChance
+	for i, p := range strategy {
+		childReach := reach
+		childReach[player] *= float64(p)
+		if err := collectRefinedReach(old, node.GetChild(i), coarsen, childReach, reachChance, refined); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
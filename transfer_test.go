@@ -0,0 +1,170 @@
+package cfr_test
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+func TestTransferPolicyTable(t *testing.T) {
+	root := kuhn.NewGame()
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(policy)
+	runCFR(t, opt, policy, 100)
+
+	identity := func(key string) []string { return []string{key} }
+	transferred, err := cfr.TransferPolicyTable(policy, cfr.DiscountParams{}, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if transferred.Iter() != policy.Iter() {
+		t.Errorf("expected iter %d, got %d", policy.Iter(), transferred.Iter())
+	}
+
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		p1 := policy.GetPolicy(node).GetStrategy()
+		p2 := transferred.GetPolicy(node).GetStrategy()
+		if !reflect.DeepEqual(p1, p2) {
+			t.Errorf("expected %v, got %v", p1, p2)
+		}
+	})
+}
+
+// coarseNode abstracts Kuhn poker by merging the InfoSets of player 1
+// after player 0 checks and bets.
+type coarseNode struct {
+	cfr.GameTreeNode
+}
+
+func (n coarseNode) GetChild(i int) cfr.GameTreeNode {
+	return coarseNode{n.GameTreeNode.GetChild(i)}
+}
+
+func (n coarseNode) InfoSet(player int) cfr.InfoSet {
+	is := n.GameTreeNode.InfoSet(player)
+	coarse := coarseInfoSet(coarsenHistory(kuhnHistory(is)) + "-" + kuhnCard(is).String())
+	return &coarse
+}
+
+type coarseInfoSet string
+
+func (is coarseInfoSet) Key() string                     { return string(is) }
+func (is coarseInfoSet) MarshalBinary() ([]byte, error)  { return []byte(is), nil }
+func (is *coarseInfoSet) UnmarshalBinary(b []byte) error { *is = coarseInfoSet(b); return nil }
+
+func coarsenHistory(history string) string {
+	if len(history) == 3 {
+		return "rr*"
+	}
+
+	return history
+}
+
+func TestRefinePolicyTable(t *testing.T) {
+	coarse := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.New(coarse)
+	coarseRoot := coarseNode{kuhn.NewGame()}
+	for i := 0; i < 1000; i++ {
+		opt.Run(coarseRoot)
+		coarse.Update()
+	}
+
+	coarsen := func(key string) string {
+		parts := strings.SplitN(key, "-", 2)
+		return coarsenHistory(parts[0]) + "-" + parts[1]
+	}
+
+	root := kuhn.NewGame()
+	refined, err := cfr.RefinePolicyTable(coarse, root, cfr.DiscountParams{}, coarsen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if refined.Iter() != coarse.Iter() {
+		t.Errorf("expected iter %d, got %d", coarse.Iter(), refined.Iter())
+	}
+
+	// The regrets of each coarse InfoSet are divided between its refinements.
+	type regretSummer interface {
+		GetRegretSum() []float32
+	}
+
+	seen := make(map[string]bool)
+	refinedSums := make(map[string][]float32)
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		key := node.InfoSet(node.Player()).Key()
+		if node.Type() != cfr.PlayerNodeType || seen[key] {
+			return
+		}
+
+		seen[key] = true
+		regrets := refined.GetPolicy(node).(regretSummer).GetRegretSum()
+		sum := refinedSums[coarsen(key)]
+		if sum == nil {
+			sum = make([]float32, len(regrets))
+			refinedSums[coarsen(key)] = sum
+		}
+
+		for i, r := range regrets {
+			sum[i] += r
+		}
+	})
+
+	tree.Visit(coarseRoot, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		expected := coarse.GetPolicy(node).(regretSummer).GetRegretSum()
+		for i, r := range refinedSums[key] {
+			if math.Abs(float64(r-expected[i])) > 1e-3*math.Max(1, math.Abs(float64(expected[i]))) {
+				t.Errorf("%s: expected refined regrets to sum to %v, got %v", key, expected, refinedSums[key])
+				break
+			}
+		}
+	})
+
+	// In particular, player 1's regrets after a check are the fraction
+	// of the coarse regrets given by the probability that player 0 checks.
+	strategies := make(map[string][]float32)
+	coarseSums := make(map[string][]float32)
+	tree.Visit(coarseRoot, func(node cfr.GameTreeNode) {
+		if node.Type() == cfr.PlayerNodeType {
+			key := node.InfoSet(node.Player()).Key()
+			strategies[key] = coarse.GetPolicy(node).GetAverageStrategy()
+			coarseSums[key] = coarse.GetPolicy(node).(regretSummer).GetRegretSum()
+		}
+	})
+
+	regretSums := make(map[string][]float32)
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() == cfr.PlayerNodeType {
+			regretSums[node.InfoSet(node.Player()).Key()] = refined.GetPolicy(node).(regretSummer).GetRegretSum()
+		}
+	})
+
+	for _, card := range []kuhn.Card{kuhn.Jack, kuhn.Queen, kuhn.King} {
+		var pCheck float32
+		for _, other := range []kuhn.Card{kuhn.Jack, kuhn.Queen, kuhn.King} {
+			if other != card {
+				pCheck += strategies["rr-"+other.String()][0] / 2
+			}
+		}
+
+		expected := pCheck * coarseSums["rr*-"+card.String()][0]
+		if got := regretSums["rrc-"+card.String()][0]; math.Abs(float64(got-expected)) > 1e-3 {
+			t.Errorf("rrc-%v: expected regret %v, got %v", card, expected, got)
+		}
+	}
+}
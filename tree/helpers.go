@@ -53,3 +53,37 @@ func CountInfoSets(root cfr.GameTreeNode) int {
 	VisitInfoSets(root, func(player int, infoSet cfr.InfoSet) { total++ })
 	return total
 }
+
+// GetStats computes statistics for the game tree rooted at root
+// by enumerating all of its nodes.
+func GetStats(root cfr.GameTreeNode) cfr.TreeStats {
+	var stats cfr.TreeStats
+	seen := make(map[string]struct{})
+	getStatsHelper(root, 0, &stats, seen)
+	stats.NumInfoSets = len(seen)
+	return stats
+}
+
+func getStatsHelper(node cfr.GameTreeNode, depth int, stats *cfr.TreeStats, seen map[string]struct{}) {
+	if depth > stats.MaxDepth {
+		stats.MaxDepth = depth
+	}
+
+	if node.Type() == cfr.PlayerNodeType {
+		if n := node.NumChildren(); n > stats.MaxBranching {
+			stats.MaxBranching = n
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			stats.KeyBytes += len(key)
+		}
+	}
+
+	for i := 0; i < node.NumChildren(); i++ {
+		getStatsHelper(node.GetChild(i), depth+1, stats, seen)
+	}
+
+	node.Close()
+}
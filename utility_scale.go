@@ -0,0 +1,21 @@
+package cfr
+
+// UtilityScale is the typical magnitude of the utilities of each player, which
+// MCCFR (and the runners built on it) uses to normalize utilities during traversal
+// (see MCCFR.SetUtilityScale). In games where the payoffs of one player are much
+// larger than those of the other, this keeps the regrets of both players in a
+// similar range, which reduces the loss of precision in float32 accumulators
+// for the smaller player.
+//
+// Regret matching is invariant to scaling all of the regrets of an InfoSet, so
+// the strategies computed are unchanged. A zero entry is treated as one.
+type UtilityScale [2]float32
+
+// get returns the scale of the given player.
+func (s UtilityScale) get(player int) float32 {
+	if s[player] == 0 {
+		return 1.0
+	}
+
+	return s[player]
+}
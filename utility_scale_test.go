@@ -0,0 +1,32 @@
+package cfr_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestExternalSamplingUtilityScale(t *testing.T) {
+	policy := cfr.NewPolicyTable(cfr.DiscountParams{})
+	opt := cfr.NewExternalSampling(policy)
+	opt.SetUtilityScale(cfr.UtilityScale{100, 0.01})
+	root := kuhn.NewGame()
+	var expectedValue float32
+	nIter := 10000
+	for i := 0; i < nIter; i++ {
+		expectedValue += opt.Run(root)
+		policy.Update()
+	}
+
+	// Values are returned in the units of the game, as without scaling.
+	expectedValue /= float32(nIter)
+	if math.Abs(float64(expectedValue)-1.0/18) > 0.05 {
+		t.Errorf("expected game value near 1/18, got %v", expectedValue)
+	}
+
+	if exploitability := cfr.Exploitability(root, policy); exploitability > 0.05 {
+		t.Errorf("expected exploitability near 0, got %v", exploitability)
+	}
+}
@@ -6,25 +6,84 @@ import (
 
 type CFR struct {
 	strategyProfile StrategyProfile
-	slicePool       *floatSlicePool
+	slicePool       SlicePool
+
+	// Map of ChanceKey -> outcomes of static chance nodes, if caching is enabled.
+	chanceOutcomes map[string]*chanceOutcomes
+	partialPruning bool
+
+	// Depth limit and evaluator of leaf nodes, if set, and the
+	// number of player actions above the node being traversed.
+	maxDepth           int
+	leafEvaluator      LeafEvaluator
+	multiLeafEvaluator MultiValuedLeafEvaluator
+	depth              int
+}
+
+// chanceOutcomes are the cached children of a StaticChanceNode.
+type chanceOutcomes struct {
+	children      []GameTreeNode
+	probabilities []float32
 }
 
 func New(strategyProfile StrategyProfile) *CFR {
 	return &CFR{
 		strategyProfile: strategyProfile,
-		slicePool:       &floatSlicePool{},
+		slicePool:       NewFloatSlicePool(SlicePoolParams{}),
 	}
 }
 
+// SetSlicePool sets the pool used to allocate temporary slices during
+// traversal. A single pool may be shared by multiple runners.
+func (c *CFR) SetSlicePool(pool SlicePool) {
+	c.slicePool = pool
+}
+
+// SetCacheChanceOutcomes enables or disables caching of the outcomes of chance
+// nodes that implement StaticChanceNode, which avoids rebuilding their children
+// on every iteration. Disabling it releases the cached outcomes.
+func (c *CFR) SetCacheChanceOutcomes(enabled bool) {
+	if !enabled {
+		c.chanceOutcomes = nil
+	} else if c.chanceOutcomes == nil {
+		c.chanceOutcomes = make(map[string]*chanceOutcomes)
+	}
+}
+
+// SetPartialPruning enables or disables skipping subtrees that cannot affect
+// any regret or strategy sum on this iteration: those with zero chance reach, or
+// in which both players have zero reach. Because both players are updated on each
+// traversal, the reach of the traverser cannot be ignored as in alternating updates.
+//
+// The results are unchanged, except that DiscountParams which discount regrets
+// are not applied to InfoSets that were only reached in pruned subtrees, as with
+// Monte Carlo runners.
+func (c *CFR) SetPartialPruning(enabled bool) {
+	c.partialPruning = enabled
+}
+
 func (c *CFR) Run(node GameTreeNode) float32 {
 	return c.runHelper(node, node.Player(), 1.0, 1.0, 1.0)
 }
 
 func (c *CFR) runHelper(node GameTreeNode, lastPlayer int, reachP0, reachP1, reachChance float32) float32 {
+	if c.partialPruning && canPrune(reachP0, reachP1, reachChance) {
+		// The value of a pruned subtree is always weighted by zero in its
+		// ancestors, since the action leading to it had zero probability.
+		node.Close()
+		return 0
+	}
+
+	if c.atDepthLimit(node) {
+		ev := c.evaluateLeaf(node, lastPlayer, reachP0, reachP1, reachChance)
+		node.Close()
+		return ev
+	}
+
 	var ev float32
 	switch node.Type() {
 	case TerminalNodeType:
-		ev = float32(node.Utility(lastPlayer))
+		ev = float32(terminalUtility(node, lastPlayer))
 	case ChanceNodeType:
 		ev = c.handleChanceNode(node, lastPlayer, reachP0, reachP1, reachChance)
 	default:
@@ -32,21 +91,74 @@ func (c *CFR) runHelper(node GameTreeNode, lastPlayer int, reachP0, reachP1, rea
 		ev = sgn * c.handlePlayerNode(node, reachP0, reachP1, reachChance)
 	}
 
+	if Debug {
+		assertUtilityBounds(node, float64(ev))
+	}
+
 	node.Close()
 	return ev
 }
 
 func (c *CFR) handleChanceNode(node GameTreeNode, lastPlayer int, reachP0, reachP1, reachChance float32) float32 {
+	if outcomes := c.getChanceOutcomes(node); outcomes != nil {
+		var expectedValue float32
+		for i, child := range outcomes.children {
+			p := outcomes.probabilities[i]
+			expectedValue += p * c.runHelper(child, lastPlayer, reachP0, reachP1, reachChance*p)
+		}
+
+		return expectedValue
+	}
+
+	children := prefetch(c.strategyProfile, node)
+	defer children.Close()
 	var expectedValue float32
 	for i := 0; i < node.NumChildren(); i++ {
-		child := node.GetChild(i)
 		p := float32(node.GetChildProbability(i))
+		child := children.GetChild(i)
 		expectedValue += p * c.runHelper(child, lastPlayer, reachP0, reachP1, reachChance*p)
 	}
 
 	return expectedValue
 }
 
+// getChanceOutcomes returns the cached outcomes of node, enumerating them if
+// necessary, or nil if caching is disabled or node is not a StaticChanceNode.
+// Children of nodes that share state cannot all be open at once, and are not cached.
+func (c *CFR) getChanceOutcomes(node GameTreeNode) *chanceOutcomes {
+	if c.chanceOutcomes == nil {
+		return nil
+	}
+
+	static, ok := node.(StaticChanceNode)
+	if !ok {
+		return nil
+	}
+
+	if s, ok := node.(SharedStateNode); ok && s.SharesState() {
+		return nil
+	}
+
+	key := static.ChanceKey()
+	outcomes, ok := c.chanceOutcomes[key]
+	if !ok {
+		n := node.NumChildren()
+		outcomes = &chanceOutcomes{
+			children:      make([]GameTreeNode, n),
+			probabilities: make([]float32, n),
+		}
+
+		for i := 0; i < n; i++ {
+			outcomes.children[i] = node.GetChild(i)
+			outcomes.probabilities[i] = float32(node.GetChildProbability(i))
+		}
+
+		c.chanceOutcomes[key] = outcomes
+	}
+
+	return outcomes
+}
+
 func (c *CFR) handlePlayerNode(node GameTreeNode, reachP0, reachP1, reachChance float32) float32 {
 	player := node.Player()
 	nChildren := node.NumChildren()
@@ -57,18 +169,23 @@ func (c *CFR) handlePlayerNode(node GameTreeNode, reachP0, reachP1, reachChance
 	}
 
 	policy := c.strategyProfile.GetPolicy(node)
+	children := prefetch(c.strategyProfile, node)
+	defer children.Close()
 	strategy := policy.GetStrategy()
-	regrets := c.slicePool.alloc(nChildren)
-	defer c.slicePool.free(regrets)
+	regrets := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(regrets)
+	c.depth++
+	defer func() { c.depth-- }()
 	var cfValue float32
 	for i := 0; i < nChildren; i++ {
-		child := node.GetChild(i)
 		p := strategy[i]
 		var util float32
-		if player == 0 {
-			util = c.runHelper(child, player, p*reachP0, reachP1, reachChance)
+		if u, ok := terminalChildUtility(node, i, player); ok {
+			util = u
+		} else if player == 0 {
+			util = c.runHelper(children.GetChild(i), player, p*reachP0, reachP1, reachChance)
 		} else {
-			util = c.runHelper(child, player, reachP0, p*reachP1, reachChance)
+			util = c.runHelper(children.GetChild(i), player, reachP0, p*reachP1, reachChance)
 		}
 
 		regrets[i] = util
@@ -79,8 +196,8 @@ func (c *CFR) handlePlayerNode(node GameTreeNode, reachP0, reachP1, reachChance
 	// subtracting out the expected utility over all possible actions.
 	f32.AddConst(-cfValue, regrets)
 	counterFactualP := counterFactualProb(player, reachP0, reachP1, reachChance)
-	ones := c.slicePool.alloc(nChildren)
-	defer c.slicePool.free(ones)
+	ones := c.slicePool.Alloc(nChildren)
+	defer c.slicePool.Free(ones)
 	for i := range ones {
 		ones[i] = 1.0
 	}
@@ -90,6 +207,28 @@ func (c *CFR) handlePlayerNode(node GameTreeNode, reachP0, reachP1, reachChance
 	return cfValue
 }
 
+// terminalChildUtility returns the utility of player at child i of a
+// TerminalChildNode, if it is known without constructing the child.
+func terminalChildUtility(node GameTreeNode, i, player int) (float32, bool) {
+	tn, ok := node.(TerminalChildNode)
+	if !ok {
+		return 0, false
+	}
+
+	u, ok := tn.TerminalChildUtility(i, player)
+	if ok && Debug {
+		assertUtilityBounds(node, u)
+	}
+
+	return float32(u), ok
+}
+
+// canPrune returns true if all regret and strategy updates in the subtree
+// with the given reach probabilities would have zero weight.
+func canPrune(reachP0, reachP1, reachChance float32) bool {
+	return reachChance == 0 || (reachP0 == 0 && reachP1 == 0)
+}
+
 func getSign(player1, player2 int) float32 {
 	if player1 == player2 {
 		return 1.0
@@ -0,0 +1,324 @@
+// Package verify implements slow, high-precision reference computations
+// that can be used to check that float32 numerical error is not what is
+// driving the behavior observed during training.
+package verify
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/tree"
+)
+
+// Divergence summarizes the difference between the float32 PolicyTable
+// and the arbitrary-precision reference after one iteration.
+type Divergence struct {
+	Iter int
+	// The largest absolute difference in the current strategy of any InfoSet,
+	// and the key of the InfoSet at which it occurred.
+	MaxStrategyDiff float64
+	MaxStrategyKey  string
+	// The largest absolute difference in the average strategy of any InfoSet,
+	// and the key of the InfoSet at which it occurred.
+	MaxAverageStrategyDiff float64
+	MaxAverageStrategyKey  string
+}
+
+// CompareVanilla runs nIter iterations of vanilla CFR on the game rooted at root
+// using a cfr.PolicyTable, and in lockstep runs the same iterations with all
+// accumulation performed in big.Float with the given precision (in bits).
+// It returns the divergence between the two after each iteration.
+//
+// The reference implements regret matching with the discounting of
+// params.GetDiscountFactors, which covers UseRegretMatchingPlus, LinearWeighting,
+// DiscountAlpha/Beta/Gamma and Schedule. CompensatedSummation and BaselineDecay
+// do not change the result in exact arithmetic (or in vanilla CFR), and so are
+// also supported. An error is returned if any other params are set.
+//
+// This is very slow and is only intended to be used with a small number of
+// iterations (or a small game).
+func CompareVanilla(root cfr.GameTreeNode, params cfr.DiscountParams, nIter int, prec uint) ([]Divergence, error) {
+	if err := checkSupported(params); err != nil {
+		return nil, err
+	}
+
+	pt := cfr.NewPolicyTable(params)
+	opt := cfr.New(pt)
+	ref := newReference(params, prec)
+
+	result := make([]Divergence, 0, nIter)
+	for i := 0; i < nIter; i++ {
+		opt.Run(root)
+		ref.run(root)
+		pt.Update()
+		ref.update()
+		d := compare(root, pt, ref)
+		d.Iter = ref.iter - 1
+		result = append(result, d)
+	}
+
+	return result, nil
+}
+
+// checkSupported returns an error if params sets any
+// options that are not modeled by the reference.
+func checkSupported(params cfr.DiscountParams) error {
+	switch {
+	case params.UsePredictiveRegretMatching:
+		return fmt.Errorf("verify: UsePredictiveRegretMatching is not supported")
+	case params.Minimizer != nil:
+		return fmt.Errorf("verify: Minimizer is not supported")
+	case params.DynamicThreshold != 0:
+		return fmt.Errorf("verify: DynamicThreshold is not supported")
+	case params.DominanceMinSamples > 0:
+		return fmt.Errorf("verify: DominanceMinSamples is not supported")
+	}
+
+	return nil
+}
+
+func compare(root cfr.GameTreeNode, pt *cfr.PolicyTable, ref *reference) Divergence {
+	var d Divergence
+	seen := make(map[string]struct{})
+	tree.Visit(root, func(node cfr.GameTreeNode) {
+		if node.Type() != cfr.PlayerNodeType || node.NumChildren() <= 1 {
+			return
+		}
+
+		key := node.InfoSet(node.Player()).Key()
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+
+		p := pt.GetPolicy(node)
+		bp := ref.getPolicy(key, node.NumChildren())
+		if diff := maxAbsDiff(p.GetStrategy(), bp.currentStrategy); diff > d.MaxStrategyDiff {
+			d.MaxStrategyDiff = diff
+			d.MaxStrategyKey = key
+		}
+
+		if diff := maxAbsDiff(p.GetAverageStrategy(), bp.averageStrategy()); diff > d.MaxAverageStrategyDiff {
+			d.MaxAverageStrategyDiff = diff
+			d.MaxAverageStrategyKey = key
+		}
+	})
+
+	return d
+}
+
+func maxAbsDiff(x []float32, y []*big.Float) float64 {
+	var result float64
+	for i, xi := range x {
+		yi, _ := y[i].Float64()
+		diff := float64(xi) - yi
+		if diff < 0 {
+			diff = -diff
+		}
+
+		if diff > result {
+			result = diff
+		}
+	}
+
+	return result
+}
+
+// reference is a vanilla CFR implementation with big.Float accumulators.
+type reference struct {
+	params   cfr.DiscountParams
+	prec     uint
+	iter     int
+	policies map[string]*bigPolicy
+}
+
+func newReference(params cfr.DiscountParams, prec uint) *reference {
+	return &reference{
+		params:   params,
+		prec:     prec,
+		iter:     1,
+		policies: make(map[string]*bigPolicy),
+	}
+}
+
+func (r *reference) newFloat(x float64) *big.Float {
+	return new(big.Float).SetPrec(r.prec).SetFloat64(x)
+}
+
+func (r *reference) getPolicy(key string, nActions int) *bigPolicy {
+	p, ok := r.policies[key]
+	if !ok {
+		p = &bigPolicy{
+			currentStrategy: make([]*big.Float, nActions),
+			regretSum:       make([]*big.Float, nActions),
+			strategySum:     make([]*big.Float, nActions),
+			weight:          r.newFloat(0),
+		}
+
+		for i := 0; i < nActions; i++ {
+			p.currentStrategy[i] = r.newFloat(1.0 / float64(nActions))
+			p.regretSum[i] = r.newFloat(0)
+			p.strategySum[i] = r.newFloat(0)
+		}
+
+		r.policies[key] = p
+	}
+
+	return p
+}
+
+func (r *reference) run(root cfr.GameTreeNode) {
+	r.runHelper(root, root.Player(), r.newFloat(1), r.newFloat(1), r.newFloat(1))
+}
+
+func (r *reference) runHelper(node cfr.GameTreeNode, lastPlayer int, reachP0, reachP1, reachChance *big.Float) *big.Float {
+	var ev *big.Float
+	switch node.Type() {
+	case cfr.TerminalNodeType:
+		ev = r.newFloat(node.Utility(lastPlayer))
+	case cfr.ChanceNodeType:
+		ev = r.handleChanceNode(node, lastPlayer, reachP0, reachP1, reachChance)
+	default:
+		ev = r.handlePlayerNode(node, reachP0, reachP1, reachChance)
+		if lastPlayer != node.Player() {
+			ev.Neg(ev)
+		}
+	}
+
+	node.Close()
+	return ev
+}
+
+func (r *reference) handleChanceNode(node cfr.GameTreeNode, lastPlayer int, reachP0, reachP1, reachChance *big.Float) *big.Float {
+	ev := r.newFloat(0)
+	for i := 0; i < node.NumChildren(); i++ {
+		child := node.GetChild(i)
+		p := r.newFloat(node.GetChildProbability(i))
+		childReach := r.newFloat(0).Mul(reachChance, p)
+		u := r.runHelper(child, lastPlayer, reachP0, reachP1, childReach)
+		ev.Add(ev, u.Mul(u, p))
+	}
+
+	return ev
+}
+
+func (r *reference) handlePlayerNode(node cfr.GameTreeNode, reachP0, reachP1, reachChance *big.Float) *big.Float {
+	player := node.Player()
+	nChildren := node.NumChildren()
+	if nChildren == 1 {
+		child := node.GetChild(0)
+		return r.runHelper(child, player, reachP0, reachP1, reachChance)
+	}
+
+	key := node.InfoSet(player).Key()
+	policy := r.getPolicy(key, nChildren)
+	regrets := make([]*big.Float, nChildren)
+	cfValue := r.newFloat(0)
+	for i := 0; i < nChildren; i++ {
+		child := node.GetChild(i)
+		p := policy.currentStrategy[i]
+		var util *big.Float
+		if player == 0 {
+			util = r.runHelper(child, player, r.newFloat(0).Mul(p, reachP0), reachP1, reachChance)
+		} else {
+			util = r.runHelper(child, player, reachP0, r.newFloat(0).Mul(p, reachP1), reachChance)
+		}
+
+		regrets[i] = util
+		cfValue.Add(cfValue, r.newFloat(0).Mul(p, util))
+	}
+
+	cfP, reachP := r.newFloat(0), r.newFloat(0)
+	if player == 0 {
+		cfP.Mul(reachP1, reachChance)
+		reachP.Mul(reachP0, reachChance)
+	} else {
+		cfP.Mul(reachP0, reachChance)
+		reachP.Mul(reachP1, reachChance)
+	}
+
+	for i, regret := range regrets {
+		regret.Sub(regret, cfValue)
+		policy.regretSum[i].Add(policy.regretSum[i], regret.Mul(regret, cfP))
+	}
+
+	policy.weight.Add(policy.weight, reachP)
+	return cfValue
+}
+
+func (r *reference) update() {
+	discountPos, discountNeg, discountSum := r.params.GetDiscountFactors(r.iter)
+	dPos := r.newFloat(float64(discountPos))
+	dNeg := r.newFloat(float64(discountNeg))
+	dSum := r.newFloat(float64(discountSum))
+	for _, p := range r.policies {
+		p.nextStrategy(dPos, dNeg, dSum, r.prec)
+	}
+
+	r.iter++
+}
+
+type bigPolicy struct {
+	currentStrategy []*big.Float
+	weight          *big.Float
+	regretSum       []*big.Float
+	strategySum     []*big.Float
+}
+
+func (p *bigPolicy) nextStrategy(discountPos, discountNeg, discountSum *big.Float, prec uint) {
+	for i, s := range p.strategySum {
+		s.Mul(s, discountSum)
+		w := new(big.Float).SetPrec(prec).Mul(p.weight, p.currentStrategy[i])
+		s.Add(s, w)
+	}
+
+	for _, r := range p.regretSum {
+		if r.Sign() > 0 {
+			r.Mul(r, discountPos)
+		} else if r.Sign() < 0 {
+			r.Mul(r, discountNeg)
+		}
+	}
+
+	total := new(big.Float).SetPrec(prec)
+	for i, r := range p.regretSum {
+		if r.Sign() > 0 {
+			p.currentStrategy[i].Set(r)
+			total.Add(total, r)
+		} else {
+			p.currentStrategy[i].SetFloat64(0)
+		}
+	}
+
+	if total.Sign() > 0 {
+		for _, s := range p.currentStrategy {
+			s.Quo(s, total)
+		}
+	} else {
+		for _, s := range p.currentStrategy {
+			s.SetFloat64(1.0 / float64(len(p.currentStrategy)))
+		}
+	}
+
+	p.weight.SetFloat64(0)
+}
+
+func (p *bigPolicy) averageStrategy() []*big.Float {
+	prec := p.weight.Prec()
+	total := new(big.Float).SetPrec(prec)
+	for _, s := range p.strategySum {
+		total.Add(total, s)
+	}
+
+	result := make([]*big.Float, len(p.strategySum))
+	for i, s := range p.strategySum {
+		if total.Sign() > 0 {
+			result[i] = new(big.Float).SetPrec(prec).Quo(s, total)
+		} else {
+			result[i] = new(big.Float).SetPrec(prec).SetFloat64(1.0 / float64(len(p.strategySum)))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,31 @@
+package verify
+
+import (
+	"testing"
+
+	"github.com/timpalpant/go-cfr"
+	"github.com/timpalpant/go-cfr/kuhn"
+)
+
+func TestCompareVanilla(t *testing.T) {
+	root := kuhn.NewGame()
+	result, err := CompareVanilla(root, cfr.DiscountParams{}, 20, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range result {
+		t.Logf("%+v", d)
+		if d.MaxStrategyDiff > 1e-4 || d.MaxAverageStrategyDiff > 1e-4 {
+			t.Errorf("[iter=%d] unexpected divergence: %+v", d.Iter, d)
+		}
+	}
+}
+
+func TestCompareVanilla_Unsupported(t *testing.T) {
+	root := kuhn.NewGame()
+	params := cfr.DiscountParams{UsePredictiveRegretMatching: true}
+	if _, err := CompareVanilla(root, params, 1, 256); err == nil {
+		t.Error("expected error for params not modeled by the reference")
+	}
+}
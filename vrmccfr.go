@@ -11,10 +11,10 @@ type VRMCCFR struct {
 	traversingSampler    Sampler
 	notTraversingSampler Sampler
 
-	slicePool *floatSlicePool
-	mapPool   *keyIntMapPool
-	rng       *rand.Rand
+	arena *arena
+	rng   *rand.Rand
 
+	schedule         PlayerSchedule
 	traversingPlayer int
 	sampledActions   map[string]int
 }
@@ -24,17 +24,28 @@ func NewVRMCCFR(strategyProfile StrategyProfile, traversingSampler, notTraversin
 		strategyProfile:      strategyProfile,
 		traversingSampler:    traversingSampler,
 		notTraversingSampler: notTraversingSampler,
-		slicePool:            &floatSlicePool{},
-		mapPool:              &keyIntMapPool{},
+		arena:                &arena{},
 		rng:                  rand.New(rand.NewSource(rand.Int63())),
 	}
 }
 
+// SetPlayerSchedule sets the PlayerSchedule of the runner.
+func (c *VRMCCFR) SetPlayerSchedule(schedule PlayerSchedule) {
+	c.schedule = schedule
+}
+
+// Run performs a traversal from node for each player of the current
+// iteration's schedule, and returns the mean of their values.
 func (c *VRMCCFR) Run(node GameTreeNode) float32 {
-	iter := c.strategyProfile.Iter()
-	c.traversingPlayer = int(iter % 2)
-	c.sampledActions = c.mapPool.alloc()
-	defer c.mapPool.free(c.sampledActions)
+	return runScheduled(c.schedule, c.strategyProfile.Iter(), func(player int) float32 {
+		c.traversingPlayer = player
+		return c.run(node)
+	})
+}
+
+func (c *VRMCCFR) run(node GameTreeNode) float32 {
+	c.sampledActions = c.arena.allocMap()
+	defer c.arena.reset()
 	return c.runHelper(node, node.Player(), 1.0, 1.0)
 }
 
@@ -42,7 +53,7 @@ func (c *VRMCCFR) runHelper(node GameTreeNode, lastPlayer int, sampleProb, reach
 	var ev float32
 	switch node.Type() {
 	case TerminalNodeType:
-		ev = float32(node.Utility(lastPlayer))
+		ev = float32(terminalUtility(node, lastPlayer))
 	case ChanceNodeType:
 		ev = c.handleChanceNode(node, lastPlayer, sampleProb, reachProb)
 	default:
@@ -78,16 +89,16 @@ func (c *VRMCCFR) handleTraversingPlayerNode(node GameTreeNode, sampleProb, reac
 
 	policy := c.strategyProfile.GetPolicy(node)
 	baseline := policy.GetBaseline()
-	qs := c.slicePool.alloc(nChildren)
+	qs := c.arena.alloc(nChildren)
 	copy(qs, c.traversingSampler.Sample(node, policy))
-	regrets := c.slicePool.alloc(nChildren)
+	regrets := c.arena.alloc(nChildren)
 	oldSampledActions := c.sampledActions
-	c.sampledActions = c.mapPool.alloc()
+	c.sampledActions = c.arena.allocMap()
 
 	for i, q := range qs {
-		child := node.GetChild(i)
 		uHat := baseline[i]
 		if q > 0 {
+			child := node.GetChild(i)
 			u := c.runHelper(child, player, q*sampleProb, reachProb)
 			uHat += (u - baseline[i]) / q
 			policy.UpdateBaseline(1.0/q, i, u)
@@ -100,9 +111,9 @@ func (c *VRMCCFR) handleTraversingPlayerNode(node GameTreeNode, sampleProb, reac
 	f32.AddConst(-cfValue, regrets)
 	policy.AddRegret(reachProb/sampleProb, qs, regrets)
 
-	c.slicePool.free(qs)
-	c.slicePool.free(regrets)
-	c.mapPool.free(c.sampledActions)
+	c.arena.freeMap(c.sampledActions)
+	c.arena.free(regrets)
+	c.arena.free(qs)
 	c.sampledActions = oldSampledActions
 	return cfValue
 }
@@ -122,15 +133,15 @@ func (c *VRMCCFR) handleSampledPlayerNode(node GameTreeNode, sampleProb, reachPr
 		policy.AddStrategyWeight(1.0 / sampleProb)
 	}
 
-	qs := c.slicePool.alloc(nChildren)
+	qs := c.arena.alloc(nChildren)
 	copy(qs, c.notTraversingSampler.Sample(node, policy))
-	regrets := c.slicePool.alloc(nChildren)
+	regrets := c.arena.alloc(nChildren)
 
 	for i, q := range qs {
 		p := strategy[i]
-		child := node.GetChild(i)
 		uHat := baseline[i]
 		if q > 0 {
+			child := node.GetChild(i)
 			u := c.runHelper(child, player, q*sampleProb, p*reachProb)
 			uHat += (u - baseline[i]) / q
 			policy.UpdateBaseline(1.0/q, i, u)
@@ -139,7 +150,7 @@ func (c *VRMCCFR) handleSampledPlayerNode(node GameTreeNode, sampleProb, reachPr
 		regrets[i] = uHat
 	}
 
-	c.slicePool.free(qs)
-	c.slicePool.free(regrets)
+	c.arena.free(regrets)
+	c.arena.free(qs)
 	return f32.DotUnitary(policy.GetStrategy(), regrets)
 }
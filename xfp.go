@@ -0,0 +1,137 @@
+package cfr
+
+import (
+	"fmt"
+)
+
+// strategySummer is implemented by NodePolicies whose strategy sums may be
+// updated directly, such as those of PolicyTable.
+type strategySummer interface {
+	ScaleSums(wRegret, wStrategy float32)
+	AddWeightedSums(wRegret float32, regretSum []float32, wStrategy float32, strategySum []float32)
+}
+
+// StepSize returns the mixing step size of fictitious play on the given
+// iteration, starting from 1: the weight of the new best responses in the
+// average strategy.
+type StepSize func(iter int) float32
+
+// HarmonicStepSize is the step size 1/t of extensive-form fictitious play,
+// with which the average strategy is the uniform average of all best responses.
+func HarmonicStepSize(iter int) float32 {
+	return 1.0 / float32(iter)
+}
+
+// FictitiousPlay implements extensive-form fictitious play (XFP; Heinrich,
+// Lanctot & Silver, 2015) as a baseline to CFR. On each iteration, both players
+// compute an exact best response to the other's average strategy, and the average
+// strategies are then updated towards the best responses. The update of each
+// InfoSet is weighted by the realization probabilities of the average strategy
+// and the best response, so that the average strategy is equivalent to the mixed
+// strategy in which the best response of each iteration is played with
+// probability given by the step sizes.
+//
+// The average strategies are stored in the strategy sums of the StrategyProfile,
+// whose policies must support updating them directly (as those of PolicyTable do),
+// so the exploitability and serialization of the profile work as for CFR. Regrets
+// are not used, so the current strategy of the profile is meaningless.
+// The profile should not discount strategy sums.
+type FictitiousPlay struct {
+	strategyProfile StrategyProfile
+	stepSize        StepSize
+
+	iter int
+	// InfoSets that have been updated on the current iteration.
+	updated map[string]struct{}
+}
+
+// NewFictitiousPlay returns a new XFP runner with HarmonicStepSize.
+func NewFictitiousPlay(strategyProfile StrategyProfile) *FictitiousPlay {
+	return &FictitiousPlay{
+		strategyProfile: strategyProfile,
+		stepSize:        HarmonicStepSize,
+	}
+}
+
+// SetStepSize sets the step sizes of the updates. Step sizes that decrease more
+// slowly than 1/t result in generalized weakened fictitious play, which updates
+// the average strategy more aggressively. The first step size must be 1.
+func (c *FictitiousPlay) SetStepSize(stepSize StepSize) {
+	c.stepSize = stepSize
+}
+
+// Run performs a single iteration of XFP from the root node, and returns the
+// exploitability of the average strategy before the update.
+func (c *FictitiousPlay) Run(node GameTreeNode) float32 {
+	averageStrategy := func(node GameTreeNode) []float32 {
+		return c.strategyProfile.GetPolicy(node).GetAverageStrategy()
+	}
+
+	br := [2]*BestResponse{
+		ComputeBestResponse(node, 0, averageStrategy),
+		ComputeBestResponse(node, 1, averageStrategy),
+	}
+
+	c.iter++
+	c.updated = make(map[string]struct{})
+	c.update(node, br, [2]float32{1, 1}, c.stepSize(c.iter))
+	return float32(br[0].Value()+br[1].Value()) / 2
+}
+
+// update mixes the best responses into the strategy sums of the InfoSets in the
+// subtree rooted at node with step size alpha, where brReach is the realization
+// probability of node under the best response of each player.
+func (c *FictitiousPlay) update(node GameTreeNode, br [2]*BestResponse, brReach [2]float32, alpha float32) {
+	switch node.Type() {
+	case TerminalNodeType:
+	case ChanceNodeType:
+		for i := 0; i < node.NumChildren(); i++ {
+			c.update(node.GetChild(i), br, brReach, alpha)
+		}
+	default:
+		player := node.Player()
+		nChildren := node.NumChildren()
+		action := 0
+		if nChildren > 1 {
+			action = br[player].Action(node)
+			c.mixBestResponse(node, action, brReach[player], alpha)
+		}
+
+		for i := 0; i < nChildren; i++ {
+			childReach := brReach
+			if i != action {
+				childReach[player] = 0
+			}
+
+			c.update(node.GetChild(i), br, childReach, alpha)
+		}
+	}
+
+	node.Close()
+}
+
+// mixBestResponse mixes the best response action into the strategy sums of the
+// InfoSet of node, once per iteration. Since the strategy sums are the realization
+// probabilities of the average strategy, the mixture of the average strategy and
+// the best response is their weighted sum. With perfect recall, the realization
+// probability of the best response is the same for all nodes in the InfoSet.
+func (c *FictitiousPlay) mixBestResponse(node GameTreeNode, action int, brReach, alpha float32) {
+	key := node.InfoSet(node.Player()).Key()
+	if _, ok := c.updated[key]; ok {
+		return
+	}
+	c.updated[key] = struct{}{}
+
+	p := c.strategyProfile.GetPolicy(node)
+	summer, ok := p.(strategySummer)
+	if !ok {
+		panic(fmt.Errorf("cfr: fictitious play requires policies that support updating strategy sums, got %T", p))
+	}
+
+	summer.ScaleSums(1, 1-alpha)
+	if brReach > 0 {
+		strategy := make([]float32, node.NumChildren())
+		strategy[action] = alpha * brReach
+		summer.AddWeightedSums(0, nil, 1, strategy)
+	}
+}